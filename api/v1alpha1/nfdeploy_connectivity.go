@@ -0,0 +1,117 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"sync"
+)
+
+// Allowed values for Site.NFType
+const (
+	UPFNFType  NFType = "upf"
+	SMFNFType  NFType = "smf"
+	AMFNFType  NFType = "amf"
+	AUSFNFType NFType = "ausf"
+	UDMNFType  NFType = "udm"
+)
+
+// ReferencePoint is the 3GPP name of the interface between two NF types,
+// for e.g. N4 between UPF and SMF.
+type ReferencePoint string
+
+const (
+	N3  ReferencePoint = "N3"
+	N4  ReferencePoint = "N4"
+	N6  ReferencePoint = "N6"
+	N7  ReferencePoint = "N7"
+	N8  ReferencePoint = "N8"
+	N9  ReferencePoint = "N9"
+	N10 ReferencePoint = "N10"
+	N11 ReferencePoint = "N11"
+	N12 ReferencePoint = "N12"
+	N13 ReferencePoint = "N13"
+	N14 ReferencePoint = "N14"
+)
+
+// nfTypePair is an unordered pair of NF types
+type nfTypePair struct {
+	first  NFType
+	second NFType
+}
+
+func newNFTypePair(nfType1 NFType, nfType2 NFType) nfTypePair {
+	if nfType1 > nfType2 {
+		nfType1, nfType2 = nfType2, nfType1
+	}
+	return nfTypePair{first: nfType1, second: nfType2}
+}
+
+// ConnectivityMatrix holds the pairs of NF types which are allowed to be
+// connected in an NfDeploy along with the reference point between them.
+// A pair is unordered i.e. registering (upf, smf) also allows (smf, upf).
+// Thread-safe.
+type ConnectivityMatrix struct {
+	mu    sync.RWMutex
+	pairs map[nfTypePair]ReferencePoint
+}
+
+// NewConnectivityMatrix returns an empty ConnectivityMatrix
+func NewConnectivityMatrix() *ConnectivityMatrix {
+	return &ConnectivityMatrix{pairs: make(map[nfTypePair]ReferencePoint)}
+}
+
+// Register allows the connectivity between nfType1 and nfType2 over the given
+// reference point. Registering an already present pair overrides its
+// reference point.
+func (m *ConnectivityMatrix) Register(
+	nfType1 NFType, nfType2 NFType, referencePoint ReferencePoint,
+) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pairs[newNFTypePair(nfType1, nfType2)] = referencePoint
+}
+
+// ReferencePoint returns the reference point between nfType1 and nfType2 and
+// false if the two NF types are not allowed to be connected.
+func (m *ConnectivityMatrix) ReferencePoint(
+	nfType1 NFType, nfType2 NFType,
+) (ReferencePoint, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	referencePoint, ok := m.pairs[newNFTypePair(nfType1, nfType2)]
+	return referencePoint, ok
+}
+
+// NewDefaultConnectivityMatrix returns the ConnectivityMatrix with the 3GPP
+// reference points between the NF types supported by NfDeploy.
+func NewDefaultConnectivityMatrix() *ConnectivityMatrix {
+	m := NewConnectivityMatrix()
+	m.Register(UPFNFType, SMFNFType, N4)
+	m.Register(UPFNFType, UPFNFType, N9)
+	m.Register(SMFNFType, UDMNFType, N10)
+	m.Register(SMFNFType, AMFNFType, N11)
+	m.Register(AMFNFType, AUSFNFType, N12)
+	m.Register(AUSFNFType, UDMNFType, N13)
+	m.Register(AMFNFType, UDMNFType, N8)
+	m.Register(AMFNFType, AMFNFType, N14)
+	return m
+}
+
+// DefaultConnectivityMatrix is used by the NfDeploy validation webhook to
+// validate the connectivities between sites. NF types added later should
+// register their reference points here before the webhook is started.
+var DefaultConnectivityMatrix = NewDefaultConnectivityMatrix()
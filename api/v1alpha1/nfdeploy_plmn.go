@@ -0,0 +1,82 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+)
+
+// IsEmpty returns true if the Plmn is not specified
+func (p Plmn) IsEmpty() bool {
+	return p == Plmn{}
+}
+
+// GetMNCLength returns the number of digits of MNC
+func (p Plmn) GetMNCLength() int {
+	if p.MNCLength != 0 {
+		return p.MNCLength
+	}
+	if p.MNC > 99 {
+		return 3
+	}
+	return 2
+}
+
+// MCCString returns the MCC as a 3 digit string, for e.g. 001
+func (p Plmn) MCCString() string {
+	return fmt.Sprintf("%03d", p.MCC)
+}
+
+// MNCString returns the MNC as a 2 or 3 digit string, for e.g. 01 or 001
+func (p Plmn) MNCString() string {
+	return fmt.Sprintf("%0*d", p.GetMNCLength(), p.MNC)
+}
+
+// String returns the PLMN in MCC-MNC format, for e.g. 310-260
+func (p Plmn) String() string {
+	return p.MCCString() + "-" + p.MNCString()
+}
+
+// Validate returns an error if MCC or MNC is not set, MCC does not have 3
+// digits or MNC does not have 2 or 3 digits
+func (p Plmn) Validate() error {
+	if p.MCC == 0 || p.MNC == 0 {
+		return fmt.Errorf("Invalid PLMN %s: MCC and MNC must be set", p)
+	}
+	if p.MCC < 0 || p.MCC > 999 {
+		return fmt.Errorf("Invalid MCC %d: MCC must have 3 digits", p.MCC)
+	}
+	if p.MNCLength != 0 && p.MNCLength != 2 && p.MNCLength != 3 {
+		return fmt.Errorf("Invalid MNC length %d: MNC must have 2 or 3 digits", p.MNCLength)
+	}
+	if p.MNC < 0 || p.MNC > 999 {
+		return fmt.Errorf("Invalid MNC %d: MNC must have 2 or 3 digits", p.MNC)
+	}
+	if p.GetMNCLength() == 2 && p.MNC > 99 {
+		return fmt.Errorf("Invalid MNC %d: MNC does not fit in 2 digits", p.MNC)
+	}
+	return nil
+}
+
+// GetPlmns returns all the PLMNs of the NfDeploy, starting with spec.plmn
+func (s *NfDeploySpec) GetPlmns() []Plmn {
+	resp := []Plmn{}
+	if !s.Plmn.IsEmpty() {
+		resp = append(resp, s.Plmn)
+	}
+	return append(resp, s.Plmns...)
+}
@@ -17,16 +17,31 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// Plmn is the identity of a public land mobile network
 type Plmn struct {
+	// MCC is the 3 digit mobile country code
 	MCC int `json:"mcc,omitempty" yaml:"mcc,omitempty"`
+	// MNC is the 2 or 3 digit mobile network code
 	MNC int `json:"mnc,omitempty" yaml:"mnc,omitempty"`
+	// MNCLength is the number of digits of MNC, either 2 or 3. Needed only
+	// when a 3 digit MNC has a leading zero like 001. Defaults to 3 when MNC
+	// is above 99 and 2 otherwise.
+	MNCLength int `json:"mncLength,omitempty" yaml:"mncLength,omitempty"`
 }
 
 type Connectivity struct {
 	NeighborName string `json:"neighborName,omitempty" yaml:"neighborName,omitempty"`
+	// ReferencePoint is the interface over which the sites are connected.
+	// Defaults to the reference point between the NF types of the sites.
+	ReferencePoint ReferencePoint `json:"referencePoint,omitempty" yaml:"referencePoint,omitempty"`
 }
 
 type Site struct {
@@ -38,13 +53,174 @@ type Site struct {
 	NFVersion      string         `json:"nfVersion,omitempty" yaml:"nfVersion,omitempty"`
 	IPAddrBlock    []string       `json:"ipAddrBlock,omitempty" yaml:"ipAddrBlock,omitempty"`
 	Connectivities []Connectivity `json:"connectivities,omitempty" yaml:"connectivities,omitempty"`
+	// Flavor is the user defined size of the NF like small, medium or large.
+	// When set, the capacity profile of the site is the one mapped to the
+	// flavor for the NF type and vendor of the site by a NfFlavor in the
+	// nf-profiles instead of the one referenced by its NfType.
+	Flavor string `json:"flavor,omitempty" yaml:"flavor,omitempty"`
+	// CapacityWeight is the share of the site in the capacity of NfDeploy
+	// relative to the other sites of the same NF type. Defaults to 1 i.e.
+	// the capacity is split evenly.
+	CapacityWeight int `json:"capacityWeight,omitempty" yaml:"capacityWeight,omitempty"`
+	// Overrides are applied to the NfTypeDeploy generated for the site
+	Overrides *SiteOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// SiteOverrides change the NfTypeDeploy (like UpfDeploy) generated for a
+// site from the nf-profiles. Merge is applied first and then JSONPatch.
+type SiteOverrides struct {
+	// Merge is a partial NfTypeDeploy which is strategic-merged onto the
+	// generated one. Elements of lists are merged by their name.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	Merge *runtime.RawExtension `json:"merge,omitempty" yaml:"merge,omitempty"`
+	// JSONPatch is the list of RFC 6902 operations applied to the generated
+	// NfTypeDeploy
+	JSONPatch []JSONPatchOperation `json:"jsonPatch,omitempty" yaml:"jsonPatch,omitempty"`
+}
+
+// JSONPatchOperation is an RFC 6902 JSON patch operation
+type JSONPatchOperation struct {
+	// +kubebuilder:validation:Enum=add;remove;replace;move;copy;test
+	Op   string `json:"op" yaml:"op"`
+	Path string `json:"path" yaml:"path"`
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Value *runtime.RawExtension `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Capacity is the capacity intent of NfDeploy. The UPF sites together
+// provide the throughput and the sessions while the SMF sites together
+// provide the sessions and the subscribers.
+type Capacity struct {
+	UplinkThroughput   *resource.Quantity `json:"uplinkThroughput,omitempty" yaml:"uplinkThroughput,omitempty"`
+	DownlinkThroughput *resource.Quantity `json:"downlinkThroughput,omitempty" yaml:"downlinkThroughput,omitempty"`
+	MaxSessions        int                `json:"maxSessions,omitempty" yaml:"maxSessions,omitempty"`
+	MaxSubscribers     int                `json:"maxSubscribers,omitempty" yaml:"maxSubscribers,omitempty"`
 }
 
 // NfDeploySpec defines the desired state of NfDeploy
 type NfDeploySpec struct {
-	Plmn     Plmn   `json:"plmn,omitempty" yaml:"plmn,omitempty"`
+	Plmn Plmn `json:"plmn,omitempty" yaml:"plmn,omitempty"`
+	// Plmns are the additional PLMNs served by the NFs when the network is
+	// shared
+	Plmns []Plmn `json:"plmns,omitempty" yaml:"plmns,omitempty"`
+	// Capacity is the free-form capacity of NfDeploy. It is not used for
+	// hydration, CapacityIntent is.
 	Capacity string `json:"capacity,omitempty" yaml:"capacity,omitempty"`
-	Sites    []Site `json:"sites,omitempty" yaml:"sites,omitempty"`
+	// CapacityIntent is the capacity intent split across the UPF and SMF
+	// sites
+	CapacityIntent *Capacity `json:"capacityIntent,omitempty" yaml:"capacityIntent,omitempty"`
+	Sites          []Site    `json:"sites,omitempty" yaml:"sites,omitempty"`
+	// Rollout is the strategy used to roll out the sites. The sites are
+	// rolled out all at once when not set.
+	Rollout *RolloutStrategy `json:"rollout,omitempty" yaml:"rollout,omitempty"`
+	// Upgrade is the strategy used to upgrade the NF software version of the
+	// sites
+	Upgrade *UpgradeStrategy `json:"upgrade,omitempty" yaml:"upgrade,omitempty"`
+	// DeletionPolicy is what happens to the deploy packages of the sites when
+	// NfDeploy is deleted
+	// +kubebuilder:validation:Enum=Delete;Orphan;RetainUntilApproved
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty" yaml:"deletionPolicy,omitempty"`
+	// Drift is the strategy used when the published deploy packages of the
+	// sites drift from the content rendered for NfDeploy
+	Drift *DriftStrategy `json:"drift,omitempty" yaml:"drift,omitempty"`
+}
+
+// RolloutType is the way the deploy packages of the sites are created
+type RolloutType string
+
+const (
+	// AllAtOnceRollout creates the deploy packages of all the sites at once
+	AllAtOnceRollout RolloutType = "AllAtOnce"
+	// OrderedRollout creates the deploy packages wave by wave, in the order
+	// of the dependencies between connected sites, e.g. the SMF before the
+	// UPFs it controls. A wave is released once the NFs of the previous wave
+	// are Ready.
+	OrderedRollout RolloutType = "Ordered"
+	// ProgressiveRollout creates the deploy packages batch by batch of
+	// clusters, starting with the canary sites if any. A batch is released
+	// once the NFs of the previous batch are Ready.
+	ProgressiveRollout RolloutType = "Progressive"
+)
+
+// RolloutStrategy is the strategy used to roll out the sites of NfDeploy
+type RolloutStrategy struct {
+	// Type of the rollout
+	// +kubebuilder:validation:Enum=AllAtOnce;Ordered;Progressive
+	// +kubebuilder:default=AllAtOnce
+	Type RolloutType `json:"type,omitempty"`
+
+	// MaxUnavailable is the maximum number of clusters rolled out together in
+	// a batch of a progressive rollout, as an absolute number or a percentage
+	// of the clusters of the NfDeploy. Defaults to 1.
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// CanarySites are the ids of the sites rolled out in the first batch of a
+	// progressive rollout, on their own
+	CanarySites []string `json:"canarySites,omitempty"`
+}
+
+// IsOrdered returns true if the sites are rolled out wave by wave
+func (r *RolloutStrategy) IsOrdered() bool {
+	return r != nil && r.Type == OrderedRollout
+}
+
+// IsProgressive returns true if the sites are rolled out batch by batch of
+// clusters
+func (r *RolloutStrategy) IsProgressive() bool {
+	return r != nil && r.Type == ProgressiveRollout
+}
+
+// DeletionPolicy is what happens to the deploy packages of the sites when
+// NfDeploy is deleted
+type DeletionPolicy string
+
+const (
+	// DeleteDeletionPolicy deletes all the revisions of the deploy packages
+	DeleteDeletionPolicy DeletionPolicy = "Delete"
+	// OrphanDeletionPolicy leaves the deploy packages in place and removes
+	// the label that marks them as owned by NfDeploy
+	OrphanDeletionPolicy DeletionPolicy = "Orphan"
+	// RetainUntilApprovedDeletionPolicy proposes the deletion of the
+	// published revisions of the deploy packages in Porch, and keeps NfDeploy
+	// until the deletion is approved and the revisions are gone
+	RetainUntilApprovedDeletionPolicy DeletionPolicy = "RetainUntilApproved"
+)
+
+// DriftStrategy is the strategy used when the published deploy packages of
+// the sites drift from the content rendered for NfDeploy
+type DriftStrategy struct {
+	// AutoRemediate creates a new draft revision of the drifted deploy
+	// packages with the content rendered for NfDeploy. The drafts have to be
+	// approved like any other revision.
+	AutoRemediate bool `json:"autoRemediate,omitempty"`
+}
+
+// DefaultProgressDeadline is the progress deadline of an upgrade when not set
+const DefaultProgressDeadline = 30 * time.Minute
+
+// UpgradeStrategy is the strategy used to upgrade the NF software version of
+// the sites of NfDeploy. The deploy and actuator packages of the upgraded
+// sites are reverted to their previous revisions when the upgrade fails.
+type UpgradeStrategy struct {
+	// ProgressDeadlineSeconds is the time the NFs of the upgraded sites have
+	// to be Ready in, from the creation of the packages of the new version
+	// including their approval, before the upgrade is rolled back. Defaults
+	// to 1800.
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// ProgressDeadline returns the progress deadline of an upgrade
+func (u *UpgradeStrategy) ProgressDeadline() time.Duration {
+	if u == nil || u.ProgressDeadlineSeconds == nil {
+		return DefaultProgressDeadline
+	}
+	return time.Duration(*u.ProgressDeadlineSeconds) * time.Second
 }
 
 //+kubebuilder:object:root=true
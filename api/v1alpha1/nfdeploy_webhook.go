@@ -17,9 +17,11 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -62,9 +64,32 @@ func (r *NfDeploy) ValidateCreate() error {
 		}
 		presentNodes[site.Id] = present
 	}
+	if err := validateConnectivities(r.Spec.Sites); err != nil {
+		return err
+	}
+	if err := validatePlmns(r.Spec.GetPlmns()); err != nil {
+		return err
+	}
+	if err := validateCapacity(r.Spec); err != nil {
+		return err
+	}
+	if err := validateOverrides(r.Spec.Sites); err != nil {
+		return err
+	}
+	return validateConnectivityPairs(r.Spec.Sites, DefaultConnectivityMatrix)
+}
+
+// validateConnectivities returns an error if two sites are connected more
+// than once, or a site is connected to a neighbor which is not present or
+// not connected back to it
+func validateConnectivities(sites []Site) error {
+	var presentNodes = make(map[string]void)
+	for _, site := range sites {
+		presentNodes[site.Id] = present
+	}
 	var presentConnections = make(map[string]map[string]void)
 
-	for _, site := range r.Spec.Sites {
+	for _, site := range sites {
 		for _, connection := range site.Connectivities {
 			if _, present := presentConnections[site.Id]; !present {
 				presentConnections[site.Id] = make(map[string]void)
@@ -78,7 +103,7 @@ func (r *NfDeploy) ValidateCreate() error {
 			presentConnections[site.Id][connection.NeighborName] = connected
 		}
 	}
-	for _, site := range r.Spec.Sites {
+	for _, site := range sites {
 		for _, connection := range site.Connectivities {
 			if _, isPresent := presentNodes[connection.NeighborName]; !isPresent {
 				return errors.New("NF with id " + connection.NeighborName + " is not present")
@@ -94,15 +119,145 @@ func (r *NfDeploy) ValidateCreate() error {
 	return nil
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+// validatePlmns returns an error if any of the PLMNs is invalid or present
+// more than once
+func validatePlmns(plmns []Plmn) error {
+	var presentPlmns = make(map[string]void)
+	for _, plmn := range plmns {
+		if err := plmn.Validate(); err != nil {
+			return err
+		}
+		if _, isPresent := presentPlmns[plmn.String()]; isPresent {
+			return errors.New("PLMN " + plmn.String() + " is already present")
+		}
+		presentPlmns[plmn.String()] = present
+	}
+	return nil
+}
+
+// validateCapacity returns an error if the capacity intent or the capacity
+// weight of any site is negative
+func validateCapacity(spec NfDeploySpec) error {
+	for _, site := range spec.Sites {
+		if site.CapacityWeight < 0 {
+			return fmt.Errorf("Invalid capacityWeight %d of site %s: must not be negative",
+				site.CapacityWeight, site.Id)
+		}
+	}
+	c := spec.CapacityIntent
+	if c == nil {
+		return nil
+	}
+	if (c.UplinkThroughput != nil && c.UplinkThroughput.Sign() < 0) ||
+		(c.DownlinkThroughput != nil && c.DownlinkThroughput.Sign() < 0) ||
+		c.MaxSessions < 0 || c.MaxSubscribers < 0 {
+		return errors.New("Invalid capacity: must not be negative")
+	}
+	return nil
+}
+
+// validateOverrides returns an error if the overrides of any site are
+// malformed. Whether they apply to the generated NfTypeDeploy is only known
+// during hydration.
+func validateOverrides(sites []Site) error {
+	for _, site := range sites {
+		if site.Overrides == nil {
+			continue
+		}
+		if merge := site.Overrides.Merge; merge != nil && len(merge.Raw) > 0 {
+			var obj map[string]interface{}
+			if err := json.Unmarshal(merge.Raw, &obj); err != nil {
+				return fmt.Errorf("Invalid overrides of site %s: merge must be an object", site.Id)
+			}
+		}
+		for i, op := range site.Overrides.JSONPatch {
+			if !strings.HasPrefix(op.Path, "/") {
+				return fmt.Errorf("Invalid overrides of site %s: jsonPatch[%d] path must start with /",
+					site.Id, i)
+			}
+			switch op.Op {
+			case "add", "replace", "test":
+				if op.Value == nil {
+					return fmt.Errorf("Invalid overrides of site %s: jsonPatch[%d] %s requires value",
+						site.Id, i, op.Op)
+				}
+			case "move", "copy":
+				if !strings.HasPrefix(op.From, "/") {
+					return fmt.Errorf("Invalid overrides of site %s: jsonPatch[%d] %s requires from",
+						site.Id, i, op.Op)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateConnectivityPairs returns an error if any two connected sites have
+// NF types which are not allowed to be connected as per the given matrix, or
+// are connected over a reference point other than the one in the matrix
+func validateConnectivityPairs(sites []Site, matrix *ConnectivityMatrix) error {
+	var nfTypes = make(map[string]NFType)
+	for _, site := range sites {
+		nfTypes[site.Id] = NFType(site.NFType)
+	}
+	for _, site := range sites {
+		for _, connection := range site.Connectivities {
+			neighborNFType := nfTypes[connection.NeighborName]
+			referencePoint, ok := matrix.ReferencePoint(NFType(site.NFType), neighborNFType)
+			if !ok {
+				return fmt.Errorf(
+					"Connectivity between %s (%s) and %s (%s) is not allowed: "+
+						"no reference point exists between %s and %s",
+					site.Id, site.NFType, connection.NeighborName, neighborNFType,
+					site.NFType, neighborNFType,
+				)
+			}
+			if connection.ReferencePoint != "" && connection.ReferencePoint != referencePoint {
+				return fmt.Errorf(
+					"Connectivity between %s and %s is over %s, not %s",
+					site.Id, connection.NeighborName, referencePoint, connection.ReferencePoint,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// Only the NF versions, flavors and connectivities of the sites and the
+// rollout, upgrade, drift and deletion strategies may change. The changed
+// connectivities are validated as on create.
 func (r *NfDeploy) ValidateUpdate(old runtime.Object) error {
 	nfdeploylog.Info("validate update", "name", r.Name)
 
 	oldNfDeploy := old.(*NfDeploy)
-	if reflect.DeepEqual(r.Spec, oldNfDeploy.Spec) {
-		return nil
+	if !reflect.DeepEqual(immutableSpec(r.Spec), immutableSpec(oldNfDeploy.Spec)) {
+		return fmt.Errorf("NFDeploy update not allowed: only sites[].nfVersion, sites[].flavor, " +
+			"sites[].connectivities, rollout, upgrade, drift and deletionPolicy may change")
+	}
+	if err := validateConnectivities(r.Spec.Sites); err != nil {
+		return err
+	}
+	if err := validatePlmns(r.Spec.GetPlmns()); err != nil {
+		return err
+	}
+	return validateConnectivityPairs(r.Spec.Sites, DefaultConnectivityMatrix)
+}
+
+// immutableSpec returns a copy of the spec without the fields which may
+// change on update
+func immutableSpec(spec NfDeploySpec) NfDeploySpec {
+	immutable := *spec.DeepCopy()
+	immutable.Rollout = nil
+	immutable.Upgrade = nil
+	immutable.Drift = nil
+	immutable.DeletionPolicy = ""
+	for i := range immutable.Sites {
+		immutable.Sites[i].NFVersion = ""
+		immutable.Sites[i].Flavor = ""
+		immutable.Sites[i].Connectivities = nil
 	}
-	return fmt.Errorf("NFDeploy update not allowed")
+	return immutable
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -61,6 +61,61 @@ var _ = Describe("NFDeploy Validator Webhook", func() {
 				})
 			})
 
+			When("Only the NF versions and the strategies are different", func() {
+				It("Should allow the admission request", func() {
+					object.Spec.Sites = []Site{{Id: "upf-1", NFType: "upf", NFVersion: "v1.0"}}
+					Expect(k8sClient.Create(ctx, object)).To(Succeed())
+					object.Spec.Sites[0].NFVersion = "v1.1"
+					object.Spec.Sites[0].Flavor = "large"
+					object.Spec.Rollout = &RolloutStrategy{Type: ProgressiveRollout}
+					object.Spec.Upgrade = &UpgradeStrategy{}
+					object.Spec.Drift = &DriftStrategy{}
+					object.Spec.DeletionPolicy = OrphanDeletionPolicy
+					Expect(k8sClient.Update(ctx, object)).To(Succeed())
+				})
+			})
+
+			When("Only the connectivities are different", func() {
+				It("Should allow the admission request", func() {
+					object.Spec.Sites = []Site{{Id: "upf-1", NFType: "upf"}, {Id: "smf-1", NFType: "smf"}}
+					Expect(k8sClient.Create(ctx, object)).To(Succeed())
+					object.Spec.Sites[0].Connectivities = []Connectivity{{NeighborName: "smf-1"}}
+					object.Spec.Sites[1].Connectivities = []Connectivity{{NeighborName: "upf-1"}}
+					Expect(k8sClient.Update(ctx, object)).To(Succeed())
+				})
+			})
+
+			When("A connectivity between NF types which are not allowed is added", func() {
+				It("Should deny the admission request", func() {
+					object.Spec.Sites = []Site{{Id: "upf-1", NFType: "upf"}, {Id: "udm-1", NFType: "udm"}}
+					Expect(k8sClient.Create(ctx, object)).To(Succeed())
+					object.Spec.Sites[0].Connectivities = []Connectivity{{NeighborName: "udm-1"}}
+					object.Spec.Sites[1].Connectivities = []Connectivity{{NeighborName: "upf-1"}}
+					err := k8sClient.Update(ctx, object)
+					Expect(err).To(HaveOccurred())
+					Expect(apierrors.ReasonForError(err)).To(Equal(v1.StatusReason(
+						"Connectivity between upf-1 (upf) and udm-1 (udm) is not allowed: " +
+							"no reference point exists between upf and udm")))
+				})
+			})
+
+			When("A PLMN is empty", func() {
+				It("Should deny the admission request", func() {
+					object.Spec.Plmns = []Plmn{{}}
+					Expect(object.ValidateUpdate(object.DeepCopy())).To(MatchError(
+						"Invalid PLMN 000-00: MCC and MNC must be set"))
+				})
+			})
+
+			When("A site is added", func() {
+				It("Should deny the admission request", func() {
+					object.Spec.Sites = []Site{{Id: "upf-1", NFType: "upf"}}
+					Expect(k8sClient.Create(ctx, object)).To(Succeed())
+					object.Spec.Sites = append(object.Spec.Sites, Site{Id: "upf-2", NFType: "upf"})
+					Expect(k8sClient.Update(ctx, object)).NotTo(Succeed())
+				})
+			})
+
 		})
 	})
 
@@ -132,5 +187,99 @@ var _ = Describe("NFDeploy Validator Webhook", func() {
 
 			})
 		})
+		When("When valid PLMNs are provided", func() {
+			It("Should return no error", func(ctx SpecContext) {
+				object.Name = "test-nfdeploy-plmn"
+				object.Spec.Plmn = Plmn{MCC: 310, MNC: 260}
+				object.Spec.Plmns = []Plmn{{MCC: 1, MNC: 1, MNCLength: 3}}
+				err := k8sClient.Create(ctx, object)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+		When("When MCC has more than 3 digits", func() {
+			It("Should return error", func(ctx SpecContext) {
+				object.Spec.Plmn = Plmn{MCC: 3100, MNC: 26}
+				err := k8sClient.Create(ctx, object)
+				Expect(err).To(HaveOccurred())
+				Expect(apierrors.ReasonForError(err)).To(Equal(v1.StatusReason("Invalid MCC 3100: MCC must have 3 digits")))
+			})
+		})
+		When("When MNC does not fit in its length", func() {
+			It("Should return error", func(ctx SpecContext) {
+				object.Spec.Plmn = Plmn{MCC: 310, MNC: 260, MNCLength: 2}
+				err := k8sClient.Create(ctx, object)
+				Expect(err).To(HaveOccurred())
+				Expect(apierrors.ReasonForError(err)).To(Equal(v1.StatusReason("Invalid MNC 260: MNC does not fit in 2 digits")))
+			})
+		})
+		When("When a PLMN has no MNC", func() {
+			It("Should return error", func(ctx SpecContext) {
+				object.Spec.Plmns = []Plmn{{MCC: 310, MNC: 26}, {MCC: 310}}
+				err := k8sClient.Create(ctx, object)
+				Expect(err).To(HaveOccurred())
+				Expect(apierrors.ReasonForError(err)).To(Equal(v1.StatusReason("Invalid PLMN 310-00: MCC and MNC must be set")))
+			})
+		})
+		When("When a PLMN is provided more than once", func() {
+			It("Should return error", func(ctx SpecContext) {
+				object.Spec.Plmn = Plmn{MCC: 310, MNC: 26}
+				object.Spec.Plmns = []Plmn{{MCC: 310, MNC: 26}}
+				err := k8sClient.Create(ctx, object)
+				Expect(err).To(HaveOccurred())
+				Expect(apierrors.ReasonForError(err)).To(Equal(v1.StatusReason("PLMN 310-26 is already present")))
+			})
+		})
+		When("When a site has negative capacity weight", func() {
+			It("Should return error", func(ctx SpecContext) {
+				object.Spec.Sites[0].CapacityWeight = -1
+				err := k8sClient.Create(ctx, object)
+				Expect(err).To(HaveOccurred())
+				Expect(apierrors.ReasonForError(err)).To(Equal(v1.StatusReason("Invalid capacityWeight -1 of site upf: must not be negative")))
+			})
+		})
+		When("When a site has a json patch without value", func() {
+			It("Should return error", func(ctx SpecContext) {
+				object.Spec.Sites[0].Overrides = &SiteOverrides{
+					JSONPatch: []JSONPatchOperation{{Op: "replace", Path: "/spec/capacity"}},
+				}
+				err := k8sClient.Create(ctx, object)
+				Expect(err).To(HaveOccurred())
+				Expect(apierrors.ReasonForError(err)).To(Equal(v1.StatusReason(
+					"Invalid overrides of site upf: jsonPatch[0] replace requires value")))
+			})
+		})
+		When("When connected NF types have a reference point", func() {
+			It("Should return no error", func(ctx SpecContext) {
+				object.Name = "test-nfdeploy-n4"
+				object.Spec.Sites = []Site{{Id: "site-a", NFType: "upf"}, {Id: "site-b", NFType: "smf"}}
+				object.Spec.Sites[0].Connectivities = []Connectivity{{NeighborName: object.Spec.Sites[1].Id}}
+				object.Spec.Sites[1].Connectivities = []Connectivity{{NeighborName: object.Spec.Sites[0].Id}}
+				err := k8sClient.Create(ctx, object)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+		When("When connected NF types name another reference point", func() {
+			It("Should return error", func(ctx SpecContext) {
+				object.Spec.Sites[0].NFType = "upf"
+				object.Spec.Sites[1].NFType = "smf"
+				object.Spec.Sites[0].Connectivities = []Connectivity{{NeighborName: object.Spec.Sites[1].Id, ReferencePoint: N9}}
+				object.Spec.Sites[1].Connectivities = []Connectivity{{NeighborName: object.Spec.Sites[0].Id}}
+				err := k8sClient.Create(ctx, object)
+				Expect(err).To(HaveOccurred())
+				Expect(apierrors.ReasonForError(err)).To(Equal(v1.StatusReason(
+					"Connectivity between upf and smf is over N4, not N9")))
+			})
+		})
+		When("When connected NF types have no reference point", func() {
+			It("Should return error", func(ctx SpecContext) {
+				object.Spec.Sites = []Site{{Id: "site-a", NFType: "upf"}, {Id: "site-b", NFType: "udm"}}
+				object.Spec.Sites[0].Connectivities = []Connectivity{{NeighborName: object.Spec.Sites[1].Id}}
+				object.Spec.Sites[1].Connectivities = []Connectivity{{NeighborName: object.Spec.Sites[0].Id}}
+				err := k8sClient.Create(ctx, object)
+				Expect(err).To(HaveOccurred())
+				Expect(apierrors.ReasonForError(err)).To(Equal(v1.StatusReason(
+					"Connectivity between site-a (upf) and site-b (udm) is not allowed: no reference point exists between upf and udm")))
+			})
+		})
 	})
 })
@@ -0,0 +1,378 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+)
+
+var _ conversion.Convertible = &NfDeploy{}
+
+// CapacityAnnotation holds the free-form capacity of a v1alpha1 NfDeploy,
+// which v1beta1 has no field for, so that it survives a round-trip
+const CapacityAnnotation = "nfdeploy.nephio.org/v1alpha1-capacity"
+
+// ConvertTo converts this NfDeploy to the hub version v1alpha1
+func (src *NfDeploy) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.NfDeploy)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec.Capacity = ""
+	if capacity, ok := dst.Annotations[CapacityAnnotation]; ok {
+		dst.Spec.Capacity = capacity
+		delete(dst.Annotations, CapacityAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Spec.Plmn = v1alpha1.Plmn(src.Spec.Plmn)
+	dst.Spec.Plmns = nil
+	for _, plmn := range src.Spec.Plmns {
+		dst.Spec.Plmns = append(dst.Spec.Plmns, v1alpha1.Plmn(plmn))
+	}
+	dst.Spec.CapacityIntent = nil
+	if src.Spec.Capacity != nil {
+		capacity := v1alpha1.Capacity(*src.Spec.Capacity)
+		dst.Spec.CapacityIntent = &capacity
+	}
+	dst.Spec.Sites = nil
+	for _, site := range src.Spec.Sites {
+		dst.Spec.Sites = append(dst.Spec.Sites, convertSiteTo(site))
+	}
+	dst.Spec.Rollout = nil
+	if src.Spec.Rollout != nil {
+		dst.Spec.Rollout = &v1alpha1.RolloutStrategy{
+			Type:           v1alpha1.RolloutType(src.Spec.Rollout.Type),
+			MaxUnavailable: src.Spec.Rollout.DeepCopy().MaxUnavailable,
+			CanarySites:    append([]string(nil), src.Spec.Rollout.CanarySites...),
+		}
+	}
+
+	dst.Spec.Upgrade = nil
+	if src.Spec.Upgrade != nil {
+		upgrade := v1alpha1.UpgradeStrategy(*src.Spec.Upgrade.DeepCopy())
+		dst.Spec.Upgrade = &upgrade
+	}
+	dst.Spec.DeletionPolicy = v1alpha1.DeletionPolicy(src.Spec.DeletionPolicy)
+
+	dst.Spec.Drift = nil
+	if src.Spec.Drift != nil {
+		drift := v1alpha1.DriftStrategy(*src.Spec.Drift)
+		dst.Spec.Drift = &drift
+	}
+
+	dst.Status = convertStatusTo(src.Status)
+	return nil
+}
+
+// ConvertFrom converts the hub version v1alpha1 to this NfDeploy
+func (dst *NfDeploy) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.NfDeploy)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if src.Spec.Capacity != "" {
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[CapacityAnnotation] = src.Spec.Capacity
+	}
+
+	dst.Spec.Plmn = Plmn(src.Spec.Plmn)
+	dst.Spec.Plmns = nil
+	for _, plmn := range src.Spec.Plmns {
+		dst.Spec.Plmns = append(dst.Spec.Plmns, Plmn(plmn))
+	}
+	dst.Spec.Capacity = nil
+	if src.Spec.CapacityIntent != nil {
+		capacity := Capacity(*src.Spec.CapacityIntent)
+		dst.Spec.Capacity = &capacity
+	}
+	dst.Spec.Sites = nil
+	for _, site := range src.Spec.Sites {
+		dst.Spec.Sites = append(dst.Spec.Sites, convertSiteFrom(site))
+	}
+	dst.Spec.Rollout = nil
+	if src.Spec.Rollout != nil {
+		dst.Spec.Rollout = &RolloutStrategy{
+			Type:           RolloutType(src.Spec.Rollout.Type),
+			MaxUnavailable: src.Spec.Rollout.DeepCopy().MaxUnavailable,
+			CanarySites:    append([]string(nil), src.Spec.Rollout.CanarySites...),
+		}
+	}
+
+	dst.Spec.Upgrade = nil
+	if src.Spec.Upgrade != nil {
+		upgrade := UpgradeStrategy(*src.Spec.Upgrade.DeepCopy())
+		dst.Spec.Upgrade = &upgrade
+	}
+	dst.Spec.DeletionPolicy = DeletionPolicy(src.Spec.DeletionPolicy)
+
+	dst.Spec.Drift = nil
+	if src.Spec.Drift != nil {
+		drift := DriftStrategy(*src.Spec.Drift)
+		dst.Spec.Drift = &drift
+	}
+
+	dst.Status = convertStatusFrom(src.Status)
+	return nil
+}
+
+func convertSiteTo(src Site) v1alpha1.Site {
+	dst := v1alpha1.Site{
+		Id:             src.Id,
+		ClusterName:    src.ClusterName,
+		NFType:         string(src.NFType),
+		NFTypeName:     src.NFTypeRef.Name,
+		NFVendor:       src.Vendor.Name,
+		NFVersion:      src.Vendor.Version,
+		IPAddrBlock:    src.IPAddrBlock,
+		Flavor:         src.Flavor,
+		CapacityWeight: src.CapacityWeight,
+	}
+	for _, connectivity := range src.Connectivities {
+		dst.Connectivities = append(dst.Connectivities, v1alpha1.Connectivity{
+			NeighborName:   connectivity.NeighborName,
+			ReferencePoint: v1alpha1.ReferencePoint(connectivity.ReferencePoint),
+		})
+	}
+	if src.Overrides != nil {
+		dst.Overrides = &v1alpha1.SiteOverrides{Merge: src.Overrides.Merge}
+		for _, op := range src.Overrides.JSONPatch {
+			dst.Overrides.JSONPatch = append(dst.Overrides.JSONPatch, v1alpha1.JSONPatchOperation(op))
+		}
+	}
+	return dst
+}
+
+func convertSiteFrom(src v1alpha1.Site) Site {
+	dst := Site{
+		Id:             src.Id,
+		ClusterName:    src.ClusterName,
+		NFType:         NFType(src.NFType),
+		NFTypeRef:      NFTypeReference{Name: src.NFTypeName},
+		Vendor:         NFVendor{Name: src.NFVendor, Version: src.NFVersion},
+		IPAddrBlock:    src.IPAddrBlock,
+		Flavor:         src.Flavor,
+		CapacityWeight: src.CapacityWeight,
+	}
+	for _, connectivity := range src.Connectivities {
+		dst.Connectivities = append(dst.Connectivities, Connectivity{
+			NeighborName:   connectivity.NeighborName,
+			ReferencePoint: ReferencePoint(connectivity.ReferencePoint),
+		})
+	}
+	if src.Overrides != nil {
+		dst.Overrides = &SiteOverrides{Merge: src.Overrides.Merge}
+		for _, op := range src.Overrides.JSONPatch {
+			dst.Overrides.JSONPatch = append(dst.Overrides.JSONPatch, JSONPatchOperation(op))
+		}
+	}
+	return dst
+}
+
+func convertStatusTo(src NfDeployStatus) v1alpha1.NfDeployStatus {
+	dst := v1alpha1.NfDeployStatus{
+		ObservedGeneration: src.ObservedGeneration,
+		TargetedNFs:        src.TargetedNFs,
+		ReadyNFs:           src.ReadyNFs,
+		AvailableNFs:       src.AvailableNFs,
+		StalledNFs:         src.StalledNFs,
+		Conditions:         src.Conditions,
+	}
+	for _, link := range src.Links {
+		dst.Links = append(dst.Links, v1alpha1.NfDeployLink{
+			FirstSite:      link.FirstSite,
+			SecondSite:     link.SecondSite,
+			ReferencePoint: v1alpha1.ReferencePoint(link.ReferencePoint),
+			Peering:        link.Peering,
+			Message:        link.Message,
+		})
+	}
+	if src.Rollout != nil {
+		dst.Rollout = &v1alpha1.RolloutStatus{
+			ObservedGeneration: src.Rollout.ObservedGeneration,
+			CurrentWave:        src.Rollout.CurrentWave,
+			Paused:             src.Rollout.Paused,
+			PauseReason:        src.Rollout.PauseReason,
+		}
+		for _, wave := range src.Rollout.Waves {
+			dst.Rollout.Waves = append(dst.Rollout.Waves, v1alpha1.RolloutWave{
+				Sites: append([]string(nil), wave.Sites...),
+			})
+		}
+		for _, revision := range src.Rollout.PreviousRevisions {
+			dst.Rollout.PreviousRevisions = append(dst.Rollout.PreviousRevisions,
+				v1alpha1.PackageRevisionRef(revision))
+		}
+	}
+	if src.Upgrade != nil {
+		dst.Upgrade = convertUpgradeTo(*src.Upgrade)
+	}
+	if src.Termination != nil {
+		dst.Termination = &v1alpha1.TerminationStatus{
+			StartTime: src.Termination.StartTime.DeepCopy(),
+			TimedOut:  src.Termination.TimedOut,
+		}
+		for _, site := range src.Termination.Sites {
+			dst.Termination.Sites = append(dst.Termination.Sites, v1alpha1.SiteTermination{
+				Site:        site.Site,
+				ClusterName: site.ClusterName,
+				Phase:       v1alpha1.TerminationPhase(site.Phase),
+			})
+		}
+	}
+	if src.Profiles != nil {
+		dst.Profiles = &v1alpha1.ProfilesStatus{Revision: src.Profiles.Revision}
+		for _, object := range src.Profiles.Objects {
+			dst.Profiles.Objects = append(dst.Profiles.Objects, v1alpha1.ProfileObject(object))
+		}
+	}
+	for _, update := range src.CatalogUpdates {
+		dst.CatalogUpdates = append(dst.CatalogUpdates, v1alpha1.CatalogUpdate(update))
+	}
+	return dst
+}
+
+func convertUpgradeTo(src UpgradeStatus) *v1alpha1.UpgradeStatus {
+	dst := &v1alpha1.UpgradeStatus{
+		ObservedGeneration: src.ObservedGeneration,
+		Phase:              v1alpha1.UpgradePhase(src.Phase),
+		StartTime:          src.StartTime.DeepCopy(),
+		Message:            src.Message,
+	}
+	for _, version := range src.Versions {
+		dst.Versions = append(dst.Versions, v1alpha1.SiteVersion(version))
+	}
+	for _, site := range src.Sites {
+		dst.Sites = append(dst.Sites, v1alpha1.SiteUpgrade(site))
+	}
+	for _, revision := range src.PreviousRevisions {
+		dst.PreviousRevisions = append(dst.PreviousRevisions, v1alpha1.PackageRevisionRef(revision))
+	}
+	for _, rollback := range src.RollbackHistory {
+		r := v1alpha1.UpgradeRollback{
+			Generation: rollback.Generation,
+			Time:       *rollback.Time.DeepCopy(),
+			Reason:     rollback.Reason,
+		}
+		for _, site := range rollback.Sites {
+			r.Sites = append(r.Sites, v1alpha1.SiteUpgrade(site))
+		}
+		for _, revision := range rollback.RestoredRevisions {
+			r.RestoredRevisions = append(r.RestoredRevisions, v1alpha1.PackageRevisionRef(revision))
+		}
+		dst.RollbackHistory = append(dst.RollbackHistory, r)
+	}
+	return dst
+}
+
+func convertStatusFrom(src v1alpha1.NfDeployStatus) NfDeployStatus {
+	dst := NfDeployStatus{
+		ObservedGeneration: src.ObservedGeneration,
+		TargetedNFs:        src.TargetedNFs,
+		ReadyNFs:           src.ReadyNFs,
+		AvailableNFs:       src.AvailableNFs,
+		StalledNFs:         src.StalledNFs,
+		Conditions:         src.Conditions,
+	}
+	for _, link := range src.Links {
+		dst.Links = append(dst.Links, NfDeployLink{
+			FirstSite:      link.FirstSite,
+			SecondSite:     link.SecondSite,
+			ReferencePoint: ReferencePoint(link.ReferencePoint),
+			Peering:        link.Peering,
+			Message:        link.Message,
+		})
+	}
+	if src.Rollout != nil {
+		dst.Rollout = &RolloutStatus{
+			ObservedGeneration: src.Rollout.ObservedGeneration,
+			CurrentWave:        src.Rollout.CurrentWave,
+			Paused:             src.Rollout.Paused,
+			PauseReason:        src.Rollout.PauseReason,
+		}
+		for _, wave := range src.Rollout.Waves {
+			dst.Rollout.Waves = append(dst.Rollout.Waves, RolloutWave{
+				Sites: append([]string(nil), wave.Sites...),
+			})
+		}
+		for _, revision := range src.Rollout.PreviousRevisions {
+			dst.Rollout.PreviousRevisions = append(dst.Rollout.PreviousRevisions,
+				PackageRevisionRef(revision))
+		}
+	}
+	if src.Upgrade != nil {
+		dst.Upgrade = convertUpgradeFrom(*src.Upgrade)
+	}
+	if src.Termination != nil {
+		dst.Termination = &TerminationStatus{
+			StartTime: src.Termination.StartTime.DeepCopy(),
+			TimedOut:  src.Termination.TimedOut,
+		}
+		for _, site := range src.Termination.Sites {
+			dst.Termination.Sites = append(dst.Termination.Sites, SiteTermination{
+				Site:        site.Site,
+				ClusterName: site.ClusterName,
+				Phase:       TerminationPhase(site.Phase),
+			})
+		}
+	}
+	if src.Profiles != nil {
+		dst.Profiles = &ProfilesStatus{Revision: src.Profiles.Revision}
+		for _, object := range src.Profiles.Objects {
+			dst.Profiles.Objects = append(dst.Profiles.Objects, ProfileObject(object))
+		}
+	}
+	for _, update := range src.CatalogUpdates {
+		dst.CatalogUpdates = append(dst.CatalogUpdates, CatalogUpdate(update))
+	}
+	return dst
+}
+
+func convertUpgradeFrom(src v1alpha1.UpgradeStatus) *UpgradeStatus {
+	dst := &UpgradeStatus{
+		ObservedGeneration: src.ObservedGeneration,
+		Phase:              UpgradePhase(src.Phase),
+		StartTime:          src.StartTime.DeepCopy(),
+		Message:            src.Message,
+	}
+	for _, version := range src.Versions {
+		dst.Versions = append(dst.Versions, SiteVersion(version))
+	}
+	for _, site := range src.Sites {
+		dst.Sites = append(dst.Sites, SiteUpgrade(site))
+	}
+	for _, revision := range src.PreviousRevisions {
+		dst.PreviousRevisions = append(dst.PreviousRevisions, PackageRevisionRef(revision))
+	}
+	for _, rollback := range src.RollbackHistory {
+		r := UpgradeRollback{
+			Generation: rollback.Generation,
+			Time:       *rollback.Time.DeepCopy(),
+			Reason:     rollback.Reason,
+		}
+		for _, site := range rollback.Sites {
+			r.Sites = append(r.Sites, SiteUpgrade(site))
+		}
+		for _, revision := range rollback.RestoredRevisions {
+			r.RestoredRevisions = append(r.RestoredRevisions, PackageRevisionRef(revision))
+		}
+		dst.RollbackHistory = append(dst.RollbackHistory, r)
+	}
+	return dst
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/api/v1beta1"
+)
+
+const samplesDir = "../../config/samples"
+
+// readSample unmarshals the sample manifest into obj after checking that it
+// is of the apiVersion of obj
+func readSample(name string, apiVersion string, obj interface{}) bool {
+	content, err := os.ReadFile(filepath.Join(samplesDir, name))
+	Expect(err).NotTo(HaveOccurred())
+	typeMeta := struct {
+		APIVersion string `json:"apiVersion"`
+	}{}
+	Expect(yaml.Unmarshal(content, &typeMeta)).To(Succeed())
+	if typeMeta.APIVersion != apiVersion {
+		return false
+	}
+	Expect(yaml.UnmarshalStrict(content, obj)).To(Succeed())
+	return true
+}
+
+var _ = Describe("NfDeploy conversion", func() {
+	samples, err := os.ReadDir(samplesDir)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, sample := range samples {
+		name := sample.Name()
+		It("Should round-trip "+name, func() {
+			alpha := &v1alpha1.NfDeploy{}
+			if readSample(name, v1alpha1.GroupVersion.String(), alpha) {
+				beta := &v1beta1.NfDeploy{}
+				Expect(beta.ConvertFrom(alpha)).To(Succeed())
+				for i, site := range alpha.Spec.Sites {
+					Expect(string(beta.Spec.Sites[i].NFType)).To(Equal(site.NFType))
+					Expect(beta.Spec.Sites[i].NFTypeRef.Name).To(Equal(site.NFTypeName))
+					Expect(beta.Spec.Sites[i].Vendor.Name).To(Equal(site.NFVendor))
+				}
+				got := &v1alpha1.NfDeploy{}
+				Expect(beta.ConvertTo(got)).To(Succeed())
+				Expect(got.ObjectMeta).To(Equal(alpha.ObjectMeta))
+				Expect(got.Spec).To(Equal(alpha.Spec))
+				Expect(got.Status).To(Equal(alpha.Status))
+				return
+			}
+			beta := &v1beta1.NfDeploy{}
+			Expect(readSample(name, v1beta1.GroupVersion.String(), beta)).To(BeTrue())
+			alpha = &v1alpha1.NfDeploy{}
+			Expect(beta.ConvertTo(alpha)).To(Succeed())
+			for i, site := range beta.Spec.Sites {
+				Expect(alpha.Spec.Sites[i].NFVersion).To(Equal(site.Vendor.Version))
+			}
+			got := &v1beta1.NfDeploy{}
+			Expect(got.ConvertFrom(alpha)).To(Succeed())
+			Expect(got.ObjectMeta).To(Equal(beta.ObjectMeta))
+			Expect(got.Spec).To(Equal(beta.Spec))
+			Expect(got.Status).To(Equal(beta.Status))
+		})
+	}
+
+	It("Should convert the rollout, upgrade, deletion policy and status", func() {
+		maxUnavailable := intstr.FromString("25%")
+		progressDeadline := int32(600)
+		alpha := &v1alpha1.NfDeploy{
+			Spec: v1alpha1.NfDeploySpec{
+				Rollout: &v1alpha1.RolloutStrategy{
+					Type:           v1alpha1.ProgressiveRollout,
+					MaxUnavailable: &maxUnavailable,
+					CanarySites:    []string{"upf-1"},
+				},
+				Upgrade:        &v1alpha1.UpgradeStrategy{ProgressDeadlineSeconds: &progressDeadline},
+				DeletionPolicy: v1alpha1.RetainUntilApprovedDeletionPolicy,
+				Drift:          &v1alpha1.DriftStrategy{AutoRemediate: true},
+			},
+			Status: v1alpha1.NfDeployStatus{
+				ObservedGeneration: 2,
+				TargetedNFs:        3,
+				Conditions: []metav1.Condition{
+					{Type: string(v1alpha1.DeploymentReady), Status: metav1.ConditionTrue,
+						ObservedGeneration: 2, Reason: "AllNFsReady"},
+				},
+				Links: []v1alpha1.NfDeployLink{
+					{FirstSite: "upf-1", SecondSite: "smf-1", ReferencePoint: v1alpha1.N4,
+						Peering: metav1.ConditionFalse, Message: "upf-1: association lost"},
+				},
+				Rollout: &v1alpha1.RolloutStatus{
+					ObservedGeneration: 2,
+					Waves: []v1alpha1.RolloutWave{
+						{Sites: []string{"upf-1"}}, {Sites: []string{"smf-1"}},
+					},
+					Paused:      true,
+					PauseReason: "sites [upf-1] of wave 1 are Stalled",
+					PreviousRevisions: []v1alpha1.PackageRevisionRef{
+						{ClusterName: "cluster1", Name: "deploy-v1"},
+					},
+				},
+				Upgrade: &v1alpha1.UpgradeStatus{
+					Versions:           []v1alpha1.SiteVersion{{Site: "upf-1", Version: "1.1"}},
+					ObservedGeneration: 2,
+					Phase:              v1alpha1.UpgradeRolledBack,
+					Sites: []v1alpha1.SiteUpgrade{
+						{Site: "upf-1", FromVersion: "1.0", ToVersion: "1.1"},
+					},
+					PreviousRevisions: []v1alpha1.PackageRevisionRef{
+						{ClusterName: "edge-1", Name: "deploy-repo-1"},
+					},
+					RollbackHistory: []v1alpha1.UpgradeRollback{
+						{Generation: 2, Reason: "sites [upf-1] are Stalled"},
+					},
+				},
+				Termination: &v1alpha1.TerminationStatus{
+					Sites: []v1alpha1.SiteTermination{
+						{Site: "upf-1", ClusterName: "edge-1", Phase: v1alpha1.SiteTerminating},
+					},
+					TimedOut: true,
+				},
+				Profiles: &v1alpha1.ProfilesStatus{
+					Revision: "nf-profiles-v2",
+					Objects: []v1alpha1.ProfileObject{
+						{Kind: "InterfaceConfig", Name: "upf-1", Hash: "3b1f"},
+					},
+				},
+				CatalogUpdates: []v1alpha1.CatalogUpdate{
+					{
+						Package:            "casa/1.0/upf/actuators",
+						Revision:           "private-catalog-3c8e",
+						PackageNames:       []string{"cluster-1-deploy-repo-9f2a"},
+						ObservedGeneration: 2,
+					},
+				},
+			},
+		}
+		beta := &v1beta1.NfDeploy{}
+		Expect(beta.ConvertFrom(alpha)).To(Succeed())
+		Expect(beta.Status.Conditions).To(Equal(alpha.Status.Conditions))
+		Expect(beta.Status.Links).To(HaveLen(1))
+		Expect(beta.Status.Links[0].ReferencePoint).To(Equal(v1beta1.ReferencePoint("N4")))
+		got := &v1alpha1.NfDeploy{}
+		Expect(beta.ConvertTo(got)).To(Succeed())
+		Expect(got.Status).To(Equal(alpha.Status))
+		Expect(got.Spec.Rollout).To(Equal(alpha.Spec.Rollout))
+		Expect(got.Spec.Upgrade).To(Equal(alpha.Spec.Upgrade))
+		Expect(got.Spec.DeletionPolicy).To(Equal(alpha.Spec.DeletionPolicy))
+		Expect(got.Spec.Drift).To(Equal(alpha.Spec.Drift))
+	})
+
+	It("Should decode and round-trip a v1alpha1 NfDeploy with a free-form capacity", func() {
+		stored := []byte(`
+apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: NfDeploy
+metadata:
+  name: nfdeploy-1
+  annotations:
+    owner: team-a
+spec:
+  capacity: 10Gbps
+  capacityIntent:
+    maxSessions: 1000
+`)
+		alpha := &v1alpha1.NfDeploy{}
+		Expect(yaml.UnmarshalStrict(stored, alpha)).To(Succeed())
+		Expect(alpha.Spec.Capacity).To(Equal("10Gbps"))
+		Expect(alpha.Spec.CapacityIntent.MaxSessions).To(Equal(1000))
+
+		beta := &v1beta1.NfDeploy{}
+		Expect(beta.ConvertFrom(alpha)).To(Succeed())
+		Expect(beta.Spec.Capacity.MaxSessions).To(Equal(1000))
+		Expect(beta.Annotations).To(HaveKeyWithValue(v1beta1.CapacityAnnotation, "10Gbps"))
+		Expect(alpha.Annotations).NotTo(HaveKey(v1beta1.CapacityAnnotation))
+
+		got := &v1alpha1.NfDeploy{}
+		Expect(beta.ConvertTo(got)).To(Succeed())
+		Expect(got.ObjectMeta).To(Equal(alpha.ObjectMeta))
+		Expect(got.Spec).To(Equal(alpha.Spec))
+	})
+})
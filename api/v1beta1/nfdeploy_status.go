@@ -0,0 +1,373 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NFDeployConditionType is the type of a condition in NfDeployStatus
+type NFDeployConditionType string
+
+const (
+	// Reconciling implies that the deployment is progressing.
+	// Reconciliation for a deployment is considered when a
+	// 1. new version of at-least one NF is adopted,
+	// 2. when new pods scale up or old pods scale down,
+	// 3. when required peering is in progress, or,
+	// 4. location of at-least one NF changes.
+	//
+	// Condition name follows Kpt guidelines.
+	DeploymentReconciling NFDeployConditionType = "Reconciling"
+
+	// Deployment is unable to make progress towards Reconciliation.
+	// Reasons could be NF creation failure, Peering failure etc.
+	//
+	// Condition name follows Kpt guidelines.
+	DeploymentStalled NFDeployConditionType = "Stalled"
+
+	// The Deployment is considered available when following conditions hold:
+	// 1. All the NFs are Available.
+	// 2. The NFs are making progress towards peering on the required
+	//    interfaces.
+	DeploymentPeering NFDeployConditionType = "Peering"
+
+	// The Deployment is said to be Ready when all the NFs are Ready.
+	// At this stage, the deployment is ready to serve requests.
+	DeploymentReady NFDeployConditionType = "Ready"
+
+	// The Deployment is said to be Drifted when the latest published deploy
+	// package of at-least one cluster differs from the content rendered for
+	// the current generation of NfDeploy.
+	DeploymentDrifted NFDeployConditionType = "Drifted"
+)
+
+// NfDeployStatus follows the kstatus conventions so that tools like kpt,
+// Config Sync and Argo compute the status of NfDeploy as
+//   - InProgress while observedGeneration is behind the generation or the
+//     Reconciling condition is True,
+//   - Failed while the Stalled condition is True and
+//   - Current otherwise.
+type NfDeployStatus struct {
+	// The generation observed by the deployment controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Total number of NFs targeted by this deployment
+	TargetedNFs int32 `json:"targetedNFs,omitempty"`
+
+	// Total number of NFs targeted by this deployment with a Ready Condition set.
+	ReadyNFs int32 `json:"readyNFs,omitempty"`
+
+	// Total number of NFs targeted by this deployment with an Available Condition set.
+	AvailableNFs int32 `json:"availableNFs,omitempty"`
+
+	// Total number of NFs targeted by this deployment with a Stalled Condition set.
+	StalledNFs int32 `json:"stalledNFs,omitempty"`
+
+	// Links is the peering status of the connectivities between the sites,
+	// reported by the NFs of the sites.
+	Links []NfDeployLink `json:"links,omitempty"`
+
+	// Rollout is the progress of the ordered or progressive rollout of the
+	// sites. Not set when the sites are rolled out all at once.
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// Upgrade is the progress of the last upgrade of the NF software version
+	// of the sites and the history of the upgrades rolled back
+	Upgrade *UpgradeStatus `json:"upgrade,omitempty"`
+
+	// Termination is the progress of the removal of the NFs of the sites from
+	// their workload clusters once the NfDeploy is deleted
+	Termination *TerminationStatus `json:"termination,omitempty"`
+
+	// Profiles are the NF profile objects read from the nf-profiles package
+	// by the last successful hydration. The NfDeploy is hydrated again when
+	// one of them changes in a newly published revision of the package.
+	Profiles *ProfilesStatus `json:"profiles,omitempty"`
+
+	// CatalogUpdates are the new revisions of the vendor catalog packages
+	// used by the sites, propagated to the deploy repos since the generation
+	// was hydrated. The packages created from them need to be approved.
+	CatalogUpdates []CatalogUpdate `json:"catalogUpdates,omitempty"`
+
+	// Conditions are the Reconciling, Stalled, Peering and Ready conditions
+	// of the NfDeploy. The observedGeneration of a condition is the
+	// generation of the NfDeploy it was computed for.
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// NfDeployLink is the peering status of a connectivity between two sites
+type NfDeployLink struct {
+	// FirstSite is the id of one of the connected sites
+	FirstSite string `json:"firstSite"`
+
+	// SecondSite is the id of the other connected site
+	SecondSite string `json:"secondSite"`
+
+	// ReferencePoint is the interface over which the sites are connected.
+	ReferencePoint ReferencePoint `json:"referencePoint,omitempty"`
+
+	// Peering is False when the NF of either site reports the link broken,
+	// True when the NF of a site reports it peered and Unknown otherwise.
+	Peering metav1.ConditionStatus `json:"peering"`
+
+	// Message is the peering status of the link reported by the NFs.
+	Message string `json:"message,omitempty"`
+}
+
+// RolloutStatus is the progress of the ordered or progressive rollout of
+// the sites
+type RolloutStatus struct {
+	// ObservedGeneration is the generation of the NfDeploy being rolled out
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Waves are the ids of the sites of each wave, in rollout order
+	Waves []RolloutWave `json:"waves,omitempty"`
+
+	// CurrentWave is the index of the last wave whose deploy packages are
+	// created
+	CurrentWave int32 `json:"currentWave"`
+
+	// Paused is true when the rollout is stopped at the current wave because
+	// some of its sites are Stalled. A paused rollout is resumed by the next
+	// generation of the NfDeploy.
+	Paused bool `json:"paused,omitempty"`
+
+	// PauseReason is why the rollout is paused
+	PauseReason string `json:"pauseReason,omitempty"`
+
+	// PreviousRevisions are the revisions of the deploy packages of the
+	// clusters of the current wave before its packages were created. The
+	// next wave is released once each of these clusters has a newer
+	// published revision.
+	PreviousRevisions []PackageRevisionRef `json:"previousRevisions,omitempty"`
+}
+
+// RolloutWave is a set of sites rolled out together
+type RolloutWave struct {
+	// Sites are the ids of the sites of the wave
+	Sites []string `json:"sites"`
+}
+
+// IsComplete returns true if the deploy packages of all the waves are created
+func (r *RolloutStatus) IsComplete() bool {
+	return r == nil || int(r.CurrentWave) >= len(r.Waves)-1
+}
+
+// UpgradePhase is the phase of the upgrade of the NF software version of the
+// sites
+type UpgradePhase string
+
+const (
+	// UpgradeProgressing : the packages of the new versions are created, the
+	// NFs of the upgraded sites are not Ready yet
+	UpgradeProgressing UpgradePhase = "Progressing"
+	// UpgradeSucceeded : the NFs of the upgraded sites are Ready
+	UpgradeSucceeded UpgradePhase = "Succeeded"
+	// UpgradeRolledBack : an NF of the upgraded sites got Stalled or the
+	// progress deadline passed, the packages are reverted to the previous
+	// revisions
+	UpgradeRolledBack UpgradePhase = "RolledBack"
+)
+
+// UpgradeStatus is the progress of the upgrade of the NF software version of
+// the sites
+type UpgradeStatus struct {
+	// Versions are the NF software versions the sites are deployed with, as
+	// last hydrated or restored by a rollback
+	Versions []SiteVersion `json:"versions,omitempty"`
+
+	// ObservedGeneration is the generation of the NfDeploy the sites were
+	// last hydrated for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase of the last upgrade. Not set when the last hydrated generation
+	// did not change any version.
+	Phase UpgradePhase `json:"phase,omitempty"`
+
+	// StartTime is when the packages of the last upgrade were created
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Sites are the sites whose version was changed by the last upgrade
+	Sites []SiteUpgrade `json:"sites,omitempty"`
+
+	// PreviousRevisions are the revisions of the deploy and actuator packages
+	// before the last upgrade, which are restored when it is rolled back
+	PreviousRevisions []PackageRevisionRef `json:"previousRevisions,omitempty"`
+
+	// Message is a human readable message about the last upgrade
+	Message string `json:"message,omitempty"`
+
+	// RollbackHistory are the upgrades rolled back, the most recent last
+	RollbackHistory []UpgradeRollback `json:"rollbackHistory,omitempty"`
+}
+
+// SiteVersion is the NF software version of a site
+type SiteVersion struct {
+	// Site is the id of the site
+	Site string `json:"site"`
+
+	// Version is the NF software version of the site
+	Version string `json:"version,omitempty"`
+}
+
+// SiteUpgrade is the change of the NF software version of a site
+type SiteUpgrade struct {
+	// Site is the id of the site
+	Site string `json:"site"`
+
+	// FromVersion is the version the site is upgraded from
+	FromVersion string `json:"fromVersion,omitempty"`
+
+	// ToVersion is the version the site is upgraded to
+	ToVersion string `json:"toVersion,omitempty"`
+}
+
+// PackageRevisionRef is a revision of a package in the deploy repo of a
+// cluster
+type PackageRevisionRef struct {
+	// ClusterName is the cluster of the deploy repo
+	ClusterName string `json:"clusterName"`
+
+	// Name is the name of the PackageRevision
+	Name string `json:"name"`
+}
+
+// UpgradeRollback is an upgrade which was rolled back
+type UpgradeRollback struct {
+	// Generation is the generation of the NfDeploy which was rolled back
+	Generation int64 `json:"generation"`
+
+	// Time is when the upgrade was rolled back
+	Time metav1.Time `json:"time"`
+
+	// Reason is why the upgrade was rolled back
+	Reason string `json:"reason"`
+
+	// Sites are the sites whose version was reverted
+	Sites []SiteUpgrade `json:"sites,omitempty"`
+
+	// RestoredRevisions are the revisions the packages were reverted to
+	RestoredRevisions []PackageRevisionRef `json:"restoredRevisions,omitempty"`
+}
+
+// TerminationPhase is the phase of the removal of the NF of a site from its
+// workload cluster
+type TerminationPhase string
+
+const (
+	// SiteTerminating : the NF of the site is still reported by its workload
+	// cluster
+	SiteTerminating TerminationPhase = "Terminating"
+	// SiteRemoved : the workload cluster reported the removal of the NF of the
+	// site, or never reported the NF
+	SiteRemoved TerminationPhase = "Removed"
+)
+
+// TerminationStatus is the progress of the removal of the NFs of the sites
+// from their workload clusters
+type TerminationStatus struct {
+	// StartTime is when the deploy packages of the sites were removed and the
+	// removal of the NFs started to be awaited
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Sites is the removal progress of the NF of each site
+	Sites []SiteTermination `json:"sites,omitempty"`
+
+	// TimedOut is true when some workload clusters did not confirm the
+	// removal of their NFs within the termination timeout. The NfDeploy is
+	// still kept until they do.
+	TimedOut bool `json:"timedOut,omitempty"`
+}
+
+// SiteTermination is the removal progress of the NF of a site
+type SiteTermination struct {
+	// Site is the id of the site
+	Site string `json:"site"`
+
+	// ClusterName is the workload cluster of the site
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// Phase of the removal of the NF of the site
+	Phase TerminationPhase `json:"phase"`
+}
+
+// TerminatingSites returns the ids of the sites whose NFs are not removed yet
+// and the clusters of these sites, sorted
+func (t *TerminationStatus) TerminatingSites() (sites []string, clusters []string) {
+	if t == nil {
+		return nil, nil
+	}
+	clusterSet := map[string]bool{}
+	for _, site := range t.Sites {
+		if site.Phase != SiteTerminating {
+			continue
+		}
+		sites = append(sites, site.Site)
+		if !clusterSet[site.ClusterName] {
+			clusterSet[site.ClusterName] = true
+			clusters = append(clusters, site.ClusterName)
+		}
+	}
+	sort.Strings(sites)
+	sort.Strings(clusters)
+	return sites, clusters
+}
+
+// ProfilesStatus are the NF profile objects read by a hydration
+type ProfilesStatus struct {
+	// Revision is the name of the nf-profiles package revision the objects
+	// are read from
+	Revision string `json:"revision,omitempty"`
+
+	// Objects are the profile objects read, sorted by kind and name
+	Objects []ProfileObject `json:"objects,omitempty"`
+}
+
+// ProfileObject is a NF profile object, e.g. an InterfaceConfig or a
+// capacity profile
+type ProfileObject struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+
+	// Hash is the sha256 of the content of the object
+	Hash string `json:"hash"`
+}
+
+// CatalogUpdate is a newly published revision of the actuators or extension
+// package of a vendor NF in the vendor catalog
+type CatalogUpdate struct {
+	// Package is the name of the catalog package, e.g. casa/1.0/upf/actuators
+	Package string `json:"package"`
+
+	// Revision is the name of the published revision of the catalog package
+	Revision string `json:"revision"`
+
+	// PackageNames are the packages created from the revision in the deploy
+	// repos, which need to be approved
+	PackageNames []string `json:"packageNames,omitempty"`
+
+	// ObservedGeneration is the generation of NfDeploy the revision was
+	// propagated to
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/drift"
+	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// PackageKind is the kind of a package of a vendor NF in the vendor catalog
+type PackageKind string
+
+const (
+	// Actuators packages hold the operators actuating the NFs on the edge,
+	// copied as is to the deploy repo of every cluster running the NF
+	Actuators PackageKind = "actuators"
+	// Extension packages hold the vendor specific objects referenced by the
+	// VendorRef of the NfTypeDeploys in the deploy packages
+	Extension PackageKind = "extension"
+)
+
+// nc is the naming context of the catalog packages, which depends neither on
+// the cluster nor on the NfDeploy
+var nc util.NamingContext
+
+// ParsePackageName returns the vendor NF and the kind of the catalog package
+// named pkgName, or false if it is neither an actuators nor an extension
+// package
+func ParsePackageName(pkgName string) (packageservice.VendorNFKey, PackageKind, bool) {
+	parts := strings.Split(pkgName, "/")
+	if len(parts) != 4 {
+		return packageservice.VendorNFKey{}, "", false
+	}
+	key := packageservice.VendorNFKey{Vendor: parts[0], Version: parts[1], NFType: parts[2]}
+	switch pkgName {
+	case nc.GetNFDeployActuatorPackageName(key.Vendor, key.Version, key.NFType):
+		return key, Actuators, true
+	case nc.GetVendorExtensionPackageName(key.Vendor, key.Version, key.NFType):
+		return key, Extension, true
+	}
+	return packageservice.VendorNFKey{}, "", false
+}
+
+// Clusters returns the clusters of the sites of nfDeploy running the vendor
+// NF of key, in the order of the sites
+func Clusters(nfDeploy v1alpha1.NfDeploy, key packageservice.VendorNFKey) []string {
+	var clusters []string
+	seen := map[string]bool{}
+	for _, site := range nfDeploy.Spec.Sites {
+		if site.NFVendor != key.Vendor || site.NFVersion != key.Version ||
+			site.NFType != key.NFType || seen[site.ClusterName] {
+			continue
+		}
+		seen[site.ClusterName] = true
+		clusters = append(clusters, site.ClusterName)
+	}
+	return clusters
+}
+
+// UpdateVendorObjects returns the published content of a deploy package with
+// the objects referenced by the VendorRef of its NfTypeDeploys, and the
+// VendorRefs, taken from the rendered content. The other objects keep their
+// published content, e.g. hand edits, and so do the files whose objects are
+// unchanged. The Kptfile is left out as Porch maintains it.
+func UpdateVendorObjects(rendered, published map[string]string) (map[string]string, error) {
+	renderedNodes := map[string][]*yaml.RNode{}
+	for name, content := range rendered {
+		if name == packageservice.KptfileName {
+			continue
+		}
+		nodes, err := util.ParseStringToYamlNode(content)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing rendered file %s: %w", name, err)
+		}
+		renderedNodes[name] = nodes
+	}
+	renderedObjects := map[objectID]*yaml.RNode{}
+	renderedRefs := map[objectID]bool{}
+	for _, nodes := range renderedNodes {
+		for _, node := range nodes {
+			renderedObjects[idOf(node)] = node
+			if ref, ok := vendorRef(node); ok {
+				renderedRefs[ref] = true
+			}
+		}
+	}
+
+	updated := map[string]string{}
+	publishedNodes := map[string][]*yaml.RNode{}
+	publishedRefs := map[objectID]bool{}
+	for name, content := range published {
+		if name == packageservice.KptfileName {
+			continue
+		}
+		nodes, err := util.ParseStringToYamlNode(content)
+		if err != nil {
+			// not a file of objects, kept as is
+			updated[name] = content
+			continue
+		}
+		publishedNodes[name] = nodes
+		for _, node := range nodes {
+			if ref, ok := vendorRef(node); ok {
+				publishedRefs[ref] = true
+			}
+		}
+	}
+
+	placed := map[objectID]bool{}
+	for name, nodes := range publishedNodes {
+		var objects []*yaml.RNode
+		for _, node := range nodes {
+			id := idOf(node)
+			renderedNode, isRendered := renderedObjects[id]
+			switch {
+			case renderedRefs[id] && isRendered:
+				objects = append(objects, renderedNode)
+				placed[id] = true
+			case publishedRefs[id] && !renderedRefs[id]:
+				// no longer referenced
+			default:
+				if isRendered {
+					if err := copyVendorRef(renderedNode, node); err != nil {
+						return nil, fmt.Errorf("error updating the VendorRef of %s: %w", id.name, err)
+					}
+				}
+				objects = append(objects, node)
+			}
+		}
+		publishedNodes[name] = objects
+	}
+	// the vendor objects new to the package are added to their rendered file
+	names := make([]string, 0, len(renderedNodes))
+	for name := range renderedNodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, node := range renderedNodes[name] {
+			if id := idOf(node); renderedRefs[id] && !placed[id] {
+				publishedNodes[name] = append(publishedNodes[name], node)
+				placed[id] = true
+			}
+		}
+	}
+
+	for name, nodes := range publishedNodes {
+		if len(nodes) == 0 {
+			continue
+		}
+		contents := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			contents = append(contents, node.MustString())
+		}
+		content := strings.Join(contents, util.YamlObjectDelimiter+"\n")
+		if len(drift.Diff(map[string]string{name: content},
+			map[string]string{name: published[name]})) == 0 {
+			content = published[name]
+		}
+		updated[name] = content
+	}
+	return updated, nil
+}
+
+// objectID identifies an object of a package the way a VendorRef does
+type objectID struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+func idOf(node *yaml.RNode) objectID {
+	return objectID{kind: node.GetKind(), namespace: node.GetNamespace(), name: node.GetName()}
+}
+
+// vendorRef returns the object referenced by the VendorRef of node, false if
+// it has none
+func vendorRef(node *yaml.RNode) (objectID, bool) {
+	name, err := node.GetString("spec.vendorRef.name")
+	if err != nil || name == "" {
+		return objectID{}, false
+	}
+	kind, _ := node.GetString("spec.vendorRef.kind")
+	namespace, _ := node.GetString("spec.vendorRef.namespace")
+	return objectID{kind: kind, namespace: namespace, name: name}, true
+}
+
+// copyVendorRef sets the VendorRef of to the one of from, if any of them has
+// one
+func copyVendorRef(from, to *yaml.RNode) error {
+	ref, err := from.Pipe(yaml.Lookup("spec", "vendorRef"))
+	if err != nil {
+		return err
+	}
+	if ref != nil {
+		return to.SetMapField(ref.Copy(), "spec", "vendorRef")
+	}
+	spec, err := to.Pipe(yaml.Lookup("spec"))
+	if err != nil || spec == nil {
+		return err
+	}
+	_, err = spec.Pipe(yaml.Clear("vendorRef"))
+	return err
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/catalog"
+	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+)
+
+var _ = Describe("ParsePackageName", func() {
+	It("Should parse the actuators and extension packages", func() {
+		key, kind, ok := catalog.ParsePackageName("casa/1.0/upf/actuators")
+		Expect(ok).To(BeTrue())
+		Expect(kind).To(Equal(catalog.Actuators))
+		Expect(key).To(Equal(packageservice.VendorNFKey{Vendor: "casa", Version: "1.0", NFType: "upf"}))
+
+		key, kind, ok = catalog.ParsePackageName("casa/1.0/upf/extension")
+		Expect(ok).To(BeTrue())
+		Expect(kind).To(Equal(catalog.Extension))
+		Expect(key.NFType).To(Equal("upf"))
+	})
+
+	It("Should not parse the other packages", func() {
+		for _, name := range []string{"nf-profiles", "casa/1.0/upf/docs", "casa/1.0/upf"} {
+			_, _, ok := catalog.ParsePackageName(name)
+			Expect(ok).To(BeFalse(), name)
+		}
+	})
+})
+
+var _ = Describe("Clusters", func() {
+	It("Should return the clusters of the sites running the vendor NF once", func() {
+		nfDeploy := v1alpha1.NfDeploy{
+			Spec: v1alpha1.NfDeploySpec{
+				Sites: []v1alpha1.Site{
+					{Id: "upf-1", ClusterName: "edge-1", NFType: "upf", NFVendor: "casa", NFVersion: "1.0"},
+					{Id: "upf-2", ClusterName: "edge-1", NFType: "upf", NFVendor: "casa", NFVersion: "1.0"},
+					{Id: "upf-3", ClusterName: "edge-2", NFType: "upf", NFVendor: "casa", NFVersion: "2.0"},
+					{Id: "smf-1", ClusterName: "core", NFType: "smf", NFVendor: "casa", NFVersion: "1.0"},
+					{Id: "upf-4", ClusterName: "edge-3", NFType: "upf", NFVendor: "casa", NFVersion: "1.0"},
+				},
+			},
+		}
+		Expect(catalog.Clusters(nfDeploy, packageservice.VendorNFKey{
+			Vendor: "casa", Version: "1.0", NFType: "upf",
+		})).To(Equal([]string{"edge-1", "edge-3"}))
+	})
+})
+
+var _ = Describe("UpdateVendorObjects", func() {
+	It("Should update the objects referenced by VendorRef only", func() {
+		published := map[string]string{
+			"Kptfile": "kind: Kptfile\n",
+			"upf-1.yaml": `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata:
+  name: upf-1
+spec:
+  replicas: 2
+  vendorRef:
+    kind: CasaUpfConfig
+    name: upf-1-extension
+---
+apiVersion: casa.com/v1
+kind: CasaUpfConfig
+metadata:
+  name: upf-1-extension
+spec:
+  logLevel: info
+`,
+			"upf-2.yaml": `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata:
+  name: upf-2
+spec:
+  replicas: 3
+`,
+		}
+		rendered := map[string]string{
+			"upf-1.yaml": `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata:
+  name: upf-1
+spec:
+  replicas: 1
+---
+`,
+			"upf-2.yaml": `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata:
+  name: upf-2
+spec:
+  replicas: 1
+  vendorRef:
+    kind: CasaUpfConfig
+    name: upf-2-extension
+---
+apiVersion: casa.com/v1
+kind: CasaUpfConfig
+metadata:
+  name: upf-2-extension
+spec:
+  logLevel: debug
+`,
+		}
+
+		updated, err := catalog.UpdateVendorObjects(rendered, published)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(Equal(map[string]string{
+			// the extension object is no longer referenced
+			"upf-1.yaml": `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata:
+  name: upf-1
+spec:
+  replicas: 2
+`,
+			// the extension object is new
+			"upf-2.yaml": `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata:
+  name: upf-2
+spec:
+  replicas: 3
+  vendorRef:
+    kind: CasaUpfConfig
+    name: upf-2-extension
+---
+apiVersion: casa.com/v1
+kind: CasaUpfConfig
+metadata:
+  name: upf-2-extension
+spec:
+  logLevel: debug
+`,
+		}))
+	})
+})
@@ -0,0 +1,288 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/drift"
+	"github.com/nephio-project/nf-deploy-controller/hydration"
+	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/status"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// rewatchDelay is the delay before the PackageRevisions are watched again
+// once a watch ends
+const rewatchDelay = 5 * time.Second
+
+// Watcher watches the PackageRevisions of the actuators and extension
+// packages of the vendor catalog. When a revision is published, it is
+// propagated to the clusters whose NfDeploys run the vendor NF: a new
+// revision of the actuators package is created in their deploy repos when
+// its content changed, and a new revision of their deploy packages is
+// created with the extension objects referenced by VendorRef when these
+// change their content, keeping the other objects as published. The created
+// packages are listed in status.catalogUpdates of the
+// NfDeploys until approved like any other package.
+type Watcher struct {
+	// Client reads the NfDeploys
+	Client client.Reader
+	// PorchClient watches the PackageRevisions
+	PorchClient      client.WithWatch
+	Hydration        hydration.HydrationInterface
+	PS               packageservice.PackageServiceInterface
+	StatusAggregator status.Aggregator
+	Log              logr.Logger
+
+	// lastRevisions are the last published revisions propagated, by catalog
+	// package name
+	lastRevisions map[string]string
+}
+
+// Start watches the PackageRevisions until ctx is done. Implements
+// manager.Runnable.
+func (w *Watcher) Start(ctx context.Context) error {
+	for {
+		if err := w.watch(ctx); err != nil {
+			w.Log.Error(err, "error watching the vendor catalog package revisions")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(rewatchDelay):
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable as the
+// packages are created by the leader only
+func (w *Watcher) NeedLeaderElection() bool {
+	return true
+}
+
+// watch handles the events of the PackageRevisions until the watch ends. A
+// new watch lists the existing revisions first, so that the revisions
+// published while no watch was running are propagated too. Propagating a
+// revision twice creates no package.
+func (w *Watcher) watch(ctx context.Context) error {
+	watcher, err := w.PorchClient.Watch(ctx, &porchapi.PackageRevisionList{},
+		client.InNamespace(nc.GetNamespace()))
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	if w.lastRevisions == nil {
+		w.lastRevisions = map[string]string{}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-watcher.ResultChan():
+			if !ok {
+				w.Log.V(1).Info("Watch of the package revisions ended")
+				return nil
+			}
+			if e.Type != watch.Added && e.Type != watch.Modified {
+				continue
+			}
+			pr, ok := e.Object.(*porchapi.PackageRevision)
+			if !ok || !isLatestCatalogPackage(pr) || w.lastRevisions[pr.Spec.PackageName] == pr.Name {
+				continue
+			}
+			if err := w.Published(ctx, pr.Spec.PackageName, pr.Name); err != nil {
+				w.Log.Error(err, "error propagating the published catalog revision",
+					"package", pr.Spec.PackageName, "revision", pr.Name)
+				continue
+			}
+			w.lastRevisions[pr.Spec.PackageName] = pr.Name
+		}
+	}
+}
+
+// Published propagates the published revision of the catalog package named
+// pkgName to the clusters of the NfDeploys running its vendor NF
+func (w *Watcher) Published(ctx context.Context, pkgName string, revision string) error {
+	key, kind, ok := ParsePackageName(pkgName)
+	if !ok {
+		return nil
+	}
+	var nfDeploys v1alpha1.NfDeployList
+	if err := w.Client.List(ctx, &nfDeploys); err != nil {
+		return fmt.Errorf("error listing NfDeploys: %w", err)
+	}
+	// the actuators packages are shared by the NfDeploys of a cluster
+	actuators := map[string]string{}
+	failed := []string{}
+	for _, nfDeploy := range nfDeploys.Items {
+		clusters := Clusters(nfDeploy, key)
+		if len(clusters) == 0 {
+			continue
+		}
+		if reason := skipReason(nfDeploy); reason != "" {
+			w.Log.V(1).Info("Skipping the propagation of the catalog revision",
+				"nfDeployName", nfDeploy.Name, "package", pkgName, "reason", reason)
+			continue
+		}
+		var names []string
+		var err error
+		switch kind {
+		case Actuators:
+			names, err = w.updateActuators(ctx, nfDeploy, key, clusters, actuators)
+		case Extension:
+			names, err = w.updateDeployPackages(ctx, nfDeploy, clusters)
+		}
+		if err != nil {
+			w.Log.Error(err, "error propagating the catalog revision",
+				"nfDeployName", nfDeploy.Name, "package", pkgName, "revision", revision)
+			failed = append(failed, nfDeploy.Name)
+			continue
+		}
+		if len(names) == 0 {
+			continue
+		}
+		w.Log.Info("Created packages from the catalog revision", "nfDeployName", nfDeploy.Name,
+			"package", pkgName, "revision", revision, "packageNames", names)
+		if err := w.StatusAggregator.SetCatalogUpdate(ctx,
+			types.NamespacedName{Namespace: nfDeploy.Namespace, Name: nfDeploy.Name},
+			v1alpha1.CatalogUpdate{
+				Package:            pkgName,
+				Revision:           revision,
+				PackageNames:       names,
+				ObservedGeneration: nfDeploy.Generation,
+			}); err != nil {
+			w.Log.Error(err, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+			failed = append(failed, nfDeploy.Name)
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("error propagating revision %s to NfDeploys %v", revision, failed)
+	}
+	return nil
+}
+
+// updateActuators creates a new revision of the actuators package of key in
+// the deploy repo of the clusters when its content differs from the catalog,
+// and returns the names of the created revisions. created caches the
+// revision of each cluster across the NfDeploys, empty when none is created.
+func (w *Watcher) updateActuators(ctx context.Context, nfDeploy v1alpha1.NfDeploy,
+	key packageservice.VendorNFKey, clusters []string, created map[string]string) ([]string, error) {
+	names := []string{}
+	for _, cluster := range clusters {
+		name, ok := created[cluster]
+		if !ok {
+			nc, err := util.NewNamingContext(cluster, nfDeploy.Name)
+			if err != nil {
+				return nil, fmt.Errorf("error creating naming context: %w", err)
+			}
+			pkgName, isNew, err := w.PS.CreateNFDeployActuators(ctx, nc, key)
+			if err != nil {
+				return nil, fmt.Errorf("error creating actuators in cluster %s: %w", cluster, err)
+			}
+			if isNew {
+				name = pkgName
+			}
+			created[cluster] = name
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// updateDeployPackages renders nfDeploy with the latest extension objects
+// and creates a new revision of the deploy package of the clusters whose
+// extension objects changed, and returns the names of the created revisions.
+// Only the extension objects are taken from the rendered content, so that
+// the drift of the other objects is left to the drift detection. The
+// packages with a revision awaiting approval are left as is.
+func (w *Watcher) updateDeployPackages(ctx context.Context, nfDeploy v1alpha1.NfDeploy,
+	clusters []string) ([]string, error) {
+	contents, err := w.Hydration.Render(ctx, nfDeploy)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering NfDeploy: %w", err)
+	}
+	names := []string{}
+	for _, cluster := range clusters {
+		nc, err := util.NewNamingContext(cluster, nfDeploy.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error creating naming context: %w", err)
+		}
+		revision, published, pending, err := w.PS.GetPublishedDeployPackage(ctx, nc)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching the deploy package of cluster %s: %w", cluster, err)
+		}
+		if pending || revision == "" {
+			w.Log.V(1).Info("Skipping the deploy package, a revision awaits approval",
+				"nfDeployName", nfDeploy.Name, "package", nc.GetDeployPackageName())
+			continue
+		}
+		updated, err := UpdateVendorObjects(contents[cluster], published)
+		if err != nil {
+			return nil, fmt.Errorf("error updating the deploy package of cluster %s: %w", cluster, err)
+		}
+		if len(drift.Diff(updated, published)) == 0 {
+			continue
+		}
+		name, err := w.PS.CreateDeployPackage(ctx, updated, nc)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the deploy package of cluster %s: %w", cluster, err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// skipReason returns why the catalog revisions are not propagated to
+// nfDeploy, empty if they are. The hydration of a new generation, a rollout
+// or an upgrade creates the packages from the latest catalog revisions.
+func skipReason(nfDeploy v1alpha1.NfDeploy) string {
+	switch {
+	case !nfDeploy.DeletionTimestamp.IsZero():
+		return "NfDeploy is being deleted"
+	case nfDeploy.Status.ObservedGeneration != nfDeploy.Generation:
+		return "the generation is not hydrated yet"
+	case !nfDeploy.Status.Rollout.IsComplete():
+		return "the rollout is in progress"
+	}
+	if upgrade := nfDeploy.Status.Upgrade; upgrade != nil &&
+		upgrade.ObservedGeneration == nfDeploy.Generation &&
+		upgrade.Phase == v1alpha1.UpgradeProgressing {
+		return "the upgrade is in progress"
+	}
+	return ""
+}
+
+// isLatestCatalogPackage returns true if pr is the latest published revision
+// of a package of the vendor catalog
+func isLatestCatalogPackage(pr *porchapi.PackageRevision) bool {
+	return pr.Namespace == nc.GetNamespace() &&
+		pr.Spec.RepositoryName == nc.GetVendorNFManifestsRepoName() &&
+		pr.Spec.Lifecycle == porchapi.PackageRevisionLifecyclePublished &&
+		pr.Labels[porchapi.LatestPackageRevisionKey] == porchapi.LatestPackageRevisionValue
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/catalog"
+	hydrationmock "github.com/nephio-project/nf-deploy-controller/hydration/mock"
+	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+	psmock "github.com/nephio-project/nf-deploy-controller/packageservice/mock"
+	"github.com/nephio-project/nf-deploy-controller/status"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+const upfDeploy = `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata:
+  name: upf-1
+spec:
+  vendorRef:
+    apiGroup: casa.com/v1
+    kind: CasaUpfConfig
+    name: upfdeploy-upf-1-extension
+`
+
+const upfExtension = `apiVersion: casa.com/v1
+kind: CasaUpfConfig
+metadata:
+  name: upfdeploy-upf-1-extension
+spec:
+  logLevel: %s
+`
+
+var _ = Describe("Watcher", func() {
+	var k8sClient client.WithWatch
+	var mockPS *psmock.MockPackageServiceInterface
+	var mockHydration *hydrationmock.MockHydrationInterface
+	var watcher *catalog.Watcher
+	ctx := context.Background()
+	key := packageservice.VendorNFKey{Vendor: "casa", Version: "1.0", NFType: "upf"}
+
+	nfDeploy := func(name string, generation, observedGeneration int64, sites ...v1alpha1.Site) *v1alpha1.NfDeploy {
+		return &v1alpha1.NfDeploy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Generation: generation},
+			Spec:       v1alpha1.NfDeploySpec{Sites: sites},
+			Status:     v1alpha1.NfDeployStatus{ObservedGeneration: observedGeneration},
+		}
+	}
+	upfSite := func(id, cluster string) v1alpha1.Site {
+		return v1alpha1.Site{Id: id, ClusterName: cluster, NFType: "upf", NFVendor: "casa", NFVersion: "1.0"}
+	}
+	catalogUpdates := func(name string) []v1alpha1.CatalogUpdate {
+		var nfDeploy v1alpha1.NfDeploy
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &nfDeploy)).
+			To(Succeed())
+		return nfDeploy.Status.CatalogUpdates
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(porchapi.AddToScheme(scheme)).To(Succeed())
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			nfDeploy("nf1", 2, 2, upfSite("upf-1", "edge-1"), upfSite("upf-2", "edge-2")),
+			nfDeploy("nf2", 1, 1, upfSite("upf-3", "edge-1")),
+			// the generation is being hydrated
+			nfDeploy("nf3", 3, 2, upfSite("upf-4", "edge-3")),
+			nfDeploy("nf4", 1, 1, v1alpha1.Site{
+				Id: "smf-1", ClusterName: "core", NFType: "smf", NFVendor: "casa", NFVersion: "1.0",
+			}),
+		).Build()
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockPS = psmock.NewMockPackageServiceInterface(mockCtrl)
+		mockHydration = hydrationmock.NewMockHydrationInterface(mockCtrl)
+		watcher = &catalog.Watcher{
+			Client:           k8sClient,
+			PorchClient:      k8sClient,
+			Hydration:        mockHydration,
+			PS:               mockPS,
+			StatusAggregator: status.NewAggregator(k8sClient, k8sClient.Status(), logr.Discard()),
+			Log:              logr.Discard(),
+		}
+	})
+
+	It("Should create the actuators packages whose content changed once per cluster", func() {
+		mockPS.EXPECT().CreateNFDeployActuators(gomock.Any(), gomock.Any(), key).
+			DoAndReturn(func(_ context.Context, nc util.NamingContext, _ packageservice.VendorNFKey) (string, bool, error) {
+				if nc.GetDeployRepoName() == "edge-1-deploy-repo" {
+					return "edge-1-actuators-v2", true, nil
+				}
+				return "edge-2-actuators-v1", false, nil
+			}).Times(2)
+
+		Expect(watcher.Published(ctx, "casa/1.0/upf/actuators", "catalog-v2")).To(Succeed())
+		update := v1alpha1.CatalogUpdate{
+			Package:            "casa/1.0/upf/actuators",
+			Revision:           "catalog-v2",
+			PackageNames:       []string{"edge-1-actuators-v2"},
+			ObservedGeneration: 2,
+		}
+		Expect(catalogUpdates("nf1")).To(Equal([]v1alpha1.CatalogUpdate{update}))
+		update.ObservedGeneration = 1
+		Expect(catalogUpdates("nf2")).To(Equal([]v1alpha1.CatalogUpdate{update}))
+		Expect(catalogUpdates("nf3")).To(BeEmpty())
+		Expect(catalogUpdates("nf4")).To(BeEmpty())
+	})
+
+	It("Should create the deploy packages whose extension objects changed", func() {
+		rendered := map[string]string{
+			"upf.yaml":       upfDeploy,
+			"extension.yaml": fmt.Sprintf(upfExtension, "debug"),
+		}
+		// edited by hand since published
+		edited := upfDeploy + "  replicas: 2\n"
+		mockHydration.EXPECT().Render(gomock.Any(), gomock.Any()).
+			Return(map[string]map[string]string{"edge-1": rendered, "edge-2": rendered}, nil).Times(2)
+		mockPS.EXPECT().GetPublishedDeployPackage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, nc util.NamingContext) (string, map[string]string, bool, error) {
+				switch nc.GetDeployPackageName() {
+				case "nf1-edge-1":
+					return "nf1-edge-1-v1", map[string]string{
+						"Kptfile":        "kind: Kptfile",
+						"upf.yaml":       edited,
+						"extension.yaml": fmt.Sprintf(upfExtension, "info"),
+					}, false, nil
+				case "nf1-edge-2":
+					return "nf1-edge-2-v1", rendered, false, nil
+				}
+				// a revision of the package awaits approval
+				return "nf2-edge-1-v1", nil, true, nil
+			}).Times(3)
+		// the hand edits are kept
+		mockPS.EXPECT().CreateDeployPackage(gomock.Any(), map[string]string{
+			"upf.yaml":       edited,
+			"extension.yaml": fmt.Sprintf(upfExtension, "debug"),
+		}, gomock.Any()).Return("nf1-edge-1-v2", nil)
+
+		Expect(watcher.Published(ctx, "casa/1.0/upf/extension", "catalog-v3")).To(Succeed())
+		Expect(catalogUpdates("nf1")).To(Equal([]v1alpha1.CatalogUpdate{
+			{
+				Package:            "casa/1.0/upf/extension",
+				Revision:           "catalog-v3",
+				PackageNames:       []string{"nf1-edge-1-v2"},
+				ObservedGeneration: 2,
+			},
+		}))
+		Expect(catalogUpdates("nf2")).To(BeEmpty())
+	})
+
+	It("Should propagate the revisions of the catalog packages once published", func() {
+		var calls int32
+		mockPS.EXPECT().CreateNFDeployActuators(gomock.Any(), gomock.Any(), key).
+			DoAndReturn(func(context.Context, util.NamingContext, packageservice.VendorNFKey) (string, bool, error) {
+				atomic.AddInt32(&calls, 1)
+				return "actuators-v1", false, nil
+			}).Times(2)
+		propagated := func() int32 { return atomic.LoadInt32(&calls) }
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(watcher.Start(watchCtx)).To(Succeed())
+		}()
+
+		pr := &porchapi.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "nephio-user", Name: "catalog-v2"},
+			Spec: porchapi.PackageRevisionSpec{
+				PackageName:    "casa/1.0/upf/actuators",
+				RepositoryName: "private-catalog",
+				Lifecycle:      porchapi.PackageRevisionLifecycleProposed,
+			},
+		}
+		Expect(k8sClient.Create(ctx, pr)).To(Succeed())
+		// modified until the watch is running
+		modify := func() {
+			defer GinkgoRecover()
+			pr.Annotations = map[string]string{"modified": time.Now().String()}
+			Expect(k8sClient.Update(ctx, pr)).To(Succeed())
+		}
+		Consistently(func() int32 { modify(); return propagated() }).Should(BeZero())
+
+		pr.Spec.Lifecycle = porchapi.PackageRevisionLifecyclePublished
+		pr.Labels = map[string]string{porchapi.LatestPackageRevisionKey: porchapi.LatestPackageRevisionValue}
+		Expect(k8sClient.Update(ctx, pr)).To(Succeed())
+		// once per cluster of the NfDeploys
+		Eventually(propagated).Should(Equal(int32(2)))
+
+		// the revision is propagated once
+		Consistently(func() int32 { modify(); return propagated() }).Should(Equal(int32(2)))
+		Expect(catalogUpdates("nf1")).To(BeEmpty())
+	})
+})
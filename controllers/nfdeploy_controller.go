@@ -18,26 +18,29 @@ package controllers
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	validator "github.com/nephio-project/common-lib/nfdeploy/validator"
 	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 	"github.com/nephio-project/nf-deploy-controller/deployment"
 	"github.com/nephio-project/nf-deploy-controller/hydration"
 	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
-	"github.com/nephio-project/nf-deploy-controller/util"
+	"github.com/nephio-project/nf-deploy-controller/status"
+	"github.com/nephio-project/nf-deploy-controller/upgrade"
 )
 
 var (
@@ -52,13 +55,37 @@ type NfDeployReconciler struct {
 	Log               logr.Logger
 	Hydration         hydration.HydrationInterface
 	PS                ps.PackageServiceInterface
+	// StatusAggregator is the only writer of the NfDeploy status
+	StatusAggregator status.Aggregator
+	// RolloutPollInterval is the interval at which the NFs of the current
+	// wave of a rollout or of an upgrade are checked.
+	// DefaultRolloutPollInterval is used when not set.
+	RolloutPollInterval time.Duration
+	// DeletionPollInterval is the interval at which a deleted NfDeploy is
+	// checked while the deletion of its deploy packages awaits approval or
+	// its NFs are being removed from the workload clusters.
+	// DefaultDeletionPollInterval is used when not set.
+	DeletionPollInterval time.Duration
+	// TerminationTimeout is the time the workload clusters have to confirm
+	// the removal of the NFs of a deleted NfDeploy before it is reported
+	// Stalled. DefaultTerminationTimeout is used when not set.
+	TerminationTimeout time.Duration
+	// ProfileEvents are the NfDeploys to hydrate again because NF profile
+	// objects they read changed. Not watched when nil.
+	ProfileEvents <-chan event.GenericEvent
+
+	// resubscriptions holds the deleted NfDeploys whose subscription to the
+	// edge events is being created again
+	resubscriptions sync.Map
 }
 
 //+kubebuilder:rbac:groups=nfdeploy.nephio.org,resources=nfdeploys,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=nfdeploy.nephio.org,resources=nfdeploys/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=nfdeploy.nephio.org,resources=nfdeploys/finalizers,verbs=update
-//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions;packagerevisionresources,verbs=get;list;create;update;delete
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions;packagerevisionresources,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=cloud.nephio.org,resources=edgeclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;update;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions/approval,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -81,6 +108,11 @@ func (r *NfDeployReconciler) Reconcile(
 	}
 
 	isDeleted, err := r.manageNfDeployFinalizer(ctx, req)
+	if errors.Is(err, errDeletionNotApproved) || errors.Is(err, errNFsNotRemoved) {
+		r.Log.Info("Deletion of NfDeploy is pending", "nfDeploy", nfDeploy.Name,
+			"reason", err.Error())
+		return ctrl.Result{RequeueAfter: r.deletionPollInterval()}, nil
+	}
 	if err != nil {
 		r.Log.Error(err, "error managing finalizer")
 		return ctrl.Result{}, err
@@ -95,164 +127,182 @@ func (r *NfDeployReconciler) Reconcile(
 	}
 	r.Log.Info("Started to process NfDeploy", "nfDeploy", nfDeploy.Name)
 
-	if err := r.setInitialStatus(ctx, req, nfDeploy.Generation); err != nil {
+	if nfDeploy.Spec.Rollout.IsOrdered() || nfDeploy.Spec.Rollout.IsProgressive() {
+		return r.reconcileRollout(ctx, req, nfDeploy)
+	}
+	return r.reconcileAllAtOnce(ctx, req, nfDeploy)
+}
+
+// reconcileAllAtOnce creates the packages of all the sites of nfDeploy. When
+// the NF software version of sites changes, the previous revisions of their
+// packages are recorded first and the upgrade is tracked until it succeeds or
+// is rolled back.
+func (r *NfDeployReconciler) reconcileAllAtOnce(ctx context.Context,
+	req ctrl.Request, nfDeploy nfdeployv1alpha1.NfDeploy) (ctrl.Result, error) {
+	previous := nfDeploy.Status.Upgrade
+	if previous != nil && previous.ObservedGeneration == nfDeploy.Generation {
+		switch previous.Phase {
+		case nfdeployv1alpha1.UpgradeProgressing:
+			return r.reconcileUpgrade(ctx, req, nfDeploy, *previous)
+		case nfdeployv1alpha1.UpgradeRolledBack:
+			r.Log.Info("Upgrade of the generation was rolled back, waiting for a new generation",
+				"nfDeploy", nfDeploy.Name)
+			return ctrl.Result{}, nil
+		}
+	}
+	var upgradedSites []nfdeployv1alpha1.SiteUpgrade
+	var previousRevisions []nfdeployv1alpha1.PackageRevisionRef
+	if previous != nil {
+		upgradedSites = upgrade.UpgradedSites(nfDeploy, previous.Versions)
+	}
+	if len(upgradedSites) != 0 {
+		var err error
+		previousRevisions, err = r.getPackageRevisions(ctx, nfDeploy, upgradedSites)
+		if err != nil {
+			r.Log.Error(err, "error recording package revisions before upgrade", "nfDeployName", nfDeploy.Name)
+			if e := r.setHydrationFailureStatus(ctx, req, nfDeploy.Generation, err, nil); e != nil {
+				r.Log.Error(e, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+				return ctrl.Result{}, e
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	packageNames, profiles, err := r.hydrate(ctx, req, nfDeploy, nil, nil)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	upgradeStatus := upgrade.Hydrated(previous, nfDeploy, upgradedSites, previousRevisions, time.Now())
+	if err := r.setHydrationSuccessStatus(ctx, req, nfDeploy.Generation, packageNames,
+		nil, upgradeStatus, profiles); err != nil {
 		r.Log.Error(err, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
 		return ctrl.Result{}, err
 	}
+	if profiles != nil {
+		// the deployment resolves the intents from the hydrated profiles
+		nfDeploy.Status.Profiles = profiles
+	}
+	// all the sites are hydrated from the generation
+	nfDeploy.Status.Rollout = nil
+	go r.DeploymentManager.ReportNFDeployEvent(nfDeploy, req.NamespacedName)
+	if len(upgradedSites) != 0 {
+		r.Log.Info("Upgrading sites", "nfDeploy", nfDeploy.Name, "sites", upgradedSites)
+		return ctrl.Result{RequeueAfter: r.rolloutPollInterval()}, nil
+	}
+	r.Log.Info("Reconciled successfully!", "nfDeploy", nfDeploy.Name)
+	return ctrl.Result{}, nil
+}
+
+// hydrate creates the deploy and actuator packages of the sites of nfDeploy
+// and returns the names of the created packages and the NF profile objects
+// read to create them. When siteIds is not nil, only the sites with these
+// ids, the released sites of a rollout, are hydrated. The hydration of its
+// generation is reported in progress or failed along with rollout, the
+// progress of the ordered rollout before the sites are released, which is nil
+// when all the sites are rolled out at once.
+func (r *NfDeployReconciler) hydrate(ctx context.Context, req ctrl.Request,
+	nfDeploy nfdeployv1alpha1.NfDeploy, siteIds []string,
+	rollout *nfdeployv1alpha1.RolloutStatus) ([]string, *nfdeployv1alpha1.ProfilesStatus, error) {
+	if err := r.setInitialStatus(ctx, req, nfDeploy.Generation, rollout); err != nil {
+		r.Log.Error(err, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+		return nil, nil, err
+	}
 	createdPackageNames := []string{}
-	packageNames, err := r.Hydration.Hydrate(ctx, nfDeploy)
+	var packageNames []string
+	var profiles *nfdeployv1alpha1.ProfilesStatus
+	var err error
+	actuated := nfDeploy
+	if siteIds == nil {
+		packageNames, profiles, err = r.Hydration.Hydrate(ctx, nfDeploy)
+	} else {
+		packageNames, profiles, err = r.Hydration.HydrateSites(ctx, nfDeploy, siteIds)
+		actuated = withSites(nfDeploy, siteIds)
+	}
 	if err != nil {
 		r.Log.Error(err, "error hydrating nfDeploy", "nfDeployName", nfDeploy.Name)
-		if e := r.setHydrationFailureStatus(ctx, req, nfDeploy.Generation, err); e != nil {
+		if e := r.setHydrationFailureStatus(ctx, req, nfDeploy.Generation, err, rollout); e != nil {
 			r.Log.Error(e, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
-			return ctrl.Result{}, e
+			return nil, nil, e
 		}
-		return ctrl.Result{}, err
+		return nil, nil, err
 	}
 	createdPackageNames = append(createdPackageNames, packageNames...)
-	packageNames, err = r.Hydration.CreateNFDeployActuators(ctx, nfDeploy)
+	packageNames, err = r.Hydration.CreateNFDeployActuators(ctx, actuated)
 	if err != nil {
 		r.Log.Error(err, "error creating operator packages to actuate nfDeploy", "nfDeployName", nfDeploy.Name)
-		if e := r.setHydrationFailureStatus(ctx, req, nfDeploy.Generation, err); e != nil {
+		if e := r.setHydrationFailureStatus(ctx, req, nfDeploy.Generation, err, rollout); e != nil {
 			r.Log.Error(e, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
-			return ctrl.Result{}, e
+			return nil, nil, e
 		}
-		return ctrl.Result{}, err
+		return nil, nil, err
 	}
-	createdPackageNames = append(createdPackageNames, packageNames...)
+	return append(createdPackageNames, packageNames...), profiles, nil
+}
 
-	if err := r.setHydrationSuccessStatus(ctx, req, nfDeploy.Generation, createdPackageNames); err != nil {
-		r.Log.Error(err, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
-		return ctrl.Result{}, err
+// withSites returns nfDeploy with only the sites with the given ids
+func withSites(nfDeploy nfdeployv1alpha1.NfDeploy, siteIds []string) nfdeployv1alpha1.NfDeploy {
+	ids := make(map[string]bool, len(siteIds))
+	for _, siteId := range siteIds {
+		ids[siteId] = true
 	}
-	go r.DeploymentManager.ReportNFDeployEvent(nfDeploy, req.NamespacedName)
-	r.Log.Info("Reconciled successfully!", "nfDeploy", nfDeploy.Name)
-	return ctrl.Result{}, nil
+	result := *nfDeploy.DeepCopy()
+	result.Spec.Sites = nil
+	for _, site := range nfDeploy.Spec.Sites {
+		if ids[site.Id] {
+			result.Spec.Sites = append(result.Spec.Sites, *site.DeepCopy())
+		}
+	}
+	return result
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NfDeployReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&nfdeployv1alpha1.NfDeploy{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
-		Complete(r)
+		WithEventFilter(predicate.GenerationChangedPredicate{})
+	if r.ProfileEvents != nil {
+		// the generic events pass GenerationChangedPredicate
+		b = b.Watches(&source.Channel{Source: r.ProfileEvents}, &handler.EnqueueRequestForObject{})
+	}
+	return b.Complete(r)
 }
 
 func (r *NfDeployReconciler) setInitialStatus(ctx context.Context,
-	req ctrl.Request, generation int64) error {
-	return r.setNfDeployStatus(ctx, req, generation,
-		map[nfdeployv1alpha1.NFDeployConditionType]nfdeployv1alpha1.NFDeployCondition{
-			nfdeployv1alpha1.DeploymentReconciling: {
-				Type:    nfdeployv1alpha1.DeploymentReconciling,
-				Status:  corev1.ConditionTrue,
-				Reason:  "NewVersionAvailable",
-				Message: "Reconciling NfDeploy",
-			},
-			nfdeployv1alpha1.DeploymentStalled: {
-				Type:   nfdeployv1alpha1.DeploymentStalled,
-				Status: corev1.ConditionFalse,
-			},
-			nfdeployv1alpha1.DeploymentPeering: {
-				Type:   nfdeployv1alpha1.DeploymentPeering,
-				Status: corev1.ConditionUnknown,
-			},
-			nfdeployv1alpha1.DeploymentReady: {
-				Type:   nfdeployv1alpha1.DeploymentReady,
-				Status: corev1.ConditionUnknown,
-			},
+	req ctrl.Request, generation int64, rollout *nfdeployv1alpha1.RolloutStatus) error {
+	return r.StatusAggregator.SetHydrationStatus(ctx, req.NamespacedName,
+		status.HydrationStatus{
+			Generation: generation,
+			Phase:      status.Hydrating,
+			Rollout:    rollout,
 		})
 }
 
 func (r *NfDeployReconciler) setHydrationSuccessStatus(ctx context.Context,
-	req ctrl.Request, generation int64, packageNames []string) error {
-	return r.setNfDeployStatus(ctx, req, generation,
-		map[nfdeployv1alpha1.NFDeployConditionType]nfdeployv1alpha1.NFDeployCondition{
-			nfdeployv1alpha1.DeploymentReconciling: {
-				Type:    nfdeployv1alpha1.DeploymentReconciling,
-				Status:  corev1.ConditionTrue,
-				Reason:  "AwaitingApproval",
-				Message: fmt.Sprintf("These porch packages needs to be approved: %v", packageNames),
-			},
-			nfdeployv1alpha1.DeploymentStalled: {
-				Type:   nfdeployv1alpha1.DeploymentStalled,
-				Status: corev1.ConditionFalse,
-			},
-			nfdeployv1alpha1.DeploymentPeering: {
-				Type:   nfdeployv1alpha1.DeploymentPeering,
-				Status: corev1.ConditionUnknown,
-			},
-			nfdeployv1alpha1.DeploymentReady: {
-				Type:   nfdeployv1alpha1.DeploymentReady,
-				Status: corev1.ConditionUnknown,
-			},
+	req ctrl.Request, generation int64, packageNames []string,
+	rollout *nfdeployv1alpha1.RolloutStatus, upgradeStatus *nfdeployv1alpha1.UpgradeStatus,
+	profiles *nfdeployv1alpha1.ProfilesStatus) error {
+	return r.StatusAggregator.SetHydrationStatus(ctx, req.NamespacedName,
+		status.HydrationStatus{
+			Generation:   generation,
+			Phase:        status.AwaitingApproval,
+			PackageNames: packageNames,
+			Rollout:      rollout,
+			Upgrade:      upgradeStatus,
+			Profiles:     profiles,
 		})
 }
 
 func (r *NfDeployReconciler) setHydrationFailureStatus(ctx context.Context,
-	req ctrl.Request, generation int64, err error) error {
-	return r.setNfDeployStatus(ctx, req, generation,
-		map[nfdeployv1alpha1.NFDeployConditionType]nfdeployv1alpha1.NFDeployCondition{
-			nfdeployv1alpha1.DeploymentReconciling: {
-				Type:    nfdeployv1alpha1.DeploymentReconciling,
-				Status:  corev1.ConditionFalse,
-				Reason:  "Stalled",
-				Message: fmt.Errorf("Error Hydrating NfDeploy: %w", err).Error(),
-			},
-			nfdeployv1alpha1.DeploymentStalled: {
-				Type:    nfdeployv1alpha1.DeploymentStalled,
-				Status:  corev1.ConditionTrue,
-				Reason:  "HydrationFailure",
-				Message: fmt.Errorf("Error Hydrating NfDeploy: %w", err).Error(),
-			},
-			nfdeployv1alpha1.DeploymentPeering: {
-				Type:   nfdeployv1alpha1.DeploymentPeering,
-				Status: corev1.ConditionUnknown,
-			},
-			nfdeployv1alpha1.DeploymentReady: {
-				Type:   nfdeployv1alpha1.DeploymentReady,
-				Status: corev1.ConditionUnknown,
-			},
+	req ctrl.Request, generation int64, err error,
+	rollout *nfdeployv1alpha1.RolloutStatus) error {
+	return r.StatusAggregator.SetHydrationStatus(ctx, req.NamespacedName,
+		status.HydrationStatus{
+			Generation: generation,
+			Phase:      status.HydrationFailed,
+			Err:        err,
+			Rollout:    rollout,
 		})
 }
 
-func (r *NfDeployReconciler) setNfDeployStatus(ctx context.Context,
-	req ctrl.Request, generation int64,
-	condMap map[nfdeployv1alpha1.NFDeployConditionType]nfdeployv1alpha1.NFDeployCondition) error {
-
-	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		// fetching latest nfDeploy
-		var nfDeploy nfdeployv1alpha1.NfDeploy
-		if err := r.Get(ctx, req.NamespacedName, &nfDeploy); err != nil {
-			return err
-		}
-
-		currConditions := make(map[nfdeployv1alpha1.NFDeployConditionType]nfdeployv1alpha1.NFDeployCondition)
-		now := metav1.NewTime(time.Now())
-		for _, c := range nfDeploy.Status.Conditions {
-			currConditions[c.Type] = c
-			cond := condMap[c.Type]
-			if c.Status != cond.Status {
-				cond.LastTransitionTime = now
-			}
-			if c.Status != cond.Status || c.Reason != cond.Reason || c.Message != cond.Message {
-				cond.LastUpdateTime = now
-			}
-			condMap[c.Type] = cond
-		}
-		conditions := []nfdeployv1alpha1.NFDeployCondition{}
-		for _, v := range condMap {
-			conditions = append(conditions, v)
-		}
-		nfDeploy.Status.ObservedGeneration = int32(generation)
-		nfDeploy.Status.Conditions = conditions
-		if err := r.Status().Update(ctx, &nfDeploy); err != nil {
-			return fmt.Errorf("error updating NfDeploy status: %w", err)
-		}
-		return nil
-	})
-	return err
-}
-
 func (r *NfDeployReconciler) manageNfDeployFinalizer(ctx context.Context, req ctrl.Request) (bool, error) {
 	isDeleted := false
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
@@ -296,21 +346,3 @@ func (r *NfDeployReconciler) manageNfDeployFinalizer(ctx context.Context, req ct
 	})
 	return isDeleted, err
 }
-
-func (r *NfDeployReconciler) handleResourceDeletion(ctx context.Context, nfDeploy *nfdeployv1alpha1.NfDeploy) error {
-	clusterMap := make(map[string]bool)
-	for _, s := range nfDeploy.Spec.Sites {
-		clusterMap[s.ClusterName] = true
-	}
-	for cluster := range clusterMap {
-		nc, err := util.NewNamingContext(cluster, nfDeploy.Name)
-		if err != nil {
-			return err
-		}
-		if err = r.PS.DeleteDeployPackage(ctx, nc); err != nil {
-			return err
-		}
-	}
-	r.DeploymentManager.ReportNFDeployDeleteEvent(*nfDeploy)
-	return nil
-}
@@ -40,6 +40,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 var (
@@ -48,9 +49,9 @@ var (
 )
 
 func generateUPFEdgeEvent(
-	stalledStatus corev1.ConditionStatus, availableStatus corev1.ConditionStatus,
-	readyStatus corev1.ConditionStatus, peeringStatus corev1.ConditionStatus,
-	reconcilingStatus corev1.ConditionStatus, name string,
+	stalledStatus metav1.ConditionStatus, availableStatus metav1.ConditionStatus,
+	readyStatus metav1.ConditionStatus, peeringStatus metav1.ConditionStatus,
+	reconcilingStatus metav1.ConditionStatus, name string,
 ) preprocessor.Event {
 	upfDeploy := types2.UpfDeploy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -59,11 +60,11 @@ func generateUPFEdgeEvent(
 		},
 		Status: types2.UpfDeployStatus{
 			Conditions: []types2.NFCondition{
-				{Type: types2.Stalled, Status: stalledStatus},
-				{Type: types2.Reconciling, Status: reconcilingStatus},
-				{Type: types2.Available, Status: availableStatus},
-				{Type: types2.Peering, Status: peeringStatus},
-				{Type: types2.Ready, Status: readyStatus},
+				{Type: types2.Stalled, Status: corev1.ConditionStatus(stalledStatus)},
+				{Type: types2.Reconciling, Status: corev1.ConditionStatus(reconcilingStatus)},
+				{Type: types2.Available, Status: corev1.ConditionStatus(availableStatus)},
+				{Type: types2.Peering, Status: corev1.ConditionStatus(peeringStatus)},
+				{Type: types2.Ready, Status: corev1.ConditionStatus(readyStatus)},
 			},
 		},
 	}
@@ -82,9 +83,9 @@ func generateUPFEdgeEvent(
 }
 
 func generateSMFEdgeEvent(
-	stalledStatus corev1.ConditionStatus, availableStatus corev1.ConditionStatus,
-	readyStatus corev1.ConditionStatus, peeringStatus corev1.ConditionStatus,
-	reconcilingStatus corev1.ConditionStatus, name string,
+	stalledStatus metav1.ConditionStatus, availableStatus metav1.ConditionStatus,
+	readyStatus metav1.ConditionStatus, peeringStatus metav1.ConditionStatus,
+	reconcilingStatus metav1.ConditionStatus, name string,
 ) preprocessor.Event {
 	smfDeploy := types2.SmfDeploy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -93,11 +94,11 @@ func generateSMFEdgeEvent(
 		},
 		Status: types2.SmfDeployStatus{
 			Conditions: []types2.NFCondition{
-				{Type: types2.Stalled, Status: stalledStatus},
-				{Type: types2.Reconciling, Status: reconcilingStatus},
-				{Type: types2.Available, Status: availableStatus},
-				{Type: types2.Peering, Status: peeringStatus},
-				{Type: types2.Ready, Status: readyStatus},
+				{Type: types2.Stalled, Status: corev1.ConditionStatus(stalledStatus)},
+				{Type: types2.Reconciling, Status: corev1.ConditionStatus(reconcilingStatus)},
+				{Type: types2.Available, Status: corev1.ConditionStatus(availableStatus)},
+				{Type: types2.Peering, Status: corev1.ConditionStatus(peeringStatus)},
+				{Type: types2.Ready, Status: corev1.ConditionStatus(readyStatus)},
 			},
 		},
 	}
@@ -117,9 +118,9 @@ func generateSMFEdgeEvent(
 }
 
 func generateUDMEdgeEvent(
-	stalledStatus corev1.ConditionStatus, availableStatus corev1.ConditionStatus,
-	readyStatus corev1.ConditionStatus, peeringStatus corev1.ConditionStatus,
-	reconcilingStatus corev1.ConditionStatus,
+	stalledStatus metav1.ConditionStatus, availableStatus metav1.ConditionStatus,
+	readyStatus metav1.ConditionStatus, peeringStatus metav1.ConditionStatus,
+	reconcilingStatus metav1.ConditionStatus,
 ) preprocessor.Event {
 	udmDeploy := types3.UdmDeploy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -128,11 +129,11 @@ func generateUDMEdgeEvent(
 		},
 		Status: types3.UdmDeployStatus{
 			Conditions: []types2.NFCondition{
-				{Type: types2.Stalled, Status: stalledStatus},
-				{Type: types2.Reconciling, Status: reconcilingStatus},
-				{Type: types2.Available, Status: availableStatus},
-				{Type: types2.Peering, Status: peeringStatus},
-				{Type: types2.Ready, Status: readyStatus},
+				{Type: types2.Stalled, Status: corev1.ConditionStatus(stalledStatus)},
+				{Type: types2.Reconciling, Status: corev1.ConditionStatus(reconcilingStatus)},
+				{Type: types2.Available, Status: corev1.ConditionStatus(availableStatus)},
+				{Type: types2.Peering, Status: corev1.ConditionStatus(peeringStatus)},
+				{Type: types2.Ready, Status: corev1.ConditionStatus(readyStatus)},
 			},
 		},
 	}
@@ -152,9 +153,9 @@ func generateUDMEdgeEvent(
 }
 
 func generateAUSFEdgeEvent(
-	stalledStatus corev1.ConditionStatus, availableStatus corev1.ConditionStatus,
-	readyStatus corev1.ConditionStatus, peeringStatus corev1.ConditionStatus,
-	reconcilingStatus corev1.ConditionStatus,
+	stalledStatus metav1.ConditionStatus, availableStatus metav1.ConditionStatus,
+	readyStatus metav1.ConditionStatus, peeringStatus metav1.ConditionStatus,
+	reconcilingStatus metav1.ConditionStatus,
 ) preprocessor.Event {
 	ausfDeploy := types4.AusfDeploy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -163,11 +164,11 @@ func generateAUSFEdgeEvent(
 		},
 		Status: types4.AusfDeployStatus{
 			Conditions: []types2.NFCondition{
-				{Type: types2.Stalled, Status: stalledStatus},
-				{Type: types2.Reconciling, Status: reconcilingStatus},
-				{Type: types2.Available, Status: availableStatus},
-				{Type: types2.Peering, Status: peeringStatus},
-				{Type: types2.Ready, Status: readyStatus},
+				{Type: types2.Stalled, Status: corev1.ConditionStatus(stalledStatus)},
+				{Type: types2.Reconciling, Status: corev1.ConditionStatus(reconcilingStatus)},
+				{Type: types2.Available, Status: corev1.ConditionStatus(availableStatus)},
+				{Type: types2.Peering, Status: corev1.ConditionStatus(peeringStatus)},
+				{Type: types2.Ready, Status: corev1.ConditionStatus(readyStatus)},
 			},
 		},
 	}
@@ -207,7 +208,7 @@ func getNfDeployCr(path string) (*v1alpha1.NfDeploy, error) {
 
 func executeAndTestEdgeEventSequence(
 	edgeEvents []preprocessor.Event,
-	finalExpectedStatus map[v1alpha1.NFDeployConditionType]corev1.ConditionStatus,
+	finalExpectedStatus map[v1alpha1.NFDeployConditionType]metav1.ConditionStatus,
 	nfDeployName string,
 ) {
 	nfDeploy, err := getNfDeployCr(crNfDeployPath)
@@ -222,7 +223,7 @@ func executeAndTestEdgeEventSequence(
 	}
 	var newNfDeploy v1alpha1.NfDeploy
 	Eventually(
-		func() map[v1alpha1.NFDeployConditionType]corev1.ConditionStatus {
+		func() map[v1alpha1.NFDeployConditionType]metav1.ConditionStatus {
 			// fetching latest nfDeploy
 			err := k8sClient.Get(
 				ctx, types.NamespacedName{
@@ -233,9 +234,9 @@ func executeAndTestEdgeEventSequence(
 			if err != nil {
 				return nil
 			}
-			newMap := make(map[v1alpha1.NFDeployConditionType]corev1.ConditionStatus)
+			newMap := make(map[v1alpha1.NFDeployConditionType]metav1.ConditionStatus)
 			for _, c := range newNfDeploy.Status.Conditions {
-				newMap[c.Type] = c.Status
+				newMap[v1alpha1.NFDeployConditionType(c.Type)] = c.Status
 			}
 			return newMap
 		},
@@ -313,7 +314,7 @@ var _ = Describe(
 								).Should(Succeed())
 								req := <-fakeDeploymentManager.SubscriptionReqChan
 								req.Error <- nil
-								var cond v1alpha1.NFDeployCondition
+								var cond metav1.Condition
 								var newNfDeploy v1alpha1.NfDeploy
 								Eventually(
 									func() (string, error) {
@@ -327,10 +328,8 @@ var _ = Describe(
 										if err != nil {
 											return "", err
 										}
-										conditionTypes := []v1alpha1.NFDeployConditionType{}
 										for _, c := range newNfDeploy.Status.Conditions {
-											conditionTypes = append(conditionTypes, c.Type)
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												cond = c
 												break
 											}
@@ -338,10 +337,10 @@ var _ = Describe(
 										return cond.Reason, nil
 									},
 								).Should(Equal("AwaitingApproval"))
-								Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+								Expect(cond.Status).To(Equal(metav1.ConditionTrue))
 								Expect(cond.Message).To(ContainSubstring("resourceName"))
 								Expect(cond.Message).To(ContainSubstring("operator-resourceName"))
-								Expect(newNfDeploy.Status.ObservedGeneration).To(Equal(int32(nfDeploy.Generation)))
+								Expect(newNfDeploy.Status.ObservedGeneration).To(Equal(nfDeploy.Generation))
 							},
 						)
 					},
@@ -361,10 +360,10 @@ var _ = Describe(
 										context.TODO(), nfDeploy,
 									),
 								).Should(Succeed())
-								var condReconciling, condStalled v1alpha1.NFDeployCondition
+								var condReconciling, condStalled metav1.Condition
 								var newNfDeploy v1alpha1.NfDeploy
 								Eventually(
-									func() (corev1.ConditionStatus, error) {
+									func() (metav1.ConditionStatus, error) {
 										// fetching latest nfDeploy
 										err := k8sClient.Get(
 											ctx, types.NamespacedName{
@@ -373,23 +372,21 @@ var _ = Describe(
 											}, &newNfDeploy,
 										)
 										if err != nil {
-											return corev1.ConditionUnknown, err
+											return metav1.ConditionUnknown, err
 										}
-										conditionTypes := []v1alpha1.NFDeployConditionType{}
 										for _, c := range newNfDeploy.Status.Conditions {
-											conditionTypes = append(conditionTypes, c.Type)
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												condReconciling = c
-											} else if c.Type == v1alpha1.DeploymentStalled {
+											} else if c.Type == string(v1alpha1.DeploymentStalled) {
 												condStalled = c
 											}
 										}
 										return condStalled.Status, nil
 									},
-								).Should(Equal(corev1.ConditionTrue))
+								).Should(Equal(metav1.ConditionTrue))
 
 								// For DeploymentReconciling
-								Expect(condReconciling.Status).To(Equal(corev1.ConditionFalse))
+								Expect(condReconciling.Status).To(Equal(metav1.ConditionFalse))
 								Expect(condReconciling.Reason).To(Equal("Stalled"))
 								Expect(condReconciling.Message).To(ContainSubstring(expectedErr.Error()))
 
@@ -413,10 +410,10 @@ var _ = Describe(
 										context.TODO(), nfDeploy,
 									),
 								).Should(Succeed())
-								var condReconciling, condStalled v1alpha1.NFDeployCondition
+								var condReconciling, condStalled metav1.Condition
 								var newNfDeploy v1alpha1.NfDeploy
 								Eventually(
-									func() (corev1.ConditionStatus, error) {
+									func() (metav1.ConditionStatus, error) {
 										// fetching latest nfDeploy
 										err := k8sClient.Get(
 											ctx, types.NamespacedName{
@@ -425,23 +422,21 @@ var _ = Describe(
 											}, &newNfDeploy,
 										)
 										if err != nil {
-											return corev1.ConditionUnknown, err
+											return metav1.ConditionUnknown, err
 										}
-										conditionTypes := []v1alpha1.NFDeployConditionType{}
 										for _, c := range newNfDeploy.Status.Conditions {
-											conditionTypes = append(conditionTypes, c.Type)
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												condReconciling = c
-											} else if c.Type == v1alpha1.DeploymentStalled {
+											} else if c.Type == string(v1alpha1.DeploymentStalled) {
 												condStalled = c
 											}
 										}
 										return condStalled.Status, nil
 									},
-								).Should(Equal(corev1.ConditionTrue))
+								).Should(Equal(metav1.ConditionTrue))
 
 								// For DeploymentReconciling
-								Expect(condReconciling.Status).To(Equal(corev1.ConditionFalse))
+								Expect(condReconciling.Status).To(Equal(metav1.ConditionFalse))
 								Expect(condReconciling.Reason).To(Equal("Stalled"))
 								Expect(condReconciling.Message).To(ContainSubstring(expectedErr.Error()))
 
@@ -466,7 +461,7 @@ var _ = Describe(
 						nfDeploy.Name = "nfdeploy-deletion"
 
 						Expect(k8sClient.Create(context.TODO(), nfDeploy)).Should(Succeed())
-						var cond v1alpha1.NFDeployCondition
+						var cond metav1.Condition
 						var newNfDeploy v1alpha1.NfDeploy
 						req := <-fakeDeploymentManager.SubscriptionReqChan
 						req.Error <- nil
@@ -481,10 +476,8 @@ var _ = Describe(
 								); err != nil {
 									return "", err
 								}
-								conditionTypes := []v1alpha1.NFDeployConditionType{}
 								for _, c := range newNfDeploy.Status.Conditions {
-									conditionTypes = append(conditionTypes, c.Type)
-									if c.Type == v1alpha1.DeploymentReconciling {
+									if c.Type == string(v1alpha1.DeploymentReconciling) {
 										cond = c
 										break
 									}
@@ -531,7 +524,7 @@ var _ = Describe(
 						nfDeploy.Name = "nfdeploy-deletion-error"
 
 						Expect(k8sClient.Create(context.TODO(), nfDeploy)).Should(Succeed())
-						var cond v1alpha1.NFDeployCondition
+						var cond metav1.Condition
 						var newNfDeploy v1alpha1.NfDeploy
 						req := <-fakeDeploymentManager.SubscriptionReqChan
 						req.Error <- nil
@@ -546,10 +539,8 @@ var _ = Describe(
 								); err != nil {
 									return "", err
 								}
-								conditionTypes := []v1alpha1.NFDeployConditionType{}
 								for _, c := range newNfDeploy.Status.Conditions {
-									conditionTypes = append(conditionTypes, c.Type)
-									if c.Type == v1alpha1.DeploymentReconciling {
+									if c.Type == string(v1alpha1.DeploymentReconciling) {
 										cond = c
 										break
 									}
@@ -619,10 +610,8 @@ var _ = Describe(
 										if err != nil {
 											return ""
 										}
-										conditionTypes := []v1alpha1.NFDeployConditionType{}
 										for _, c := range newNfDeploy.Status.Conditions {
-											conditionTypes = append(conditionTypes, c.Type)
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												return c.Reason
 											}
 										}
@@ -630,7 +619,7 @@ var _ = Describe(
 									},
 								).Should(Equal("EdgeConnectionFailure"))
 								for _, value := range newNfDeploy.Status.Conditions {
-									Expect(value.Status).To(Equal(corev1.ConditionUnknown))
+									Expect(value.Status).To(Equal(metav1.ConditionUnknown))
 									Expect(value.Reason).To(Equal("EdgeConnectionFailure"))
 								}
 							},
@@ -667,10 +656,8 @@ var _ = Describe(
 										if err != nil {
 											return ""
 										}
-										conditionTypes := []v1alpha1.NFDeployConditionType{}
 										for _, c := range newNfDeploy.Status.Conditions {
-											conditionTypes = append(conditionTypes, c.Type)
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												return c.Reason
 											}
 										}
@@ -678,7 +665,7 @@ var _ = Describe(
 									},
 								).Should(Equal("EdgeConnectionFailure"))
 								for _, value := range newNfDeploy.Status.Conditions {
-									Expect(value.Status).To(Equal(corev1.ConditionUnknown))
+									Expect(value.Status).To(Equal(metav1.ConditionUnknown))
 									Expect(value.Reason).To(Equal("EdgeConnectionFailure"))
 								}
 							},
@@ -715,10 +702,8 @@ var _ = Describe(
 										if err != nil {
 											return ""
 										}
-										conditionTypes := []v1alpha1.NFDeployConditionType{}
 										for _, c := range newNfDeploy.Status.Conditions {
-											conditionTypes = append(conditionTypes, c.Type)
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												return c.Reason
 											}
 										}
@@ -726,7 +711,7 @@ var _ = Describe(
 									},
 								).Should(Equal("EdgeConnectionBroken"))
 								for _, value := range newNfDeploy.Status.Conditions {
-									Expect(value.Status).To(Equal(corev1.ConditionUnknown))
+									Expect(value.Status).To(Equal(metav1.ConditionUnknown))
 									Expect(value.Reason).To(Equal("EdgeConnectionBroken"))
 								}
 							},
@@ -750,9 +735,9 @@ var _ = Describe(
 								req := <-fakeDeploymentManager.SubscriptionReqChan
 								req.Error <- nil
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionUnknown, corev1.ConditionUnknown,
-									corev1.ConditionUnknown, corev1.ConditionUnknown,
-									corev1.ConditionUnknown, "upf-dummy",
+									metav1.ConditionUnknown, metav1.ConditionUnknown,
+									metav1.ConditionUnknown, metav1.ConditionUnknown,
+									metav1.ConditionUnknown, "upf-dummy",
 								)
 								var newNfDeploy v1alpha1.NfDeploy
 								Consistently(
@@ -768,10 +753,8 @@ var _ = Describe(
 											return ""
 										}
 
-										conditionTypes := []v1alpha1.NFDeployConditionType{}
 										for _, c := range newNfDeploy.Status.Conditions {
-											conditionTypes = append(conditionTypes, c.Type)
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												return c.Reason
 											}
 										}
@@ -803,9 +786,9 @@ var _ = Describe(
 
 								// first NF reconciling
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionTrue, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionTrue, "upf-dummy",
 								)
 								var newNfDeploy v1alpha1.NfDeploy
 								Eventually(
@@ -822,7 +805,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												return c.Reason
 											}
 										}
@@ -832,9 +815,9 @@ var _ = Describe(
 
 								// second NF reconciling
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionTrue, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionTrue, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -850,7 +833,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												return c.Reason
 											}
 										}
@@ -860,14 +843,14 @@ var _ = Describe(
 
 								// No NF reconciling
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "upf-dummy",
 								)
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -883,7 +866,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												return c.Reason
 											}
 										}
@@ -893,14 +876,14 @@ var _ = Describe(
 
 								// All NFs reconciled
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, "upf-dummy",
 								)
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -916,7 +899,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentReconciling {
+											if c.Type == string(v1alpha1.DeploymentReconciling) {
 												return c.Reason
 											}
 										}
@@ -946,9 +929,9 @@ var _ = Describe(
 
 								// first NF peering
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionTrue, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionTrue, "upf-dummy",
 								)
 								var newNfDeploy v1alpha1.NfDeploy
 								Eventually(
@@ -965,7 +948,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentPeering {
+											if c.Type == string(v1alpha1.DeploymentPeering) {
 												return c.Reason
 											}
 										}
@@ -975,9 +958,9 @@ var _ = Describe(
 
 								// second NF peering
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionTrue, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionTrue, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -993,7 +976,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentPeering {
+											if c.Type == string(v1alpha1.DeploymentPeering) {
 												return c.Reason
 											}
 										}
@@ -1003,14 +986,14 @@ var _ = Describe(
 
 								// No NF peering
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "upf-dummy",
 								)
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -1026,7 +1009,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentPeering {
+											if c.Type == string(v1alpha1.DeploymentPeering) {
 												return c.Reason
 											}
 										}
@@ -1036,14 +1019,14 @@ var _ = Describe(
 
 								// All NFs peered
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, "upf-dummy",
 								)
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -1059,7 +1042,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentPeering {
+											if c.Type == string(v1alpha1.DeploymentPeering) {
 												return c.Reason
 											}
 										}
@@ -1089,9 +1072,9 @@ var _ = Describe(
 
 								// first NF ready
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, "upf-dummy",
 								)
 								var newNfDeploy v1alpha1.NfDeploy
 								Eventually(
@@ -1108,7 +1091,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentReady {
+											if c.Type == string(v1alpha1.DeploymentReady) {
 												return c.Reason
 											}
 										}
@@ -1118,9 +1101,9 @@ var _ = Describe(
 
 								// second NF ready
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -1136,7 +1119,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentReady {
+											if c.Type == string(v1alpha1.DeploymentReady) {
 												return c.Reason
 											}
 										}
@@ -1146,14 +1129,14 @@ var _ = Describe(
 
 								// No NF ready
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "upf-dummy",
 								)
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -1169,7 +1152,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentReady {
+											if c.Type == string(v1alpha1.DeploymentReady) {
 												return c.Reason
 											}
 										}
@@ -1200,14 +1183,14 @@ var _ = Describe(
 
 								// first NF stalled
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "upf-dummy",
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "upf-dummy",
 								)
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "smf-dummy",
 								)
 								var newNfDeploy v1alpha1.NfDeploy
 								Eventually(
@@ -1224,7 +1207,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentStalled {
+											if c.Type == string(v1alpha1.DeploymentStalled) {
 												return c.Reason
 											}
 										}
@@ -1234,9 +1217,9 @@ var _ = Describe(
 
 								// second NF stalled
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "smf-dummy",
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -1252,7 +1235,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentStalled {
+											if c.Type == string(v1alpha1.DeploymentStalled) {
 												return c.Reason
 											}
 										}
@@ -1262,14 +1245,14 @@ var _ = Describe(
 
 								// No NF stalled
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "upf-dummy",
 								)
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, "smf-dummy",
 								)
 								Eventually(
 									func() string {
@@ -1285,7 +1268,7 @@ var _ = Describe(
 										}
 
 										for _, c := range newNfDeploy.Status.Conditions {
-											if c.Type == v1alpha1.DeploymentStalled {
+											if c.Type == string(v1alpha1.DeploymentStalled) {
 												return c.Reason
 											}
 										}
@@ -1304,43 +1287,43 @@ var _ = Describe(
 								var edgeEvents []preprocessor.Event
 								edgeEvents = append(
 									edgeEvents, generateUPFEdgeEvent(
-										corev1.ConditionFalse, corev1.ConditionFalse,
-										corev1.ConditionFalse, corev1.ConditionFalse,
-										corev1.ConditionFalse, "upf-dummy",
+										metav1.ConditionFalse, metav1.ConditionFalse,
+										metav1.ConditionFalse, metav1.ConditionFalse,
+										metav1.ConditionFalse, "upf-dummy",
 									), generateUPFEdgeEvent(
-										corev1.ConditionFalse, corev1.ConditionFalse,
-										corev1.ConditionTrue, corev1.ConditionFalse,
-										corev1.ConditionFalse, "upf-dummy",
+										metav1.ConditionFalse, metav1.ConditionFalse,
+										metav1.ConditionTrue, metav1.ConditionFalse,
+										metav1.ConditionFalse, "upf-dummy",
 									),
 									generateUPFEdgeEvent(
-										corev1.ConditionFalse, corev1.ConditionTrue,
-										corev1.ConditionFalse, corev1.ConditionTrue,
-										corev1.ConditionFalse, "upf-dummy",
+										metav1.ConditionFalse, metav1.ConditionTrue,
+										metav1.ConditionFalse, metav1.ConditionTrue,
+										metav1.ConditionFalse, "upf-dummy",
 									),
 									generateUPFEdgeEvent(
-										corev1.ConditionFalse, corev1.ConditionFalse,
-										corev1.ConditionTrue, corev1.ConditionTrue,
-										corev1.ConditionFalse, "upf-dummy",
+										metav1.ConditionFalse, metav1.ConditionFalse,
+										metav1.ConditionTrue, metav1.ConditionTrue,
+										metav1.ConditionFalse, "upf-dummy",
 									),
 									generateUPFEdgeEvent(
-										corev1.ConditionTrue, corev1.ConditionFalse,
-										corev1.ConditionTrue, corev1.ConditionFalse,
-										corev1.ConditionFalse, "upf-dummy",
+										metav1.ConditionTrue, metav1.ConditionFalse,
+										metav1.ConditionTrue, metav1.ConditionFalse,
+										metav1.ConditionFalse, "upf-dummy",
 									), generateUPFEdgeEvent(
-										corev1.ConditionFalse, corev1.ConditionTrue,
-										corev1.ConditionTrue, corev1.ConditionFalse,
-										corev1.ConditionFalse, "upf-dummy",
+										metav1.ConditionFalse, metav1.ConditionTrue,
+										metav1.ConditionTrue, metav1.ConditionFalse,
+										metav1.ConditionFalse, "upf-dummy",
 									), generateSMFEdgeEvent(
-										corev1.ConditionFalse, corev1.ConditionTrue,
-										corev1.ConditionTrue, corev1.ConditionFalse,
-										corev1.ConditionFalse, "smf-dummy",
+										metav1.ConditionFalse, metav1.ConditionTrue,
+										metav1.ConditionTrue, metav1.ConditionFalse,
+										metav1.ConditionFalse, "smf-dummy",
 									),
 								)
-								finalExpectedStatus := make(map[v1alpha1.NFDeployConditionType]corev1.ConditionStatus)
-								finalExpectedStatus[v1alpha1.DeploymentStalled] = corev1.ConditionFalse
-								finalExpectedStatus[v1alpha1.DeploymentPeering] = corev1.ConditionFalse
-								finalExpectedStatus[v1alpha1.DeploymentReady] = corev1.ConditionTrue
-								finalExpectedStatus[v1alpha1.DeploymentReconciling] = corev1.ConditionFalse
+								finalExpectedStatus := make(map[v1alpha1.NFDeployConditionType]metav1.ConditionStatus)
+								finalExpectedStatus[v1alpha1.DeploymentStalled] = metav1.ConditionFalse
+								finalExpectedStatus[v1alpha1.DeploymentPeering] = metav1.ConditionFalse
+								finalExpectedStatus[v1alpha1.DeploymentReady] = metav1.ConditionTrue
+								finalExpectedStatus[v1alpha1.DeploymentReconciling] = metav1.ConditionFalse
 								executeAndTestEdgeEventSequence(
 									edgeEvents, finalExpectedStatus, "edge-sequence-test-1",
 								)
@@ -1355,24 +1338,24 @@ var _ = Describe(
 								var edgeEvents []preprocessor.Event
 								edgeEvents = append(
 									edgeEvents, generateUPFEdgeEvent(
-										corev1.ConditionFalse, corev1.ConditionFalse,
-										corev1.ConditionFalse, corev1.ConditionFalse,
-										corev1.ConditionFalse, "upf-dummy",
+										metav1.ConditionFalse, metav1.ConditionFalse,
+										metav1.ConditionFalse, metav1.ConditionFalse,
+										metav1.ConditionFalse, "upf-dummy",
 									), generateUPFEdgeEvent(
-										corev1.ConditionUnknown, corev1.ConditionUnknown,
-										corev1.ConditionTrue, corev1.ConditionUnknown,
-										corev1.ConditionUnknown, "upf-dummy",
+										metav1.ConditionUnknown, metav1.ConditionUnknown,
+										metav1.ConditionTrue, metav1.ConditionUnknown,
+										metav1.ConditionUnknown, "upf-dummy",
 									), generateSMFEdgeEvent(
-										corev1.ConditionTrue, corev1.ConditionUnknown,
-										corev1.ConditionUnknown, corev1.ConditionUnknown,
-										corev1.ConditionTrue, "smf-dummy",
+										metav1.ConditionTrue, metav1.ConditionUnknown,
+										metav1.ConditionUnknown, metav1.ConditionUnknown,
+										metav1.ConditionTrue, "smf-dummy",
 									),
 								)
-								finalExpectedStatus := make(map[v1alpha1.NFDeployConditionType]corev1.ConditionStatus)
-								finalExpectedStatus[v1alpha1.DeploymentStalled] = corev1.ConditionTrue
-								finalExpectedStatus[v1alpha1.DeploymentPeering] = corev1.ConditionFalse
-								finalExpectedStatus[v1alpha1.DeploymentReady] = corev1.ConditionFalse
-								finalExpectedStatus[v1alpha1.DeploymentReconciling] = corev1.ConditionTrue
+								finalExpectedStatus := make(map[v1alpha1.NFDeployConditionType]metav1.ConditionStatus)
+								finalExpectedStatus[v1alpha1.DeploymentStalled] = metav1.ConditionTrue
+								finalExpectedStatus[v1alpha1.DeploymentPeering] = metav1.ConditionFalse
+								finalExpectedStatus[v1alpha1.DeploymentReady] = metav1.ConditionFalse
+								finalExpectedStatus[v1alpha1.DeploymentReconciling] = metav1.ConditionTrue
 								executeAndTestEdgeEventSequence(
 									edgeEvents, finalExpectedStatus, "edge-sequence-test-2",
 								)
@@ -1398,14 +1381,14 @@ var _ = Describe(
 								req.Error <- nil
 
 								req.SubscriberInfo.Channel <- generateAUSFEdgeEvent(
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse,
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse,
 								)
 								req.SubscriberInfo.Channel <- generateUDMEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse,
 								)
 								var newNfDeploy v1alpha1.NfDeploy
 								Eventually(
@@ -1426,9 +1409,9 @@ var _ = Describe(
 								).Should(Equal(int32(1)))
 
 								req.SubscriberInfo.Channel <- generateUDMEdgeEvent(
-									corev1.ConditionTrue, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse,
+									metav1.ConditionTrue, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse,
 								)
 								Eventually(
 									func() int32 {
@@ -1449,14 +1432,14 @@ var _ = Describe(
 
 								// No NF stalled
 								req.SubscriberInfo.Channel <- generateAUSFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse,
 								)
 								req.SubscriberInfo.Channel <- generateUDMEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionTrue,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionTrue,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse,
 								)
 								Eventually(
 									func() int32 {
@@ -1482,6 +1465,54 @@ var _ = Describe(
 			},
 		)
 
+		Context(
+			"When NF profile objects read by a rolled out NfDeploy change", func() {
+				It(
+					"Should hydrate the released sites again", func() {
+						nfDeploy, err := getNfDeployCr(crNfDeployPath)
+						Expect(err).NotTo(HaveOccurred())
+						nfDeploy.Name = "profiles-changed"
+						nfDeploy.Spec.Rollout = &v1alpha1.RolloutStrategy{
+							Type: v1alpha1.ProgressiveRollout,
+						}
+						Expect(k8sClient.Create(context.TODO(), nfDeploy)).Should(Succeed())
+						Eventually(fakeDeploymentManager.SignalChan).Should(Receive(nil))
+						req := <-fakeDeploymentManager.SubscriptionReqChan
+						req.Error <- nil
+
+						var newNfDeploy v1alpha1.NfDeploy
+						Eventually(
+							func(g Gomega) {
+								g.Expect(
+									k8sClient.Get(
+										ctx, types.NamespacedName{
+											Namespace: nfDeploy.Namespace,
+											Name:      nfDeploy.Name,
+										}, &newNfDeploy,
+									),
+								).To(Succeed())
+								// the sites of the single cluster are rolled out in one batch
+								g.Expect(newNfDeploy.Status.Rollout).NotTo(BeNil())
+								g.Expect(newNfDeploy.Status.Rollout.IsComplete()).To(BeTrue())
+								g.Expect(newNfDeploy.Status.Profiles).NotTo(BeNil())
+								g.Expect(newNfDeploy.Status.Profiles.Revision).To(Equal("nf-profiles-v1"))
+							},
+						).Should(Succeed())
+						Expect(fakeHydration.Hydrations(nfDeploy.Name)).To(Equal(1))
+
+						profileEvents <- event.GenericEvent{Object: &newNfDeploy}
+						Eventually(
+							func() int {
+								return fakeHydration.Hydrations(nfDeploy.Name)
+							},
+						).Should(Equal(2))
+						Eventually(fakeDeploymentManager.SignalChan).Should(Receive(nil))
+						Expect(k8sClient.Delete(ctx, &newNfDeploy)).To(Succeed())
+					},
+				)
+			},
+		)
+
 		Context(
 			"Deployment state cleanup on NFDeploy deletion", func() {
 				When(
@@ -1501,14 +1532,14 @@ var _ = Describe(
 								req.Error <- nil
 
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionTrue, "upf-dummy",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionTrue, "upf-dummy",
 								)
 								req.SubscriberInfo.Channel <- generateSMFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionUnknown,
-									corev1.ConditionTrue, corev1.ConditionUnknown,
-									corev1.ConditionUnknown, "smf-dummy",
+									metav1.ConditionFalse, metav1.ConditionUnknown,
+									metav1.ConditionTrue, metav1.ConditionUnknown,
+									metav1.ConditionUnknown, "smf-dummy",
 								)
 								Eventually(
 									func(g Gomega) {
@@ -1522,9 +1553,9 @@ var _ = Describe(
 										g.Expect(err).To(BeNil())
 
 										g.Expect((int)(newNfDeploy.Status.TargetedNFs)).To(Equal(len(nfDeploy.Spec.Sites)))
-										var cond v1alpha1.NFDeployCondition
+										var cond metav1.Condition
 										for _, condition := range newNfDeploy.Status.Conditions {
-											if condition.Type == v1alpha1.DeploymentReady {
+											if condition.Type == string(v1alpha1.DeploymentReady) {
 												cond = condition
 											}
 										}
@@ -1571,9 +1602,9 @@ var _ = Describe(
 								req = <-fakeDeploymentManager.SubscriptionReqChan
 								req.Error <- nil
 								req.SubscriberInfo.Channel <- generateUPFEdgeEvent(
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionFalse, corev1.ConditionFalse,
-									corev1.ConditionTrue, "upf-dummy-2",
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionFalse, metav1.ConditionFalse,
+									metav1.ConditionTrue, "upf-dummy-2",
 								)
 								Eventually(
 									func(g Gomega) {
@@ -1587,9 +1618,9 @@ var _ = Describe(
 										g.Expect(err).To(BeNil())
 
 										g.Expect((int)(newNfDeploy.Status.TargetedNFs)).To(Equal(len(nfDeploy2.Spec.Sites)))
-										var cond v1alpha1.NFDeployCondition
+										var cond metav1.Condition
 										for _, condition := range newNfDeploy.Status.Conditions {
-											if condition.Type == v1alpha1.DeploymentReady {
+											if condition.Type == string(v1alpha1.DeploymentReady) {
 												cond = condition
 											}
 										}
@@ -0,0 +1,195 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/status"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// DefaultDeletionPollInterval is the interval at which a deleted NfDeploy is
+// checked while the deletion of its deploy packages awaits approval or its NFs
+// are being removed from the workload clusters
+const DefaultDeletionPollInterval = 30 * time.Second
+
+// DefaultTerminationTimeout is the time the workload clusters have to confirm
+// the removal of the NFs of a deleted NfDeploy before it is reported Stalled
+const DefaultTerminationTimeout = 10 * time.Minute
+
+var (
+	// errDeletionNotApproved keeps the finalizer of NfDeploy until the
+	// proposed deletion of its deploy packages is approved
+	errDeletionNotApproved = errors.New("deletion of the deploy packages is not approved yet")
+	// errNFsNotRemoved keeps the finalizer of NfDeploy until the workload
+	// clusters confirm the removal of its NFs
+	errNFsNotRemoved = errors.New("removal of the NFs is not confirmed by the edge yet")
+)
+
+// handleResourceDeletion applies the deletion policy of NfDeploy to the
+// deploy packages of its clusters, waits for the NFs to be removed from the
+// workload clusters unless the packages are orphaned, then releases what
+// NfDeploy holds. It returns errDeletionNotApproved while deploy packages
+// retained until approved are still present, and errNFsNotRemoved while the
+// edge still reports NFs of NfDeploy.
+func (r *NfDeployReconciler) handleResourceDeletion(ctx context.Context, nfDeploy *nfdeployv1alpha1.NfDeploy) error {
+	clusterMap := make(map[string]bool)
+	for _, s := range nfDeploy.Spec.Sites {
+		clusterMap[s.ClusterName] = true
+	}
+	pending := []string{}
+	for cluster := range clusterMap {
+		nc, err := util.NewNamingContext(cluster, nfDeploy.Name)
+		if err != nil {
+			return err
+		}
+		switch nfDeploy.Spec.DeletionPolicy {
+		case nfdeployv1alpha1.OrphanDeletionPolicy:
+			err = r.PS.OrphanDeployPackage(ctx, nc)
+		case nfdeployv1alpha1.RetainUntilApprovedDeletionPolicy:
+			var names []string
+			names, err = r.PS.ProposeDeployPackageDeletion(ctx, nc)
+			pending = append(pending, names...)
+		default:
+			err = r.PS.DeleteDeployPackage(ctx, nc)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(pending) > 0 {
+		if err := r.StatusAggregator.SetHydrationStatus(ctx, client.ObjectKeyFromObject(nfDeploy),
+			status.HydrationStatus{
+				Generation:   nfDeploy.Generation,
+				Phase:        status.AwaitingDeletionApproval,
+				PackageNames: pending,
+			}); err != nil {
+			return err
+		}
+		return errDeletionNotApproved
+	}
+	// the NFs of orphaned packages keep running with their interface
+	// addresses, which are not released for other NfDeploys
+	if nfDeploy.Spec.DeletionPolicy != nfdeployv1alpha1.OrphanDeletionPolicy {
+		if err := r.awaitNFsRemoval(ctx, nfDeploy); err != nil {
+			return err
+		}
+		if err := r.Hydration.ReleaseAllocations(ctx, *nfDeploy); err != nil {
+			return err
+		}
+	}
+	r.DeploymentManager.ReportNFDeployDeleteEvent(*nfDeploy)
+	r.StatusAggregator.Forget(client.ObjectKeyFromObject(nfDeploy))
+	return nil
+}
+
+// awaitNFsRemoval returns errNFsNotRemoved and reports the sites whose NFs
+// are still reported by their workload cluster as Terminating, until none is.
+// NFs which never reported any edge event are considered removed, unless the
+// subscription to the edge events was created again for the deletion: their
+// workload cluster has to send an edge event, e.g. the list of its NFs, first.
+func (r *NfDeployReconciler) awaitNFsRemoval(ctx context.Context, nfDeploy *nfdeployv1alpha1.NfDeploy) error {
+	key := client.ObjectKeyFromObject(nfDeploy)
+	terminating, ok := r.DeploymentManager.GetTerminatingNFs(*nfDeploy)
+	if !ok {
+		// the edge events of NfDeploy are not listened to anymore, e.g. after
+		// a restart of the controller, so the subscription is created again
+		r.resubscribe(*nfDeploy.DeepCopy())
+		return errNFsNotRemoved
+	}
+	if len(terminating) == 0 {
+		return nil
+	}
+	termination := terminationStatus(nfDeploy.Status.Termination, nfDeploy.Spec.Sites,
+		terminating, metav1.Now(), r.terminationTimeout())
+	if err := r.StatusAggregator.SetHydrationStatus(ctx, key, status.HydrationStatus{
+		Generation:  nfDeploy.Generation,
+		Phase:       status.Terminating,
+		Termination: termination,
+	}); err != nil {
+		return err
+	}
+	return errNFsNotRemoved
+}
+
+// resubscribe creates the subscription to the edge events of the deleted
+// nfDeploy again, unless it is already being created. ReportNFDeployEvent
+// listens to the edge events of a new deployment, so it returns only once the
+// subscription fails or is cancelled, which allows a new one.
+func (r *NfDeployReconciler) resubscribe(nfDeploy nfdeployv1alpha1.NfDeploy) {
+	key := client.ObjectKeyFromObject(&nfDeploy)
+	if _, inFlight := r.resubscriptions.LoadOrStore(key, true); inFlight {
+		return
+	}
+	r.Log.Info("Subscribing to the edge events of the deleted NfDeploy again",
+		"nfDeploy", nfDeploy.Name)
+	go func() {
+		defer r.resubscriptions.Delete(key)
+		r.DeploymentManager.ReportNFDeployEvent(nfDeploy, key)
+		r.Log.Info("Subscription to the edge events of the deleted NfDeploy ended",
+			"nfDeploy", nfDeploy.Name)
+	}()
+}
+
+// terminationStatus returns the removal progress of the NFs of the sites,
+// given the ids of the sites whose NFs are still reported by the edge. The
+// start time of the previous status is kept.
+func terminationStatus(previous *nfdeployv1alpha1.TerminationStatus, sites []nfdeployv1alpha1.Site,
+	terminating []string, now metav1.Time, timeout time.Duration) *nfdeployv1alpha1.TerminationStatus {
+	termination := &nfdeployv1alpha1.TerminationStatus{StartTime: &now}
+	if previous != nil && previous.StartTime != nil {
+		termination.StartTime = previous.StartTime.DeepCopy()
+	}
+	terminatingSet := make(map[string]bool, len(terminating))
+	for _, site := range terminating {
+		terminatingSet[site] = true
+	}
+	for _, site := range sites {
+		phase := nfdeployv1alpha1.SiteRemoved
+		if terminatingSet[site.Id] {
+			phase = nfdeployv1alpha1.SiteTerminating
+		}
+		termination.Sites = append(termination.Sites, nfdeployv1alpha1.SiteTermination{
+			Site:        site.Id,
+			ClusterName: site.ClusterName,
+			Phase:       phase,
+		})
+	}
+	termination.TimedOut = now.Sub(termination.StartTime.Time) >= timeout
+	return termination
+}
+
+func (r *NfDeployReconciler) terminationTimeout() time.Duration {
+	if r.TerminationTimeout > 0 {
+		return r.TerminationTimeout
+	}
+	return DefaultTerminationTimeout
+}
+
+func (r *NfDeployReconciler) deletionPollInterval() time.Duration {
+	if r.DeletionPollInterval > 0 {
+		return r.DeletionPollInterval
+	}
+	return DefaultDeletionPollInterval
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	hydrationmock "github.com/nephio-project/nf-deploy-controller/hydration/mock"
+	psmock "github.com/nephio-project/nf-deploy-controller/packageservice/mock"
+	"github.com/nephio-project/nf-deploy-controller/tests/utils"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+var _ = Describe("NfDeploy deletion", func() {
+	var reconciler *NfDeployReconciler
+	var deploymentManager *utils.StubDeploymentManager
+	var mockHydration *hydrationmock.MockHydrationInterface
+	var mockPS *psmock.MockPackageServiceInterface
+	var nc util.NamingContext
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "deleted"}
+	req := ctrl.Request{NamespacedName: key}
+
+	// deleteNfDeploy creates the reconciler of a NfDeploy with the deletion
+	// policy and deletes the NfDeploy, which is kept by its finalizer
+	deleteNfDeploy := func(policy nfdeployv1alpha1.DeletionPolicy) {
+		nfDeploy := &nfdeployv1alpha1.NfDeploy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace, Name: key.Name, Generation: 1,
+				Finalizers: []string{nfDeployFinalizerName},
+			},
+			Spec: nfdeployv1alpha1.NfDeploySpec{
+				DeletionPolicy: policy,
+				Sites: []nfdeployv1alpha1.Site{
+					{Id: "upf-1", ClusterName: "cluster1", NFType: "upf"},
+				},
+			},
+		}
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockHydration = hydrationmock.NewMockHydrationInterface(mockCtrl)
+		mockPS = psmock.NewMockPackageServiceInterface(mockCtrl)
+		reconciler, deploymentManager = newStubReconciler(mockHydration, mockPS, nfDeploy)
+		deploymentManager.SetTerminatingNFs(nil, true)
+		Expect(reconciler.Delete(ctx, nfDeploy)).To(Succeed())
+	}
+	getNfDeploy := func() nfdeployv1alpha1.NfDeploy {
+		var nfDeploy nfdeployv1alpha1.NfDeploy
+		Expect(reconciler.Get(ctx, key, &nfDeploy)).To(Succeed())
+		return nfDeploy
+	}
+	// expectFinalized expects the reconciliation to remove the finalizer
+	expectFinalized := func() {
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		var nfDeploy nfdeployv1alpha1.NfDeploy
+		if err := reconciler.Get(ctx, key, &nfDeploy); err != nil {
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			return
+		}
+		Expect(nfDeploy.Finalizers).To(BeEmpty())
+	}
+	// expectPending expects the deletion to be pending
+	expectPending := func() {
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultDeletionPollInterval))
+		Expect(getNfDeploy().Finalizers).To(ConsistOf(nfDeployFinalizerName))
+	}
+	condition := func(conditionType nfdeployv1alpha1.NFDeployConditionType) *metav1.Condition {
+		return meta.FindStatusCondition(getNfDeploy().Status.Conditions, string(conditionType))
+	}
+
+	BeforeEach(func() {
+		var err error
+		nc, err = util.NewNamingContext("cluster1", key.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("When the deletion policy is Delete", func() {
+		It("Should delete the packages and release the allocations once the NFs are removed", func() {
+			deleteNfDeploy(nfdeployv1alpha1.DeleteDeletionPolicy)
+			mockPS.EXPECT().DeleteDeployPackage(gomock.Any(), nc).Return(nil)
+			mockHydration.EXPECT().ReleaseAllocations(gomock.Any(), gomock.Any()).Return(nil)
+			expectFinalized()
+		})
+	})
+
+	Context("When the deletion policy is Orphan", func() {
+		It("Should orphan the packages without waiting for the NFs", func() {
+			deleteNfDeploy(nfdeployv1alpha1.OrphanDeletionPolicy)
+			deploymentManager.SetTerminatingNFs([]string{"upf-1"}, true)
+			mockPS.EXPECT().OrphanDeployPackage(gomock.Any(), nc).Return(nil)
+			expectFinalized()
+		})
+	})
+
+	Context("When the deletion policy is RetainUntilApproved", func() {
+		It("Should keep NfDeploy until the proposed deletion is approved", func() {
+			deleteNfDeploy(nfdeployv1alpha1.RetainUntilApprovedDeletionPolicy)
+			mockPS.EXPECT().ProposeDeployPackageDeletion(gomock.Any(), nc).
+				Return([]string{"deploy-v1"}, nil)
+			expectPending()
+			reconciling := condition(nfdeployv1alpha1.DeploymentReconciling)
+			Expect(reconciling).NotTo(BeNil())
+			Expect(reconciling.Reason).To(Equal("AwaitingDeletionApproval"))
+			Expect(reconciling.Message).To(ContainSubstring("deploy-v1"))
+
+			By("finalizing NfDeploy once the revisions are gone")
+			mockPS.EXPECT().ProposeDeployPackageDeletion(gomock.Any(), nc).Return(nil, nil)
+			mockHydration.EXPECT().ReleaseAllocations(gomock.Any(), gomock.Any()).Return(nil)
+			expectFinalized()
+		})
+	})
+
+	Context("When the workload clusters do not confirm the removal of the NFs", func() {
+		It("Should report the termination timed out", func() {
+			deleteNfDeploy(nfdeployv1alpha1.DeleteDeletionPolicy)
+			reconciler.TerminationTimeout = time.Millisecond
+			deploymentManager.SetTerminatingNFs([]string{"upf-1"}, true)
+			mockPS.EXPECT().DeleteDeployPackage(gomock.Any(), nc).Return(nil).Times(3)
+			expectPending()
+			termination := getNfDeploy().Status.Termination
+			Expect(termination).NotTo(BeNil())
+			Expect(termination.Sites).To(Equal([]nfdeployv1alpha1.SiteTermination{
+				{Site: "upf-1", ClusterName: "cluster1", Phase: nfdeployv1alpha1.SiteTerminating},
+			}))
+
+			time.Sleep(2 * time.Millisecond)
+			expectPending()
+			Expect(getNfDeploy().Status.Termination.TimedOut).To(BeTrue())
+			stalled := condition(nfdeployv1alpha1.DeploymentStalled)
+			Expect(stalled).NotTo(BeNil())
+			Expect(stalled.Status).To(Equal(metav1.ConditionTrue))
+			Expect(stalled.Reason).To(Equal("TerminationTimedOut"))
+
+			By("finalizing NfDeploy once the removal is confirmed")
+			deploymentManager.SetTerminatingNFs(nil, true)
+			mockHydration.EXPECT().ReleaseAllocations(gomock.Any(), gomock.Any()).Return(nil)
+			expectFinalized()
+		})
+
+		It("Should subscribe to the edge events again when they are not listened to", func() {
+			deleteNfDeploy(nfdeployv1alpha1.DeleteDeletionPolicy)
+			deploymentManager.SetTerminatingNFs(nil, false)
+			deploymentManager.BlockReports()
+			mockPS.EXPECT().DeleteDeployPackage(gomock.Any(), nc).Return(nil).Times(3)
+			expectPending()
+			Eventually(deploymentManager.Reported).Should(Equal(1))
+
+			By("creating a single subscription at a time")
+			expectPending()
+			Consistently(deploymentManager.Reported).Should(Equal(1))
+
+			By("subscribing again once the subscription ended")
+			deploymentManager.ReleaseReports()
+			Eventually(func() bool {
+				_, inFlight := reconciler.resubscriptions.Load(key)
+				return inFlight
+			}).Should(BeFalse())
+			expectPending()
+			Eventually(deploymentManager.Reported).Should(Equal(2))
+		})
+	})
+})
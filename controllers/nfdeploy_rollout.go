@@ -0,0 +1,317 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	nfdeploytypes "github.com/nephio-project/common-lib/nfdeploy"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/profiles"
+	"github.com/nephio-project/nf-deploy-controller/rollout"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// DefaultRolloutPollInterval is the interval at which the NFs of the current
+// wave of a rollout are checked when not configured
+const DefaultRolloutPollInterval = 10 * time.Second
+
+// reconcileRollout creates the packages of the sites of nfDeploy wave by
+// wave, in dependency order for an ordered rollout or in batches of clusters
+// for a progressive one. The first wave is released when a generation is
+// reconciled for the first time; every next wave once the packages of the
+// current wave are published and all its NFs are Ready, i.e. have observed
+// the generation of their NF object. The rollout is paused when any NF of the
+// current wave, the last one included, is Stalled, until the next generation. The progress of the
+// rollout is kept in the NfDeploy status, so the reconciliation is requeued
+// until the last wave is released. The released sites of a complete or paused
+// rollout are hydrated again when NF profile objects they read change.
+//
+// A site is bound to its cluster and has a single NF, so no NF can be
+// surged: a wave always replaces the NFs of its sites in place, and
+// maxUnavailable alone sizes the batches of a progressive rollout.
+func (r *NfDeployReconciler) reconcileRollout(ctx context.Context,
+	req ctrl.Request, nfDeploy nfdeployv1alpha1.NfDeploy) (ctrl.Result, error) {
+	waves, err := rollout.ComputeRolloutWaves(nfDeploy, rollout.DefaultDependencyRules)
+	if err != nil {
+		r.Log.Error(err, "error planning the rollout of sites", "nfDeployName", nfDeploy.Name)
+		if e := r.setHydrationFailureStatus(ctx, req, nfDeploy.Generation, err, nil); e != nil {
+			r.Log.Error(e, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+			return ctrl.Result{}, e
+		}
+		return ctrl.Result{}, err
+	}
+	if len(waves) == 0 {
+		// no site to roll out
+		return r.reconcileAllAtOnce(ctx, req, nfDeploy)
+	}
+	nextWave := 0
+	if current := nfDeploy.Status.Rollout; current != nil &&
+		current.ObservedGeneration == nfDeploy.Generation &&
+		int(current.CurrentWave) < len(waves) {
+		if current.Paused {
+			return r.reconcileReleasedSites(ctx, req, nfDeploy, waves, *current)
+		}
+		states := r.DeploymentManager.GetNFStates(nfDeploy)
+		wave := waves[current.CurrentWave]
+		if stalled := sitesInState(states, wave, nfdeploytypes.Stalled); len(stalled) != 0 {
+			return ctrl.Result{}, r.pauseRollout(ctx, req, nfDeploy, *current, stalled)
+		}
+		if int(current.CurrentWave) == len(waves)-1 {
+			result, err := r.reconcileReleasedSites(ctx, req, nfDeploy, waves, *current)
+			if err == nil && len(sitesInState(states, wave, nfdeploytypes.Ready)) != len(wave) {
+				// the last wave is checked until Ready, so that a Stalled NF
+				// of it pauses the rollout too
+				result.RequeueAfter = r.rolloutPollInterval()
+			}
+			return result, err
+		}
+		published, err := r.isWavePublished(ctx, nfDeploy, wave, current.PreviousRevisions)
+		if err != nil {
+			r.Log.Error(err, "error checking wave packages", "nfDeployName", nfDeploy.Name)
+			return ctrl.Result{}, err
+		}
+		if !published {
+			r.Log.V(1).Info("Waiting for the packages of the current wave to be published",
+				"nfDeploy", nfDeploy.Name, "wave", current.CurrentWave)
+			return ctrl.Result{RequeueAfter: r.rolloutPollInterval()}, nil
+		}
+		// the NFs whose observedGeneration lags are Reconciling, not Ready
+		if len(sitesInState(states, wave, nfdeploytypes.Ready)) != len(wave) {
+			r.Log.V(1).Info("Waiting for the NFs of the current wave to be Ready",
+				"nfDeploy", nfDeploy.Name, "wave", current.CurrentWave)
+			return ctrl.Result{RequeueAfter: r.rolloutPollInterval()}, nil
+		}
+		nextWave = int(current.CurrentWave) + 1
+	}
+
+	r.Log.Info("Rolling out wave", "nfDeploy", nfDeploy.Name, "wave", nextWave,
+		"sites", waves[nextWave])
+	// until its packages are created, the rollout stays at the previous wave
+	// so that a failed hydration of the wave is retried
+	var previousStatus *nfdeployv1alpha1.RolloutStatus
+	if nextWave > 0 {
+		previousStatus = rollout.NewStatus(nfDeploy, waves, nextWave-1)
+	}
+	previousRevisions, err := r.getWaveRevisions(ctx, nfDeploy, waves[nextWave])
+	if err != nil {
+		r.Log.Error(err, "error recording package revisions before the wave", "nfDeployName", nfDeploy.Name)
+		if e := r.setHydrationFailureStatus(ctx, req, nfDeploy.Generation, err, previousStatus); e != nil {
+			r.Log.Error(e, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+			return ctrl.Result{}, e
+		}
+		return ctrl.Result{}, err
+	}
+	// the packages of the clusters of the wave keep the published content of
+	// the sites of the later waves
+	packageNames, profiles, err := r.hydrate(ctx, req,
+		rollout.ReleasedConnectivities(nfDeploy, waves, nextWave),
+		rollout.ReleasedSiteIds(waves, nextWave), previousStatus)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	rolloutStatus := rollout.NewStatus(nfDeploy, waves, nextWave)
+	rolloutStatus.PreviousRevisions = previousRevisions
+	if err := r.setHydrationSuccessStatus(ctx, req, nfDeploy.Generation, packageNames,
+		rolloutStatus, nil, profiles); err != nil {
+		r.Log.Error(err, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+		return ctrl.Result{}, err
+	}
+	if profiles != nil {
+		// the deployment resolves the intents from the hydrated profiles
+		nfDeploy.Status.Profiles = profiles
+	}
+	// the deployment graph holds all the sites, so that the NfDeploy is not
+	// Ready until the last wave is, and the released sites are expected to be
+	// hydrated from the generation
+	nfDeploy.Status.Rollout = rolloutStatus
+	go r.DeploymentManager.ReportNFDeployEvent(nfDeploy, req.NamespacedName)
+	if rolloutStatus.IsComplete() {
+		r.Log.Info("Reconciled successfully!", "nfDeploy", nfDeploy.Name)
+	}
+	// the NFs of the last wave are checked as well until they are Ready
+	return ctrl.Result{RequeueAfter: r.rolloutPollInterval()}, nil
+}
+
+// reconcileReleasedSites hydrates the released sites of a complete or paused
+// rollout again when NF profile objects read by their last hydration changed,
+// e.g. on the events of ProfileEvents. The progress of the rollout is kept.
+func (r *NfDeployReconciler) reconcileReleasedSites(ctx context.Context, req ctrl.Request,
+	nfDeploy nfdeployv1alpha1.NfDeploy, waves [][]string,
+	current nfdeployv1alpha1.RolloutStatus) (ctrl.Result, error) {
+	if current.Paused {
+		r.Log.V(1).Info("Rollout is paused", "nfDeploy", nfDeploy.Name,
+			"reason", current.PauseReason)
+	} else {
+		r.Log.V(1).Info("All waves are rolled out", "nfDeploy", nfDeploy.Name)
+	}
+	changed, err := r.changedProfiles(ctx, nfDeploy)
+	if err != nil {
+		r.Log.Error(err, "error checking the NF profiles", "nfDeployName", nfDeploy.Name)
+		return ctrl.Result{}, err
+	}
+	if len(changed) == 0 {
+		return ctrl.Result{}, nil
+	}
+	r.Log.Info("NF profiles changed, hydrating the released sites again",
+		"nfDeploy", nfDeploy.Name, "profiles", changed)
+	packageNames, profiles, err := r.hydrate(ctx, req,
+		rollout.ReleasedConnectivities(nfDeploy, waves, int(current.CurrentWave)),
+		rollout.ReleasedSiteIds(waves, int(current.CurrentWave)), &current)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setHydrationSuccessStatus(ctx, req, nfDeploy.Generation, packageNames,
+		&current, nil, profiles); err != nil {
+		r.Log.Error(err, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+		return ctrl.Result{}, err
+	}
+	if profiles != nil {
+		// the deployment resolves the intents from the hydrated profiles
+		nfDeploy.Status.Profiles = profiles
+	}
+	go r.DeploymentManager.ReportNFDeployEvent(nfDeploy, req.NamespacedName)
+	return ctrl.Result{}, nil
+}
+
+// changedProfiles returns the NF profile objects read by the last hydration
+// of nfDeploy which changed in, or were removed from, the latest published
+// revision of the nf-profiles package
+func (r *NfDeployReconciler) changedProfiles(ctx context.Context,
+	nfDeploy nfdeployv1alpha1.NfDeploy) ([]nfdeployv1alpha1.ProfileObject, error) {
+	recorded := nfDeploy.Status.Profiles
+	if recorded == nil {
+		return nil, nil
+	}
+	// the nf-profiles package depends neither on the cluster nor on the
+	// NfDeploy
+	revision, resources, err := r.PS.GetNFProfilesPackage(ctx, util.NamingContext{}, "")
+	if err != nil {
+		return nil, err
+	}
+	if revision == recorded.Revision {
+		return nil, nil
+	}
+	return profiles.Changed(recorded, profiles.Objects(resources)), nil
+}
+
+// getWaveRevisions returns the latest published revisions of the deploy
+// packages of the clusters of the sites of the wave
+func (r *NfDeployReconciler) getWaveRevisions(ctx context.Context,
+	nfDeploy nfdeployv1alpha1.NfDeploy, wave []string) ([]nfdeployv1alpha1.PackageRevisionRef, error) {
+	revisions := []nfdeployv1alpha1.PackageRevisionRef{}
+	for _, clusterName := range waveClusters(nfDeploy, wave) {
+		nc, err := util.NewNamingContext(clusterName, nfDeploy.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error creating naming context: %w", err)
+		}
+		revision, err := r.PS.GetDeployPackageRevision(ctx, nc)
+		if err != nil {
+			return nil, fmt.Errorf("error getting deploy package revision of cluster %s: %w",
+				clusterName, err)
+		}
+		if revision != "" {
+			revisions = append(revisions, nfdeployv1alpha1.PackageRevisionRef{
+				ClusterName: clusterName, Name: revision,
+			})
+		}
+	}
+	return revisions, nil
+}
+
+// isWavePublished returns true if the deploy packages of the clusters of the
+// wave have a published revision newer than the recorded ones
+func (r *NfDeployReconciler) isWavePublished(ctx context.Context, nfDeploy nfdeployv1alpha1.NfDeploy,
+	wave []string, previousRevisions []nfdeployv1alpha1.PackageRevisionRef) (bool, error) {
+	recorded := make(map[string]bool, len(previousRevisions))
+	for _, revision := range previousRevisions {
+		recorded[revision.Name] = true
+	}
+	for _, clusterName := range waveClusters(nfDeploy, wave) {
+		nc, err := util.NewNamingContext(clusterName, nfDeploy.Name)
+		if err != nil {
+			return false, fmt.Errorf("error creating naming context: %w", err)
+		}
+		revision, err := r.PS.GetDeployPackageRevision(ctx, nc)
+		if err != nil {
+			return false, err
+		}
+		if revision == "" || recorded[revision] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// waveClusters returns the sorted names of the clusters of the sites of the
+// wave
+func waveClusters(nfDeploy nfdeployv1alpha1.NfDeploy, wave []string) []string {
+	inWave := make(map[string]bool, len(wave))
+	for _, siteId := range wave {
+		inWave[siteId] = true
+	}
+	seen := make(map[string]bool)
+	var clusters []string
+	for _, site := range nfDeploy.Spec.Sites {
+		if inWave[site.Id] && !seen[site.ClusterName] {
+			seen[site.ClusterName] = true
+			clusters = append(clusters, site.ClusterName)
+		}
+	}
+	sort.Strings(clusters)
+	return clusters
+}
+
+// pauseRollout records the rollout paused at its current wave because the
+// NFs of the stalled sites are Stalled
+func (r *NfDeployReconciler) pauseRollout(ctx context.Context, req ctrl.Request,
+	nfDeploy nfdeployv1alpha1.NfDeploy, current nfdeployv1alpha1.RolloutStatus,
+	stalled []string) error {
+	current.Paused = true
+	current.PauseReason = fmt.Sprintf("sites %v are Stalled", stalled)
+	r.Log.Info("Pausing rollout", "nfDeploy", nfDeploy.Name,
+		"wave", current.CurrentWave, "reason", current.PauseReason)
+	if err := r.StatusAggregator.SetRolloutStatus(ctx, req.NamespacedName, current); err != nil {
+		r.Log.Error(err, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+		return err
+	}
+	return nil
+}
+
+// sitesInState returns the ids of the sites of the wave whose NF is in state
+func sitesInState(states map[string]nfdeploytypes.NFConditionType,
+	wave []string, state nfdeploytypes.NFConditionType) []string {
+	var sites []string
+	for _, siteId := range wave {
+		if states[siteId] == state {
+			sites = append(sites, siteId)
+		}
+	}
+	return sites
+}
+
+func (r *NfDeployReconciler) rolloutPollInterval() time.Duration {
+	if r.RolloutPollInterval > 0 {
+		return r.RolloutPollInterval
+	}
+	return DefaultRolloutPollInterval
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	nfdeploytypes "github.com/nephio-project/common-lib/nfdeploy"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	hydrationmock "github.com/nephio-project/nf-deploy-controller/hydration/mock"
+	psmock "github.com/nephio-project/nf-deploy-controller/packageservice/mock"
+	"github.com/nephio-project/nf-deploy-controller/tests/utils"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+var _ = Describe("NfDeploy rollout", func() {
+	var reconciler *NfDeployReconciler
+	var deploymentManager *utils.StubDeploymentManager
+	var mockHydration *hydrationmock.MockHydrationInterface
+	var mockPS *psmock.MockPackageServiceInterface
+	var nc1 util.NamingContext
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "rolled-out"}
+	req := ctrl.Request{NamespacedName: key}
+
+	getRollout := func() *nfdeployv1alpha1.RolloutStatus {
+		var nfDeploy nfdeployv1alpha1.NfDeploy
+		Expect(reconciler.Get(ctx, key, &nfDeploy)).To(Succeed())
+		return nfDeploy.Status.Rollout
+	}
+	// expectWave expects the sites of the waves up to the released one to be
+	// hydrated from all the sites, and the actuators of the released sites
+	expectWave := func(released []string) {
+		mockHydration.EXPECT().HydrateSites(gomock.Any(), gomock.Any(), released).DoAndReturn(
+			func(_ context.Context, nfDeploy nfdeployv1alpha1.NfDeploy, _ []string) (
+				[]string, *nfdeployv1alpha1.ProfilesStatus, error) {
+				Expect(nfDeploy.Spec.Sites).To(HaveLen(3))
+				return []string{"deploy-pkg"}, nil, nil
+			})
+		mockHydration.EXPECT().CreateNFDeployActuators(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, nfDeploy nfdeployv1alpha1.NfDeploy) ([]string, error) {
+				Expect(nfDeploy.Spec.Sites).To(HaveLen(len(released)))
+				return nil, nil
+			})
+	}
+
+	BeforeEach(func() {
+		// the canary upf-1 shares cluster1 with upf-2, which is rolled out in
+		// the next batch
+		nfDeploy := &nfdeployv1alpha1.NfDeploy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace, Name: key.Name, Generation: 1,
+				Finalizers: []string{nfDeployFinalizerName},
+			},
+			Spec: nfdeployv1alpha1.NfDeploySpec{
+				Rollout: &nfdeployv1alpha1.RolloutStrategy{
+					Type:        nfdeployv1alpha1.ProgressiveRollout,
+					CanarySites: []string{"upf-1"},
+				},
+				Sites: []nfdeployv1alpha1.Site{
+					{Id: "upf-1", ClusterName: "cluster1", NFType: "upf"},
+					{Id: "upf-2", ClusterName: "cluster1", NFType: "upf"},
+					{Id: "upf-3", ClusterName: "cluster2", NFType: "upf"},
+				},
+			},
+		}
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockHydration = hydrationmock.NewMockHydrationInterface(mockCtrl)
+		mockPS = psmock.NewMockPackageServiceInterface(mockCtrl)
+		reconciler, deploymentManager = newStubReconciler(mockHydration, mockPS, nfDeploy)
+		var err error
+		nc1, err = util.NewNamingContext("cluster1", key.Name)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("releasing the canary batch")
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc1).Return("deploy-v1", nil)
+		expectWave([]string{"upf-1"})
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultRolloutPollInterval))
+		rolloutStatus := getRollout()
+		Expect(rolloutStatus.CurrentWave).To(BeZero())
+		Expect(rolloutStatus.Waves).To(HaveLen(3))
+		Expect(rolloutStatus.PreviousRevisions).To(Equal([]nfdeployv1alpha1.PackageRevisionRef{
+			{ClusterName: "cluster1", Name: "deploy-v1"},
+		}))
+	})
+
+	It("Should release the next batch once the packages are published and the NFs Ready", func() {
+		By("waiting for the packages of the batch to be published")
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Ready,
+		})
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc1).Return("deploy-v1", nil)
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultRolloutPollInterval))
+		Expect(getRollout().CurrentWave).To(BeZero())
+
+		By("waiting for the NFs to observe the generation of their NF object")
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Reconciling,
+		})
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc1).Return("deploy-v2", nil)
+		result, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultRolloutPollInterval))
+		Expect(getRollout().CurrentWave).To(BeZero())
+
+		By("releasing the batch of cluster1")
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Ready,
+		})
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc1).Return("deploy-v2", nil).Times(2)
+		expectWave([]string{"upf-1", "upf-2"})
+		result, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultRolloutPollInterval))
+		rolloutStatus := getRollout()
+		Expect(rolloutStatus.CurrentWave).To(Equal(int32(1)))
+		Expect(rolloutStatus.PreviousRevisions).To(Equal([]nfdeployv1alpha1.PackageRevisionRef{
+			{ClusterName: "cluster1", Name: "deploy-v2"},
+		}))
+		Eventually(deploymentManager.Reported).Should(Equal(2))
+	})
+
+	It("Should pause the rollout when an NF of the current batch is Stalled", func() {
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Stalled,
+		})
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		var nfDeploy nfdeployv1alpha1.NfDeploy
+		Expect(reconciler.Get(ctx, key, &nfDeploy)).To(Succeed())
+		Expect(nfDeploy.Status.Rollout.CurrentWave).To(BeZero())
+		Expect(nfDeploy.Status.Rollout.Paused).To(BeTrue())
+		Expect(nfDeploy.Status.Rollout.PauseReason).To(Equal("sites [upf-1] are Stalled"))
+		stalled := meta.FindStatusCondition(nfDeploy.Status.Conditions,
+			string(nfdeployv1alpha1.DeploymentStalled))
+		Expect(stalled).NotTo(BeNil())
+		Expect(stalled.Status).To(Equal(metav1.ConditionTrue))
+		Expect(stalled.Reason).To(Equal("RolloutPaused"))
+
+		By("keeping the rollout paused until the next generation")
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Ready,
+		})
+		result, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(getRollout().Paused).To(BeTrue())
+		Expect(getRollout().CurrentWave).To(BeZero())
+	})
+
+	It("Should pause the rollout when an NF of the last batch is Stalled", func() {
+		nc2, err := util.NewNamingContext("cluster2", key.Name)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("releasing the batch of cluster1")
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Ready,
+		})
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc1).Return("deploy-v2", nil).Times(2)
+		expectWave([]string{"upf-1", "upf-2"})
+		_, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("releasing the last batch")
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Ready, "upf-2": nfdeploytypes.Ready,
+		})
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc1).Return("deploy-v3", nil)
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc2).Return("deploy-v1", nil)
+		expectWave([]string{"upf-1", "upf-2", "upf-3"})
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultRolloutPollInterval))
+		Expect(getRollout().CurrentWave).To(Equal(int32(2)))
+
+		By("checking the NFs of the last batch until they are Ready")
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Ready, "upf-2": nfdeploytypes.Ready,
+			"upf-3": nfdeploytypes.Reconciling,
+		})
+		result, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultRolloutPollInterval))
+
+		By("pausing the rollout")
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Ready, "upf-2": nfdeploytypes.Ready,
+			"upf-3": nfdeploytypes.Stalled,
+		})
+		result, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		rolloutStatus := getRollout()
+		Expect(rolloutStatus.CurrentWave).To(Equal(int32(2)))
+		Expect(rolloutStatus.Paused).To(BeTrue())
+		Expect(rolloutStatus.PauseReason).To(Equal("sites [upf-3] are Stalled"))
+	})
+})
@@ -0,0 +1,174 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/status"
+	"github.com/nephio-project/nf-deploy-controller/upgrade"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// reconcileUpgrade checks the upgrade in progress of the NF software version
+// of the sites of nfDeploy. It succeeds once the new packages are published
+// and the NFs of the upgraded sites are Ready; it is rolled back when one of
+// them is Stalled or the progress deadline passes. Requeued until then.
+func (r *NfDeployReconciler) reconcileUpgrade(ctx context.Context, req ctrl.Request,
+	nfDeploy nfdeployv1alpha1.NfDeploy, upgradeStatus nfdeployv1alpha1.UpgradeStatus) (ctrl.Result, error) {
+	published, err := r.isUpgradePublished(ctx, nfDeploy, upgradeStatus)
+	if err != nil {
+		r.Log.Error(err, "error checking upgrade packages", "nfDeployName", nfDeploy.Name)
+		return ctrl.Result{}, err
+	}
+	phase, reason := upgrade.Check(upgradeStatus, r.DeploymentManager.GetNFStates(nfDeploy),
+		published, time.Now(), nfDeploy.Spec.Upgrade.ProgressDeadline())
+	switch phase {
+	case nfdeployv1alpha1.UpgradeSucceeded:
+		r.Log.Info("Upgrade succeeded", "nfDeploy", nfDeploy.Name, "sites", upgradeStatus.Sites)
+		if err := r.StatusAggregator.SetUpgradeStatus(ctx, req.NamespacedName,
+			*upgrade.Succeeded(upgradeStatus)); err != nil {
+			r.Log.Error(err, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	case nfdeployv1alpha1.UpgradeRolledBack:
+		return ctrl.Result{}, r.rollbackUpgrade(ctx, req, nfDeploy, upgradeStatus, reason)
+	default:
+		r.Log.V(1).Info("Waiting for the NFs of the upgraded sites to be Ready",
+			"nfDeploy", nfDeploy.Name)
+		return ctrl.Result{RequeueAfter: r.rolloutPollInterval()}, nil
+	}
+}
+
+// rollbackUpgrade reverts the deploy and actuator packages of the upgraded
+// sites to the revisions recorded before the upgrade. The restored packages
+// await approval like any other.
+func (r *NfDeployReconciler) rollbackUpgrade(ctx context.Context, req ctrl.Request,
+	nfDeploy nfdeployv1alpha1.NfDeploy, upgradeStatus nfdeployv1alpha1.UpgradeStatus, reason string) error {
+	r.Log.Info("Rolling back upgrade", "nfDeploy", nfDeploy.Name, "reason", reason)
+	packageNames := []string{}
+	for _, revision := range upgradeStatus.PreviousRevisions {
+		nc, err := util.NewNamingContext(revision.ClusterName, nfDeploy.Name)
+		if err != nil {
+			return fmt.Errorf("error creating naming context: %w", err)
+		}
+		name, err := r.PS.RestorePackageRevision(ctx, nc, revision.Name)
+		if err != nil {
+			r.Log.Error(err, "error restoring package revision", "nfDeployName", nfDeploy.Name,
+				"revision", revision.Name)
+			return err
+		}
+		packageNames = append(packageNames, name)
+	}
+	if err := r.StatusAggregator.SetHydrationStatus(ctx, req.NamespacedName,
+		status.HydrationStatus{
+			Generation:   nfDeploy.Generation,
+			Phase:        status.AwaitingApproval,
+			PackageNames: packageNames,
+			Upgrade:      upgrade.RolledBack(upgradeStatus, reason, time.Now()),
+		}); err != nil {
+		r.Log.Error(err, "error updating NfDeploy status", "nfDeployName", nfDeploy.Name)
+		return err
+	}
+	return nil
+}
+
+// getPackageRevisions returns the latest published revisions of the deploy
+// packages of the clusters of the upgraded sites, and of the actuator
+// packages of their previous versions
+func (r *NfDeployReconciler) getPackageRevisions(ctx context.Context,
+	nfDeploy nfdeployv1alpha1.NfDeploy, upgradedSites []nfdeployv1alpha1.SiteUpgrade) (
+	[]nfdeployv1alpha1.PackageRevisionRef, error) {
+	sites := make(map[string]nfdeployv1alpha1.Site, len(nfDeploy.Spec.Sites))
+	for _, site := range nfDeploy.Spec.Sites {
+		sites[site.Id] = site
+	}
+	revisions := []nfdeployv1alpha1.PackageRevisionRef{}
+	seen := make(map[nfdeployv1alpha1.PackageRevisionRef]bool)
+	addRevision := func(clusterName string, name string) {
+		revision := nfdeployv1alpha1.PackageRevisionRef{ClusterName: clusterName, Name: name}
+		if name != "" && !seen[revision] {
+			seen[revision] = true
+			revisions = append(revisions, revision)
+		}
+	}
+	for _, upgradedSite := range upgradedSites {
+		site := sites[upgradedSite.Site]
+		nc, err := util.NewNamingContext(site.ClusterName, nfDeploy.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error creating naming context: %w", err)
+		}
+		deployRevision, err := r.PS.GetDeployPackageRevision(ctx, nc)
+		if err != nil {
+			return nil, fmt.Errorf("error getting deploy package revision of cluster %s: %w",
+				site.ClusterName, err)
+		}
+		addRevision(site.ClusterName, deployRevision)
+		actuatorsRevision, err := r.PS.GetNFDeployActuatorsRevision(ctx, nc, ps.VendorNFKey{
+			Vendor:  site.NFVendor,
+			Version: upgradedSite.FromVersion,
+			NFType:  site.NFType,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error getting actuators package revision of cluster %s: %w",
+				site.ClusterName, err)
+		}
+		addRevision(site.ClusterName, actuatorsRevision)
+	}
+	return revisions, nil
+}
+
+// isUpgradePublished returns true if the deploy packages of the clusters of
+// the upgraded sites have a published revision newer than the recorded ones
+func (r *NfDeployReconciler) isUpgradePublished(ctx context.Context,
+	nfDeploy nfdeployv1alpha1.NfDeploy, upgradeStatus nfdeployv1alpha1.UpgradeStatus) (bool, error) {
+	recorded := make(map[string]bool, len(upgradeStatus.PreviousRevisions))
+	for _, revision := range upgradeStatus.PreviousRevisions {
+		recorded[revision.Name] = true
+	}
+	clusters := make(map[string]bool)
+	upgraded := make(map[string]bool, len(upgradeStatus.Sites))
+	for _, site := range upgradeStatus.Sites {
+		upgraded[site.Site] = true
+	}
+	for _, site := range nfDeploy.Spec.Sites {
+		if upgraded[site.Id] {
+			clusters[site.ClusterName] = true
+		}
+	}
+	for clusterName := range clusters {
+		nc, err := util.NewNamingContext(clusterName, nfDeploy.Name)
+		if err != nil {
+			return false, fmt.Errorf("error creating naming context: %w", err)
+		}
+		revision, err := r.PS.GetDeployPackageRevision(ctx, nc)
+		if err != nil {
+			return false, err
+		}
+		if revision == "" || recorded[revision] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
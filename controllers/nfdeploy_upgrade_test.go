@@ -0,0 +1,149 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	nfdeploytypes "github.com/nephio-project/common-lib/nfdeploy"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	hydrationmock "github.com/nephio-project/nf-deploy-controller/hydration/mock"
+	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
+	psmock "github.com/nephio-project/nf-deploy-controller/packageservice/mock"
+	"github.com/nephio-project/nf-deploy-controller/tests/utils"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+var _ = Describe("NfDeploy upgrade", func() {
+	var reconciler *NfDeployReconciler
+	var deploymentManager *utils.StubDeploymentManager
+	var mockHydration *hydrationmock.MockHydrationInterface
+	var mockPS *psmock.MockPackageServiceInterface
+	var nc util.NamingContext
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "upgraded"}
+	req := ctrl.Request{NamespacedName: key}
+
+	getNfDeploy := func() nfdeployv1alpha1.NfDeploy {
+		var nfDeploy nfdeployv1alpha1.NfDeploy
+		Expect(reconciler.Get(ctx, key, &nfDeploy)).To(Succeed())
+		return nfDeploy
+	}
+
+	BeforeEach(func() {
+		// the spec changed the version of the site from v1 to v2
+		nfDeploy := &nfdeployv1alpha1.NfDeploy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace, Name: key.Name, Generation: 2,
+				Finalizers: []string{nfDeployFinalizerName},
+			},
+			Spec: nfdeployv1alpha1.NfDeploySpec{
+				Sites: []nfdeployv1alpha1.Site{{
+					Id: "upf-1", ClusterName: "cluster1", NFType: "upf",
+					NFVendor: "vendor", NFVersion: "v2",
+				}},
+			},
+			Status: nfdeployv1alpha1.NfDeployStatus{
+				ObservedGeneration: 1,
+				Upgrade: &nfdeployv1alpha1.UpgradeStatus{
+					ObservedGeneration: 1,
+					Versions:           []nfdeployv1alpha1.SiteVersion{{Site: "upf-1", Version: "v1"}},
+				},
+			},
+		}
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockHydration = hydrationmock.NewMockHydrationInterface(mockCtrl)
+		mockPS = psmock.NewMockPackageServiceInterface(mockCtrl)
+		reconciler, deploymentManager = newStubReconciler(mockHydration, mockPS, nfDeploy)
+		var err error
+		nc, err = util.NewNamingContext("cluster1", key.Name)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("recording the revisions of the packages before the upgrade")
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc).Return("deploy-v1", nil)
+		mockPS.EXPECT().GetNFDeployActuatorsRevision(gomock.Any(), nc, ps.VendorNFKey{
+			Vendor: "vendor", Version: "v1", NFType: "upf",
+		}).Return("actuators-v1", nil)
+		mockHydration.EXPECT().Hydrate(gomock.Any(), gomock.Any()).
+			Return([]string{"deploy-v2"}, nil, nil)
+		mockHydration.EXPECT().CreateNFDeployActuators(gomock.Any(), gomock.Any()).
+			Return([]string{"actuators-v2"}, nil)
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultRolloutPollInterval))
+		upgradeStatus := getNfDeploy().Status.Upgrade
+		Expect(upgradeStatus.Phase).To(Equal(nfdeployv1alpha1.UpgradeProgressing))
+		Expect(upgradeStatus.ObservedGeneration).To(Equal(int64(2)))
+		Expect(upgradeStatus.Sites).To(Equal([]nfdeployv1alpha1.SiteUpgrade{
+			{Site: "upf-1", FromVersion: "v1", ToVersion: "v2"},
+		}))
+		Expect(upgradeStatus.PreviousRevisions).To(Equal([]nfdeployv1alpha1.PackageRevisionRef{
+			{ClusterName: "cluster1", Name: "deploy-v1"},
+			{ClusterName: "cluster1", Name: "actuators-v1"},
+		}))
+	})
+
+	It("Should succeed once the packages are published and the NFs are Ready", func() {
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc).Return("deploy-v1", nil)
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Ready,
+		})
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(DefaultRolloutPollInterval))
+		Expect(getNfDeploy().Status.Upgrade.Phase).To(Equal(nfdeployv1alpha1.UpgradeProgressing))
+
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc).Return("deploy-v2", nil)
+		result, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(getNfDeploy().Status.Upgrade.Phase).To(Equal(nfdeployv1alpha1.UpgradeSucceeded))
+	})
+
+	It("Should roll back the upgrade when an NF is Stalled", func() {
+		mockPS.EXPECT().GetDeployPackageRevision(gomock.Any(), nc).Return("deploy-v2", nil)
+		deploymentManager.SetNFStates(map[string]nfdeploytypes.NFConditionType{
+			"upf-1": nfdeploytypes.Stalled,
+		})
+		mockPS.EXPECT().RestorePackageRevision(gomock.Any(), nc, "deploy-v1").Return("deploy-v3", nil)
+		mockPS.EXPECT().RestorePackageRevision(gomock.Any(), nc, "actuators-v1").
+			Return("actuators-v3", nil)
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		upgradeStatus := getNfDeploy().Status.Upgrade
+		Expect(upgradeStatus.Phase).To(Equal(nfdeployv1alpha1.UpgradeRolledBack))
+		Expect(upgradeStatus.Versions).To(Equal([]nfdeployv1alpha1.SiteVersion{
+			{Site: "upf-1", Version: "v1"},
+		}))
+		Expect(upgradeStatus.RollbackHistory).To(HaveLen(1))
+		Expect(upgradeStatus.RollbackHistory[0].RestoredRevisions).
+			To(Equal(upgradeStatus.PreviousRevisions))
+
+		By("waiting for a new generation")
+		result, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+	})
+})
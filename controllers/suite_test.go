@@ -21,19 +21,26 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	//+kubebuilder:scaffold:imports
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration"
+	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/status"
 	"github.com/nephio-project/nf-deploy-controller/tests/utils"
 )
 
@@ -47,6 +54,8 @@ func TestAPIs(t *testing.T) {
 
 var (
 	fakeDeploymentManager utils.FakeDeploymentManager
+	fakeHydration         *utils.FakeHydration
+	profileEvents         chan event.GenericEvent
 	cfg                   *rest.Config
 	k8sClient             client.Client // You'll be using this client in your tests.
 	testEnvSuite          *envtest.Environment
@@ -89,17 +98,23 @@ var _ = BeforeSuite(
 			},
 		)
 		Expect(err).ToNot(HaveOccurred())
+		statusAggregator := status.NewAggregator(
+			k8sClient, k8sClient.Status(), ctrl.Log.WithName("StatusAggregator"),
+		)
 		fakeDeploymentManager = utils.NewFakeDeploymentManager(
-			k8sClient, k8sClient.Status(),
-			ctrl.Log.WithName("Deployment"),
+			statusAggregator, ctrl.Log.WithName("Deployment"),
 		)
+		fakeHydration = &utils.FakeHydration{}
+		profileEvents = make(chan event.GenericEvent)
 		err = (&NfDeployReconciler{
 			Client:            k8sManager.GetClient(),
 			Scheme:            k8sManager.GetScheme(),
 			DeploymentManager: &fakeDeploymentManager,
 			Log:               ctrl.Log.WithName("controllers").WithName("NfDeploy"),
-			Hydration:         &utils.FakeHydration{},
+			Hydration:         fakeHydration,
 			PS:                &utils.FakePackageService{},
+			StatusAggregator:  statusAggregator,
+			ProfileEvents:     profileEvents,
 		}).SetupWithManager(k8sManager)
 		Expect(err).ToNot(HaveOccurred())
 
@@ -111,6 +126,29 @@ var _ = BeforeSuite(
 	},
 )
 
+// newStubReconciler returns a reconciler of the given NfDeploys which reads
+// and writes them with a fake client, and whose NF states are set by the test
+func newStubReconciler(
+	hydration hydration.HydrationInterface, packageService ps.PackageServiceInterface,
+	nfDeploys ...client.Object,
+) (*NfDeployReconciler, *utils.StubDeploymentManager) {
+	testScheme := runtime.NewScheme()
+	Expect(deployv1alpha1.AddToScheme(testScheme)).To(Succeed())
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(nfDeploys...).Build()
+	deploymentManager := &utils.StubDeploymentManager{}
+	return &NfDeployReconciler{
+		Client:            fakeClient,
+		Scheme:            testScheme,
+		DeploymentManager: deploymentManager,
+		Log:               logr.Discard(),
+		Hydration:         hydration,
+		PS:                packageService,
+		StatusAggregator: status.NewAggregator(
+			fakeClient, fakeClient.Status(), logr.Discard(),
+		),
+	}, deploymentManager
+}
+
 var _ = AfterSuite(
 	func() {
 		cancel()
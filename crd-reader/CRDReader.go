@@ -16,7 +16,11 @@ limitations under the License.
 
 package crdreader
 
-import types "github.com/nephio-project/common-lib/nfdeploy"
+import (
+	"context"
+
+	types "github.com/nephio-project/common-lib/nfdeploy"
+)
 
 // CRDReader : CRDReader interface exposes CRD config files and optionally
 // converts them to corresponding Objects.
@@ -40,3 +44,14 @@ type CRDReader interface {
 	// based on its metadata name
 	GetSMFCapacityProfileObject(crdName string) (types.SMFCapacityProfile, error)
 }
+
+// RevisionReader : RevisionReader is a CRDReader which also reads the objects
+// of a given revision of the NF profiles, e.g. the one a NfDeploy was hydrated
+// with.
+type RevisionReader interface {
+	CRDReader
+
+	// ForRevision : This method returns a CRDReader of the objects of the given
+	// revision, or of the latest one when revision is empty
+	ForRevision(ctx context.Context, revision string) (CRDReader, error)
+}
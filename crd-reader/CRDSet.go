@@ -18,12 +18,15 @@ package crdreader
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	types "github.com/nephio-project/common-lib/nfdeploy"
 	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/nephio-project/nf-deploy-controller/util"
 )
 
 type CRDSet struct {
@@ -90,6 +93,33 @@ func (crdSet *CRDSet) ReadCRDFiles(directory string) error {
 	if err != nil {
 		return err
 	}
+	crdSet.load(yamlNodes)
+	return nil
+}
+
+// ReadCRDResources : This method reads the yaml files of a package, keyed by
+// file name, and stores them in an in-memory map. The other files of the
+// package, like the Kptfile or a README, are ignored.
+func (crdSet *CRDSet) ReadCRDResources(resources map[string]string) error {
+	crdSet.init()
+	var yamlNodes []*yaml.RNode
+	for name, content := range resources {
+		if ext := filepath.Ext(name); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		nodes, err := util.ParseStringToYamlNode(content)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", name, err)
+		}
+		yamlNodes = append(yamlNodes, nodes...)
+	}
+	crdSet.load(yamlNodes)
+	return nil
+}
+
+// load: This method stores the UpfType, SmfType and capacity profile objects
+// of the yaml RNodes provided in the in-memory maps
+func (crdSet *CRDSet) load(yamlNodes []*yaml.RNode) {
 	filteredNodes := filterYamlsByKind(UPFTypeObject, yamlNodes)
 	for _, node := range filteredNodes {
 		upfType := &types.UPFType{}
@@ -114,7 +144,6 @@ func (crdSet *CRDSet) ReadCRDFiles(directory string) error {
 		node.YNode().Decode(smfCapacityProfile)
 		crdSet.smfCapacityProfiles[node.GetName()] = *smfCapacityProfile
 	}
-	return nil
 }
 
 // GetUPFTypeObject : This method returns a UpfType object based on its metadata name
@@ -205,3 +205,38 @@ var _ = Describe(
 		)
 	},
 )
+
+var _ = Describe(
+	"ReadCRDResources", func() {
+		var crdSet CRDSet
+
+		Context(
+			"When the yaml files of a package are provided", func() {
+				It(
+					"Should read them and ignore the other files", func() {
+						resources := readTestResources("testfiles/kyaml-readable-files")
+						resources["Kptfile"] = "apiVersion: kpt.dev/v1\nkind: Kptfile\n"
+						resources["README.md"] = "# nf-profiles\n"
+						err := crdSet.ReadCRDResources(resources)
+						Expect(err).To(Not(HaveOccurred()))
+						Expect(crdSet.upfTypes).To(HaveKey("UpfTypeTest"))
+						Expect(crdSet.smfTypes).To(HaveKey("SmfTypeTest"))
+						Expect(crdSet.smfCapacityProfiles).To(HaveKey("SmfCapacityProfileTest"))
+						Expect(crdSet.upfCapacityProfiles).To(HaveKey("UpfCapacityProfileTest"))
+					},
+				)
+			},
+		)
+		Context(
+			"When a yaml file is not parsable", func() {
+				It(
+					"Should return error", func() {
+						err := crdSet.ReadCRDResources(map[string]string{"upftype.yaml": "kind: [UpfType"})
+						Expect(err).To(HaveOccurred())
+						Expect(crdSet.upfTypes).To(BeEmpty())
+					},
+				)
+			},
+		)
+	},
+)
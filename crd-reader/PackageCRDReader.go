@@ -0,0 +1,201 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdreader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	types "github.com/nephio-project/common-lib/nfdeploy"
+	"k8s.io/utils/clock"
+
+	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+const (
+	// latestRevisionTTL is how long the latest published revision read is
+	// used before the latest revision is read from Porch again
+	latestRevisionTTL = 30 * time.Second
+	// maxRevisions is the number of revisions whose objects are kept, the
+	// least recently used ones are evicted
+	maxRevisions = 16
+)
+
+// PackageCRDReader : PackageCRDReader reads the objects from the revisions of
+// the nf-profiles package in Porch, which the hydration reads them from too.
+// The CRDReader methods read the latest published revision.
+type PackageCRDReader struct {
+	PS packageservice.PackageServiceInterface
+
+	mu sync.Mutex
+	// revisions are the objects of the revisions read by revision name,
+	// which do not change once published
+	revisions map[string]*CRDSet
+	// used are the names of the revisions by least recent use
+	used []string
+	// latestRevision is the name of the latest published revision, read at
+	// latestRead
+	latestRevision string
+	latestRead     time.Time
+	// clock is clock.RealClock when nil
+	clock clock.PassiveClock
+}
+
+var _ RevisionReader = &PackageCRDReader{}
+
+// nc is the naming context of the nf-profiles package, which depends neither
+// on the cluster nor on the NfDeploy
+var nc util.NamingContext
+
+// ForRevision : This method returns a CRDReader of the objects of the given
+// revision of the nf-profiles package, or of its latest published revision
+// when revision is empty. The revision is read from Porch without holding
+// the lock, so that concurrent readers of cached revisions are not blocked.
+func (reader *PackageCRDReader) ForRevision(ctx context.Context, revision string) (CRDReader, error) {
+	now := reader.now()
+	if crdSet, ok := reader.cached(revision, now); ok {
+		return crdSet, nil
+	}
+	name, resources, err := reader.PS.GetNFProfilesPackage(ctx, nc, revision)
+	if err != nil {
+		return nil, err
+	}
+	crdSet := &CRDSet{}
+	if err := crdSet.ReadCRDResources(resources); err != nil {
+		return nil, fmt.Errorf("error reading revision %s of the NF profiles: %w", name, err)
+	}
+
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+	if revision == "" && !now.Before(reader.latestRead) {
+		reader.latestRevision, reader.latestRead = name, now
+	}
+	if cachedSet, ok := reader.revisions[name]; ok {
+		// read concurrently by another caller
+		reader.use(name)
+		return cachedSet, nil
+	}
+	if reader.revisions == nil {
+		reader.revisions = map[string]*CRDSet{}
+	}
+	reader.revisions[name] = crdSet
+	reader.use(name)
+	return crdSet, nil
+}
+
+// cached : This method returns the cached objects of the given revision, or
+// of the latest published revision when revision is empty and the latest
+// revision was read within latestRevisionTTL of now
+func (reader *PackageCRDReader) cached(revision string, now time.Time) (*CRDSet, bool) {
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+	if revision == "" {
+		if now.Sub(reader.latestRead) >= latestRevisionTTL {
+			return nil, false
+		}
+		revision = reader.latestRevision
+	}
+	crdSet, ok := reader.revisions[revision]
+	if ok {
+		reader.use(revision)
+	}
+	return crdSet, ok
+}
+
+// use : This method marks the revision as the most recently used one and
+// evicts the least recently used revisions beyond maxRevisions
+func (reader *PackageCRDReader) use(name string) {
+	for i, used := range reader.used {
+		if used == name {
+			reader.used = append(reader.used[:i], reader.used[i+1:]...)
+			break
+		}
+	}
+	reader.used = append(reader.used, name)
+	for len(reader.used) > maxRevisions {
+		delete(reader.revisions, reader.used[0])
+		reader.used = reader.used[1:]
+	}
+}
+
+// now : This method returns the current time of the clock of the reader
+func (reader *PackageCRDReader) now() time.Time {
+	if reader.clock == nil {
+		return clock.RealClock{}.Now()
+	}
+	return reader.clock.Now()
+}
+
+// ReadCRDFiles : This method reads the latest published revision of the
+// nf-profiles package. The directory is ignored.
+func (reader *PackageCRDReader) ReadCRDFiles(directory string) error {
+	_, err := reader.latest()
+	return err
+}
+
+// GetUPFTypeObject : This method returns a UpfType object of the latest
+// published revision based on its metadata name
+func (reader *PackageCRDReader) GetUPFTypeObject(crdName string) (types.UPFType, error) {
+	crdReader, err := reader.latest()
+	if err != nil {
+		return types.UPFType{}, err
+	}
+	return crdReader.GetUPFTypeObject(crdName)
+}
+
+// GetSMFTypeObject : This method returns an SmfType object of the latest
+// published revision based on its metadata name
+func (reader *PackageCRDReader) GetSMFTypeObject(crdName string) (types.SMFType, error) {
+	crdReader, err := reader.latest()
+	if err != nil {
+		return types.SMFType{}, err
+	}
+	return crdReader.GetSMFTypeObject(crdName)
+}
+
+// GetUPFCapacityProfileObject : This method returns a UPFCapacityProfile
+// object of the latest published revision based on its metadata name
+func (reader *PackageCRDReader) GetUPFCapacityProfileObject(crdName string) (
+	types.UPFCapacityProfile, error,
+) {
+	crdReader, err := reader.latest()
+	if err != nil {
+		return types.UPFCapacityProfile{}, err
+	}
+	return crdReader.GetUPFCapacityProfileObject(crdName)
+}
+
+// GetSMFCapacityProfileObject : This method returns an SMFCapacityProfile
+// object of the latest published revision based on its metadata name
+func (reader *PackageCRDReader) GetSMFCapacityProfileObject(crdName string) (
+	types.SMFCapacityProfile, error,
+) {
+	crdReader, err := reader.latest()
+	if err != nil {
+		return types.SMFCapacityProfile{}, err
+	}
+	return crdReader.GetSMFCapacityProfileObject(crdName)
+}
+
+// latest : This method returns a CRDReader of the objects of the latest
+// published revision of the nf-profiles package
+func (reader *PackageCRDReader) latest() (CRDReader, error) {
+	return reader.ForRevision(context.Background(), "")
+}
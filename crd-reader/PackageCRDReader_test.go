@@ -0,0 +1,225 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdreader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	testingclock "k8s.io/utils/clock/testing"
+
+	psmock "github.com/nephio-project/nf-deploy-controller/packageservice/mock"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// readTestResources reads the files of a test directory keyed by file name,
+// the way the package service returns the resources of a package
+func readTestResources(directory string) map[string]string {
+	entries, err := os.ReadDir(directory)
+	Expect(err).To(Not(HaveOccurred()))
+	resources := map[string]string{}
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(directory, entry.Name()))
+		Expect(err).To(Not(HaveOccurred()))
+		resources[entry.Name()] = string(content)
+	}
+	return resources
+}
+
+var _ = Describe(
+	"PackageCRDReader", func() {
+		var mockCtrl *gomock.Controller
+		var mockPS *psmock.MockPackageServiceInterface
+		var reader *PackageCRDReader
+		var resources map[string]string
+
+		BeforeEach(
+			func() {
+				mockCtrl = gomock.NewController(GinkgoT())
+				mockPS = psmock.NewMockPackageServiceInterface(mockCtrl)
+				reader = &PackageCRDReader{PS: mockPS}
+				resources = readTestResources("testfiles/kyaml-readable-files")
+			},
+		)
+		AfterEach(
+			func() {
+				mockCtrl.Finish()
+			},
+		)
+
+		Context(
+			"When a revision is read twice", func() {
+				It(
+					"Should read it from the package service once", func() {
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "rev1").
+							Return("rev1", resources, nil).Times(1)
+						for i := 0; i < 2; i++ {
+							crdReader, err := reader.ForRevision(context.TODO(), "rev1")
+							Expect(err).To(Not(HaveOccurred()))
+							_, err = crdReader.GetUPFTypeObject("UpfTypeTest")
+							Expect(err).To(Not(HaveOccurred()))
+						}
+					},
+				)
+			},
+		)
+		Context(
+			"When the latest revision was already read by name", func() {
+				It(
+					"Should return the objects read before", func() {
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "rev1").
+							Return("rev1", resources, nil).Times(1)
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "").
+							Return("rev1", map[string]string{}, nil).Times(1)
+						byName, err := reader.ForRevision(context.TODO(), "rev1")
+						Expect(err).To(Not(HaveOccurred()))
+						latest, err := reader.ForRevision(context.TODO(), "")
+						Expect(err).To(Not(HaveOccurred()))
+						Expect(latest).To(BeIdenticalTo(byName))
+					},
+				)
+			},
+		)
+		Context(
+			"When the objects are read without a revision", func() {
+				It(
+					"Should read them from the latest published revision", func() {
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "").
+							Return("rev2", resources, nil).AnyTimes()
+						Expect(reader.ReadCRDFiles("ignored")).To(Succeed())
+						_, err := reader.GetUPFTypeObject("UpfTypeTest")
+						Expect(err).To(Not(HaveOccurred()))
+						_, err = reader.GetSMFTypeObject("SmfTypeTest")
+						Expect(err).To(Not(HaveOccurred()))
+						_, err = reader.GetUPFCapacityProfileObject("UpfCapacityProfileTest")
+						Expect(err).To(Not(HaveOccurred()))
+						_, err = reader.GetSMFCapacityProfileObject("SmfCapacityProfileTest")
+						Expect(err).To(Not(HaveOccurred()))
+					},
+				)
+			},
+		)
+		Context(
+			"When the latest revision is read again", func() {
+				It(
+					"Should read it from the package service once per TTL", func() {
+						fakeClock := testingclock.NewFakePassiveClock(time.Now())
+						reader.clock = fakeClock
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "").
+							Return("rev1", resources, nil).Times(1)
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "").
+							Return("rev2", resources, nil).Times(1)
+						for i := 0; i < 3; i++ {
+							_, err := reader.GetUPFTypeObject("UpfTypeTest")
+							Expect(err).To(Not(HaveOccurred()))
+						}
+						Expect(reader.latestRevision).To(Equal("rev1"))
+
+						fakeClock.SetTime(fakeClock.Now().Add(latestRevisionTTL))
+						_, err := reader.GetUPFTypeObject("UpfTypeTest")
+						Expect(err).To(Not(HaveOccurred()))
+						Expect(reader.latestRevision).To(Equal("rev2"))
+					},
+				)
+			},
+		)
+		Context(
+			"When more than maxRevisions revisions are read", func() {
+				It(
+					"Should evict the least recently used ones", func() {
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), gomock.Any()).
+							DoAndReturn(
+								func(_ context.Context, _ util.NamingContext, revision string) (
+									string, map[string]string, error,
+								) {
+									return revision, resources, nil
+								},
+							).Times(maxRevisions + 2)
+						for i := 0; i <= maxRevisions; i++ {
+							_, err := reader.ForRevision(context.TODO(), fmt.Sprintf("rev%d", i))
+							Expect(err).To(Not(HaveOccurred()))
+						}
+						Expect(reader.revisions).To(HaveLen(maxRevisions))
+						Expect(reader.revisions).NotTo(HaveKey("rev0"))
+						_, err := reader.ForRevision(context.TODO(), "rev0")
+						Expect(err).To(Not(HaveOccurred()))
+						Expect(reader.revisions).NotTo(HaveKey("rev1"))
+					},
+				)
+			},
+		)
+		Context(
+			"When a revision is read from the package service", func() {
+				It(
+					"Should not block the reads of the cached revisions", func() {
+						fetching := make(chan struct{})
+						release := make(chan struct{})
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "rev1").
+							Return("rev1", resources, nil).Times(1)
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "rev2").
+							DoAndReturn(
+								func(_ context.Context, _ util.NamingContext, revision string) (
+									string, map[string]string, error,
+								) {
+									close(fetching)
+									<-release
+									return revision, resources, nil
+								},
+							).Times(1)
+						_, err := reader.ForRevision(context.TODO(), "rev1")
+						Expect(err).To(Not(HaveOccurred()))
+
+						done := make(chan error)
+						go func() {
+							_, err := reader.ForRevision(context.TODO(), "rev2")
+							done <- err
+						}()
+						Eventually(fetching).Should(BeClosed())
+						_, err = reader.ForRevision(context.TODO(), "rev1")
+						Expect(err).To(Not(HaveOccurred()))
+						close(release)
+						Eventually(done).Should(Receive(BeNil()))
+						Expect(reader.revisions).To(HaveKey("rev2"))
+					},
+				)
+			},
+		)
+		Context(
+			"When the package service fails", func() {
+				It(
+					"Should return error and not cache the revision", func() {
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "rev1").
+							Return("", nil, errors.New("porch unavailable")).Times(1)
+						mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "rev1").
+							Return("rev1", resources, nil).Times(1)
+						_, err := reader.ForRevision(context.TODO(), "rev1")
+						Expect(err).To(HaveOccurred())
+						_, err = reader.ForRevision(context.TODO(), "rev1")
+						Expect(err).To(Not(HaveOccurred()))
+					},
+				)
+			},
+		)
+	},
+)
@@ -19,6 +19,7 @@
 package crdreader
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -121,3 +122,115 @@ func (mr *MockCRDReaderMockRecorder) ReadCRDFiles(directory interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCRDFiles", reflect.TypeOf((*MockCRDReader)(nil).ReadCRDFiles), directory)
 }
+
+// MockRevisionReader is a mock of RevisionReader interface.
+type MockRevisionReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevisionReaderMockRecorder
+}
+
+// MockRevisionReaderMockRecorder is the mock recorder for MockRevisionReader.
+type MockRevisionReaderMockRecorder struct {
+	mock *MockRevisionReader
+}
+
+// NewMockRevisionReader creates a new mock instance.
+func NewMockRevisionReader(ctrl *gomock.Controller) *MockRevisionReader {
+	mock := &MockRevisionReader{ctrl: ctrl}
+	mock.recorder = &MockRevisionReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevisionReader) EXPECT() *MockRevisionReaderMockRecorder {
+	return m.recorder
+}
+
+// ForRevision mocks base method.
+func (m *MockRevisionReader) ForRevision(ctx context.Context, revision string) (CRDReader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForRevision", ctx, revision)
+	ret0, _ := ret[0].(CRDReader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForRevision indicates an expected call of ForRevision.
+func (mr *MockRevisionReaderMockRecorder) ForRevision(ctx, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForRevision", reflect.TypeOf((*MockRevisionReader)(nil).ForRevision), ctx, revision)
+}
+
+// GetSMFCapacityProfileObject mocks base method.
+func (m *MockRevisionReader) GetSMFCapacityProfileObject(crdName string) (nfdeploy.SMFCapacityProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSMFCapacityProfileObject", crdName)
+	ret0, _ := ret[0].(nfdeploy.SMFCapacityProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSMFCapacityProfileObject indicates an expected call of GetSMFCapacityProfileObject.
+func (mr *MockRevisionReaderMockRecorder) GetSMFCapacityProfileObject(crdName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSMFCapacityProfileObject", reflect.TypeOf((*MockRevisionReader)(nil).GetSMFCapacityProfileObject), crdName)
+}
+
+// GetSMFTypeObject mocks base method.
+func (m *MockRevisionReader) GetSMFTypeObject(crdName string) (nfdeploy.SMFType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSMFTypeObject", crdName)
+	ret0, _ := ret[0].(nfdeploy.SMFType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSMFTypeObject indicates an expected call of GetSMFTypeObject.
+func (mr *MockRevisionReaderMockRecorder) GetSMFTypeObject(crdName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSMFTypeObject", reflect.TypeOf((*MockRevisionReader)(nil).GetSMFTypeObject), crdName)
+}
+
+// GetUPFCapacityProfileObject mocks base method.
+func (m *MockRevisionReader) GetUPFCapacityProfileObject(crdName string) (nfdeploy.UPFCapacityProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUPFCapacityProfileObject", crdName)
+	ret0, _ := ret[0].(nfdeploy.UPFCapacityProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUPFCapacityProfileObject indicates an expected call of GetUPFCapacityProfileObject.
+func (mr *MockRevisionReaderMockRecorder) GetUPFCapacityProfileObject(crdName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUPFCapacityProfileObject", reflect.TypeOf((*MockRevisionReader)(nil).GetUPFCapacityProfileObject), crdName)
+}
+
+// GetUPFTypeObject mocks base method.
+func (m *MockRevisionReader) GetUPFTypeObject(crdName string) (nfdeploy.UPFType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUPFTypeObject", crdName)
+	ret0, _ := ret[0].(nfdeploy.UPFType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUPFTypeObject indicates an expected call of GetUPFTypeObject.
+func (mr *MockRevisionReaderMockRecorder) GetUPFTypeObject(crdName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUPFTypeObject", reflect.TypeOf((*MockRevisionReader)(nil).GetUPFTypeObject), crdName)
+}
+
+// ReadCRDFiles mocks base method.
+func (m *MockRevisionReader) ReadCRDFiles(directory string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadCRDFiles", directory)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadCRDFiles indicates an expected call of ReadCRDFiles.
+func (mr *MockRevisionReaderMockRecorder) ReadCRDFiles(directory interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCRDFiles", reflect.TypeOf((*MockRevisionReader)(nil).ReadCRDFiles), directory)
+}
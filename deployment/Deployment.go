@@ -34,10 +34,11 @@ import (
 	"github.com/nephio-project/edge-watcher/preprocessor"
 	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 	crdreader "github.com/nephio-project/nf-deploy-controller/crd-reader"
-	corev1 "k8s.io/api/core/v1"
+	"github.com/nephio-project/nf-deploy-controller/status"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	. "k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 )
 
 // Deployment : This fundamental module represents a single NFDeploy
@@ -47,6 +48,8 @@ type Deployment struct {
 	ctx       context.Context
 	cancelCtx func()
 	name      string
+	// generation of the NfDeploy the status is computed for
+	generation int64
 	// protects NF Nodes and Edges
 	deploymentMu sync.RWMutex
 	upfNodes     map[string]UPFNode
@@ -63,11 +66,39 @@ type Deployment struct {
 	smfIntentProcessor crdreader.SMFIntentProcessor
 	edgeErrorChan      chan error
 	edgeEventsChan     chan preprocessor.Event
-
-	statusReader   client.Reader
-	statusWriter   client.StatusWriter
-	namespacedName NamespacedName
-	logger         logr.Logger
+	// edge events received and not processed yet
+	eventQueue *eventQueue
+	// minimum interval between two status updates driven by edge events
+	statusFlushInterval time.Duration
+	// set when the status of an NF changes and NfDeploy status is not
+	// updated yet, protected by deploymentMu
+	statusDirty bool
+	// NFs of a type are marked StatusStale when no edge event is received
+	// within its staleness timeout
+	stalenessTimeouts map[NFType]time.Duration
+	clock             clock.WithTicker
+	recorder          record.EventRecorder
+	// uid of the NfDeploy, to record events on it
+	uid UID
+	// CRDReader given at init. When it reads the revisions of the NF
+	// profiles, crdReader reads the revision NfDeploy was hydrated with.
+	profiles crdreader.CRDReader
+	// siteClusters are the workload clusters of the sites by site id
+	siteClusters map[string]string
+	// hydratedGenerations are the generations of the NfDeploy the packages
+	// of the sites are hydrated from by site id, which the NF objects
+	// reported from the edge are expected to be hydrated from
+	hydratedGenerations map[string]int64
+	// awaitSync is set when the deployment is created for an NfDeploy being
+	// deleted, e.g. after a restart of the controller. The NFs which never
+	// reported are then not considered removed until their workload cluster
+	// sent an edge event, recorded in syncedClusters.
+	awaitSync      bool
+	syncedClusters map[string]bool
+
+	statusAggregator status.Aggregator
+	namespacedName   NamespacedName
+	logger           logr.Logger
 }
 
 var _ DeploymentProcessor = &Deployment{}
@@ -78,14 +109,22 @@ var connected void
 // present : Represents if a node is present in list
 var present void
 
+const (
+	// minStatusRetryInterval is the interval before the first retry of a
+	// failed status update, doubled for each further retry up to
+	// maxStatusRetryInterval
+	minStatusRetryInterval = time.Second
+	maxStatusRetryInterval = 2 * time.Minute
+)
+
 // Init : This method initialises the deployment
 func (deployment *Deployment) Init(
 	CRDReader crdreader.CRDReader,
 	upfIntentProcessor crdreader.UPFIntentProcessor,
 	smfIntentProcessor crdreader.SMFIntentProcessor,
-	statusReader client.Reader,
-	statusWriter client.StatusWriter,
+	statusAggregator status.Aggregator,
 	namespacedName NamespacedName,
+	options Options,
 	logger logr.Logger,
 ) {
 	deployment.ctx = context.Background()
@@ -95,13 +134,24 @@ func (deployment *Deployment) Init(
 	deployment.amfNodes = make(map[string]AMFNode)
 	deployment.ausfNodes = make(map[string]AUSFNode)
 	deployment.udmNodes = make(map[string]UDMNode)
+	deployment.siteClusters = make(map[string]string)
+	deployment.hydratedGenerations = make(map[string]int64)
+	deployment.syncedClusters = make(map[string]bool)
 	deployment.upfIntentProcessor = upfIntentProcessor
 	deployment.smfIntentProcessor = smfIntentProcessor
 	deployment.crdReader = CRDReader
+	deployment.profiles = CRDReader
 	deployment.edgeErrorChan = make(chan error)
 	deployment.edgeEventsChan = make(chan preprocessor.Event)
-	deployment.statusWriter = statusWriter
-	deployment.statusReader = statusReader
+	deployment.eventQueue = newEventQueue(options.EventQueueSize)
+	deployment.statusFlushInterval = options.StatusFlushInterval
+	deployment.stalenessTimeouts = options.StalenessTimeouts
+	deployment.clock = options.Clock
+	if deployment.clock == nil {
+		deployment.clock = clock.RealClock{}
+	}
+	deployment.recorder = options.EventRecorder
+	deployment.statusAggregator = statusAggregator
 	deployment.namespacedName = namespacedName
 	deployment.logger = logger
 }
@@ -131,7 +181,10 @@ func (deployment *Deployment) addOrUpdateUPFNode(site v1alpha1.Site) {
 	nfId := site.Id
 	clusterName := site.ClusterName
 	if _, isPresent := deployment.upfNodes[nfId]; !isPresent {
-		node := Node{Id: nfId, NFType: UPF, Connections: make(map[string]void)}
+		node := Node{
+			Id: nfId, NFType: UPF, Connections: make(map[string]void),
+			addedTime: deployment.clock.Now(),
+		}
 		upfNode := UPFNode{Node: node, Spec: UPFSpec{clusterName: clusterName}}
 		deployment.upfNodes[upfNode.Id] = upfNode
 	}
@@ -154,7 +207,10 @@ func (deployment *Deployment) addOrUpdateSMFNode(site v1alpha1.Site) {
 	nfId := site.Id
 	clusterName := site.ClusterName
 	if _, isPresent := deployment.smfNodes[nfId]; !isPresent {
-		node := Node{Id: nfId, NFType: SMF, Connections: make(map[string]void)}
+		node := Node{
+			Id: nfId, NFType: SMF, Connections: make(map[string]void),
+			addedTime: deployment.clock.Now(),
+		}
 		smfNode := SMFNode{Node: node, Spec: SMFSpec{clusterName: clusterName}}
 		deployment.smfNodes[smfNode.Id] = smfNode
 	}
@@ -189,7 +245,10 @@ func (deployment *Deployment) addOrUpdateAMFNode(site v1alpha1.Site) {
 func (deployment *Deployment) addOrUpdateAUSFNode(site v1alpha1.Site) {
 	nfId := site.Id
 	if _, isPresent := deployment.ausfNodes[nfId]; !isPresent {
-		node := Node{Id: nfId, NFType: AUSF, Connections: make(map[string]void)}
+		node := Node{
+			Id: nfId, NFType: AUSF, Connections: make(map[string]void),
+			addedTime: deployment.clock.Now(),
+		}
 		ausfNode := AUSFNode{Node: node}
 		deployment.ausfNodes[ausfNode.Id] = ausfNode
 	}
@@ -198,7 +257,10 @@ func (deployment *Deployment) addOrUpdateAUSFNode(site v1alpha1.Site) {
 func (deployment *Deployment) addOrUpdateUDMNode(site v1alpha1.Site) {
 	nfId := site.Id
 	if _, isPresent := deployment.udmNodes[nfId]; !isPresent {
-		node := Node{Id: nfId, NFType: UDM, Connections: make(map[string]void)}
+		node := Node{
+			Id: nfId, NFType: UDM, Connections: make(map[string]void),
+			addedTime: deployment.clock.Now(),
+		}
 		udmNode := UDMNode{Node: node}
 		deployment.udmNodes[udmNode.Id] = udmNode
 	}
@@ -224,13 +286,20 @@ func (deployment *Deployment) addConnection(
 
 // CreateEdge := Creates edge connection between two nfs if the connection
 // is not already present
-func (deployment *Deployment) createEdge(nfId1 string, nfId2 string) {
-	for _, edge := range deployment.edges {
+func (deployment *Deployment) createEdge(
+	nfId1 string, nfId2 string, referencePoint v1alpha1.ReferencePoint,
+) {
+	for index, edge := range deployment.edges {
 		if edge.IsEqual(nfId2, nfId1) {
+			if referencePoint != "" {
+				deployment.edges[index].ReferencePoint = referencePoint
+			}
 			return
 		}
 	}
-	edge := Edge{FirstNode: nfId1, SecondNode: nfId2}
+	edge := Edge{
+		FirstNode: nfId1, SecondNode: nfId2, ReferencePoint: referencePoint,
+	}
 	nfType1 := deployment.getNFType(nfId1)
 	nfType2 := deployment.getNFType(nfId2)
 	deployment.addConnection(nfId1, nfType1, nfId2)
@@ -251,6 +320,51 @@ func (deployment *Deployment) removeEdge(nfId1 string, nfId2 string) {
 	}
 }
 
+// removeConnection: removes nfId2 from the connections of nfId1
+func (deployment *Deployment) removeConnection(nfId1 string, nfId2 string) {
+	switch deployment.getNFType(nfId1) {
+	case UPF:
+		delete(deployment.upfNodes[nfId1].Connections, nfId2)
+	case SMF:
+		delete(deployment.smfNodes[nfId1].Connections, nfId2)
+	case AMF:
+		delete(deployment.amfNodes[nfId1].Connections, nfId2)
+	case UDM:
+		delete(deployment.udmNodes[nfId1].Connections, nfId2)
+	case AUSF:
+		delete(deployment.ausfNodes[nfId1].Connections, nfId2)
+	}
+}
+
+// removeEdges: removes the edges whose connectivity is not present in
+// nfDeploy anymore
+func (deployment *Deployment) removeEdges(nfDeploy v1alpha1.NfDeploy) {
+	var edges []Edge
+	for _, edge := range deployment.edges {
+		if isConnected(nfDeploy, edge.FirstNode, edge.SecondNode) {
+			edges = append(edges, edge)
+			continue
+		}
+		deployment.removeConnection(edge.FirstNode, edge.SecondNode)
+		deployment.removeConnection(edge.SecondNode, edge.FirstNode)
+	}
+	deployment.edges = edges
+}
+
+// isConnected returns true if either of the sites with the ids nfId1 and
+// nfId2 has a connectivity to the other in nfDeploy
+func isConnected(nfDeploy v1alpha1.NfDeploy, nfId1 string, nfId2 string) bool {
+	for _, site := range nfDeploy.Spec.Sites {
+		for _, connection := range site.Connectivities {
+			if (site.Id == nfId1 && connection.NeighborName == nfId2) ||
+				(site.Id == nfId2 && connection.NeighborName == nfId1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // removeNFs: removes NFs that are not present in nfDeploy
 func (deployment *Deployment) removeNFs(nfDeploy v1alpha1.NfDeploy) {
 	var nfList = make(map[string]NFType)
@@ -302,10 +416,21 @@ func (deployment *Deployment) removeNFs(nfDeploy v1alpha1.NfDeploy) {
 // ReportNFDeployEvent := Takes nfDeploy and creates & updates deployment graph structure.
 // It also updates the spec of individual NFs
 func (deployment *Deployment) ReportNFDeployEvent(nfDeploy v1alpha1.NfDeploy) {
+	// read before locking, as the revision may have to be read from Porch
+	crdReader := deployment.readProfiles(nfDeploy)
 
 	deployment.deploymentMu.Lock()
 	defer deployment.deploymentMu.Unlock()
 	deployment.name = nfDeploy.Name
+	deployment.generation = nfDeploy.Generation
+	deployment.uid = nfDeploy.UID
+	if crdReader != nil {
+		deployment.crdReader = crdReader
+	}
+	deployment.siteClusters = make(map[string]string, len(nfDeploy.Spec.Sites))
+	for _, site := range nfDeploy.Spec.Sites {
+		deployment.siteClusters[site.Id] = site.ClusterName
+	}
 	for _, site := range nfDeploy.Spec.Sites {
 		switch NFType(site.NFType) {
 		case UPF:
@@ -325,58 +450,158 @@ func (deployment *Deployment) ReportNFDeployEvent(nfDeploy v1alpha1.NfDeploy) {
 	}
 	for _, site := range nfDeploy.Spec.Sites {
 		for _, connection := range site.Connectivities {
-			deployment.createEdge(site.Id, connection.NeighborName)
+			deployment.createEdge(
+				site.Id, connection.NeighborName, connection.ReferencePoint,
+			)
 		}
 	}
 	deployment.removeNFs(nfDeploy)
+	deployment.removeEdges(nfDeploy)
+	deployment.hydratedGenerations = hydratedGenerations(
+		nfDeploy, deployment.hydratedGenerations,
+	)
+	deployment.markLaggingNFs()
 	deployment.logger.Info(
 		"Report NFDeploy succeeded for", "NFDeploy", nfDeploy.Name,
 	)
 }
 
+// markLaggingNFs : marks the NFs whose last reported NF object lags the
+// generation hydrated for them as reconciling, e.g. when the edge does not
+// apply the package of a new generation and so sends no further event.
+// Callers hold deploymentMu.
+func (deployment *Deployment) markLaggingNFs() {
+	deployment.updateNFStatuses(
+		func(node Node, nfStatus NFStatus) NFStatus {
+			if nfStatus.state == "" || nfStatus.state == StatusStale || nfStatus.removed {
+				return nfStatus
+			}
+			lagging := nfStatus.withGenerationLag(deployment.hydratedGenerations[node.Id])
+			if lagging.stateMessage != nfStatus.stateMessage {
+				deployment.statusDirty = true
+			}
+			return lagging
+		},
+	)
+}
+
+// updateNFStatuses : replaces the status of each NF by the status returned by
+// update for it. Callers hold deploymentMu.
+func (deployment *Deployment) updateNFStatuses(
+	update func(node Node, nfStatus NFStatus) NFStatus,
+) {
+	for nfId, node := range deployment.upfNodes {
+		node.Status = update(node.Node, node.Status)
+		deployment.upfNodes[nfId] = node
+	}
+	for nfId, node := range deployment.smfNodes {
+		node.Status = update(node.Node, node.Status)
+		deployment.smfNodes[nfId] = node
+	}
+	for nfId, node := range deployment.ausfNodes {
+		node.Status = update(node.Node, node.Status)
+		deployment.ausfNodes[nfId] = node
+	}
+	for nfId, node := range deployment.udmNodes {
+		node.Status = update(node.Node, node.Status)
+		deployment.udmNodes[nfId] = node
+	}
+}
+
+// forEachNF : calls visit with each NF and its status. Callers hold
+// deploymentMu, for reading at least.
+func (deployment *Deployment) forEachNF(visit func(node Node, nfStatus NFStatus)) {
+	for _, node := range deployment.upfNodes {
+		visit(node.Node, node.Status)
+	}
+	for _, node := range deployment.smfNodes {
+		visit(node.Node, node.Status)
+	}
+	for _, node := range deployment.ausfNodes {
+		visit(node.Node, node.Status)
+	}
+	for _, node := range deployment.udmNodes {
+		visit(node.Node, node.Status)
+	}
+}
+
+// readProfiles : returns the reader of the revision of the NF profiles
+// nfDeploy was hydrated with, which the intents of the NFs are resolved from
+// so that they match its packages. The latest revision is read when none is
+// recorded. It returns nil, so that the previous revision is kept, if the
+// revision cannot be read.
+func (deployment *Deployment) readProfiles(nfDeploy v1alpha1.NfDeploy) crdreader.CRDReader {
+	reader, ok := deployment.profiles.(crdreader.RevisionReader)
+	if !ok {
+		return nil
+	}
+	revision := ""
+	if nfDeploy.Status.Profiles != nil {
+		revision = nfDeploy.Status.Profiles.Revision
+	}
+	crdReader, err := reader.ForRevision(deployment.ctx, revision)
+	if err != nil {
+		deployment.logger.Error(
+			err, "CRD Reader failed to read the NF profiles", "NFDeploy", nfDeploy.Name,
+			"revision", revision,
+		)
+		return nil
+	}
+	return crdReader
+}
+
+// GetNFStates := Returns the state of the NFs which reported at least one
+// edge event, by site id
+func (deployment *Deployment) GetNFStates() map[string]types.NFConditionType {
+	deployment.deploymentMu.RLock()
+	defer deployment.deploymentMu.RUnlock()
+	states := make(map[string]types.NFConditionType)
+	for nfId, node := range deployment.upfNodes {
+		if node.Status.state != "" {
+			states[nfId] = node.Status.state
+		}
+	}
+	for nfId, node := range deployment.smfNodes {
+		if node.Status.state != "" {
+			states[nfId] = node.Status.state
+		}
+	}
+	for nfId, node := range deployment.ausfNodes {
+		if node.Status.state != "" {
+			states[nfId] = node.Status.state
+		}
+	}
+	for nfId, node := range deployment.udmNodes {
+		if node.Status.state != "" {
+			states[nfId] = node.Status.state
+		}
+	}
+	return states
+}
+
 // updateSubscriptionFailureCondition: updates all NFConditions in NFDeploy status
 // to unknown with given reason and message
 func (deployment *Deployment) updateSubscriptionFailureCondition(
 	reason string, message string,
 ) error {
-	var conditionSet []v1alpha1.NFDeployCondition
-	currentTime := metav1.NewTime(time.Now())
-	stalledCondition := v1alpha1.NFDeployCondition{
-		Type: v1alpha1.DeploymentStalled, Status: corev1.ConditionUnknown,
-		LastUpdateTime:     currentTime,
-		LastTransitionTime: currentTime,
-		Reason:             reason,
-		Message:            message,
-	}
-	readyCondition := v1alpha1.NFDeployCondition{
-		Type: v1alpha1.DeploymentReady, Status: corev1.ConditionUnknown,
-		LastUpdateTime:     currentTime,
-		LastTransitionTime: currentTime,
-		Reason:             reason,
-		Message:            message,
-	}
-	peeringCondition := v1alpha1.NFDeployCondition{
-		Type: v1alpha1.DeploymentPeering, Status: corev1.ConditionUnknown,
-		LastUpdateTime:     currentTime,
-		LastTransitionTime: currentTime,
-		Reason:             reason,
-		Message:            message,
-	}
-	reconcilingCondition := v1alpha1.NFDeployCondition{
-		Type: v1alpha1.DeploymentReconciling, Status: corev1.ConditionUnknown,
-		LastUpdateTime:     currentTime,
-		LastTransitionTime: currentTime,
-		Reason:             reason,
-		Message:            message,
-	}
-	conditionSet = append(
-		conditionSet, stalledCondition, readyCondition, peeringCondition,
-		reconcilingCondition,
-	)
-	return deployment.updateNFDeployStatus(
-		0, 0, 0, 0, &stalledCondition, &readyCondition, &peeringCondition,
-		&reconcilingCondition,
-	)
+	var conditionSet []metav1.Condition
+	for _, conditionType := range []v1alpha1.NFDeployConditionType{
+		v1alpha1.DeploymentStalled, v1alpha1.DeploymentReady,
+		v1alpha1.DeploymentPeering, v1alpha1.DeploymentReconciling,
+	} {
+		conditionSet = append(conditionSet, metav1.Condition{
+			Type:    string(conditionType),
+			Status:  metav1.ConditionUnknown,
+			Reason:  reason,
+			Message: message,
+		})
+	}
+	deployment.deploymentMu.RLock()
+	runtimeStatus := status.RuntimeStatus{
+		Generation: deployment.generation, Conditions: conditionSet,
+	}
+	deployment.deploymentMu.RUnlock()
+	return deployment.updateNFDeployStatus(runtimeStatus)
 }
 
 // ListenSubscriptionStatus listens for errors from edgewatcher during subscription
@@ -425,6 +650,7 @@ func (deployment *Deployment) processEdgeEvent(object *preprocessor.Event) {
 		)
 		return
 	}
+	deployment.syncedClusters[object.Key.ClusterName] = true
 
 	switch object.Key.Kind {
 	case "UPFDeploy":
@@ -448,17 +674,22 @@ func (deployment *Deployment) processEdgeEvent(object *preprocessor.Event) {
 			)
 			return
 		}
-		// TODO: add testcase to verify stale events are discarded
-		if object.Timestamp.Before(deployment.upfNodes[upfName].Status.lastEventTimestamp) {
+		version := eventObjectVersion(object)
+		if version.isOlderThan(deployment.upfNodes[upfName].Status.lastEventVersion) {
 			deployment.logger.Info(
-				"The NF event received is of previous timestamp", "UPFDeploy",
+				"The NF event received is of previous version", "UPFDeploy",
 				upfName,
 			)
 			return
 		}
 		upfNode := deployment.upfNodes[upfName]
-		upfNode.Status.lastEventTimestamp = object.Timestamp
+		upfNode.Status.lastEventVersion = version
+		upfNode.Status.lastEventTime = deployment.clock.Now()
 		deployment.upfNodes[upfName] = upfNode
+		if object.Type == preprocessor.Deleted {
+			deployment.processNFRemovedEvent(upfName)
+			return
+		}
 		deployment.processNFEdgeEvent(
 			&upfDeploy.Status.Conditions, upfName,
 		)
@@ -483,16 +714,22 @@ func (deployment *Deployment) processEdgeEvent(object *preprocessor.Event) {
 			)
 			return
 		}
-		if object.Timestamp.Before(deployment.smfNodes[smfName].Status.lastEventTimestamp) {
+		version := eventObjectVersion(object)
+		if version.isOlderThan(deployment.smfNodes[smfName].Status.lastEventVersion) {
 			deployment.logger.Info(
-				"The NF event received is of previous timestamp", "SMFDeploy",
+				"The NF event received is of previous version", "SMFDeploy",
 				smfName,
 			)
 			return
 		}
 		smfNode := deployment.smfNodes[smfName]
-		smfNode.Status.lastEventTimestamp = object.Timestamp
+		smfNode.Status.lastEventVersion = version
+		smfNode.Status.lastEventTime = deployment.clock.Now()
 		deployment.smfNodes[smfName] = smfNode
+		if object.Type == preprocessor.Deleted {
+			deployment.processNFRemovedEvent(smfName)
+			return
+		}
 		deployment.processNFEdgeEvent(
 			&smfDeploy.Status.Conditions, smfName,
 		)
@@ -517,16 +754,22 @@ func (deployment *Deployment) processEdgeEvent(object *preprocessor.Event) {
 			)
 			return
 		}
-		if object.Timestamp.Before(deployment.udmNodes[udmName].Status.lastEventTimestamp) {
+		version := eventObjectVersion(object)
+		if version.isOlderThan(deployment.udmNodes[udmName].Status.lastEventVersion) {
 			deployment.logger.Info(
-				"The NF event received is of previous timestamp", "UDMDeploy",
+				"The NF event received is of previous version", "UDMDeploy",
 				udmName,
 			)
 			return
 		}
 		udmNode := deployment.udmNodes[udmName]
-		udmNode.Status.lastEventTimestamp = object.Timestamp
+		udmNode.Status.lastEventVersion = version
+		udmNode.Status.lastEventTime = deployment.clock.Now()
 		deployment.udmNodes[udmName] = udmNode
+		if object.Type == preprocessor.Deleted {
+			deployment.processNFRemovedEvent(udmName)
+			return
+		}
 		deployment.processNFEdgeEvent(
 			&udmDeploy.Status.Conditions, udmName,
 		)
@@ -552,16 +795,22 @@ func (deployment *Deployment) processEdgeEvent(object *preprocessor.Event) {
 			)
 			return
 		}
-		if object.Timestamp.Before(deployment.ausfNodes[ausfName].Status.lastEventTimestamp) {
+		version := eventObjectVersion(object)
+		if version.isOlderThan(deployment.ausfNodes[ausfName].Status.lastEventVersion) {
 			deployment.logger.Info(
-				"The NF event received is of previous timestamp", "AUSFDeploy",
+				"The NF event received is of previous version", "AUSFDeploy",
 				ausfName,
 			)
 			return
 		}
 		ausfNode := deployment.ausfNodes[ausfName]
-		ausfNode.Status.lastEventTimestamp = object.Timestamp
+		ausfNode.Status.lastEventVersion = version
+		ausfNode.Status.lastEventTime = deployment.clock.Now()
 		deployment.ausfNodes[ausfName] = ausfNode
+		if object.Type == preprocessor.Deleted {
+			deployment.processNFRemovedEvent(ausfName)
+			return
+		}
 		deployment.processNFEdgeEvent(
 			&ausfDeploy.Status.Conditions, ausfName,
 		)
@@ -570,15 +819,88 @@ func (deployment *Deployment) processEdgeEvent(object *preprocessor.Event) {
 }
 
 // listenEdgeEvents listens for events from edgewatcher through deploymentChan
+// and queues them to be processed by processQueuedEdgeEvents, so that the
+// intake of edge events never waits for status updates
 func (deployment *Deployment) listenEdgeEvents() {
+	go deployment.processQueuedEdgeEvents()
+	nfDeployNamespace := deployment.namespacedName.Namespace
+	nfDeployName := deployment.namespacedName.Name
 	for {
 		select {
 		case <-deployment.ctx.Done():
 			return
 		case object, ok := <-deployment.edgeEventsChan:
-			if ok {
-				deployment.processEdgeEvent(&object)
-			} else {
+			if !ok {
+				deployment.eventQueue.close()
+				return
+			}
+			switch deployment.eventQueue.push(object) {
+			case eventCoalesced:
+				edgeEventsCoalesced.WithLabelValues(nfDeployNamespace, nfDeployName).Inc()
+			case eventDropped:
+				edgeEventsDropped.WithLabelValues(nfDeployNamespace, nfDeployName).Inc()
+				deployment.logger.Info(
+					"Edge event queue is full. Edge event dropped for", "NFDeploy",
+					nfDeployName, "kind", object.Key.Kind,
+				)
+			}
+		}
+	}
+}
+
+// processQueuedEdgeEvents processes the queued edge events and updates the
+// nfDeploy status at most once every statusFlushInterval. It also marks the
+// status of the NFs stale when their staleness timeout expires.
+func (deployment *Deployment) processQueuedEdgeEvents() {
+	var flushTimer clock.Timer
+	var flushChan <-chan time.Time
+	var stalenessChan <-chan time.Time
+	if interval := deployment.stalenessCheckInterval(); interval > 0 {
+		stalenessTicker := deployment.clock.NewTicker(interval)
+		defer stalenessTicker.Stop()
+		stalenessChan = stalenessTicker.C()
+	}
+	// interval before the next retry of a failed status update, zero when
+	// the last update succeeded
+	var retryInterval time.Duration
+	defer func() {
+		if flushTimer != nil {
+			flushTimer.Stop()
+		}
+	}()
+	armFlush := func(interval time.Duration) {
+		flushTimer = deployment.clock.NewTimer(interval)
+		flushChan = flushTimer.C()
+	}
+	flush := func() {
+		if err := deployment.flushNFDeployStatus(); err != nil {
+			// retried with backoff, as no further edge event may arrive
+			retryInterval = nextStatusRetryInterval(retryInterval)
+			armFlush(retryInterval)
+			return
+		}
+		retryInterval = 0
+	}
+	scheduleFlush := func() {
+		if flushChan != nil {
+			return
+		}
+		if deployment.statusFlushInterval <= 0 {
+			flush()
+			return
+		}
+		armFlush(deployment.statusFlushInterval)
+	}
+	for {
+		select {
+		case <-deployment.ctx.Done():
+			return
+		case <-deployment.eventQueue.ready:
+			events, closed := deployment.eventQueue.drain()
+			for i := range events {
+				deployment.processEdgeEvent(&events[i])
+			}
+			if closed {
 				err := deployment.updateSubscriptionFailureCondition(
 					"EdgeConnectionBroken", "Connection to edge broke unexpectedly.",
 				)
@@ -586,21 +908,45 @@ func (deployment *Deployment) listenEdgeEvents() {
 					deployment.logger.Error(
 						err, "FATAL ERROR: Connection to edge broke unexpectedly.",
 						"NFDeploy",
-						deployment.name,
+						deployment.namespacedName.Name,
 					)
 				}
+				return
 			}
+			scheduleFlush()
+		case <-stalenessChan:
+			if deployment.markStaleNFs() {
+				scheduleFlush()
+			}
+		case <-flushChan:
+			flushChan = nil
+			flush()
 		}
+	}
+}
 
+// nextStatusRetryInterval returns the interval before the retry of a failed
+// status update following a retry after interval
+func nextStatusRetryInterval(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return minStatusRetryInterval
+	}
+	if interval *= 2; interval > maxStatusRetryInterval {
+		return maxStatusRetryInterval
 	}
+	return interval
 }
 
-// processNFEdgeEvent : This method computes and updates aggregated status of
-// NFDeploy resource based on the change in status of a single NF
+// processNFEdgeEvent : This method updates the status of a single NF and
+// marks the aggregated status of NFDeploy resource to be updated
 func (deployment *Deployment) processNFEdgeEvent(
 	nfConditions *[]types.NFCondition, nfId string,
 ) {
-	conditions, conditionMessage := deployment.calculateNFConditionSet(nfConditions)
+	nfConditionSet, peerReports := splitPeerConditions(*nfConditions)
+	if deployment.updateEdgePeering(nfId, peerReports) {
+		deployment.statusDirty = true
+	}
+	conditions, conditionMessage := deployment.calculateNFConditionSet(&nfConditionSet)
 
 	if deployment.isAmbiguousConditionSet(conditions) {
 		deployment.logger.Info(
@@ -611,27 +957,34 @@ func (deployment *Deployment) processNFEdgeEvent(
 	deployment.updateCurrentNFStatus(
 		nfId, conditions, conditionMessage,
 	)
-	availableNFs, readyNFs, stalledNFs, targetedNFs := deployment.calculateNFCount()
-
-	stalledCondition := deployment.computeStalledCondition(
-		stalledNFs, targetedNFs,
-	)
-	readyCondition := deployment.computeReadyCondition(readyNFs, targetedNFs)
-
-	peeringCondition := deployment.computePeeringCondition(readyNFs, targetedNFs)
-
-	reconcilingCondition := deployment.computeReconcilingCondition(
-		readyNFs, targetedNFs,
-	)
+	deployment.statusDirty = true
+}
 
-	err := deployment.updateNFDeployStatus(
-		int32(availableNFs), int32(readyNFs), int32(stalledNFs), int32(targetedNFs),
-		&stalledCondition,
-		&readyCondition, &peeringCondition, &reconcilingCondition,
-	)
-	if err != nil {
+// flushNFDeployStatus : This method computes and updates aggregated status of
+// NFDeploy resource if the status of any NF changed since the last update.
+// The status stays to be updated when the update fails.
+func (deployment *Deployment) flushNFDeployStatus() error {
+	deployment.deploymentMu.Lock()
+	if !deployment.statusDirty {
+		deployment.deploymentMu.Unlock()
+		return nil
+	}
+	deployment.statusDirty = false
+	runtimeStatus := deployment.computeNFDeployStatus()
+	deployment.deploymentMu.Unlock()
+
+	statusFlushes.WithLabelValues(
+		deployment.namespacedName.Namespace, deployment.namespacedName.Name,
+	).Inc()
+	if err := deployment.updateNFDeployStatus(runtimeStatus); err != nil {
 		deployment.logger.Error(
-			err, "Failed to update NFDeployStatus for ", "NF", nfId,
+			err, "Failed to update NFDeployStatus for ", "NFDeploy",
+			deployment.namespacedName.Name,
 		)
+		deployment.deploymentMu.Lock()
+		deployment.statusDirty = true
+		deployment.deploymentMu.Unlock()
+		return err
 	}
+	return nil
 }
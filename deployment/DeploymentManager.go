@@ -17,6 +17,7 @@ limitations under the License.
 package deployment
 
 import (
+	nfdeploytypes "github.com/nephio-project/common-lib/nfdeploy"
 	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -46,4 +47,23 @@ type DeploymentManager interface {
 	ReportNFDeployDeleteEvent(
 		nfdeploy v1alpha1.NfDeploy,
 	)
+
+	// GetNFStates := Returns the state of the NFs of the NFDeploy by site id,
+	// as computed from the edge events. The NFs which have not reported any
+	// edge event yet are absent. Returns nil if the deployment of the
+	// NFDeploy is not present.
+	GetNFStates(
+		nfdeploy v1alpha1.NfDeploy,
+	) map[string]nfdeploytypes.NFConditionType
+
+	// GetTerminatingNFs := Returns the ids of the sites of the NFDeploy whose
+	// NFs were reported by their workload cluster and whose deletion was not
+	// reported since. The second value is false if the deployment of the
+	// NFDeploy is not present, e.g. after a restart of the controller, in
+	// which case the removal of the NFs cannot be confirmed. Once the
+	// deployment is created again for the deleted NFDeploy, the NFs are
+	// returned until their workload cluster sends an edge event.
+	GetTerminatingNFs(
+		nfdeploy v1alpha1.NfDeploy,
+	) ([]string, bool)
 }
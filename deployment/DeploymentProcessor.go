@@ -17,6 +17,7 @@ limitations under the License.
 package deployment
 
 import (
+	types "github.com/nephio-project/common-lib/nfdeploy"
 	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 )
 
@@ -28,4 +29,12 @@ type DeploymentProcessor interface {
 	//ReportNFDeployEvent : This method is responsible for changing deployment graph
 	//   and specs of individual NFs based on changes in NFDeploy
 	ReportNFDeployEvent(nfDeploy v1alpha1.NfDeploy)
+
+	//GetNFStates : This method returns the state of the NFs which reported
+	//   at least one edge event, by site id
+	GetNFStates() map[string]types.NFConditionType
+
+	//GetTerminatingNFs : This method returns the ids of the sites whose NFs
+	//   are still reported by their workload cluster
+	GetTerminatingNFs() []string
 }
@@ -19,16 +19,20 @@ package deployment
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/go-logr/logr"
+	nfdeploytypes "github.com/nephio-project/common-lib/nfdeploy"
 	edgewatcher "github.com/nephio-project/edge-watcher"
 	crdreader "github.com/nephio-project/nf-deploy-controller/crd-reader"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 
 	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/status"
 )
 
 // DeploymentInfo : It contains the information of a single deployment
@@ -49,6 +53,32 @@ type DeploymentSet struct {
 	deployments     map[string]*DeploymentInfo
 }
 
+// DefaultStatusFlushInterval is the minimum interval between two status
+// updates of a NFDeploy driven by edge events when not configured
+const DefaultStatusFlushInterval = time.Second
+
+// Options : configures the processing of edge events by the deployments
+type Options struct {
+	// EventQueueSize is the number of NFs whose edge events can be queued
+	// per deployment. Events of other NFs are dropped while the queue is
+	// full. DefaultEventQueueSize is used when not set.
+	EventQueueSize int
+	// StatusFlushInterval is the minimum interval between two status updates
+	// of a NFDeploy driven by edge events. The status is updated as soon as
+	// the queued events are processed when not set.
+	StatusFlushInterval time.Duration
+	// StalenessTimeouts is the duration per NFType after which the status of
+	// a NF is marked StatusStale if no edge event of the NF is received.
+	// Staleness is not detected for the NFTypes not set.
+	StalenessTimeouts map[NFType]time.Duration
+	// Clock is used for status flushes and staleness detection.
+	// clock.RealClock is used when not set.
+	Clock clock.WithTicker
+	// EventRecorder records the Kubernetes Events of the NFDeploys. No
+	// Events are recorded when not set.
+	EventRecorder record.EventRecorder
+}
+
 // deploymentManager : deploymentManager implements  Deployment Manager interface
 type deploymentManager struct {
 	deploymentSet      DeploymentSet
@@ -57,8 +87,8 @@ type deploymentManager struct {
 	cancellationChan   chan *edgewatcher.SubscriptionReq
 	upfIntentProcessor crdreader.UPFIntentProcessor
 	smfIntentProcessor crdreader.SMFIntentProcessor
-	statusReader       client.Reader
-	statusWriter       client.StatusWriter
+	statusAggregator   status.Aggregator
+	options            Options
 	log                logr.Logger
 }
 
@@ -67,8 +97,8 @@ func NewDeploymentManager(
 	crdReader crdreader.CRDReader,
 	subscriberChan chan *edgewatcher.SubscriptionReq,
 	cancellationChan chan *edgewatcher.SubscriptionReq,
-	statusReader client.Reader,
-	statusWriter client.StatusWriter,
+	statusAggregator status.Aggregator,
+	options Options,
 	log logr.Logger,
 ) *deploymentManager {
 	deploymentManager := deploymentManager{}
@@ -78,8 +108,8 @@ func NewDeploymentManager(
 	deploymentManager.deploymentSet = DeploymentSet{deployments: map[string]*DeploymentInfo{}}
 	deploymentManager.upfIntentProcessor = &crdreader.UPFIntent{}
 	deploymentManager.smfIntentProcessor = &crdreader.SMFIntent{}
-	deploymentManager.statusReader = statusReader
-	deploymentManager.statusWriter = statusWriter
+	deploymentManager.statusAggregator = statusAggregator
+	deploymentManager.options = options
 	// TODO: segregate logs of different verbosity in deployment. Currently
 	// all logs are with debug verbosity
 	deploymentManager.log = log.V(1)
@@ -106,9 +136,12 @@ func (deploymentManager *deploymentManager) ReportNFDeployEvent(
 		deployment := Deployment{}
 		deployment.Init(
 			deploymentManager.crdReader, deploymentManager.upfIntentProcessor,
-			deploymentManager.smfIntentProcessor, deploymentManager.statusReader,
-			deploymentManager.statusWriter, namespacedName, deploymentManager.log,
+			deploymentManager.smfIntentProcessor, deploymentManager.statusAggregator,
+			namespacedName, deploymentManager.options, deploymentManager.log,
 		)
+		// the NFs of an NfDeploy being deleted are confirmed removed by the
+		// edge events received through the new subscription only
+		deployment.awaitSync = !nfdeploy.DeletionTimestamp.IsZero()
 		deploymentInfo := DeploymentInfo{
 			deploymentName: deploymentName, deployment: &deployment, edgewatcherSubscriberName: edgewatcherSubscriberName,
 		}
@@ -150,9 +183,11 @@ func (deploymentManager *deploymentManager) ReportNFDeployDeleteEvent(
 		deploymentManager.deploymentSet.deploymentSetMu.Unlock()
 	} else {
 		edgewatcherSubscriberName := deploymentManager.deploymentSet.deployments[deploymentName].edgewatcherSubscriberName
-		deploymentManager.deploymentSet.deployments[deploymentName].deployment.cancelCtx()
+		deployment := deploymentManager.deploymentSet.deployments[deploymentName].deployment
+		deployment.cancelCtx()
 		delete(deploymentManager.deploymentSet.deployments, deploymentName)
 		deploymentManager.deploymentSet.deploymentSetMu.Unlock()
+		deleteMetrics(deployment.namespacedName)
 		errorChan := make(chan error, 1)
 		subscriptionReq := &edgewatcher.SubscriptionReq{
 			Ctx:            context.Background(),
@@ -163,3 +198,29 @@ func (deploymentManager *deploymentManager) ReportNFDeployDeleteEvent(
 		deploymentManager.cancellationChan <- subscriptionReq
 	}
 }
+
+// GetNFStates := See DeploymentManager interface for method use
+func (deploymentManager *deploymentManager) GetNFStates(
+	nfdeploy v1alpha1.NfDeploy,
+) map[string]nfdeploytypes.NFConditionType {
+	deploymentManager.deploymentSet.deploymentSetMu.Lock()
+	deploymentInfo, ok := deploymentManager.deploymentSet.deployments[nfdeploy.Name]
+	deploymentManager.deploymentSet.deploymentSetMu.Unlock()
+	if !ok {
+		return nil
+	}
+	return deploymentInfo.deployment.GetNFStates()
+}
+
+// GetTerminatingNFs := See DeploymentManager interface for method use
+func (deploymentManager *deploymentManager) GetTerminatingNFs(
+	nfdeploy v1alpha1.NfDeploy,
+) ([]string, bool) {
+	deploymentManager.deploymentSet.deploymentSetMu.Lock()
+	deploymentInfo, ok := deploymentManager.deploymentSet.deployments[nfdeploy.Name]
+	deploymentManager.deploymentSet.deploymentSetMu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return deploymentInfo.deployment.GetTerminatingNFs(), true
+}
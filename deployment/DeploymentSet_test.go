@@ -40,8 +40,7 @@ func createFakeDeploymentManager(
 	subscriberChan := make(chan *edgewatcher.SubscriptionReq)
 	cancellationChan := make(chan *edgewatcher.SubscriptionReq, 10)
 	var deploymentManager = *NewDeploymentManager(
-		crdReader, subscriberChan, cancellationChan, nil,
-		nil, logr.Discard(),
+		crdReader, subscriberChan, cancellationChan, nil, Options{}, logr.Discard(),
 	)
 	deploymentManager.smfIntentProcessor = smfIntentProcessor
 	deploymentManager.upfIntentProcessor = upfIntentProcessor
@@ -89,7 +88,7 @@ func generateRandomNfDeploy(
 		TypeMeta:   nfDeployTemplate.TypeMeta,
 		ObjectMeta: nfDeployTemplate.ObjectMeta,
 		Spec: v1alpha1.NfDeploySpec{
-			Capacity: "test", Sites: []v1alpha1.Site{},
+			Sites: []v1alpha1.Site{},
 		},
 	}
 	nfDeploy.Name = nfDeploy.Name + strconv.Itoa(rand.Intn(100))
@@ -358,12 +357,14 @@ var _ = Describe("ReportNFDeployDeleteEvent", func() {
 				nfDeploy, types.NamespacedName{Name: nfDeploy.Name},
 			)
 			Expect(len(deploymentManager.deploymentSet.deployments)).To(Equal(1))
+			statusFlushes.WithLabelValues("", nfDeploy.Name).Inc()
 			deploymentManager.ReportNFDeployDeleteEvent(
 				nfDeploy,
 			)
 			cancelReq := <-deploymentManager.cancellationChan
 			Expect(cancelReq).NotTo(BeNil())
 			Expect(len(deploymentManager.deploymentSet.deployments)).To(Equal(0))
+			Expect(statusFlushes.DeleteLabelValues("", nfDeploy.Name)).To(BeFalse())
 
 		})
 	})
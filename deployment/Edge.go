@@ -16,9 +16,31 @@ limitations under the License.
 
 package deployment
 
+import (
+	"sort"
+	"strings"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type Edge struct {
 	FirstNode  string
 	SecondNode string
+	// ReferencePoint is the interface over which the nodes are connected
+	ReferencePoint v1alpha1.ReferencePoint
+	// peering state of the edge reported by the NF of FirstNode
+	firstNodePeering peeringReport
+	// peering state of the edge reported by the NF of SecondNode
+	secondNodePeering peeringReport
+}
+
+// peeringReport : peering state of an edge reported by one of its nodes. The
+// zero value is the state of a node which did not report on the edge.
+type peeringReport struct {
+	status  corev1.ConditionStatus
+	message string
 }
 
 func (e *Edge) IsEqual(firstNode string, secondNode string) bool {
@@ -30,3 +52,70 @@ func (e *Edge) IsEqual(firstNode string, secondNode string) bool {
 		return false
 	}
 }
+
+// setPeering : records the peering state reported by nodeId on the edge.
+// Returns true if the state changed.
+func (e *Edge) setPeering(nodeId string, report peeringReport) bool {
+	switch nodeId {
+	case e.FirstNode:
+		if e.firstNodePeering == report {
+			return false
+		}
+		e.firstNodePeering = report
+	case e.SecondNode:
+		if e.secondNodePeering == report {
+			return false
+		}
+		e.secondNodePeering = report
+	default:
+		return false
+	}
+	return true
+}
+
+// isReported returns true if any node reported the peering state of the edge
+func (e *Edge) isReported() bool {
+	return e.firstNodePeering.status != "" || e.secondNodePeering.status != ""
+}
+
+// peeringStatus : returns False if either node reports the edge broken, True
+// if a node reports it peered and Unknown otherwise, along with the messages
+// of the reports
+func (e *Edge) peeringStatus() (metav1.ConditionStatus, string) {
+	status := metav1.ConditionUnknown
+	var messages []string
+	for _, report := range []struct {
+		nodeId string
+		peeringReport
+	}{
+		{e.FirstNode, e.firstNodePeering}, {e.SecondNode, e.secondNodePeering},
+	} {
+		switch report.status {
+		case corev1.ConditionFalse:
+			status = metav1.ConditionFalse
+		case corev1.ConditionTrue:
+			if status == metav1.ConditionUnknown {
+				status = metav1.ConditionTrue
+			}
+		}
+		if report.message != "" {
+			messages = append(messages, report.nodeId+": "+report.message)
+		}
+	}
+	return status, strings.Join(messages, "; ")
+}
+
+// link : returns the peering status of the edge as reported in NfDeploy
+// status, with its nodes in lexical order
+func (e *Edge) link() v1alpha1.NfDeployLink {
+	status, message := e.peeringStatus()
+	sites := []string{e.FirstNode, e.SecondNode}
+	sort.Strings(sites)
+	return v1alpha1.NfDeployLink{
+		FirstSite:      sites[0],
+		SecondSite:     sites[1],
+		ReferencePoint: e.ReferencePoint,
+		Peering:        status,
+		Message:        message,
+	}
+}
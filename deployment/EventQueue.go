@@ -0,0 +1,128 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"sync"
+
+	"github.com/nephio-project/edge-watcher/preprocessor"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// DefaultEventQueueSize is the number of NFs whose edge events can be queued
+// per deployment when not configured
+const DefaultEventQueueSize = 1024
+
+// pushResult is the outcome of pushing an edge event to eventQueue
+type pushResult int
+
+const (
+	// eventQueued : the event is queued
+	eventQueued pushResult = iota
+	// eventCoalesced : the event replaced, or was superseded by, the queued
+	// event of the same NF
+	eventCoalesced
+	// eventDropped : the event is dropped as the queue is full
+	eventDropped
+)
+
+// eventKey identifies the NF an edge event is for
+type eventKey struct {
+	preprocessor.RequestKey
+	nfId string
+}
+
+// eventQueue : A thread-safe bounded FIFO queue of edge events which keeps only
+// the latest event of every NF. It decouples the intake of edge events from
+// their processing.
+type eventQueue struct {
+	mu     sync.Mutex
+	size   int
+	keys   []eventKey
+	events map[eventKey]preprocessor.Event
+	closed bool
+	// ready is signalled when events are queued or the queue is closed
+	ready chan struct{}
+}
+
+func newEventQueue(size int) *eventQueue {
+	if size <= 0 {
+		size = DefaultEventQueueSize
+	}
+	return &eventQueue{
+		size:   size,
+		events: make(map[eventKey]preprocessor.Event),
+		ready:  make(chan struct{}, 1),
+	}
+}
+
+// push queues the event without blocking. An event of an older version of
+// the NF object than the queued event of the same NF is discarded.
+func (q *eventQueue) push(event preprocessor.Event) pushResult {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	key := eventKey{RequestKey: event.Key}
+	if obj, ok := event.Object.(*unstructured.Unstructured); ok {
+		key.nfId = obj.GetLabels()[util.NFSiteIDLabel]
+	}
+	if queued, ok := q.events[key]; ok {
+		if !eventObjectVersion(&event).isOlderThan(eventObjectVersion(&queued)) {
+			q.events[key] = event
+		}
+		return eventCoalesced
+	}
+	if len(q.keys) >= q.size {
+		return eventDropped
+	}
+	q.keys = append(q.keys, key)
+	q.events[key] = event
+	q.signal()
+	return eventQueued
+}
+
+// close marks the queue closed; the events queued before can still be
+// drained
+func (q *eventQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.signal()
+}
+
+// drain removes and returns all the queued events in the order they were
+// first queued, and whether the queue is closed
+func (q *eventQueue) drain() ([]preprocessor.Event, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := make([]preprocessor.Event, 0, len(q.keys))
+	for _, key := range q.keys {
+		events = append(events, q.events[key])
+	}
+	q.keys = nil
+	q.events = make(map[eventKey]preprocessor.Event)
+	return events, q.closed
+}
+
+// signal notifies the consumer without blocking. Must be called with mu held.
+func (q *eventQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
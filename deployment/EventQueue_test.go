@@ -0,0 +1,71 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	types "github.com/nephio-project/common-lib/nfdeploy"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe(
+	"eventQueue", func() {
+		It(
+			"Should keep the latest event of an NF", func() {
+				queue := newEventQueue(10)
+				Expect(queue.push(generateUPFEvent("upf1", "10", types.Ready))).
+					To(Equal(eventQueued))
+				Expect(queue.push(generateUPFEvent("upf2", "10", types.Ready))).
+					To(Equal(eventQueued))
+				Expect(queue.push(generateUPFEvent("upf1", "9", types.Stalled))).
+					To(Equal(eventCoalesced))
+				Expect(queue.push(generateUPFEvent("upf1", "11", types.Stalled))).
+					To(Equal(eventCoalesced))
+
+				events, closed := queue.drain()
+				Expect(closed).To(BeFalse())
+				Expect(events).To(HaveLen(2))
+				Expect(eventObjectVersion(&events[0]).resourceVersion).To(Equal("11"))
+				Expect(eventObjectVersion(&events[1]).resourceVersion).To(Equal("10"))
+				events, _ = queue.drain()
+				Expect(events).To(BeEmpty())
+			},
+		)
+
+		It(
+			"Should drop the events of new NFs when full", func() {
+				queue := newEventQueue(1)
+				Expect(queue.push(generateUPFEvent("upf1", "10", types.Ready))).
+					To(Equal(eventQueued))
+				Expect(queue.push(generateUPFEvent("upf2", "10", types.Ready))).
+					To(Equal(eventDropped))
+				Expect(queue.push(generateUPFEvent("upf1", "11", types.Stalled))).
+					To(Equal(eventCoalesced))
+			},
+		)
+
+		It(
+			"Should signal when closed", func() {
+				queue := newEventQueue(1)
+				queue.close()
+				Eventually(queue.ready).Should(Receive())
+				_, closed := queue.drain()
+				Expect(closed).To(BeTrue())
+			},
+		)
+	},
+)
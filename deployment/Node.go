@@ -22,12 +22,20 @@ import (
 	types "github.com/nephio-project/common-lib/nfdeploy"
 )
 
+// StatusStale : state of a NF from which no edge event is received within
+// the staleness timeout of its NFType. The last reported conditions of the
+// NF are no longer trusted.
+const StatusStale types.NFConditionType = "StatusStale"
+
 type void struct{}
 
 type Node struct {
 	Id          string
 	NFType      NFType
 	Connections map[string]void
+	// time at which the NF was added to the deployment, from which its
+	// staleness is measured until it sends an edge event
+	addedTime time.Time
 }
 
 type UPFNode struct {
@@ -44,10 +52,16 @@ type SMFNode struct {
 
 type NFStatus struct {
 	// all the NFConditions that were true in the last observed edge event
-	activeConditions   map[types.NFConditionType]string
-	state              types.NFConditionType
-	stateMessage       string
-	lastEventTimestamp time.Time
+	activeConditions map[types.NFConditionType]string
+	state            types.NFConditionType
+	stateMessage     string
+	// version of the NF object in the last processed edge event
+	lastEventVersion objectVersion
+	// time at which the last edge event of the NF was processed
+	lastEventTime time.Time
+	// set when the last edge event of the NF reported its deletion from the
+	// workload cluster
+	removed bool
 }
 
 type AMFNode struct {
@@ -17,12 +17,16 @@ limitations under the License.
 package deployment
 
 import (
+	"context"
+	"errors"
+
 	"github.com/golang/mock/gomock"
 	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 	crdreader "github.com/nephio-project/nf-deploy-controller/crd-reader"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	types2 "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -70,6 +74,9 @@ func createSampleDeployment() *Deployment {
 			Edge{FirstNode: sampleUPFName, SecondNode: sampleSMFName},
 			Edge{FirstNode: sampleSMFName, SecondNode: sampleAMFName},
 		},
+		siteClusters:   map[string]string{},
+		syncedClusters: map[string]bool{},
+		clock:          clock.RealClock{},
 		logger: zap.New(
 			func(options *zap.Options) {
 				options.Development = true
@@ -91,7 +98,7 @@ var _ = Describe(
 						var crdReader crdreader.CRDReader
 						deployment.Init(
 							crdReader, &crdreader.UPFIntent{}, &crdreader.SMFIntent{}, nil,
-							nil, types2.NamespacedName{}, zap.New(
+							types2.NamespacedName{}, Options{}, zap.New(
 								func(options *zap.Options) {
 									options.Development = true
 									options.DestWriter = GinkgoWriter
@@ -356,7 +363,7 @@ var _ = Describe(
 				It(
 					"Should not add the edge", func() {
 						initialEdgeSize := len(deployment.edges)
-						deployment.createEdge(sampleAMFName, sampleSMFName)
+						deployment.createEdge(sampleAMFName, sampleSMFName, "")
 						Expect(len(deployment.edges)).To(Equal(initialEdgeSize))
 					},
 				)
@@ -367,7 +374,7 @@ var _ = Describe(
 				It(
 					"Should add the edge", func() {
 						initialGraphSize := len(deployment.edges)
-						deployment.createEdge(sampleAMFName, "test-smf")
+						deployment.createEdge(sampleAMFName, "test-smf", "")
 						Expect(len(deployment.edges)).To(Equal(initialGraphSize + 1))
 					},
 				)
@@ -393,6 +400,41 @@ var _ = Describe(
 	},
 )
 
+var _ = Describe(
+	"removeEdges", func() {
+		Context(
+			"If a connectivity is not present anymore", func() {
+				It(
+					"should remove its edge and the connections of its sites", func() {
+						deployment := createSampleDeployment()
+						nfdeploy := v1alpha1.NfDeploy{
+							Spec: v1alpha1.NfDeploySpec{
+								Sites: []v1alpha1.Site{
+									{Id: sampleUPFName, NFType: string(UPF)},
+									{
+										Id: sampleSMFName, NFType: string(SMF),
+										Connectivities: []v1alpha1.Connectivity{
+											{NeighborName: sampleAMFName},
+										},
+									},
+								},
+							},
+						}
+						deployment.removeEdges(nfdeploy)
+						Expect(deployment.edges).To(Equal([]Edge{
+							{FirstNode: sampleSMFName, SecondNode: sampleAMFName},
+						}))
+						Expect(deployment.upfNodes[sampleUPFName].Connections).To(BeEmpty())
+						Expect(deployment.smfNodes[sampleSMFName].Connections).To(
+							Equal(map[string]void{sampleAMFName: present}),
+						)
+					},
+				)
+			},
+		)
+	},
+)
+
 var _ = Describe(
 	"removeNFs", func() {
 		Context(
@@ -428,7 +470,7 @@ var _ = Describe(
 		)
 		deployment.Init(
 			crdReader, &crdreader.UPFIntent{}, &crdreader.SMFIntent{}, nil,
-			nil, types2.NamespacedName{}, logger,
+			types2.NamespacedName{}, Options{}, logger,
 		)
 		nfDeploy := &v1alpha1.NfDeploy{}
 		nfDeploy2 := &v1alpha1.NfDeploy{}
@@ -490,3 +532,69 @@ var _ = Describe(
 
 	},
 )
+
+var _ = Describe(
+	"readProfiles", func() {
+		var deployment Deployment
+		var ctrl *gomock.Controller
+		var profiles *crdreader.MockRevisionReader
+		var nfDeploy v1alpha1.NfDeploy
+		BeforeEach(
+			func() {
+				deployment = *createSampleDeployment()
+				deployment.ctx = context.TODO()
+				ctrl = gomock.NewController(GinkgoT())
+				profiles = crdreader.NewMockRevisionReader(ctrl)
+				deployment.profiles = profiles
+				deployment.crdReader = profiles
+				nfDeploy = v1alpha1.NfDeploy{
+					Status: v1alpha1.NfDeployStatus{
+						Profiles: &v1alpha1.ProfilesStatus{Revision: "rev1"},
+					},
+				}
+			},
+		)
+		AfterEach(
+			func() {
+				ctrl.Finish()
+			},
+		)
+
+		Context(
+			"When NfDeploy was hydrated with a revision of the NF profiles", func() {
+				It(
+					"Should read the intents from that revision", func() {
+						revision := &crdreader.CRDSet{}
+						profiles.EXPECT().ForRevision(gomock.Any(), "rev1").Return(revision, nil)
+						Expect(deployment.readProfiles(nfDeploy)).To(BeIdenticalTo(revision))
+					},
+				)
+			},
+		)
+		Context(
+			"When NfDeploy was not hydrated yet", func() {
+				It(
+					"Should read the intents from the latest revision", func() {
+						revision := &crdreader.CRDSet{}
+						profiles.EXPECT().ForRevision(gomock.Any(), "").Return(revision, nil)
+						nfDeploy.Status.Profiles = nil
+						Expect(deployment.readProfiles(nfDeploy)).To(BeIdenticalTo(revision))
+					},
+				)
+			},
+		)
+		Context(
+			"When the revision cannot be read", func() {
+				It(
+					"Should keep the previous reader", func() {
+						profiles.EXPECT().ForRevision(gomock.Any(), "rev1").
+							Return(nil, errors.New("porch unavailable")).Times(2)
+						Expect(deployment.readProfiles(nfDeploy)).To(BeNil())
+						deployment.ReportNFDeployEvent(nfDeploy)
+						Expect(deployment.crdReader).To(BeIdenticalTo(profiles))
+					},
+				)
+			},
+		)
+	},
+)
@@ -19,6 +19,8 @@ package deployment
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func createSampleEdge() Edge {
@@ -58,3 +60,63 @@ var _ = Describe(
 		)
 	},
 )
+
+var _ = Describe(
+	"peeringStatus", func() {
+		var edge Edge
+		BeforeEach(
+			func() {
+				edge = createSampleEdge()
+			},
+		)
+
+		It(
+			"Should be unknown when no node reports on the edge", func() {
+				status, message := edge.peeringStatus()
+				Expect(status).To(Equal(metav1.ConditionUnknown))
+				Expect(message).To(BeEmpty())
+				Expect(edge.isReported()).To(BeFalse())
+			},
+		)
+
+		It(
+			"Should be peered when a node reports the edge peered", func() {
+				Expect(
+					edge.setPeering(sampleUPFName, peeringReport{status: corev1.ConditionTrue}),
+				).To(BeTrue())
+				Expect(
+					edge.setPeering(sampleUPFName, peeringReport{status: corev1.ConditionTrue}),
+				).To(BeFalse())
+				status, _ := edge.peeringStatus()
+				Expect(status).To(Equal(metav1.ConditionTrue))
+			},
+		)
+
+		It(
+			"Should be broken when either node reports the edge broken", func() {
+				edge.setPeering(sampleUPFName, peeringReport{status: corev1.ConditionTrue})
+				edge.setPeering(
+					sampleSMFName,
+					peeringReport{status: corev1.ConditionFalse, message: "N4 association lost"},
+				)
+				status, message := edge.peeringStatus()
+				Expect(status).To(Equal(metav1.ConditionFalse))
+				Expect(message).To(Equal(sampleSMFName + ": N4 association lost"))
+
+				link := edge.link()
+				Expect(link.FirstSite).To(Equal(sampleSMFName))
+				Expect(link.SecondSite).To(Equal(sampleUPFName))
+				Expect(link.Peering).To(Equal(metav1.ConditionFalse))
+			},
+		)
+
+		It(
+			"Should ignore reports of nodes not on the edge", func() {
+				Expect(
+					edge.setPeering(sampleAMFName, peeringReport{status: corev1.ConditionFalse}),
+				).To(BeFalse())
+				Expect(edge.isReported()).To(BeFalse())
+			},
+		)
+	},
+)
@@ -0,0 +1,57 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	edgeEventsCoalesced = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "nfdeploy_edge_events_coalesced_total",
+			Help: "Number of edge events merged with a queued event of the same NF",
+		}, []string{"namespace", "nfdeploy"},
+	)
+	edgeEventsDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "nfdeploy_edge_events_dropped_total",
+			Help: "Number of edge events dropped as the event queue of the NfDeploy was full",
+		}, []string{"namespace", "nfdeploy"},
+	)
+	statusFlushes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "nfdeploy_status_flushes_total",
+			Help: "Number of NfDeploy status updates driven by edge events",
+		}, []string{"namespace", "nfdeploy"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(edgeEventsCoalesced, edgeEventsDropped, statusFlushes)
+}
+
+// deleteMetrics deletes the series of the NfDeploy, once it is deleted
+func deleteMetrics(namespacedName types.NamespacedName) {
+	for _, counter := range []*prometheus.CounterVec{
+		edgeEventsCoalesced, edgeEventsDropped, statusFlushes,
+	} {
+		counter.DeleteLabelValues(namespacedName.Namespace, namespacedName.Name)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"sort"
+	"strings"
+
+	types "github.com/nephio-project/common-lib/nfdeploy"
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PeerConditionTypePrefix : prefix of the NF conditions reporting the peering
+// state of an NF with a single peer. The condition type is the prefix followed
+// by the site id of the peer, e.g. a UPFDeploy reports its N4 association with
+// the SMF of site smf-1 in the condition Peering/smf-1. True means peered and
+// False broken.
+const PeerConditionTypePrefix = "Peering/"
+
+// splitPeerConditions : splits the per-peer conditions from the conditions
+// reported by an NF. Returns the other conditions and the peering reports
+// keyed by the site id of the peer.
+func splitPeerConditions(nfConditions []types.NFCondition) (
+	[]types.NFCondition, map[string]peeringReport,
+) {
+	var conditions []types.NFCondition
+	peerReports := make(map[string]peeringReport)
+	for _, condition := range nfConditions {
+		peerId := strings.TrimPrefix(string(condition.Type), PeerConditionTypePrefix)
+		if peerId == string(condition.Type) {
+			conditions = append(conditions, condition)
+			continue
+		}
+		message := condition.Message
+		if message == "" {
+			message = condition.Reason
+		}
+		peerReports[peerId] = peeringReport{
+			status: condition.Status, message: message,
+		}
+	}
+	return conditions, peerReports
+}
+
+// updateEdgePeering : records the peering reports of nfId on its edges. An
+// edge whose peer is absent from peerReports is not reported on by nfId
+// anymore. Returns true if the peering state of any edge changed.
+func (deployment *Deployment) updateEdgePeering(
+	nfId string, peerReports map[string]peeringReport,
+) bool {
+	changed := false
+	for index := range deployment.edges {
+		edge := &deployment.edges[index]
+		var peerId string
+		switch nfId {
+		case edge.FirstNode:
+			peerId = edge.SecondNode
+		case edge.SecondNode:
+			peerId = edge.FirstNode
+		default:
+			continue
+		}
+		if edge.setPeering(nfId, peerReports[peerId]) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// getLinks : returns the peering status of all the edges of the deployment,
+// sorted by their sites
+func (deployment *Deployment) getLinks() []v1alpha1.NfDeployLink {
+	var links []v1alpha1.NfDeployLink
+	for index := range deployment.edges {
+		links = append(links, deployment.edges[index].link())
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].FirstSite != links[j].FirstSite {
+			return links[i].FirstSite < links[j].FirstSite
+		}
+		return links[i].SecondSite < links[j].SecondSite
+	})
+	return links
+}
+
+// isLinkPeeringReported : returns true if the NF of any site reports the
+// peering state of its links
+func (deployment *Deployment) isLinkPeeringReported() bool {
+	for index := range deployment.edges {
+		if deployment.edges[index].isReported() {
+			return true
+		}
+	}
+	return false
+}
+
+// computeLinkPeeringCondition : computes DeploymentPeering NFDeployCondition
+// from the peering status of the links, naming the broken links or else the
+// links which are not peered yet
+func computeLinkPeeringCondition(links []v1alpha1.NfDeployLink) metav1.Condition {
+	peeringCondition := metav1.Condition{
+		Type: string(v1alpha1.DeploymentPeering),
+	}
+	var brokenLinks, peeringLinks []string
+	for _, link := range links {
+		name := linkName(link)
+		switch link.Peering {
+		case metav1.ConditionFalse:
+			if link.Message != "" {
+				name = name + " (" + link.Message + ")"
+			}
+			brokenLinks = append(brokenLinks, name)
+		case metav1.ConditionUnknown:
+			peeringLinks = append(peeringLinks, name)
+		}
+	}
+	switch {
+	case len(brokenLinks) != 0:
+		peeringCondition.Status = metav1.ConditionTrue
+		peeringCondition.Reason = "LinksBroken"
+		peeringCondition.Message = "The broken links are: " +
+			strings.Join(brokenLinks, ", ") + "."
+	case len(peeringLinks) != 0:
+		peeringCondition.Status = metav1.ConditionTrue
+		peeringCondition.Reason = "SomeLinksPeering"
+		peeringCondition.Message = "The links which are not peered yet are: " +
+			strings.Join(peeringLinks, ", ") + "."
+	default:
+		peeringCondition.Status = metav1.ConditionFalse
+		peeringCondition.Reason = "AllLinksPeered"
+		peeringCondition.Message = "All links are peered."
+	}
+	return peeringCondition
+}
+
+// linkName : returns the name of a link as shown in NfDeploy conditions, e.g.
+// smf-1 <-> upf-1 [N4]
+func linkName(link v1alpha1.NfDeployLink) string {
+	name := link.FirstSite + " <-> " + link.SecondSite
+	if link.ReferencePoint != "" {
+		name = name + " [" + string(link.ReferencePoint) + "]"
+	}
+	return name
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	types "github.com/nephio-project/common-lib/nfdeploy"
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// staleNF : a NF whose status is marked stale
+type staleNF struct {
+	id      string
+	timeout time.Duration
+}
+
+// ParseStalenessTimeouts : parses the staleness timeouts per NFType from a
+// comma separated list of <nftype>=<duration>, e.g. "upf=5m,smf=2m30s"
+func ParseStalenessTimeouts(value string) (map[NFType]time.Duration, error) {
+	timeouts := make(map[NFType]time.Duration)
+	if strings.TrimSpace(value) == "" {
+		return timeouts, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		nfType, duration, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			return nil, fmt.Errorf(
+				"invalid staleness timeout %q, expected <nftype>=<duration>", entry,
+			)
+		}
+		switch NFType(nfType) {
+		case UPF, SMF, AUSF, UDM:
+		default:
+			return nil, fmt.Errorf("unsupported NF type %q in staleness timeouts", nfType)
+		}
+		timeout, err := time.ParseDuration(duration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid staleness timeout of %s: %w", nfType, err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("staleness timeout of %s must be positive", nfType)
+		}
+		timeouts[NFType(nfType)] = timeout
+	}
+	return timeouts, nil
+}
+
+// stalenessCheckInterval : returns the interval at which the staleness of
+// the NFs is checked, which is half of the shortest staleness timeout. Returns
+// 0 if no staleness timeout is set.
+func (deployment *Deployment) stalenessCheckInterval() time.Duration {
+	var interval time.Duration
+	for _, timeout := range deployment.stalenessTimeouts {
+		if timeout > 0 && (interval == 0 || timeout/2 < interval) {
+			interval = timeout / 2
+		}
+	}
+	return interval
+}
+
+// isStale : returns true if no edge event of a NF with the given status is
+// received within timeout, counted from its last edge event or, until it
+// sends one, from addedTime, the time at which it was added to the
+// deployment. NFs removed from their workload cluster are never stale.
+func (nfStatus NFStatus) isStale(now time.Time, addedTime time.Time, timeout time.Duration) bool {
+	since := nfStatus.lastEventTime
+	if since.IsZero() {
+		since = addedTime
+	}
+	return timeout > 0 && nfStatus.state != StatusStale && !nfStatus.removed &&
+		!since.IsZero() && now.Sub(since) >= timeout
+}
+
+// withStatusStale : returns the NF status marked stale. The last reported
+// conditions are dropped, so the NF is counted neither available nor ready
+// until its next edge event.
+func (nfStatus NFStatus) withStatusStale(timeout time.Duration) NFStatus {
+	return NFStatus{
+		state:            StatusStale,
+		stateMessage:     fmt.Sprintf("No edge event received for %s.", timeout),
+		activeConditions: map[types.NFConditionType]string{},
+		lastEventVersion: nfStatus.lastEventVersion,
+		lastEventTime:    nfStatus.lastEventTime,
+	}
+}
+
+// markStaleNFs : marks the status of the NFs whose staleness timeout expired
+// as StatusStale, drops their reports on the peering of their links and
+// records an Event for each of them. Returns true if the
+// status of any NF changed.
+func (deployment *Deployment) markStaleNFs() bool {
+	deployment.deploymentMu.Lock()
+	now := deployment.clock.Now()
+	var staleNFs []staleNF
+	deployment.updateNFStatuses(
+		func(node Node, nfStatus NFStatus) NFStatus {
+			timeout := deployment.stalenessTimeouts[node.NFType]
+			if !nfStatus.isStale(now, node.addedTime, timeout) {
+				return nfStatus
+			}
+			deployment.updateEdgePeering(node.Id, nil)
+			staleNFs = append(staleNFs, staleNF{id: node.Id, timeout: timeout})
+			return nfStatus.withStatusStale(timeout)
+		},
+	)
+	if len(staleNFs) != 0 {
+		deployment.statusDirty = true
+	}
+	deployment.deploymentMu.Unlock()
+
+	for _, nf := range staleNFs {
+		deployment.logger.Info(
+			"No edge event received within staleness timeout. NF status is stale",
+			"NF", nf.id, "timeout", nf.timeout.String(),
+		)
+		deployment.recordStaleNFEvent(nf)
+	}
+	return len(staleNFs) != 0
+}
+
+// recordStaleNFEvent : records a warning Event on the NfDeploy for a NF whose
+// status became stale
+func (deployment *Deployment) recordStaleNFEvent(nf staleNF) {
+	if deployment.recorder == nil {
+		return
+	}
+	nfDeploy := &v1alpha1.NfDeploy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: deployment.namespacedName.Namespace,
+			Name:      deployment.namespacedName.Name,
+			UID:       deployment.uid,
+		},
+	}
+	deployment.recorder.Eventf(
+		nfDeploy, corev1.EventTypeWarning, string(StatusStale),
+		"No edge event received from NF %s for %s", nf.id, nf.timeout,
+	)
+}
+
+// getStaleNFs: returns the sorted ids of the NFs whose status is stale
+func (deployment *Deployment) getStaleNFs() []string {
+	staleNFs := []string{}
+	deployment.forEachNF(
+		func(node Node, nfStatus NFStatus) {
+			if nfStatus.state == StatusStale {
+				staleNFs = append(staleNFs, node.Id)
+			}
+		},
+	)
+	sort.Strings(staleNFs)
+	return staleNFs
+}
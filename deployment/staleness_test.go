@@ -0,0 +1,54 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe(
+	"ParseStalenessTimeouts", func() {
+		DescribeTable(
+			"Should parse the staleness timeouts per NF type",
+			func(value string, expected map[NFType]time.Duration) {
+				timeouts, err := ParseStalenessTimeouts(value)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(timeouts).To(Equal(expected))
+			},
+			Entry("empty", "", map[NFType]time.Duration{}),
+			Entry(
+				"several NF types", "upf=5m, smf=2m30s",
+				map[NFType]time.Duration{UPF: 5 * time.Minute, SMF: 150 * time.Second},
+			),
+		)
+
+		DescribeTable(
+			"Should reject invalid staleness timeouts",
+			func(value string) {
+				_, err := ParseStalenessTimeouts(value)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("missing duration", "upf"),
+			Entry("unknown NF type", "foo=5m"),
+			Entry("invalid duration", "upf=5"),
+			Entry("non positive duration", "upf=0s"),
+		)
+	},
+)
@@ -18,14 +18,15 @@ package deployment
 
 import (
 	"context"
+	"sort"
 	"strings"
-	"time"
 
 	types "github.com/nephio-project/common-lib/nfdeploy"
 	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/util/retry"
+
+	"github.com/nephio-project/nf-deploy-controller/status"
 )
 
 // isAmbiguousConditionSet: returns true when no condition's status is set to True
@@ -103,13 +104,13 @@ func (deployment *Deployment) validateNFConditionSet(
 // from status of present NFs in deployment
 func (deployment *Deployment) computeReconcilingCondition(
 	readyNFs int, targetedNFs int,
-) v1alpha1.NFDeployCondition {
-	reconcilingCondition := v1alpha1.NFDeployCondition{}
-	reconcilingCondition.Type = v1alpha1.DeploymentReconciling
+) metav1.Condition {
+	reconcilingCondition := metav1.Condition{}
+	reconcilingCondition.Type = string(v1alpha1.DeploymentReconciling)
 
 	if targetedNFs == readyNFs {
 		reconcilingCondition.Reason = "AllNFsReconciled"
-		reconcilingCondition.Status = corev1.ConditionFalse
+		reconcilingCondition.Status = metav1.ConditionFalse
 		reconcilingCondition.Message = "All NFs are in reconciled state."
 		return reconcilingCondition
 	}
@@ -144,35 +145,45 @@ func (deployment *Deployment) computeReconcilingCondition(
 		message = strings.TrimSuffix(message, ", ")
 		message = message + "."
 		reconcilingCondition.Message = message
-		reconcilingCondition.Status = corev1.ConditionTrue
+		reconcilingCondition.Status = metav1.ConditionTrue
 	} else if reconcilingNFs != 0 {
 		reconcilingCondition.Reason = "SomeNFsReconciling"
 		message = strings.TrimSuffix(message, ", ")
 		message = message + "."
 		reconcilingCondition.Message = message
-		reconcilingCondition.Status = corev1.ConditionTrue
+		reconcilingCondition.Status = metav1.ConditionTrue
 
 	} else {
 		reconcilingCondition.Reason = "NoNFsReconciling"
 		reconcilingCondition.Message = "No NFs are in reconciling state."
-		reconcilingCondition.Status = corev1.ConditionFalse
+		reconcilingCondition.Status = metav1.ConditionFalse
 
 	}
+	if laggingNFs := deployment.getLaggingNFs(); len(laggingNFs) != 0 {
+		reconcilingCondition.Reason = "ObservedGenerationLagging"
+		reconcilingCondition.Message = reconcilingCondition.Message +
+			" The NFs which have not observed their latest generation are: " +
+			strings.Join(laggingNFs, ", ") + "."
+	}
 	return reconcilingCondition
 
 }
 
 // computePeeringCondition : computes DeploymentPeering NFDeployCondition
+// from the peering status of the links when the NFs report it, otherwise
 // from status of present NFs in deployment
 func (deployment *Deployment) computePeeringCondition(
 	readyNFs int, targetedNFs int,
-) v1alpha1.NFDeployCondition {
-	peeringCondition := v1alpha1.NFDeployCondition{}
-	peeringCondition.Type = v1alpha1.DeploymentPeering
+) metav1.Condition {
+	if deployment.isLinkPeeringReported() {
+		return computeLinkPeeringCondition(deployment.getLinks())
+	}
+	peeringCondition := metav1.Condition{}
+	peeringCondition.Type = string(v1alpha1.DeploymentPeering)
 
 	if targetedNFs == readyNFs {
 		peeringCondition.Reason = "AllNFsPeered"
-		peeringCondition.Status = corev1.ConditionFalse
+		peeringCondition.Status = metav1.ConditionFalse
 		peeringCondition.Message = "All NFs are in Peered state."
 		return peeringCondition
 	}
@@ -207,19 +218,19 @@ func (deployment *Deployment) computePeeringCondition(
 		message = strings.TrimSuffix(message, ", ")
 		message = message + "."
 		peeringCondition.Message = message
-		peeringCondition.Status = corev1.ConditionTrue
+		peeringCondition.Status = metav1.ConditionTrue
 
 	} else if peeringNFs != 0 {
 		peeringCondition.Reason = "SomeNFsPeering"
 		message = strings.TrimSuffix(message, ", ")
 		message = message + "."
 		peeringCondition.Message = message
-		peeringCondition.Status = corev1.ConditionTrue
+		peeringCondition.Status = metav1.ConditionTrue
 
 	} else {
 		peeringCondition.Reason = "NoNFsPeering"
 		peeringCondition.Message = "No NFs are in peering state."
-		peeringCondition.Status = corev1.ConditionFalse
+		peeringCondition.Status = metav1.ConditionFalse
 
 	}
 	return peeringCondition
@@ -230,13 +241,13 @@ func (deployment *Deployment) computePeeringCondition(
 // from status of present NFs in deployment
 func (deployment *Deployment) computeReadyCondition(
 	readyNFs int, targetedNFs int,
-) v1alpha1.NFDeployCondition {
-	readyCondition := v1alpha1.NFDeployCondition{}
-	readyCondition.Type = v1alpha1.DeploymentReady
+) metav1.Condition {
+	readyCondition := metav1.Condition{}
+	readyCondition.Type = string(v1alpha1.DeploymentReady)
 	if readyNFs == targetedNFs {
-		readyCondition.Status = corev1.ConditionTrue
+		readyCondition.Status = metav1.ConditionTrue
 	} else {
-		readyCondition.Status = corev1.ConditionFalse
+		readyCondition.Status = metav1.ConditionFalse
 	}
 	if readyNFs == 0 {
 		readyCondition.Reason = "NoNFsReady"
@@ -273,6 +284,16 @@ func (deployment *Deployment) computeReadyCondition(
 	message = strings.TrimSuffix(message, ", ")
 	message = message + "."
 	readyCondition.Message = message
+	if staleNFs := deployment.getStaleNFs(); len(staleNFs) != 0 {
+		// the NFs may well be ready, their status is just not known anymore
+		if readyNFs+len(staleNFs) == targetedNFs {
+			readyCondition.Status = metav1.ConditionUnknown
+		}
+		readyCondition.Reason = "NFStatusStale"
+		readyCondition.Message = readyCondition.Message +
+			" The NFs whose status is stale are: " +
+			strings.Join(staleNFs, ", ") + "."
+	}
 	return readyCondition
 }
 
@@ -280,13 +301,13 @@ func (deployment *Deployment) computeReadyCondition(
 // from status of present NFs in deployment
 func (deployment *Deployment) computeStalledCondition(
 	stalledNFs int, targetedNFs int,
-) v1alpha1.NFDeployCondition {
-	stalledCondition := v1alpha1.NFDeployCondition{}
-	stalledCondition.Type = v1alpha1.DeploymentStalled
+) metav1.Condition {
+	stalledCondition := metav1.Condition{}
+	stalledCondition.Type = string(v1alpha1.DeploymentStalled)
 	if stalledNFs != 0 {
-		stalledCondition.Status = corev1.ConditionTrue
+		stalledCondition.Status = metav1.ConditionTrue
 	} else {
-		stalledCondition.Status = corev1.ConditionFalse
+		stalledCondition.Status = metav1.ConditionFalse
 	}
 	if stalledNFs == targetedNFs {
 		stalledCondition.Reason = "AllNFsStalled"
@@ -462,30 +483,86 @@ func (deployment *Deployment) updateCurrentNFStatus(
 	}
 	if _, isPresent := deployment.upfNodes[nfId]; isPresent {
 		nf := deployment.upfNodes[nfId]
-		currentStatus.lastEventTimestamp = nf.Status.lastEventTimestamp
-		nf.Status = currentStatus
+		currentStatus.lastEventVersion = nf.Status.lastEventVersion
+		currentStatus.lastEventTime = nf.Status.lastEventTime
+		nf.Status = currentStatus.withGenerationLag(deployment.hydratedGenerations[nfId])
 		deployment.upfNodes[nfId] = nf
 	}
 	if _, isPresent := deployment.smfNodes[nfId]; isPresent {
 		nf := deployment.smfNodes[nfId]
-		currentStatus.lastEventTimestamp = nf.Status.lastEventTimestamp
-		nf.Status = currentStatus
+		currentStatus.lastEventVersion = nf.Status.lastEventVersion
+		currentStatus.lastEventTime = nf.Status.lastEventTime
+		nf.Status = currentStatus.withGenerationLag(deployment.hydratedGenerations[nfId])
 		deployment.smfNodes[nfId] = nf
 	}
 	if _, isPresent := deployment.ausfNodes[nfId]; isPresent {
 		nf := deployment.ausfNodes[nfId]
-		currentStatus.lastEventTimestamp = nf.Status.lastEventTimestamp
-		nf.Status = currentStatus
+		currentStatus.lastEventVersion = nf.Status.lastEventVersion
+		currentStatus.lastEventTime = nf.Status.lastEventTime
+		nf.Status = currentStatus.withGenerationLag(deployment.hydratedGenerations[nfId])
 		deployment.ausfNodes[nfId] = nf
 	}
 	if _, isPresent := deployment.udmNodes[nfId]; isPresent {
 		nf := deployment.udmNodes[nfId]
-		currentStatus.lastEventTimestamp = nf.Status.lastEventTimestamp
-		nf.Status = currentStatus
+		currentStatus.lastEventVersion = nf.Status.lastEventVersion
+		currentStatus.lastEventTime = nf.Status.lastEventTime
+		nf.Status = currentStatus.withGenerationLag(deployment.hydratedGenerations[nfId])
 		deployment.udmNodes[nfId] = nf
 	}
 }
 
+// withGenerationLag: returns the NF status as reconciling if the status
+// reported by the NF is of an older generation of the NF object, or if the NF
+// object is hydrated from an older generation of the NfDeploy than
+// hydratedGeneration, so that the NF is not counted ready on the status of
+// its previous manifest
+func (nfStatus NFStatus) withGenerationLag(hydratedGeneration int64) NFStatus {
+	if !nfStatus.lastEventVersion.isLagging(hydratedGeneration) {
+		return nfStatus
+	}
+	message := nfStatus.lastEventVersion.lagMessage(hydratedGeneration)
+	activeConditions := map[types.NFConditionType]string{types.Reconciling: message}
+	if availableMessage, isPresent := nfStatus.activeConditions[types.Available]; isPresent {
+		activeConditions[types.Available] = availableMessage
+	}
+	return NFStatus{
+		state:            types.Reconciling,
+		stateMessage:     message,
+		activeConditions: activeConditions,
+		lastEventVersion: nfStatus.lastEventVersion,
+		lastEventTime:    nfStatus.lastEventTime,
+	}
+}
+
+// getLaggingNFs: returns the sorted ids of the NFs whose last reported NF
+// object lags the generation hydrated for them, or whose observedGeneration
+// lags the generation of their NF object
+func (deployment *Deployment) getLaggingNFs() []string {
+	laggingNFs := []string{}
+	for _, node := range deployment.upfNodes {
+		if node.Status.lastEventVersion.isLagging(deployment.hydratedGenerations[node.Id]) {
+			laggingNFs = append(laggingNFs, node.Id)
+		}
+	}
+	for _, node := range deployment.smfNodes {
+		if node.Status.lastEventVersion.isLagging(deployment.hydratedGenerations[node.Id]) {
+			laggingNFs = append(laggingNFs, node.Id)
+		}
+	}
+	for _, node := range deployment.ausfNodes {
+		if node.Status.lastEventVersion.isLagging(deployment.hydratedGenerations[node.Id]) {
+			laggingNFs = append(laggingNFs, node.Id)
+		}
+	}
+	for _, node := range deployment.udmNodes {
+		if node.Status.lastEventVersion.isLagging(deployment.hydratedGenerations[node.Id]) {
+			laggingNFs = append(laggingNFs, node.Id)
+		}
+	}
+	sort.Strings(laggingNFs)
+	return laggingNFs
+}
+
 // calculateNFConditionSet: Returns maps which store the Status and Message of
 // all NFConditions present in NFStatus. Assumes Unknown Status of all
 // absent conditions
@@ -517,100 +594,44 @@ func (deployment *Deployment) calculateNFConditionSet(nfConditions *[]types.NFCo
 	return conditions, conditionMessage
 }
 
-// areNFDeployStatusConditionsEqual: Returns true if two conditions have same
-// Status, Reason and Message
-func (deployment *Deployment) areNFDeployStatusConditionsEqual(
-	conditionA *v1alpha1.NFDeployCondition,
-	conditionB v1alpha1.NFDeployCondition,
-) bool {
-	if conditionA.Reason == conditionB.Reason && conditionA.Status == conditionB.Status &&
-		conditionA.Message == conditionB.Message {
-		return true
-	}
-	return false
-}
+// computeNFDeployStatus: computes the NF counts and the conditions of
+// nfdeploy from the current status of the NFs, for the generation of nfdeploy
+// the deployment is tracking. Must be called with deploymentMu held.
+func (deployment *Deployment) computeNFDeployStatus() status.RuntimeStatus {
+	availableNFs, readyNFs, stalledNFs, targetedNFs := deployment.calculateNFCount()
 
-// computeSingleNFDeployStatusConditionChange: updates the LastUpdateTime and
-// LastTransitionTime based on change in condition currently computed and the
-// one received from api-server
-func (deployment *Deployment) computeSingleNFDeployStatusConditionChange(
-	conditions []v1alpha1.NFDeployCondition,
-	currentCondition *v1alpha1.NFDeployCondition,
-) {
-	conditionIdx := -1
-	for index, originalCondition := range conditions {
-		if originalCondition.Type == currentCondition.Type {
-			conditionIdx = index
-		}
-	}
-	currentTime := metav1.NewTime(time.Now())
-	if conditionIdx == -1 {
-		currentCondition.LastTransitionTime = currentTime
-		currentCondition.LastUpdateTime = currentTime
-	} else if deployment.areNFDeployStatusConditionsEqual(
-		currentCondition, conditions[conditionIdx],
-	) {
-		currentCondition = &conditions[conditionIdx]
-	} else {
-		if currentCondition.Status == conditions[conditionIdx].Status {
-			currentCondition.LastTransitionTime = conditions[conditionIdx].LastTransitionTime
-		} else {
-			currentCondition.LastTransitionTime = currentTime
-		}
-		currentCondition.LastUpdateTime = currentTime
-	}
+	stalledCondition := deployment.computeStalledCondition(
+		stalledNFs, targetedNFs,
+	)
+	readyCondition := deployment.computeReadyCondition(readyNFs, targetedNFs)
 
+	peeringCondition := deployment.computePeeringCondition(readyNFs, targetedNFs)
+
+	reconcilingCondition := deployment.computeReconcilingCondition(
+		readyNFs, targetedNFs,
+	)
+	return status.RuntimeStatus{
+		Generation:   deployment.generation,
+		AvailableNFs: int32(availableNFs),
+		ReadyNFs:     int32(readyNFs),
+		StalledNFs:   int32(stalledNFs),
+		TargetedNFs:  int32(targetedNFs),
+		Links:        deployment.getLinks(),
+		Conditions: []metav1.Condition{
+			stalledCondition, readyCondition, peeringCondition, reconcilingCondition,
+		},
+	}
 }
 
-// updateNFDeployStatus: computes NFDeployCondition change and updates status of
-// nfdeploy resource which the deployment is tracking. Returns error if update
-// fails after exhausting retries or receiving a non-retryable error
+// updateNFDeployStatus: reports the runtime status of nfdeploy to the status
+// aggregator, which merges it with the hydration status. Must be called
+// without deploymentMu held, as the update waits for the API server. Returns
+// error if update fails after exhausting retries or receiving a
+// non-retryable error
 func (deployment *Deployment) updateNFDeployStatus(
-	availableNFs int32, readyNFs int32, stalledNFs int32, targetedNFs int32,
-	stalledCondition *v1alpha1.NFDeployCondition,
-	readyCondition *v1alpha1.NFDeployCondition,
-	peeringCondition *v1alpha1.NFDeployCondition,
-	reconcilingCondition *v1alpha1.NFDeployCondition,
+	runtimeStatus status.RuntimeStatus,
 ) error {
-	err := retry.RetryOnConflict(
-		retry.DefaultRetry, func() error {
-			var nfDeploy v1alpha1.NfDeploy
-			if err := deployment.statusReader.Get(
-				context.TODO(), deployment.namespacedName, &nfDeploy,
-			); err != nil {
-				return err
-			}
-			deployment.computeSingleNFDeployStatusConditionChange(
-				nfDeploy.Status.Conditions, stalledCondition,
-			)
-			deployment.computeSingleNFDeployStatusConditionChange(
-				nfDeploy.Status.Conditions, readyCondition,
-			)
-			deployment.computeSingleNFDeployStatusConditionChange(
-				nfDeploy.Status.Conditions, peeringCondition,
-			)
-			deployment.computeSingleNFDeployStatusConditionChange(
-				nfDeploy.Status.Conditions, reconcilingCondition,
-			)
-			newConditions := []v1alpha1.NFDeployCondition{
-				*stalledCondition, *readyCondition, *peeringCondition,
-				*reconcilingCondition,
-			}
-			newNFDeployStatus := v1alpha1.NfDeployStatus{
-				ObservedGeneration: nfDeploy.Status.ObservedGeneration,
-				TargetedNFs:        targetedNFs, ReadyNFs: readyNFs,
-				AvailableNFs: availableNFs, StalledNFs: stalledNFs,
-				Conditions: newConditions,
-			}
-			nfDeploy.Status = newNFDeployStatus
-			if err := deployment.statusWriter.Update(
-				context.TODO(), &nfDeploy,
-			); err != nil {
-				return err
-			}
-			return nil
-		},
+	return deployment.statusAggregator.SetRuntimeStatus(
+		context.TODO(), deployment.namespacedName, runtimeStatus,
 	)
-
-	return err
 }
@@ -0,0 +1,586 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	types "github.com/nephio-project/common-lib/nfdeploy"
+	"github.com/nephio-project/edge-watcher/preprocessor"
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/status"
+	"github.com/nephio-project/nf-deploy-controller/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	types2 "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func generateUPFEvent(
+	name string, resourceVersion string, conditionType types.NFConditionType,
+) preprocessor.Event {
+	return generateUPFEventOfGeneration(name, 1, 1, resourceVersion, conditionType)
+}
+
+func generateUPFEventOfGeneration(
+	name string, generation int64, observedGeneration int32,
+	resourceVersion string, conditionType types.NFConditionType,
+) preprocessor.Event {
+	return generateUPFEventWithConditions(
+		name, generation, observedGeneration, resourceVersion,
+		[]types.NFCondition{{Type: conditionType, Status: corev1.ConditionTrue}},
+	)
+}
+
+func generateUPFEventWithConditions(
+	name string, generation int64, observedGeneration int32,
+	resourceVersion string, conditions []types.NFCondition,
+) preprocessor.Event {
+	upfDeploy := types.UpfDeploy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			UID:             types2.UID(name),
+			Generation:      generation,
+			ResourceVersion: resourceVersion,
+			Labels:          map[string]string{util.NFSiteIDLabel: name},
+		},
+		Status: types.UpfDeployStatus{
+			ObservedGeneration: observedGeneration,
+			Conditions:         conditions,
+		},
+	}
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&upfDeploy)
+	Expect(err).NotTo(HaveOccurred())
+	return preprocessor.Event{
+		Timestamp: time.Now(),
+		Key:       preprocessor.RequestKey{Namespace: "upf", Kind: "UPFDeploy"},
+		Object:    &unstructured.Unstructured{Object: data},
+	}
+}
+
+func ptr(event preprocessor.Event) *preprocessor.Event {
+	return &event
+}
+
+var _ = Describe(
+	"Edge event processing", func() {
+		var deployment *Deployment
+		var k8sClient client.Client
+		var fakeClock *testingclock.FakeClock
+		var recorder *record.FakeRecorder
+		key := types2.NamespacedName{Namespace: "default", Name: "sample"}
+
+		getNfDeploy := func() v1alpha1.NfDeploy {
+			var nfDeploy v1alpha1.NfDeploy
+			Expect(k8sClient.Get(context.TODO(), key, &nfDeploy)).To(Succeed())
+			return nfDeploy
+		}
+
+		BeforeEach(
+			func() {
+				scheme := runtime.NewScheme()
+				Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+				nfDeploy := &v1alpha1.NfDeploy{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: key.Namespace, Name: key.Name, Generation: 3,
+					},
+					Status: v1alpha1.NfDeployStatus{ObservedGeneration: 2},
+				}
+				k8sClient = fake.NewClientBuilder().WithScheme(scheme).
+					WithObjects(nfDeploy).Build()
+				deployment = createSampleDeployment()
+				deployment.ctx, deployment.cancelCtx = context.WithCancel(context.Background())
+				deployment.statusAggregator = status.NewAggregator(
+					k8sClient, k8sClient.Status(), logr.Discard(),
+				)
+				deployment.namespacedName = key
+				deployment.generation = 3
+				deployment.eventQueue = newEventQueue(0)
+				deployment.statusFlushInterval = 0
+				fakeClock = testingclock.NewFakeClock(time.Now())
+				deployment.clock = fakeClock
+				recorder = record.NewFakeRecorder(10)
+				deployment.recorder = recorder
+			},
+		)
+
+		AfterEach(
+			func() {
+				deployment.cancelCtx()
+			},
+		)
+
+		Context(
+			"When events of the same NF are queued", func() {
+				It(
+					"Should process only the latest one", func() {
+						Expect(
+							deployment.eventQueue.push(
+								generateUPFEvent(sampleUPFName, "10", types.Reconciling),
+							),
+						).To(Equal(eventQueued))
+						Expect(
+							deployment.eventQueue.push(
+								generateUPFEvent(sampleUPFName, "11", types.Ready),
+							),
+						).To(Equal(eventCoalesced))
+						go deployment.processQueuedEdgeEvents()
+
+						Eventually(
+							func() int32 {
+								return getNfDeploy().Status.ReadyNFs
+							},
+						).Should(Equal(int32(1)))
+						nfDeploy := getNfDeploy()
+						Expect(nfDeploy.Status.ObservedGeneration).To(Equal(int64(2)))
+						cond := meta.FindStatusCondition(
+							nfDeploy.Status.Conditions, string(v1alpha1.DeploymentReady),
+						)
+						Expect(cond).NotTo(BeNil())
+						Expect(cond.ObservedGeneration).To(Equal(int64(3)))
+					},
+				)
+			},
+		)
+
+		Context(
+			"When an event of an older version is received", func() {
+				It(
+					"Should discard it regardless of its timestamp", func() {
+						deployment.processEdgeEvent(
+							ptr(generateUPFEvent(sampleUPFName, "11", types.Ready)),
+						)
+						stale := generateUPFEvent(sampleUPFName, "10", types.Stalled)
+						stale.Timestamp = time.Now().Add(time.Hour)
+						deployment.processEdgeEvent(&stale)
+						Expect(deployment.upfNodes[sampleUPFName].Status.state).
+							To(Equal(types.Ready))
+
+						deployment.processEdgeEvent(
+							ptr(generateUPFEventOfGeneration(
+								sampleUPFName, 2, 2, "5", types.Stalled,
+							)),
+						)
+						Expect(deployment.upfNodes[sampleUPFName].Status.state).
+							To(Equal(types.Stalled))
+					},
+				)
+			},
+		)
+
+		Context(
+			"When the observedGeneration of an NF lags its generation", func() {
+				It(
+					"Should report the NF reconciling", func() {
+						deployment.processEdgeEvent(
+							ptr(generateUPFEventOfGeneration(
+								sampleUPFName, 2, 1, "10", types.Ready,
+							)),
+						)
+						runtimeStatus := deployment.computeNFDeployStatus()
+						Expect(runtimeStatus.ReadyNFs).To(BeZero())
+						reconciling := meta.FindStatusCondition(
+							runtimeStatus.Conditions, string(v1alpha1.DeploymentReconciling),
+						)
+						Expect(reconciling.Status).To(Equal(metav1.ConditionTrue))
+						Expect(reconciling.Reason).To(Equal("ObservedGenerationLagging"))
+						Expect(reconciling.Message).To(ContainSubstring(sampleUPFName))
+					},
+				)
+			},
+		)
+
+		Context(
+			"When the NF object is hydrated from an older generation of the NfDeploy", func() {
+				hydratedFrom := func(event preprocessor.Event, generation string) *preprocessor.Event {
+					event.Object.(*unstructured.Unstructured).SetAnnotations(
+						map[string]string{util.NFDeployGenerationAnnotation: generation},
+					)
+					return &event
+				}
+
+				It(
+					"Should report the NF reconciling", func() {
+						deployment.hydratedGenerations = map[string]int64{sampleUPFName: 3}
+						deployment.processEdgeEvent(
+							hydratedFrom(generateUPFEvent(sampleUPFName, "10", types.Ready), "2"),
+						)
+						Expect(deployment.upfNodes[sampleUPFName].Status.state).
+							To(Equal(types.Reconciling))
+						runtimeStatus := deployment.computeNFDeployStatus()
+						Expect(runtimeStatus.ReadyNFs).To(BeZero())
+						reconciling := meta.FindStatusCondition(
+							runtimeStatus.Conditions, string(v1alpha1.DeploymentReconciling),
+						)
+						Expect(reconciling.Reason).To(Equal("ObservedGenerationLagging"))
+						Expect(reconciling.Message).To(ContainSubstring(sampleUPFName))
+
+						deployment.processEdgeEvent(
+							hydratedFrom(generateUPFEvent(sampleUPFName, "11", types.Ready), "3"),
+						)
+						Expect(deployment.upfNodes[sampleUPFName].Status.state).
+							To(Equal(types.Ready))
+					},
+				)
+
+				It(
+					"Should report the NF reconciling when a new generation is hydrated", func() {
+						deployment.hydratedGenerations = map[string]int64{sampleUPFName: 2}
+						deployment.processEdgeEvent(
+							hydratedFrom(generateUPFEvent(sampleUPFName, "10", types.Ready), "2"),
+						)
+						Expect(deployment.upfNodes[sampleUPFName].Status.state).
+							To(Equal(types.Ready))
+
+						deployment.hydratedGenerations[sampleUPFName] = 3
+						deployment.markLaggingNFs()
+						Expect(deployment.upfNodes[sampleUPFName].Status.state).
+							To(Equal(types.Reconciling))
+						Expect(deployment.upfNodes[sampleUPFName].Status.stateMessage).
+							To(Equal("NF is hydrated from generation 2 of 3 of the NfDeploy."))
+						Expect(deployment.statusDirty).To(BeTrue())
+					},
+				)
+			},
+		)
+
+		Context(
+			"When the status flush interval is set", func() {
+				It(
+					"Should not update the status before the interval", func() {
+						deployment.statusFlushInterval = time.Hour
+						deployment.eventQueue.push(
+							generateUPFEvent(sampleUPFName, "10", types.Ready),
+						)
+						go deployment.processQueuedEdgeEvents()
+
+						Consistently(
+							func() []metav1.Condition {
+								return getNfDeploy().Status.Conditions
+							}, "200ms",
+						).Should(BeEmpty())
+					},
+				)
+			},
+		)
+
+		Context(
+			"When the status update fails", func() {
+				It(
+					"Should retry it with backoff", func() {
+						Expect(k8sClient.Delete(context.TODO(), &v1alpha1.NfDeploy{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: key.Namespace, Name: key.Name,
+							},
+						})).To(Succeed())
+						deployment.eventQueue.push(
+							generateUPFEvent(sampleUPFName, "10", types.Ready),
+						)
+						go deployment.processQueuedEdgeEvents()
+						Eventually(fakeClock.HasWaiters).Should(BeTrue())
+
+						Expect(k8sClient.Create(context.TODO(), &v1alpha1.NfDeploy{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: key.Namespace, Name: key.Name, Generation: 3,
+							},
+						})).To(Succeed())
+						fakeClock.Step(minStatusRetryInterval)
+
+						Eventually(
+							func() int32 {
+								return getNfDeploy().Status.ReadyNFs
+							},
+						).Should(Equal(int32(1)))
+						Expect(nextStatusRetryInterval(minStatusRetryInterval)).
+							To(Equal(2 * minStatusRetryInterval))
+						Expect(nextStatusRetryInterval(maxStatusRetryInterval)).
+							To(Equal(maxStatusRetryInterval))
+					},
+				)
+			},
+		)
+
+		Context(
+			"When no edge event of an NF is received within its staleness timeout", func() {
+				It(
+					"Should mark the NF status stale and record an event", func() {
+						deployment.stalenessTimeouts = map[NFType]time.Duration{
+							UPF: time.Minute,
+						}
+						deployment.eventQueue.push(
+							generateUPFEvent(sampleUPFName, "10", types.Ready),
+						)
+						go deployment.processQueuedEdgeEvents()
+						Eventually(
+							func() int32 {
+								return getNfDeploy().Status.ReadyNFs
+							},
+						).Should(Equal(int32(1)))
+						Eventually(fakeClock.HasWaiters).Should(BeTrue())
+
+						fakeClock.Step(time.Minute)
+
+						Eventually(
+							func() string {
+								cond := meta.FindStatusCondition(
+									getNfDeploy().Status.Conditions,
+									string(v1alpha1.DeploymentReady),
+								)
+								if cond == nil {
+									return ""
+								}
+								return cond.Reason
+							},
+						).Should(Equal("NFStatusStale"))
+						nfDeploy := getNfDeploy()
+						Expect(nfDeploy.Status.ReadyNFs).To(BeZero())
+						Expect(nfDeploy.Status.AvailableNFs).To(BeZero())
+						ready := meta.FindStatusCondition(
+							nfDeploy.Status.Conditions, string(v1alpha1.DeploymentReady),
+						)
+						Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+						Expect(ready.Message).To(ContainSubstring(sampleUPFName))
+						Expect(recorder.Events).To(Receive(
+							And(ContainSubstring("Warning StatusStale"), ContainSubstring(sampleUPFName)),
+						))
+
+						deployment.processEdgeEvent(
+							ptr(generateUPFEvent(sampleUPFName, "11", types.Ready)),
+						)
+						deployment.deploymentMu.RLock()
+						defer deployment.deploymentMu.RUnlock()
+						Expect(deployment.upfNodes[sampleUPFName].Status.state).
+							To(Equal(types.Ready))
+					},
+				)
+			},
+		)
+
+		Context(
+			"When the status of all the unready NFs is stale", func() {
+				It(
+					"Should report the NfDeploy readiness unknown", func() {
+						deployment.stalenessTimeouts = map[NFType]time.Duration{
+							UPF: time.Minute, SMF: time.Minute,
+						}
+						deployment.processEdgeEvent(
+							ptr(generateUPFEvent(sampleUPFName, "10", types.Ready)),
+						)
+						fakeClock.Step(time.Minute)
+						Expect(deployment.markStaleNFs()).To(BeTrue())
+						Expect(deployment.markStaleNFs()).To(BeFalse())
+
+						runtimeStatus := deployment.computeNFDeployStatus()
+						ready := meta.FindStatusCondition(
+							runtimeStatus.Conditions, string(v1alpha1.DeploymentReady),
+						)
+						// the SMF has never reported and is not ready
+						Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+
+						deployment.smfNodes = map[string]SMFNode{}
+						runtimeStatus = deployment.computeNFDeployStatus()
+						ready = meta.FindStatusCondition(
+							runtimeStatus.Conditions, string(v1alpha1.DeploymentReady),
+						)
+						Expect(ready.Status).To(Equal(metav1.ConditionUnknown))
+						Expect(ready.Reason).To(Equal("NFStatusStale"))
+					},
+				)
+			},
+		)
+
+		Context(
+			"When an NF never sends an edge event", func() {
+				It(
+					"Should mark it stale once the timeout since it was added expires", func() {
+						deployment.stalenessTimeouts = map[NFType]time.Duration{
+							AUSF: time.Minute,
+						}
+						deployment.ausfNodes = map[string]AUSFNode{}
+						deployment.addOrUpdateAUSFNode(
+							v1alpha1.Site{Id: "sample-ausf", NFType: string(AUSF)},
+						)
+						fakeClock.Step(30 * time.Second)
+						Expect(deployment.markStaleNFs()).To(BeFalse())
+						fakeClock.Step(30 * time.Second)
+						Expect(deployment.markStaleNFs()).To(BeTrue())
+						Expect(deployment.getStaleNFs()).To(Equal([]string{"sample-ausf"}))
+						Expect(recorder.Events).To(Receive(
+							And(ContainSubstring("Warning StatusStale"), ContainSubstring("sample-ausf")),
+						))
+					},
+				)
+			},
+		)
+
+		Context(
+			"When an NF reports the peering state of its links", func() {
+				It(
+					"Should compute the Peering condition from the links", func() {
+						deployment.edges[0].ReferencePoint = v1alpha1.N4
+						deployment.processEdgeEvent(
+							ptr(generateUPFEventWithConditions(
+								sampleUPFName, 1, 1, "10", []types.NFCondition{
+									{Type: types.Peering, Status: corev1.ConditionTrue},
+									{
+										Type:    PeerConditionTypePrefix + sampleSMFName,
+										Status:  corev1.ConditionFalse,
+										Message: "N4 association lost",
+									},
+								},
+							)),
+						)
+						runtimeStatus := deployment.computeNFDeployStatus()
+						peering := meta.FindStatusCondition(
+							runtimeStatus.Conditions, string(v1alpha1.DeploymentPeering),
+						)
+						Expect(peering.Status).To(Equal(metav1.ConditionTrue))
+						Expect(peering.Reason).To(Equal("LinksBroken"))
+						Expect(peering.Message).To(Equal(
+							"The broken links are: sample-smf <-> sample-upf [N4] " +
+								"(sample-upf: N4 association lost).",
+						))
+						Expect(runtimeStatus.Links).To(Equal([]v1alpha1.NfDeployLink{
+							{
+								FirstSite: sampleAMFName, SecondSite: sampleSMFName,
+								Peering: metav1.ConditionUnknown,
+							},
+							{
+								FirstSite: sampleSMFName, SecondSite: sampleUPFName,
+								ReferencePoint: v1alpha1.N4,
+								Peering:        metav1.ConditionFalse,
+								Message:        "sample-upf: N4 association lost",
+							},
+						}))
+						// per-peer conditions are not NF conditions
+						Expect(deployment.upfNodes[sampleUPFName].Status.state).
+							To(Equal(types.Peering))
+
+						deployment.processEdgeEvent(
+							ptr(generateUPFEventWithConditions(
+								sampleUPFName, 1, 1, "11", []types.NFCondition{
+									{Type: types.Peering, Status: corev1.ConditionTrue},
+									{
+										Type:   PeerConditionTypePrefix + sampleSMFName,
+										Status: corev1.ConditionTrue,
+									},
+								},
+							)),
+						)
+						peering = meta.FindStatusCondition(
+							deployment.computeNFDeployStatus().Conditions,
+							string(v1alpha1.DeploymentPeering),
+						)
+						Expect(peering.Reason).To(Equal("SomeLinksPeering"))
+						Expect(peering.Message).To(ContainSubstring(
+							sampleAMFName + " <-> " + sampleSMFName,
+						))
+					},
+				)
+			},
+		)
+
+		Context(
+			"When the deletion of an NF is reported", func() {
+				It(
+					"Should mark the NF removed and never stale", func() {
+						deployment.stalenessTimeouts = map[NFType]time.Duration{
+							UPF: time.Minute,
+						}
+						Expect(deployment.GetTerminatingNFs()).To(BeEmpty())
+						deployment.processEdgeEvent(
+							ptr(generateUPFEvent(sampleUPFName, "10", types.Ready)),
+						)
+						Expect(deployment.GetTerminatingNFs()).To(Equal([]string{sampleUPFName}))
+
+						deleted := generateUPFEvent(sampleUPFName, "11", types.Ready)
+						deleted.Type = preprocessor.Deleted
+						deployment.processEdgeEvent(&deleted)
+						Expect(deployment.GetTerminatingNFs()).To(BeEmpty())
+						Expect(deployment.GetNFStates()).NotTo(HaveKey(sampleUPFName))
+						Expect(deployment.computeNFDeployStatus().ReadyNFs).To(BeZero())
+						fakeClock.Step(time.Minute)
+						Expect(deployment.markStaleNFs()).To(BeFalse())
+
+						deployment.processEdgeEvent(
+							ptr(generateUPFEvent(sampleUPFName, "12", types.Ready)),
+						)
+						Expect(deployment.GetTerminatingNFs()).To(Equal([]string{sampleUPFName}))
+					},
+				)
+
+				It(
+					"Should await an edge event of the cluster after re-subscribing", func() {
+						deployment.awaitSync = true
+						deployment.siteClusters = map[string]string{
+							sampleUPFName: "edge-1", sampleSMFName: "edge-2",
+						}
+						Expect(deployment.GetTerminatingNFs()).To(Equal(
+							[]string{sampleSMFName, sampleUPFName},
+						))
+
+						listed := generateUPFEvent(sampleUPFName, "10", types.Ready)
+						listed.Type = preprocessor.List
+						listed.Key.ClusterName = "edge-1"
+						deployment.processEdgeEvent(&listed)
+						Expect(deployment.GetTerminatingNFs()).To(Equal(
+							[]string{sampleSMFName, sampleUPFName},
+						))
+
+						deleted := generateUPFEvent(sampleUPFName, "11", types.Ready)
+						deleted.Type = preprocessor.Deleted
+						deleted.Key.ClusterName = "edge-1"
+						deployment.processEdgeEvent(&deleted)
+						// the cluster of the SMF sent no event yet
+						Expect(deployment.GetTerminatingNFs()).To(Equal([]string{sampleSMFName}))
+					},
+				)
+			},
+		)
+
+		Context(
+			"When the edge connection breaks", func() {
+				It(
+					"Should set all the conditions unknown", func() {
+						deployment.eventQueue.push(
+							generateUPFEvent(sampleUPFName, "10", types.Ready),
+						)
+						deployment.eventQueue.close()
+						deployment.processQueuedEdgeEvents()
+
+						nfDeploy := getNfDeploy()
+						Expect(nfDeploy.Status.Conditions).To(HaveLen(4))
+						for _, cond := range nfDeploy.Status.Conditions {
+							Expect(cond.Status).To(Equal(metav1.ConditionUnknown))
+							Expect(cond.Reason).To(Equal("EdgeConnectionBroken"))
+						}
+					},
+				)
+			},
+		)
+	},
+)
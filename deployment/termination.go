@@ -0,0 +1,111 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"sort"
+
+	types "github.com/nephio-project/common-lib/nfdeploy"
+)
+
+// withNFRemoved : returns the NF status marked removed from its workload
+// cluster. The last reported conditions are dropped, so the NF is counted
+// neither available nor ready, as if it never reported.
+func (nfStatus NFStatus) withNFRemoved() NFStatus {
+	return NFStatus{
+		activeConditions: map[types.NFConditionType]string{},
+		lastEventVersion: nfStatus.lastEventVersion,
+		lastEventTime:    nfStatus.lastEventTime,
+		removed:          true,
+	}
+}
+
+// isTerminating : returns true if the NF was reported by its workload
+// cluster and its deletion was not reported since
+func (nfStatus NFStatus) isTerminating() bool {
+	return !nfStatus.lastEventTime.IsZero() && !nfStatus.removed
+}
+
+// processNFRemovedEvent : marks nfId removed from its workload cluster, drops
+// its reports on the peering of its links and marks the aggregated status of
+// NFDeploy resource to be updated
+func (deployment *Deployment) processNFRemovedEvent(nfId string) {
+	switch deployment.getNFType(nfId) {
+	case UPF:
+		node := deployment.upfNodes[nfId]
+		node.Status = node.Status.withNFRemoved()
+		deployment.upfNodes[nfId] = node
+	case SMF:
+		node := deployment.smfNodes[nfId]
+		node.Status = node.Status.withNFRemoved()
+		deployment.smfNodes[nfId] = node
+	case AUSF:
+		node := deployment.ausfNodes[nfId]
+		node.Status = node.Status.withNFRemoved()
+		deployment.ausfNodes[nfId] = node
+	case UDM:
+		node := deployment.udmNodes[nfId]
+		node.Status = node.Status.withNFRemoved()
+		deployment.udmNodes[nfId] = node
+	}
+	deployment.logger.Info("NF removed from the edge", "NF", nfId)
+	deployment.updateEdgePeering(nfId, nil)
+	deployment.statusDirty = true
+}
+
+// isTerminating : returns true if the NF of the site is terminating, or if
+// its removal cannot be confirmed yet because the deployment awaits an edge
+// event from its workload cluster
+func (deployment *Deployment) isTerminating(nfId string, nfStatus NFStatus) bool {
+	if nfStatus.isTerminating() {
+		return true
+	}
+	return deployment.awaitSync && nfStatus.lastEventTime.IsZero() && !nfStatus.removed &&
+		!deployment.syncedClusters[deployment.siteClusters[nfId]]
+}
+
+// GetTerminatingNFs := Returns the sorted ids of the sites whose NFs were
+// reported by their workload cluster and whose deletion was not reported
+// since. When the deployment is created for an NfDeploy being deleted, the
+// NFs whose workload cluster sent no edge event yet are returned too.
+func (deployment *Deployment) GetTerminatingNFs() []string {
+	deployment.deploymentMu.RLock()
+	defer deployment.deploymentMu.RUnlock()
+	sites := []string{}
+	for nfId, node := range deployment.upfNodes {
+		if deployment.isTerminating(nfId, node.Status) {
+			sites = append(sites, nfId)
+		}
+	}
+	for nfId, node := range deployment.smfNodes {
+		if deployment.isTerminating(nfId, node.Status) {
+			sites = append(sites, nfId)
+		}
+	}
+	for nfId, node := range deployment.ausfNodes {
+		if deployment.isTerminating(nfId, node.Status) {
+			sites = append(sites, nfId)
+		}
+	}
+	for nfId, node := range deployment.udmNodes {
+		if deployment.isTerminating(nfId, node.Status) {
+			sites = append(sites, nfId)
+		}
+	}
+	sort.Strings(sites)
+	return sites
+}
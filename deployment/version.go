@@ -0,0 +1,146 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/nephio-project/edge-watcher/preprocessor"
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	. "k8s.io/apimachinery/pkg/types"
+)
+
+// objectVersion : the version of an NF object on the edge carried in an edge
+// event. Edge events of an NF are ordered by it rather than by their
+// timestamps, which depend on the clocks of the clusters.
+type objectVersion struct {
+	uid                UID
+	generation         int64
+	observedGeneration int64
+	resourceVersion    string
+	// generation of the NfDeploy the NF object is hydrated from, 0 when it
+	// is not reported
+	hydratedGeneration int64
+}
+
+// eventObjectVersion : returns the version of the NF object of the event
+func eventObjectVersion(event *preprocessor.Event) objectVersion {
+	obj, ok := event.Object.(*unstructured.Unstructured)
+	if !ok {
+		return objectVersion{}
+	}
+	observedGeneration, _, _ := unstructured.NestedInt64(
+		obj.Object, "status", "observedGeneration",
+	)
+	hydratedGeneration, _ := strconv.ParseInt(
+		obj.GetAnnotations()[util.NFDeployGenerationAnnotation], 10, 64,
+	)
+	return objectVersion{
+		uid:                obj.GetUID(),
+		generation:         obj.GetGeneration(),
+		observedGeneration: observedGeneration,
+		resourceVersion:    obj.GetResourceVersion(),
+		hydratedGeneration: hydratedGeneration,
+	}
+}
+
+// isOlderThan : returns true if the object version precedes last. Versions
+// of different objects, such as an NF object which is recreated, are never
+// older than each other. The resourceVersions are compared only when both
+// are integers, as they are opaque otherwise.
+func (version objectVersion) isOlderThan(last objectVersion) bool {
+	if version.uid != last.uid {
+		return false
+	}
+	if version.generation != last.generation {
+		return version.generation < last.generation
+	}
+	if version.observedGeneration != last.observedGeneration {
+		return version.observedGeneration < last.observedGeneration
+	}
+	resourceVersion, err := strconv.ParseUint(version.resourceVersion, 10, 64)
+	if err != nil {
+		return false
+	}
+	lastResourceVersion, err := strconv.ParseUint(last.resourceVersion, 10, 64)
+	if err != nil {
+		return false
+	}
+	return resourceVersion < lastResourceVersion
+}
+
+// isLagging : returns true if the NF object on the edge is not hydrated from
+// hydratedGeneration, the generation of the NfDeploy the controller hydrated
+// the package of the NF from, or if the NF operator has not observed the
+// latest generation of the NF object yet, i.e. the reported status is of an
+// older manifest. Generations which are not reported or not known are not
+// lagging.
+func (version objectVersion) isLagging(hydratedGeneration int64) bool {
+	return version.isHydrationLagging(hydratedGeneration) ||
+		(version.observedGeneration > 0 &&
+			version.observedGeneration < version.generation)
+}
+
+// isHydrationLagging : returns true if the NF object on the edge is hydrated
+// from an older generation of the NfDeploy than hydratedGeneration
+func (version objectVersion) isHydrationLagging(hydratedGeneration int64) bool {
+	return version.hydratedGeneration > 0 &&
+		version.hydratedGeneration < hydratedGeneration
+}
+
+// lagMessage : describes the lag of the NF object on the edge
+func (version objectVersion) lagMessage(hydratedGeneration int64) string {
+	if version.isHydrationLagging(hydratedGeneration) {
+		return fmt.Sprintf(
+			"NF is hydrated from generation %d of %d of the NfDeploy.",
+			version.hydratedGeneration, hydratedGeneration,
+		)
+	}
+	return fmt.Sprintf(
+		"NF has observed generation %d of %d.",
+		version.observedGeneration, version.generation,
+	)
+}
+
+// hydratedGenerations : returns the generation of nfDeploy the package of
+// each site is hydrated from, by site id. The sites which are not released
+// yet by the rollout of the generation keep their generation in recorded.
+func hydratedGenerations(
+	nfDeploy v1alpha1.NfDeploy, recorded map[string]int64,
+) map[string]int64 {
+	released := map[string]bool{}
+	rollout := nfDeploy.Status.Rollout
+	if rollout != nil && rollout.ObservedGeneration == nfDeploy.Generation {
+		for wave := 0; wave <= int(rollout.CurrentWave) && wave < len(rollout.Waves); wave++ {
+			for _, siteId := range rollout.Waves[wave].Sites {
+				released[siteId] = true
+			}
+		}
+	}
+	generations := make(map[string]int64, len(nfDeploy.Spec.Sites))
+	for _, site := range nfDeploy.Spec.Sites {
+		if rollout == nil || released[site.Id] {
+			generations[site.Id] = nfDeploy.Generation
+		} else if generation, isPresent := recorded[site.Id]; isPresent {
+			generations[site.Id] = generation
+		}
+	}
+	return generations
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = DescribeTable(
+	"objectVersion.isOlderThan",
+	func(version, last objectVersion, expected bool) {
+		Expect(version.isOlderThan(last)).To(Equal(expected))
+	},
+	Entry(
+		"older generation",
+		objectVersion{uid: "a", generation: 1, observedGeneration: 1, resourceVersion: "20"},
+		objectVersion{uid: "a", generation: 2, observedGeneration: 1, resourceVersion: "10"},
+		true,
+	),
+	Entry(
+		"older observedGeneration",
+		objectVersion{uid: "a", generation: 2, observedGeneration: 1, resourceVersion: "20"},
+		objectVersion{uid: "a", generation: 2, observedGeneration: 2, resourceVersion: "10"},
+		true,
+	),
+	Entry(
+		"older resourceVersion",
+		objectVersion{uid: "a", generation: 2, observedGeneration: 2, resourceVersion: "9"},
+		objectVersion{uid: "a", generation: 2, observedGeneration: 2, resourceVersion: "10"},
+		true,
+	),
+	Entry(
+		"same version",
+		objectVersion{uid: "a", generation: 2, observedGeneration: 2, resourceVersion: "10"},
+		objectVersion{uid: "a", generation: 2, observedGeneration: 2, resourceVersion: "10"},
+		false,
+	),
+	Entry(
+		"recreated object",
+		objectVersion{uid: "b", generation: 1, observedGeneration: 1, resourceVersion: "5"},
+		objectVersion{uid: "a", generation: 2, observedGeneration: 2, resourceVersion: "10"},
+		false,
+	),
+	Entry(
+		"opaque resourceVersion",
+		objectVersion{uid: "a", generation: 2, observedGeneration: 2, resourceVersion: "x"},
+		objectVersion{uid: "a", generation: 2, observedGeneration: 2, resourceVersion: "10"},
+		false,
+	),
+)
+
+var _ = DescribeTable(
+	"objectVersion.isLagging",
+	func(version objectVersion, hydratedGeneration int64, expected bool) {
+		Expect(version.isLagging(hydratedGeneration)).To(Equal(expected))
+	},
+	Entry(
+		"observedGeneration lags",
+		objectVersion{generation: 2, observedGeneration: 1}, int64(0), true,
+	),
+	Entry(
+		"hydrated from an older generation",
+		objectVersion{generation: 1, observedGeneration: 1, hydratedGeneration: 2},
+		int64(3), true,
+	),
+	Entry(
+		"hydrated from the hydrated generation",
+		objectVersion{generation: 1, observedGeneration: 1, hydratedGeneration: 3},
+		int64(3), false,
+	),
+	Entry(
+		"hydrated generation not reported",
+		objectVersion{generation: 1, observedGeneration: 1}, int64(3), false,
+	),
+)
+
+var _ = Describe(
+	"hydratedGenerations", func() {
+		nfDeploy := v1alpha1.NfDeploy{
+			ObjectMeta: metav1.ObjectMeta{Generation: 3},
+			Spec: v1alpha1.NfDeploySpec{
+				Sites: []v1alpha1.Site{{Id: "upf1"}, {Id: "upf2"}, {Id: "smf1"}},
+			},
+		}
+
+		It(
+			"Should return the generation for all the sites without rollout", func() {
+				Expect(hydratedGenerations(nfDeploy, nil)).To(Equal(
+					map[string]int64{"upf1": 3, "upf2": 3, "smf1": 3},
+				))
+			},
+		)
+
+		It(
+			"Should keep the recorded generation of the sites not released yet", func() {
+				rolledOut := *nfDeploy.DeepCopy()
+				rolledOut.Status.Rollout = &v1alpha1.RolloutStatus{
+					ObservedGeneration: 3,
+					Waves: []v1alpha1.RolloutWave{
+						{Sites: []string{"upf1"}}, {Sites: []string{"upf2", "smf1"}},
+					},
+					CurrentWave: 0,
+				}
+				Expect(hydratedGenerations(
+					rolledOut, map[string]int64{"upf1": 2, "upf2": 2, "removed": 2},
+				)).To(Equal(map[string]int64{"upf1": 3, "upf2": 2}))
+			},
+		)
+	},
+)
@@ -0,0 +1,179 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration"
+	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/status"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// DefaultInterval is the interval at which the deploy packages are checked
+// for drift when not configured
+const DefaultInterval = 10 * time.Minute
+
+// Detector periodically renders every NfDeploy in memory and compares the
+// content with the latest published revision of the deploy package of each
+// of its clusters, e.g. to find the packages edited by hand in Porch. The
+// result is reported in the Drifted condition of NfDeploy. With the
+// auto-remediation strategy, a new draft revision of the drifted packages is
+// created with the rendered content.
+type Detector struct {
+	Client           client.Client
+	Hydration        hydration.HydrationInterface
+	PS               packageservice.PackageServiceInterface
+	StatusAggregator status.Aggregator
+	Interval         time.Duration
+	Log              logr.Logger
+}
+
+// Start checks the NfDeploys every interval until ctx is done. Implements
+// manager.Runnable.
+func (d *Detector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			d.DetectAll(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable so that only
+// the leader remediates the drifted packages
+func (d *Detector) NeedLeaderElection() bool {
+	return true
+}
+
+// DetectAll checks the deploy packages of all the NfDeploys
+func (d *Detector) DetectAll(ctx context.Context) {
+	var nfDeploys v1alpha1.NfDeployList
+	if err := d.Client.List(ctx, &nfDeploys); err != nil {
+		d.Log.Error(err, "error listing NfDeploys")
+		return
+	}
+	for _, nfDeploy := range nfDeploys.Items {
+		if err := d.Detect(ctx, nfDeploy); err != nil {
+			d.Log.Error(err, "error detecting the drift of deploy packages",
+				"nfDeployName", nfDeploy.Name)
+		}
+	}
+}
+
+// Detect checks the deploy packages of nfDeploy and reports their drift. The
+// check is skipped while the packages of the generation are being created or
+// upgraded, or while a revision of a package awaits approval, as the
+// published revision is expected to differ then.
+func (d *Detector) Detect(ctx context.Context, nfDeploy v1alpha1.NfDeploy) error {
+	if reason := d.skipReason(nfDeploy); reason != "" {
+		d.Log.V(1).Info("Skipping drift detection", "nfDeployName", nfDeploy.Name,
+			"reason", reason)
+		return nil
+	}
+	contents, err := d.Hydration.Render(ctx, nfDeploy)
+	if err != nil {
+		return fmt.Errorf("error rendering NfDeploy: %w", err)
+	}
+	clusters := make([]string, 0, len(contents))
+	for cluster := range contents {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	drift := status.DriftStatus{Generation: nfDeploy.Generation}
+	for _, cluster := range clusters {
+		nc, err := util.NewNamingContext(cluster, nfDeploy.Name)
+		if err != nil {
+			return fmt.Errorf("error creating naming context: %w", err)
+		}
+		revision, published, pending, err := d.PS.GetPublishedDeployPackage(ctx, nc)
+		if err != nil {
+			return fmt.Errorf("error fetching the deploy package of cluster %s: %w", cluster, err)
+		}
+		if pending {
+			d.Log.V(1).Info("Skipping drift detection, a revision awaits approval",
+				"nfDeployName", nfDeploy.Name, "package", nc.GetDeployPackageName())
+			return nil
+		}
+		if files := Diff(contents[cluster], published); len(files) != 0 {
+			drift.Packages = append(drift.Packages, status.PackageDrift{
+				ClusterName: cluster,
+				Revision:    revision,
+				Files:       files,
+			})
+		}
+	}
+
+	key := types.NamespacedName{Namespace: nfDeploy.Namespace, Name: nfDeploy.Name}
+	if err := d.StatusAggregator.SetDriftStatus(ctx, key, drift); err != nil {
+		return fmt.Errorf("error updating NfDeploy status: %w", err)
+	}
+	if len(drift.Packages) != 0 {
+		d.Log.Info("Deploy packages drifted", "nfDeployName", nfDeploy.Name,
+			"packages", drift.Packages)
+	}
+	if nfDeploy.Spec.Drift == nil || !nfDeploy.Spec.Drift.AutoRemediate {
+		return nil
+	}
+	for _, pkg := range drift.Packages {
+		nc, err := util.NewNamingContext(pkg.ClusterName, nfDeploy.Name)
+		if err != nil {
+			return fmt.Errorf("error creating naming context: %w", err)
+		}
+		name, err := d.PS.CreateDeployPackage(ctx, contents[pkg.ClusterName], nc)
+		if err != nil {
+			return fmt.Errorf("error remediating the deploy package of cluster %s: %w",
+				pkg.ClusterName, err)
+		}
+		d.Log.Info("Created porch package to remediate the drift", "name", name,
+			"nfDeployName", nfDeploy.Name)
+	}
+	return nil
+}
+
+// skipReason returns why the deploy packages of nfDeploy are not checked,
+// empty if they are
+func (d *Detector) skipReason(nfDeploy v1alpha1.NfDeploy) string {
+	switch {
+	case !nfDeploy.DeletionTimestamp.IsZero():
+		return "NfDeploy is being deleted"
+	case nfDeploy.Status.ObservedGeneration != nfDeploy.Generation:
+		return "the generation is not hydrated yet"
+	case !nfDeploy.Status.Rollout.IsComplete():
+		return "the rollout is in progress"
+	}
+	if upgrade := nfDeploy.Status.Upgrade; upgrade != nil &&
+		upgrade.ObservedGeneration == nfDeploy.Generation &&
+		upgrade.Phase != v1alpha1.UpgradeSucceeded {
+		return fmt.Sprintf("the upgrade is %s", upgrade.Phase)
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/drift"
+	hydrationmock "github.com/nephio-project/nf-deploy-controller/hydration/mock"
+	psmock "github.com/nephio-project/nf-deploy-controller/packageservice/mock"
+	"github.com/nephio-project/nf-deploy-controller/status"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+var _ = Describe("Detector", func() {
+	var k8sClient client.Client
+	var mockHydration *hydrationmock.MockHydrationInterface
+	var mockPS *psmock.MockPackageServiceInterface
+	var detector *drift.Detector
+	var nfDeploy v1alpha1.NfDeploy
+	var nc util.NamingContext
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "nfdeploy1"}
+	rendered := map[string]map[string]string{"cluster1": {"upf.yaml": upfDeploy}}
+
+	drifted := func() *metav1.Condition {
+		var got v1alpha1.NfDeploy
+		Expect(k8sClient.Get(ctx, key, &got)).To(Succeed())
+		return meta.FindStatusCondition(got.Status.Conditions, string(v1alpha1.DeploymentDrifted))
+	}
+
+	BeforeEach(func() {
+		nfDeploy = v1alpha1.NfDeploy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace, Name: key.Name, Generation: 2,
+			},
+			Status: v1alpha1.NfDeployStatus{ObservedGeneration: 2},
+		}
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&nfDeploy).Build()
+		ctrl := gomock.NewController(GinkgoT())
+		mockHydration = hydrationmock.NewMockHydrationInterface(ctrl)
+		mockPS = psmock.NewMockPackageServiceInterface(ctrl)
+		detector = &drift.Detector{
+			Client:    k8sClient,
+			Hydration: mockHydration,
+			PS:        mockPS,
+			StatusAggregator: status.NewAggregator(
+				k8sClient, k8sClient.Status(), logr.Discard(),
+			),
+			Log: logr.Discard(),
+		}
+		var err error
+		nc, err = util.NewNamingContext("cluster1", key.Name)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should report no drift when the published package matches", func() {
+		mockHydration.EXPECT().Render(gomock.Any(), gomock.Any()).Return(rendered, nil)
+		mockPS.EXPECT().GetPublishedDeployPackage(gomock.Any(), nc).
+			Return("deployPkg1", map[string]string{"upf.yaml": upfDeploy}, false, nil)
+		Expect(detector.Detect(ctx, nfDeploy)).To(Succeed())
+		Expect(drifted().Status).To(Equal(metav1.ConditionFalse))
+	})
+
+	It("Should report the drift of the published package", func() {
+		mockHydration.EXPECT().Render(gomock.Any(), gomock.Any()).Return(rendered, nil)
+		mockPS.EXPECT().GetPublishedDeployPackage(gomock.Any(), nc).
+			Return("deployPkg1", map[string]string{"upf.yaml": "kind: UpfDeploy"}, false, nil)
+		Expect(detector.Detect(ctx, nfDeploy)).To(Succeed())
+		condition := drifted()
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("cluster cluster1 revision deployPkg1"))
+		Expect(condition.Message).To(ContainSubstring("spec"))
+	})
+
+	It("Should create a new revision with the rendered content when auto-remediating", func() {
+		nfDeploy.Spec.Drift = &v1alpha1.DriftStrategy{AutoRemediate: true}
+		mockHydration.EXPECT().Render(gomock.Any(), gomock.Any()).Return(rendered, nil)
+		mockPS.EXPECT().GetPublishedDeployPackage(gomock.Any(), nc).
+			Return("deployPkg1", map[string]string{"upf.yaml": "kind: UpfDeploy"}, false, nil)
+		mockPS.EXPECT().CreateDeployPackage(gomock.Any(), rendered["cluster1"], nc).
+			Return("deployPkg2", nil)
+		Expect(detector.Detect(ctx, nfDeploy)).To(Succeed())
+		Expect(drifted().Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should not report while a revision of the package awaits approval", func() {
+		mockHydration.EXPECT().Render(gomock.Any(), gomock.Any()).Return(rendered, nil)
+		mockPS.EXPECT().GetPublishedDeployPackage(gomock.Any(), nc).
+			Return("deployPkg1", map[string]string{"upf.yaml": "kind: UpfDeploy"}, true, nil)
+		Expect(detector.Detect(ctx, nfDeploy)).To(Succeed())
+		Expect(drifted()).To(BeNil())
+	})
+
+	It("Should skip the NfDeploy while its generation is not rolled out", func() {
+		nfDeploy.Status.Rollout = &v1alpha1.RolloutStatus{
+			ObservedGeneration: 2,
+			Waves: []v1alpha1.RolloutWave{
+				{Sites: []string{"upf-1"}}, {Sites: []string{"upf-2"}},
+			},
+		}
+		Expect(detector.Detect(ctx, nfDeploy)).To(Succeed())
+
+		nfDeploy.Status.Rollout = nil
+		nfDeploy.Status.ObservedGeneration = 1
+		Expect(detector.Detect(ctx, nfDeploy)).To(Succeed())
+
+		nfDeploy.Status.ObservedGeneration = 2
+		nfDeploy.Status.Upgrade = &v1alpha1.UpgradeStatus{
+			ObservedGeneration: 2, Phase: v1alpha1.UpgradeProgressing,
+		}
+		Expect(detector.Detect(ctx, nfDeploy)).To(Succeed())
+		Expect(drifted()).To(BeNil())
+	})
+})
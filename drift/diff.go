@@ -0,0 +1,159 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/status"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// Diff returns the files of the published package content which differ from
+// the intended one, in the order of their names. The files are compared
+// object by object and the paths of the fields which differ are listed, e.g.
+// spec.capacity.maxSessions, or "." when the objects cannot be compared
+// field by field. The Kptfile is not compared as Porch maintains it.
+func Diff(intended, published map[string]string) []status.FileDrift {
+	names := make([]string, 0, len(intended)+len(published))
+	for name := range intended {
+		names = append(names, name)
+	}
+	for name := range published {
+		if _, ok := intended[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var files []status.FileDrift
+	for _, name := range names {
+		if name == packageservice.KptfileName {
+			continue
+		}
+		want, inIntended := intended[name]
+		got, inPublished := published[name]
+		switch {
+		case !inPublished:
+			files = append(files, status.FileDrift{Name: name, Missing: true})
+		case !inIntended:
+			files = append(files, status.FileDrift{Name: name, Unexpected: true})
+		default:
+			if fields := diffFile(want, got); len(fields) != 0 {
+				files = append(files, status.FileDrift{Name: name, Fields: fields})
+			}
+		}
+	}
+	return files
+}
+
+// diffFile returns the paths of the fields of the objects of the file which
+// differ
+func diffFile(want, got string) []string {
+	if want == got {
+		return nil
+	}
+	wantObjects, err := parseObjects(want)
+	if err != nil {
+		return []string{"."}
+	}
+	gotObjects, err := parseObjects(got)
+	if err != nil || len(wantObjects) != len(gotObjects) {
+		return []string{"."}
+	}
+	var fields []string
+	for i := range wantObjects {
+		prefix := ""
+		if len(wantObjects) > 1 {
+			prefix = fmt.Sprintf("[%d]", i)
+		}
+		fields = append(fields, diffValues(prefix, wantObjects[i], gotObjects[i])...)
+	}
+	return fields
+}
+
+func parseObjects(content string) ([]map[string]interface{}, error) {
+	nodes, err := util.ParseStringToYamlNode(content)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]map[string]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		object, err := node.Map()
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// diffValues returns the paths below path of the fields which differ
+// between want and got. Lists of different lengths are reported as a whole.
+func diffValues(path string, want, got interface{}) []string {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return []string{fieldPath(path)}
+		}
+		keys := make([]string, 0, len(w)+len(g))
+		for key := range w {
+			keys = append(keys, key)
+		}
+		for key := range g {
+			if _, ok := w[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		var fields []string
+		for _, key := range keys {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			fields = append(fields, diffValues(child, w[key], g[key])...)
+		}
+		return fields
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(w) != len(g) {
+			return []string{fieldPath(path)}
+		}
+		var fields []string
+		for i := range w {
+			fields = append(fields, diffValues(fmt.Sprintf("%s[%d]", path, i), w[i], g[i])...)
+		}
+		return fields
+	default:
+		if !reflect.DeepEqual(want, got) {
+			return []string{fieldPath(path)}
+		}
+		return nil
+	}
+}
+
+func fieldPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
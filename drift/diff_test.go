@@ -0,0 +1,100 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nephio-project/nf-deploy-controller/drift"
+	"github.com/nephio-project/nf-deploy-controller/status"
+)
+
+const upfDeploy = `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata:
+  name: upf-1
+spec:
+  capacity:
+    maxSessions: 1000
+  n3Interfaces:
+  - name: n3
+    ipAddr: 10.0.0.1/24
+`
+
+var _ = Describe("Diff", func() {
+	It("Should not report equal objects formatted differently", func() {
+		published := `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata: {name: upf-1}
+spec:
+  n3Interfaces:
+    - {ipAddr: 10.0.0.1/24, name: n3}
+  capacity: {maxSessions: 1000}
+`
+		Expect(drift.Diff(
+			map[string]string{"upf.yaml": upfDeploy, "Kptfile": "kind: Kptfile"},
+			map[string]string{"upf.yaml": published, "Kptfile": "kind: Kptfile\ninfo: {}"},
+		)).To(BeEmpty())
+	})
+
+	It("Should report the fields which differ", func() {
+		published := `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfDeploy
+metadata:
+  name: upf-1
+  labels:
+    edited: "true"
+spec:
+  capacity:
+    maxSessions: 2000
+  n3Interfaces:
+  - name: n3
+    ipAddr: 10.0.0.2/24
+`
+		Expect(drift.Diff(
+			map[string]string{"upf.yaml": upfDeploy},
+			map[string]string{"upf.yaml": published},
+		)).To(Equal([]status.FileDrift{
+			{
+				Name: "upf.yaml",
+				Fields: []string{
+					"metadata.labels",
+					"spec.capacity.maxSessions",
+					"spec.n3Interfaces[0].ipAddr",
+				},
+			},
+		}))
+	})
+
+	It("Should report the missing and unexpected files", func() {
+		Expect(drift.Diff(
+			map[string]string{"upf.yaml": upfDeploy},
+			map[string]string{"smf.yaml": upfDeploy},
+		)).To(Equal([]status.FileDrift{
+			{Name: "smf.yaml", Unexpected: true},
+			{Name: "upf.yaml", Missing: true},
+		}))
+	})
+
+	It("Should report an unparsable file as a whole", func() {
+		Expect(drift.Diff(
+			map[string]string{"upf.yaml": upfDeploy},
+			map[string]string{"upf.yaml": "spec: [\n"},
+		)).To(Equal([]status.FileDrift{{Name: "upf.yaml", Fields: []string{"."}}}))
+	})
+})
@@ -21,77 +21,133 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/ipam"
 	"github.com/nephio-project/nf-deploy-controller/hydration/nftypehydration"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
 	"github.com/nephio-project/nf-deploy-controller/hydration/utils"
 	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
 	nfdeployutil "github.com/nephio-project/nf-deploy-controller/util"
 )
 
-var (
-	upfHydration, smfHydration, ausfHydration, udmHydration nftypehydration.NfTypeHydrationInterface
-)
-
 // HydrationInterface is the interface that wraps the steps involved in
 // hydration of a NFDeploy into the individual NFType deploys and all other
 // supporting manifests like operators required to meet the intent of NFDeploy.
 type HydrationInterface interface {
-	Hydrate(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy) ([]string, error)
+	Hydrate(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy) ([]string, *deployv1alpha1.ProfilesStatus, error)
+	HydrateSites(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy, siteIds []string) ([]string, *deployv1alpha1.ProfilesStatus, error)
+	Render(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy) (map[string]map[string]string, error)
 	CreateNFDeployActuators(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy) ([]string, error)
+	ReleaseAllocations(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy) error
 }
 
 type Hydration struct {
 	PS  ps.PackageServiceInterface
 	Log logr.Logger
+	// Allocator allocates the interface addresses of the sites with
+	// IPAddrBlock. Sites without IPAddrBlock use the InterfaceConfig addresses.
+	Allocator ipam.AllocatorInterface
 }
 
-func (h *Hydration) initHydration() {
-	upfHydration = &nftypehydration.UpfDeployImpl{
-		PS:  h.PS,
-		Log: h.Log,
-	}
-	smfHydration = &nftypehydration.SmfDeployImpl{
-		PS:  h.PS,
-		Log: h.Log,
-	}
-	ausfHydration = &nftypehydration.AusfDeployImpl{
-		PS:  h.PS,
-		Log: h.Log,
-	}
-	udmHydration = &nftypehydration.UdmDeployImpl{
-		PS:  h.PS,
-		Log: h.Log,
+// nfTypeHydrations are the hydration implementations of a rendering by NF type
+type nfTypeHydrations map[string]nftypehydration.NfTypeHydrationInterface
+
+// initHydration returns the hydration implementations reading the NF profiles
+// through psi and the interface addresses through allocator
+func (h *Hydration) initHydration(psi ps.PackageServiceInterface,
+	allocator ipam.AllocatorInterface) nfTypeHydrations {
+	return nfTypeHydrations{
+		utils.UPFKind: &nftypehydration.UpfDeployImpl{
+			PS:        psi,
+			Log:       h.Log,
+			Allocator: allocator,
+		},
+		utils.SMFKind: &nftypehydration.SmfDeployImpl{
+			PS:        psi,
+			Log:       h.Log,
+			Allocator: allocator,
+		},
+		utils.AUSFKind: &nftypehydration.AusfDeployImpl{
+			PS:  psi,
+			Log: h.Log,
+		},
+		utils.UDMKind: &nftypehydration.UdmDeployImpl{
+			PS:  psi,
+			Log: h.Log,
+		},
 	}
 }
 
 // Hydrate hydrates the given nfDeploy and generates the NfTypeDeploy (like UpfDeploy, SmfDeploy)
-// and creates the packages of generated artifacts using packageservice
-func (h *Hydration) Hydrate(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy) ([]string, error) {
+// and creates the packages of generated artifacts using packageservice. It also returns the NF
+// profile objects read to generate them.
+func (h *Hydration) Hydrate(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy) ([]string, *deployv1alpha1.ProfilesStatus, error) {
 	h.Log.Info("Starting Hydration", "nfDeployName", nfDeploy.Name)
-	h.initHydration()
-	packageContents := make(map[string]map[string]string)
-	errSiteIDs := []string{}
+	packageContents, profiles, err := h.render(ctx, nfDeploy, h.Allocator, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	names, err := h.createDeployPackages(ctx, nfDeploy, packageContents)
+	if err != nil {
+		return nil, nil, err
+	}
+	h.Log.Info("Hydration Successful", "nfDeployName", nfDeploy.Name)
+	return names, profiles, nil
+}
+
+// HydrateSites hydrates the sites of the given nfDeploy with the given ids like Hydrate, e.g. the
+// released sites of a rollout. Their peers and capacities are resolved from all the sites of
+// nfDeploy. Only the packages of their clusters are created, in which the other sites keep the
+// content of the latest published revision.
+func (h *Hydration) HydrateSites(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy,
+	siteIds []string) ([]string, *deployv1alpha1.ProfilesStatus, error) {
+	h.Log.Info("Starting Hydration", "nfDeployName", nfDeploy.Name, "sites", siteIds)
+	hydrated := make(map[string]bool, len(siteIds))
+	for _, siteId := range siteIds {
+		hydrated[siteId] = true
+	}
+	packageContents, profiles, err := h.render(ctx, nfDeploy, h.Allocator, hydrated)
+	if err != nil {
+		return nil, nil, err
+	}
+	published := make(map[string]map[string]string)
 	for _, s := range nfDeploy.Spec.Sites {
-		h.Log.Info("Processing site", "nfDeployName", nfDeploy.Name, "siteID", s.Id)
-		content, err := h.processSite(ctx, s, nfDeploy.Name)
-		if err != nil {
-			// We are logging the actual error here as only siteIDs are returned to parent function
-			h.Log.Error(err, "Error processing site", "nfDeployName", nfDeploy.Name, "siteID", s.Id)
-			errSiteIDs = append(errSiteIDs, s.Id)
+		contents, ok := packageContents[s.ClusterName]
+		if hydrated[s.Id] || !ok {
 			continue
 		}
-		m, ok := packageContents[s.ClusterName]
+		resources, ok := published[s.ClusterName]
 		if !ok {
-			m = make(map[string]string)
+			nc, err := nfdeployutil.NewNamingContext(s.ClusterName, nfDeploy.Name)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error creating naming context: %w", err)
+			}
+			_, resources, _, err = h.PS.GetPublishedDeployPackage(ctx, nc)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error getting published package of cluster: %s, err: %w",
+					s.ClusterName, err)
+			}
+			published[s.ClusterName] = resources
+		}
+		fileName := fmt.Sprintf(utils.OpFileName, nfDeploy.Name, s.Id)
+		if content, ok := resources[fileName]; ok {
+			contents[fileName] = content
 		}
-		m[fmt.Sprintf(utils.OpFileName, nfDeploy.Name, s.Id)] = string(content)
-		packageContents[s.ClusterName] = m
-		h.Log.Info("Processed site successfully", "nfDeployName", nfDeploy.Name, "siteID", s.Id)
 	}
-	if len(errSiteIDs) > 0 {
-		return nil, fmt.Errorf("error hydrating sites: %v", errSiteIDs)
+	names, err := h.createDeployPackages(ctx, nfDeploy, packageContents)
+	if err != nil {
+		return nil, nil, err
 	}
+	h.Log.Info("Hydration Successful", "nfDeployName", nfDeploy.Name, "sites", siteIds)
+	return names, profiles, nil
+}
+
+// createDeployPackages creates the deploy package of each cluster of packageContents and returns
+// the names of the created packages
+func (h *Hydration) createDeployPackages(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy,
+	packageContents map[string]map[string]string) ([]string, error) {
 	names := []string{}
 	for cluster, val := range packageContents {
 		nc, err := nfdeployutil.NewNamingContext(cluster, nfDeploy.Name)
@@ -105,10 +161,69 @@ func (h *Hydration) Hydrate(ctx context.Context, nfDeploy deployv1alpha1.NfDeplo
 		names = append(names, n)
 		h.Log.Info("Created porch package", "name", n, "nfDeployName", nfDeploy.Name)
 	}
-	h.Log.Info("Hydration Successful", "nfDeployName", nfDeploy.Name)
 	return names, nil
 }
 
+// Render generates the NfTypeDeploy of each site of the given nfDeploy and returns the content
+// of the deploy package of each cluster, keyed by cluster name and then by file name. No package
+// is created and no interface address is allocated, so that the result can be compared to the
+// published packages.
+func (h *Hydration) Render(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy) (map[string]map[string]string, error) {
+	packageContents, _, err := h.render(ctx, nfDeploy, ipam.ReadOnly(h.Allocator), nil)
+	return packageContents, err
+}
+
+// render returns the content of the deploy package of each cluster like Render, with the
+// interface addresses of allocator, and the NF profile objects read to generate it. Only the
+// sites in sites are rendered, unless sites is nil.
+func (h *Hydration) render(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy,
+	allocator ipam.AllocatorInterface, sites map[string]bool) (map[string]map[string]string, *deployv1alpha1.ProfilesStatus, error) {
+	recorder, err := newProfileRecorder(ctx, h.PS, nfDeploy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching NF profiles: %w", err)
+	}
+	hydrations := h.initHydration(recorder, allocator)
+	sitePeers, err := h.getSitePeers(ctx, hydrations, nfDeploy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving site connectivities: %w", err)
+	}
+	plmns := utils.GetPlmns(nfDeploy.Spec)
+	siteCapacities := utils.SplitCapacity(nfDeploy.Spec)
+	packageContents := make(map[string]map[string]string)
+	errSiteIDs := []string{}
+	for _, s := range nfDeploy.Spec.Sites {
+		if sites != nil && !sites[s.Id] {
+			continue
+		}
+		h.Log.Info("Processing site", "nfDeployName", nfDeploy.Name, "siteID", s.Id)
+		content, err := h.processSite(ctx, hydrations, s, nftypehydration.SiteDeployInput{
+			NfDeployName:       nfDeploy.Name,
+			NfDeployNamespace:  nfDeploy.Namespace,
+			NfDeployGeneration: nfDeploy.Generation,
+			Peers:              sitePeers[s.Id],
+			Plmns:              plmns,
+			Capacity:           siteCapacities[s.Id],
+		})
+		if err != nil {
+			// We are logging the actual error here as only siteIDs are returned to parent function
+			h.Log.Error(err, "Error processing site", "nfDeployName", nfDeploy.Name, "siteID", s.Id)
+			errSiteIDs = append(errSiteIDs, s.Id)
+			continue
+		}
+		m, ok := packageContents[s.ClusterName]
+		if !ok {
+			m = make(map[string]string)
+		}
+		m[fmt.Sprintf(utils.OpFileName, nfDeploy.Name, s.Id)] = string(content)
+		packageContents[s.ClusterName] = m
+		h.Log.Info("Processed site successfully", "nfDeployName", nfDeploy.Name, "siteID", s.Id)
+	}
+	if len(errSiteIDs) > 0 {
+		return nil, nil, fmt.Errorf("error hydrating sites: %v", errSiteIDs)
+	}
+	return packageContents, recorder.profiles(), nil
+}
+
 // For each unique vendor, version and nfType in the NFDeploy, creates
 // a package in the respective edge deploy repo of the cluster. The package
 // contains the operators required for the NFDeploy to be actuated on the
@@ -157,24 +272,89 @@ func (h *Hydration) CreateNFDeployActuators(ctx context.Context, nfDeploy deploy
 	return pkgNamesForApproval, nil
 }
 
+// getSitePeers resolves the connectivities of each site to the interfaces of
+// its neighbors on the reference point between them. It returns the peers of
+// each site with siteID as key.
+func (h *Hydration) getSitePeers(ctx context.Context, hydrations nfTypeHydrations,
+	nfDeploy deployv1alpha1.NfDeploy) (map[string][]types.Peer, error) {
+	sites := make(map[string]deployv1alpha1.Site)
+	for _, s := range nfDeploy.Spec.Sites {
+		sites[s.Id] = s
+	}
+	// caches the interfaces of sites which are neighbors of more than one site
+	siteInterfaces := make(map[string]map[deployv1alpha1.ReferencePoint][]types.NetworkInterface)
+	resp := make(map[string][]types.Peer)
+	for _, s := range nfDeploy.Spec.Sites {
+		for _, c := range s.Connectivities {
+			neighbor, ok := sites[c.NeighborName]
+			if !ok {
+				return nil, fmt.Errorf("neighbor %s of site %s is not present", c.NeighborName, s.Id)
+			}
+			referencePoint, ok := deployv1alpha1.DefaultConnectivityMatrix.ReferencePoint(
+				deployv1alpha1.NFType(s.NFType), deployv1alpha1.NFType(neighbor.NFType))
+			if !ok {
+				return nil, fmt.Errorf("no reference point exists between site %s (%s) and site %s (%s)",
+					s.Id, s.NFType, neighbor.Id, neighbor.NFType)
+			}
+			interfaces, ok := siteInterfaces[neighbor.Id]
+			if !ok {
+				nfHydration, err := hydrations.get(neighbor.NFType)
+				if err != nil {
+					return nil, err
+				}
+				interfaces, err = nfHydration.GetNetworkInterfaces(ctx, neighbor, k8stypes.NamespacedName{
+					Namespace: nfDeploy.Namespace, Name: nfDeploy.Name,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("error getting network interfaces of site %s: %w", neighbor.Id, err)
+				}
+				siteInterfaces[neighbor.Id] = interfaces
+			}
+			resp[s.Id] = append(resp[s.Id], types.Peer{
+				SiteID:         neighbor.Id,
+				NFType:         neighbor.NFType,
+				ReferencePoint: string(referencePoint),
+				Interfaces:     interfaces[referencePoint],
+			})
+		}
+	}
+	return resp, nil
+}
+
+// get returns the hydration implementation for the nfType
+func (n nfTypeHydrations) get(nfType string) (nftypehydration.NfTypeHydrationInterface, error) {
+	nfHydration, ok := n[nfType]
+	if !ok {
+		return nil, fmt.Errorf("invalid NfType:%s", nfType)
+	}
+	return nfHydration, nil
+}
+
 // processSite processes each site from nfDeploy
-func (h *Hydration) processSite(ctx context.Context, s deployv1alpha1.Site, nfDeployName string) ([]byte, error) {
-	var nfHydration nftypehydration.NfTypeHydrationInterface
-	switch s.NFType {
-	case utils.UPFKind:
-		nfHydration = upfHydration
-	case utils.SMFKind:
-		nfHydration = smfHydration
-	case utils.AUSFKind:
-		nfHydration = ausfHydration
-	case utils.UDMKind:
-		nfHydration = udmHydration
-	default:
-		return nil, fmt.Errorf("invalid NfType:%s", s.NFType)
-	}
-	content, err := nfHydration.GenerateNfTypeDeploy(ctx, s, nfDeployName)
+func (h *Hydration) processSite(ctx context.Context, hydrations nfTypeHydrations, s deployv1alpha1.Site,
+	in nftypehydration.SiteDeployInput) ([]byte, error) {
+	nfHydration, err := hydrations.get(s.NFType)
+	if err != nil {
+		return nil, err
+	}
+	content, err := nfHydration.GenerateNfTypeDeploy(ctx, s, in)
 	if err != nil {
 		return nil, fmt.Errorf("error generating nftypedeploy: %w", err)
 	}
+	content, err = applySiteOverrides(s, content)
+	if err != nil {
+		return nil, fmt.Errorf("error applying overrides: %w", err)
+	}
 	return content, nil
 }
+
+// ReleaseAllocations releases the interface addresses allocated to the sites
+// of the given nfDeploy during hydration
+func (h *Hydration) ReleaseAllocations(ctx context.Context, nfDeploy deployv1alpha1.NfDeploy) error {
+	if h.Allocator == nil {
+		return nil
+	}
+	return h.Allocator.Release(ctx, k8stypes.NamespacedName{
+		Namespace: nfDeploy.Namespace, Name: nfDeploy.Name,
+	})
+}
@@ -21,11 +21,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
@@ -45,6 +47,7 @@ const (
 var (
 	upfTypeSmall, upfcp, upfDeploy1    []byte
 	smfTypeSmall, smfcp, smfDeploy1    []byte
+	upfDeployPeers, smfDeployPeers     []byte
 	ausfcp, ausfDeploy1                []byte
 	udmcp, udmDeploy1                  []byte
 	interfaceConfig1, interfaceConfig2 []byte
@@ -239,6 +242,8 @@ var _ = Describe("Hydration", func() {
 	smfDeploy1, _ = os.ReadFile("testhelper/smfdeploy1.yaml")
 	ausfDeploy1, _ = os.ReadFile("testhelper/ausfdeploy1.yaml")
 	udmDeploy1, _ = os.ReadFile("testhelper/udmdeploy1.yaml")
+	upfDeployPeers, _ = os.ReadFile("testhelper/upfdeploy1withpeers.yaml")
+	smfDeployPeers, _ = os.ReadFile("testhelper/smfdeploy1withpeers.yaml")
 	ip41, _ = os.ReadFile("testhelper/interfaceprofile41.yaml")
 	ip71, _ = os.ReadFile("testhelper/interfaceprofile71.yaml")
 	ip101, _ = os.ReadFile("testhelper/interfaceprofile101.yaml")
@@ -248,6 +253,8 @@ var _ = Describe("Hydration", func() {
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		mpsi = mps.NewMockPackageServiceInterface(mockCtrl)
+		mpsi.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "").
+			Return("nf-profiles-v1", map[string]string{}, nil).AnyTimes()
 		h = &hydration.Hydration{
 			PS:  mpsi,
 			Log: ctrl.Log.WithName("Hydration"),
@@ -273,17 +280,36 @@ var _ = Describe("Hydration", func() {
 				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "upf1"): string(upfDeploy1),
 				}), gomock.Eq(nc)).Return("resourceName", nil).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(n)).To(Equal(1))
 				Expect(n[0]).To(Equal("resourceName"))
 			})
+			It("should return the NF profile objects read to process the upf", func() {
+				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Any(), gomock.Eq(nc)).
+					Return("resourceName", nil).Times(1)
+				_, profiles, err := h.Hydrate(ctx, nfDeploy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(profiles.Revision).To(Equal("nf-profiles-v1"))
+				keys := []string{}
+				for _, object := range profiles.Objects {
+					Expect(object.Hash).To(HaveLen(64))
+					keys = append(keys, object.Kind+"/"+object.Name)
+				}
+				Expect(keys).To(Equal([]string{
+					"InterfaceConfig/interfaceConfig1",
+					"InterfaceConfig/interfaceConfig2",
+					"NfBgpConfig/NfBgpConfig1",
+					"UpfCapacityProfile/upfCapacityProfile1",
+					"UpfType/upfsmall",
+				}))
+			})
 			It("should process a single upf and return an error while creating Deploy Package", func() {
 				expectedErr := errors.New("error from porch")
 				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "upf1"): string(upfDeploy1),
 				}), gomock.Eq(nc)).Return("", expectedErr).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
 				Expect(n).To(BeNil())
@@ -299,7 +325,7 @@ var _ = Describe("Hydration", func() {
 						Name:       "upfsmall",
 					},
 				}), gomock.Eq(nc)).Return(nil, errors.New("error from porch")).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("error hydrating sites: [upf1]"))
 				Expect(n).To(BeNil())
@@ -322,7 +348,7 @@ var _ = Describe("Hydration", func() {
 				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "smf1"): string(smfDeploy1),
 				}), gomock.Eq(nc)).Return("resourceName", nil).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(n)).To(Equal(1))
 				Expect(n[0]).To(Equal("resourceName"))
@@ -332,7 +358,7 @@ var _ = Describe("Hydration", func() {
 				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "smf1"): string(smfDeploy1),
 				}), gomock.Eq(nc)).Return("", expectedErr).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
 				Expect(n).To(BeNil())
@@ -348,7 +374,7 @@ var _ = Describe("Hydration", func() {
 						Name:       "smfsmall",
 					},
 				}), gomock.Eq(nc)).Return(nil, errors.New("error from porch")).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("error hydrating sites: [smf1]"))
 				Expect(n).To(BeNil())
@@ -363,7 +389,7 @@ var _ = Describe("Hydration", func() {
 			})
 			It("should return an error for invalid NfType", func() {
 				expectedErr := errors.New("error hydrating sites: [invalid1]")
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(Equal(expectedErr))
 				Expect(n).To(BeNil())
@@ -393,12 +419,114 @@ var _ = Describe("Hydration", func() {
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "upf1"): string(upfDeploy1),
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "smf1"): string(smfDeploy1),
 				}), gomock.Eq(nc)).Return("resourceName", nil).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(n)).To(Equal(1))
+				Expect(n[0]).To(Equal("resourceName"))
+			})
+			It("should render the package content of the cluster without creating the package", func() {
+				contents, err := h.Render(ctx, nfDeploy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(contents).To(Equal(map[string]map[string]string{
+					clusterName: {
+						fmt.Sprintf(expectedFileFormat, nfDeployName, "upf1"): string(upfDeploy1),
+						fmt.Sprintf(expectedFileFormat, nfDeployName, "smf1"): string(smfDeploy1),
+					},
+				}))
+			})
+		})
+	})
+
+	Describe("Testing NfDeploy Hydration for some of the sites of a cluster", func() {
+		nfDeploy := getNfDeployForSites([]deployv1alpha1.Site{
+			getSite("upf1", "upf", "upfsmall"),
+			getSite("smf1", "smf", "smfsmall"),
+		})
+		Context("testing hydration of the upf site only", func() {
+			BeforeEach(func() {
+				expectUpfType(mpsi)
+				expectReferencedProfiles(mpsi)
+				expectUpfCapacityProfile(mpsi)
+				expectGetVendorExtnPkg(mpsi, nfDeploy.Spec.Sites[0], []string{})
+			})
+			It("should keep the published content of the smf site in the package", func() {
+				smfFile := fmt.Sprintf(expectedFileFormat, nfDeployName, "smf1")
+				mpsi.EXPECT().GetPublishedDeployPackage(gomock.Any(), gomock.Eq(nc)).
+					Return("deployPkg1", map[string]string{
+						"Kptfile": "kind: Kptfile",
+						smfFile:   string(smfDeploy1),
+					}, false, nil).Times(1)
+				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
+					fmt.Sprintf(expectedFileFormat, nfDeployName, "upf1"): string(upfDeploy1),
+					smfFile: string(smfDeploy1),
+				}), gomock.Eq(nc)).Return("resourceName", nil).Times(1)
+				n, _, err := h.HydrateSites(ctx, nfDeploy, []string{"upf1"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(n).To(Equal([]string{"resourceName"}))
+			})
+			It("should return an error when the published package cannot be read", func() {
+				mpsi.EXPECT().GetPublishedDeployPackage(gomock.Any(), gomock.Eq(nc)).
+					Return("", nil, false, errors.New("error from porch")).Times(1)
+				n, _, err := h.HydrateSites(ctx, nfDeploy, []string{"upf1"})
+				Expect(err).To(HaveOccurred())
+				Expect(n).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Testing NfDeploy Hydration for connected upf and smf sites", func() {
+		upfSite := getSite("upf1", "upf", "upfsmall")
+		upfSite.Connectivities = []deployv1alpha1.Connectivity{{NeighborName: "smf1"}}
+		smfSite := getSite("smf1", "smf", "smfsmall")
+		smfSite.Connectivities = []deployv1alpha1.Connectivity{{NeighborName: "upf1"}}
+		nfDeploy := getNfDeployForSites([]deployv1alpha1.Site{upfSite, smfSite})
+		Context("testing hydration with N4 connectivity between upf and smf", func() {
+			BeforeEach(func() {
+				// resolving the interfaces of neighbors
+				expectSmfType(mpsi)
+				expectReferencedProfiles(mpsi)
+				expectInterfaceProfile(mpsi)
+				expectUpfType(mpsi)
+				expectReferencedProfiles(mpsi)
+
+				expectUpfType(mpsi)
+				expectReferencedProfiles(mpsi)
+				expectUpfCapacityProfile(mpsi)
+				expectGetVendorExtnPkg(mpsi, nfDeploy.Spec.Sites[0], []string{})
+
+				expectSmfType(mpsi)
+				expectReferencedProfiles(mpsi)
+				expectSmfCapacityProfile(mpsi)
+				expectInterfaceProfile(mpsi)
+			})
+			It("should inject the N4 interfaces of neighbors as peers", func() {
+				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
+					fmt.Sprintf(expectedFileFormat, nfDeployName, "upf1"): string(upfDeployPeers),
+					fmt.Sprintf(expectedFileFormat, nfDeployName, "smf1"): string(smfDeployPeers),
+				}), gomock.Eq(nc)).Return("resourceName", nil).Times(1)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(n)).To(Equal(1))
 				Expect(n[0]).To(Equal("resourceName"))
 			})
 		})
+		Context("expecting error from porch while resolving neighbor interfaces", func() {
+			It("should return an error", func() {
+				mpsi.EXPECT().GetNFProfiles(gomock.Any(), gomock.Eq([]ps.GetResourceRequest{
+					{
+						ID:         1,
+						ApiVersion: hydrationutil.IpAPIVersion,
+						Kind:       "SmfType",
+						Name:       "smfsmall",
+					},
+				}), gomock.Eq(nc)).Return(nil, errors.New("error from porch")).Times(1)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("error resolving site connectivities"))
+				Expect(err.Error()).To(HaveSuffix("error from porch"))
+				Expect(n).To(BeNil())
+			})
+		})
 	})
 
 	Describe("Testing NfDeploy Hydration for single ausf site", func() {
@@ -413,7 +541,7 @@ var _ = Describe("Hydration", func() {
 				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "ausf1"): string(ausfDeploy1),
 				}), gomock.Eq(nc)).Return("resourceName", nil).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(n)).To(Equal(1))
 				Expect(n[0]).To(Equal("resourceName"))
@@ -423,7 +551,7 @@ var _ = Describe("Hydration", func() {
 				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "ausf1"): string(ausfDeploy1),
 				}), gomock.Eq(nc)).Return("", expectedErr).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
 				Expect(n).To(BeNil())
@@ -431,6 +559,47 @@ var _ = Describe("Hydration", func() {
 		})
 	})
 
+	Describe("Testing NfDeploy Hydration for single ausf site with overrides", func() {
+		nfDeploy := getNfDeployForSites([]deployv1alpha1.Site{
+			getSite("ausf1", "ausf", "ausfsmall"),
+		})
+		BeforeEach(func() {
+			expectAusfCapacityProfile(mpsi)
+		})
+		Context("testing valid overrides", func() {
+			It("should apply the overrides and record them in the package", func() {
+				nfDeploy.Spec.Sites[0].Overrides = &deployv1alpha1.SiteOverrides{
+					Merge: &runtime.RawExtension{Raw: []byte(`{"spec":{"capacityProfile":{"requestedCpu":8}}}`)},
+				}
+				var content map[string]string
+				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Any(), gomock.Eq(nc)).DoAndReturn(
+					func(_ context.Context, c map[string]string, _ nfdeployutil.NamingContext) (string, error) {
+						content = c
+						return "resourceName", nil
+					}).Times(1)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(n).To(Equal([]string{"resourceName"}))
+				docs := strings.Split(content[fmt.Sprintf(expectedFileFormat, nfDeployName, "ausf1")], "---\n")
+				Expect(docs).To(HaveLen(2))
+				Expect(docs[0]).To(ContainSubstring("requestedCpu: 8"))
+				Expect(docs[0]).To(ContainSubstring("requestedMemory: 256"))
+				Expect(docs[1]).To(ContainSubstring("name: ausfdeploy-ausf1-overrides"))
+				Expect(docs[1]).To(ContainSubstring("target: AusfDeploy/ausfdeploy-ausf1"))
+			})
+		})
+		Context("testing overrides invalid for AusfDeploy", func() {
+			It("should return an error", func() {
+				nfDeploy.Spec.Sites[0].Overrides = &deployv1alpha1.SiteOverrides{
+					Merge: &runtime.RawExtension{Raw: []byte(`{"spec":{"n4Interfaces":[]}}`)},
+				}
+				n, _, err := h.Hydrate(ctx, nfDeploy)
+				Expect(err).To(HaveOccurred())
+				Expect(n).To(BeNil())
+			})
+		})
+	})
+
 	Describe("Testing NfDeploy Hydration for single udm site", func() {
 		nfDeploy := getNfDeployForSites([]deployv1alpha1.Site{
 			getSite("udm1", "udm", "udmsmall"),
@@ -443,7 +612,7 @@ var _ = Describe("Hydration", func() {
 				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "udm1"): string(udmDeploy1),
 				}), gomock.Eq(nc)).Return("resourceName", nil).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(n)).To(Equal(1))
 				Expect(n[0]).To(Equal("resourceName"))
@@ -453,7 +622,7 @@ var _ = Describe("Hydration", func() {
 				mpsi.EXPECT().CreateDeployPackage(gomock.Any(), gomock.Eq(map[string]string{
 					fmt.Sprintf(expectedFileFormat, nfDeployName, "udm1"): string(udmDeploy1),
 				}), gomock.Eq(nc)).Return("", expectedErr).Times(1)
-				n, err := h.Hydrate(ctx, nfDeploy)
+				n, _, err := h.Hydrate(ctx, nfDeploy)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
 				Expect(n).To(BeNil())
@@ -0,0 +1,429 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultAllocationsConfigMapName is the prefix of the names of the
+// ConfigMaps which hold the interface address allocations of the NfDeploys
+const DefaultAllocationsConfigMapName = "nfdeploy-ip-allocations"
+
+// Allocation is the address allocated to an interface of a site
+type Allocation struct {
+	SiteID      string `json:"siteId"`
+	InterfaceID int    `json:"interfaceId"`
+	// IPAddr is in CIDR notation with the prefix length of the block it was
+	// allocated from, for e.g. 10.10.0.2/24
+	IPAddr string `json:"ipAddr"`
+}
+
+// AllocatorInterface allocates the interface addresses of NfDeploy sites
+// from the sites' IP address blocks
+type AllocatorInterface interface {
+	// Allocate returns an address for each of the interfaceIDs of the site,
+	// keyed by interface ID. An address allocated earlier to an interface is
+	// returned again as long as it still belongs to one of the blocks. New
+	// addresses never conflict with the addresses allocated to any other
+	// interface or with the reserved addresses.
+	Allocate(
+		ctx context.Context, nfDeploy types.NamespacedName, siteID string,
+		blocks []string, interfaceIDs []int, reserved []string,
+	) (map[int]string, error)
+	// Lookup returns the addresses Allocate would return, without persisting
+	// them
+	Lookup(
+		ctx context.Context, nfDeploy types.NamespacedName, siteID string,
+		blocks []string, interfaceIDs []int, reserved []string,
+	) (map[int]string, error)
+	// Release releases all the addresses allocated for the NfDeploy
+	Release(ctx context.Context, nfDeploy types.NamespacedName) error
+}
+
+// ReadOnly returns an allocator which looks up the addresses of allocator
+// without persisting new allocations, for renderings which are only compared
+// to the published packages. It returns nil if allocator is nil.
+func ReadOnly(allocator AllocatorInterface) AllocatorInterface {
+	if allocator == nil {
+		return nil
+	}
+	return readOnlyAllocator{allocator}
+}
+
+type readOnlyAllocator struct {
+	AllocatorInterface
+}
+
+// Allocate implements AllocatorInterface with Lookup
+func (r readOnlyAllocator) Allocate(
+	ctx context.Context, nfDeploy types.NamespacedName, siteID string,
+	blocks []string, interfaceIDs []int, reserved []string,
+) (map[int]string, error) {
+	return r.Lookup(ctx, nfDeploy, siteID, blocks, interfaceIDs, reserved)
+}
+
+// Release implements AllocatorInterface and releases nothing
+func (r readOnlyAllocator) Release(ctx context.Context, nfDeploy types.NamespacedName) error {
+	return nil
+}
+
+// ConfigMapAllocator implements AllocatorInterface and persists the
+// allocations of each NfDeploy in a ConfigMap of its own, so that
+// re-hydration of a NfDeploy hands out the same addresses and no ConfigMap
+// grows with the number of NfDeploys. The ConfigMaps are named
+// <Name>.<namespace>.<name> and labelled with AllocationsLabel set to Name.
+// The allocations are kept under the key <namespace>.<name>, as ConfigMap
+// keys can not contain '/' and namespaces can not contain '.'.
+// The allocations of all the NfDeploys are read to allocate new addresses,
+// so the allocations are serialized in the controller, and retried when the
+// ConfigMap of the NfDeploy changed in the meantime.
+type ConfigMapAllocator struct {
+	Client    client.Client
+	Namespace string
+	Name      string
+	Log       logr.Logger
+
+	mu sync.Mutex
+}
+
+// AllocationsLabel is the label of the ConfigMaps of the allocations, set to
+// the Name of the ConfigMapAllocator
+const AllocationsLabel = "nfdeploy.nephio.org/ip-allocations"
+
+var _ AllocatorInterface = &ConfigMapAllocator{}
+
+// Allocate implements AllocatorInterface
+func (a *ConfigMapAllocator) Allocate(
+	ctx context.Context, nfDeploy types.NamespacedName, siteID string,
+	blocks []string, interfaceIDs []int, reserved []string,
+) (map[int]string, error) {
+	return a.allocate(ctx, nfDeploy, siteID, blocks, interfaceIDs, reserved, true)
+}
+
+// Lookup implements AllocatorInterface
+func (a *ConfigMapAllocator) Lookup(
+	ctx context.Context, nfDeploy types.NamespacedName, siteID string,
+	blocks []string, interfaceIDs []int, reserved []string,
+) (map[int]string, error) {
+	return a.allocate(ctx, nfDeploy, siteID, blocks, interfaceIDs, reserved, false)
+}
+
+// allocate returns the addresses of the interfaces of the site, and persists
+// them if persist is set. The allocation is retried on conflict.
+func (a *ConfigMapAllocator) allocate(
+	ctx context.Context, nfDeploy types.NamespacedName, siteID string,
+	blocks []string, interfaceIDs []int, reserved []string, persist bool,
+) (map[int]string, error) {
+
+	prefixes, err := parseBlocks(blocks)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var resp map[int]string
+	err = retry.OnError(retry.DefaultRetry, isConflict, func() error {
+		var err error
+		resp, err = a.tryAllocate(ctx, nfDeploy, siteID, prefixes, interfaceIDs, reserved, persist)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if persist {
+		a.Log.Info("Allocated interface addresses",
+			"nfDeploy", nfDeploy, "siteID", siteID, "addresses", resp)
+	}
+	return resp, nil
+}
+
+// tryAllocate returns the addresses of the interfaces of the site given the
+// current allocations, and persists them if persist is set
+func (a *ConfigMapAllocator) tryAllocate(
+	ctx context.Context, nfDeploy types.NamespacedName, siteID string,
+	prefixes []netip.Prefix, interfaceIDs []int, reserved []string, persist bool,
+) (map[int]string, error) {
+
+	key := allocationsKey(nfDeploy)
+	cm, found, allocations, err := a.getAllocations(ctx, nfDeploy)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[netip.Addr]bool)
+	for _, r := range reserved {
+		if addr, err := parseAddr(r); err == nil {
+			used[addr] = true
+		}
+	}
+	existing := make(map[int]string)
+	for name, nfAllocations := range allocations {
+		for _, alloc := range nfAllocations {
+			if name == key && alloc.SiteID == siteID {
+				existing[alloc.InterfaceID] = alloc.IPAddr
+				continue
+			}
+			if addr, err := parseAddr(alloc.IPAddr); err == nil {
+				used[addr] = true
+			}
+		}
+	}
+
+	resp := make(map[int]string)
+	ids := append([]int{}, interfaceIDs...)
+	sort.Ints(ids)
+	// keep the earlier allocations first so that new allocations can not
+	// take their addresses
+	for _, id := range ids {
+		ipAddr, ok := existing[id]
+		if !ok {
+			continue
+		}
+		addr, err := parseAddr(ipAddr)
+		if err != nil || used[addr] || !containedIn(prefixes, addr) {
+			continue
+		}
+		used[addr] = true
+		resp[id] = ipAddr
+	}
+	for _, id := range ids {
+		if _, ok := resp[id]; ok {
+			continue
+		}
+		ipAddr, err := nextFree(prefixes, used)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error allocating address for interface %d of site %s: %w",
+				id, siteID, err,
+			)
+		}
+		resp[id] = ipAddr
+	}
+	if !persist {
+		return resp, nil
+	}
+
+	siteAllocations := make([]Allocation, 0, len(resp))
+	for _, id := range ids {
+		siteAllocations = append(siteAllocations, Allocation{
+			SiteID: siteID, InterfaceID: id, IPAddr: resp[id],
+		})
+	}
+	nfAllocations := []Allocation{}
+	for _, alloc := range allocations[key] {
+		if alloc.SiteID != siteID {
+			nfAllocations = append(nfAllocations, alloc)
+		}
+	}
+	nfAllocations = append(nfAllocations, siteAllocations...)
+	if err := a.saveConfigMap(ctx, cm, found, key, nfAllocations); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Release implements AllocatorInterface
+func (a *ConfigMapAllocator) Release(ctx context.Context, nfDeploy types.NamespacedName) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: a.Namespace, Name: a.configMapName(nfDeploy),
+	}}
+	if err := a.Client.Delete(ctx, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error releasing addresses of %s: %w", nfDeploy, err)
+	}
+	a.Log.Info("Released interface addresses", "nfDeploy", nfDeploy)
+	return nil
+}
+
+// allocationsKey returns the ConfigMap key of the allocations of the NfDeploy
+func allocationsKey(nfDeploy types.NamespacedName) string {
+	return nfDeploy.Namespace + "." + nfDeploy.Name
+}
+
+// configMapName returns the name of the ConfigMap of the allocations of the
+// NfDeploy
+func (a *ConfigMapAllocator) configMapName(nfDeploy types.NamespacedName) string {
+	return a.Name + "." + allocationsKey(nfDeploy)
+}
+
+// getAllocations returns the ConfigMap of the allocations of the NfDeploy,
+// whether it exists, and the allocations of all the NfDeploys keyed by
+// allocationsKey
+func (a *ConfigMapAllocator) getAllocations(ctx context.Context, nfDeploy types.NamespacedName) (
+	*corev1.ConfigMap, bool, map[string][]Allocation, error) {
+	cms := &corev1.ConfigMapList{}
+	if err := a.Client.List(ctx, cms, client.InNamespace(a.Namespace),
+		client.MatchingLabels{AllocationsLabel: a.Name}); err != nil {
+		return nil, false, nil, fmt.Errorf("error listing ConfigMaps %s: %w", a.Name, err)
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: a.Namespace, Name: a.configMapName(nfDeploy),
+			Labels: map[string]string{AllocationsLabel: a.Name},
+		},
+	}
+	found := false
+	allocations := make(map[string][]Allocation)
+	for i := range cms.Items {
+		cmAllocations, err := decodeAllocations(cms.Items[i].Data)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		for name, nfAllocations := range cmAllocations {
+			allocations[name] = nfAllocations
+		}
+		if cms.Items[i].Name == cm.Name {
+			cm, found = &cms.Items[i], true
+		}
+	}
+	return cm, found, allocations, nil
+}
+
+// saveConfigMap saves the allocations of the NfDeploy with the key in its
+// ConfigMap
+func (a *ConfigMapAllocator) saveConfigMap(
+	ctx context.Context, cm *corev1.ConfigMap, found bool,
+	key string, nfAllocations []Allocation,
+) error {
+	data, err := encodeAllocations(map[string][]Allocation{key: nfAllocations})
+	if err != nil {
+		return err
+	}
+	cm.Data = data
+	if found {
+		err = a.Client.Update(ctx, cm)
+	} else {
+		err = a.Client.Create(ctx, cm)
+	}
+	if err != nil {
+		return fmt.Errorf("error saving ConfigMap %s: %w", cm.Name, err)
+	}
+	return nil
+}
+
+// isConflict returns true if the ConfigMap of the allocations was changed or
+// created since it was read
+func isConflict(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+}
+
+func decodeAllocations(data map[string]string) (map[string][]Allocation, error) {
+	resp := make(map[string][]Allocation)
+	for name, value := range data {
+		var allocations []Allocation
+		if err := json.Unmarshal([]byte(value), &allocations); err != nil {
+			return nil, fmt.Errorf("error decoding allocations of %s: %w", name, err)
+		}
+		resp[name] = allocations
+	}
+	return resp, nil
+}
+
+func encodeAllocations(allocations map[string][]Allocation) (map[string]string, error) {
+	resp := make(map[string]string)
+	for name, nfAllocations := range allocations {
+		if len(nfAllocations) == 0 {
+			continue
+		}
+		value, err := json.Marshal(nfAllocations)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding allocations of %s: %w", name, err)
+		}
+		resp[name] = string(value)
+	}
+	return resp, nil
+}
+
+func parseBlocks(blocks []string) ([]netip.Prefix, error) {
+	resp := make([]netip.Prefix, len(blocks))
+	for i, block := range blocks {
+		prefix, err := netip.ParsePrefix(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ipAddrBlock %s: %w", block, err)
+		}
+		resp[i] = prefix.Masked()
+	}
+	return resp, nil
+}
+
+// parseAddr parses both plain addresses and addresses in CIDR notation
+func parseAddr(ipAddr string) (netip.Addr, error) {
+	if strings.Contains(ipAddr, "/") {
+		prefix, err := netip.ParsePrefix(ipAddr)
+		if err != nil {
+			return netip.Addr{}, err
+		}
+		return prefix.Addr(), nil
+	}
+	return netip.ParseAddr(ipAddr)
+}
+
+func containedIn(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextFree returns the first unused host address of the blocks in CIDR
+// notation and marks it used. The network address and, for IPv4, the
+// broadcast address of a block are never handed out.
+func nextFree(prefixes []netip.Prefix, used map[netip.Addr]bool) (string, error) {
+	for _, prefix := range prefixes {
+		first := prefix.Addr()
+		if prefix.Bits() < first.BitLen()-1 {
+			first = first.Next()
+		}
+		for addr := first; addr.IsValid() && prefix.Contains(addr); addr = addr.Next() {
+			if used[addr] || isBroadcast(prefix, addr) {
+				continue
+			}
+			used[addr] = true
+			return netip.PrefixFrom(addr, prefix.Bits()).String(), nil
+		}
+	}
+	return "", fmt.Errorf("no free address left in %v", prefixes)
+}
+
+func isBroadcast(prefix netip.Prefix, addr netip.Addr) bool {
+	if !addr.Is4() || prefix.Bits() >= 31 {
+		return false
+	}
+	next := addr.Next()
+	return !next.IsValid() || !prefix.Contains(next)
+}
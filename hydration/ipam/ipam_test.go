@@ -0,0 +1,243 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nephio-project/nf-deploy-controller/hydration/ipam"
+)
+
+// conflictingClient fails the given number of updates with a conflict
+type conflictingClient struct {
+	client.Client
+	conflicts int
+}
+
+func (c *conflictingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if c.conflicts > 0 {
+		c.conflicts--
+		return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(),
+			errors.New("the object has been modified"))
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+var _ = Describe("ConfigMapAllocator", func() {
+	var allocator *ipam.ConfigMapAllocator
+	var k8sClient client.Client
+	ctx := context.Background()
+	nfDeploy1 := types.NamespacedName{Namespace: "nephio-user", Name: "nfdeploy1"}
+	nfDeploy2 := types.NamespacedName{Namespace: "nephio-user", Name: "nfdeploy2"}
+
+	BeforeEach(func() {
+		k8sClient = fake.NewClientBuilder().Build()
+		allocator = &ipam.ConfigMapAllocator{
+			Client:    k8sClient,
+			Namespace: "nephio-user",
+			Name:      ipam.DefaultAllocationsConfigMapName,
+			Log:       logr.Discard(),
+		}
+	})
+
+	Context("Allocating addresses", func() {
+		It("Should skip the network and reserved addresses", func() {
+			addrs, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"},
+				[]int{41, 31}, []string{"10.0.0.1/29"},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addrs).To(Equal(map[int]string{31: "10.0.0.2/29", 41: "10.0.0.3/29"}))
+		})
+
+		It("Should return the same addresses on re-allocation", func() {
+			addrs, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31, 41}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = allocator.Allocate(
+				ctx, nfDeploy1, "smf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			again, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31, 41}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(Equal(addrs))
+		})
+
+		It("Should not conflict with the allocations of other NfDeploys", func() {
+			_, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/30"}, []int{31, 41}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			addrs, err := allocator.Allocate(
+				ctx, nfDeploy2, "upf1", []string{"10.0.0.0/30", "10.0.1.0/30"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addrs).To(Equal(map[int]string{31: "10.0.1.1/30"}))
+		})
+
+		It("Should not share the allocations of NfDeploys with the same name", func() {
+			addrs, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			other := types.NamespacedName{Namespace: "other", Name: nfDeploy1.Name}
+			otherAddrs, err := allocator.Allocate(
+				ctx, other, "upf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(otherAddrs).NotTo(Equal(addrs))
+
+			Expect(allocator.Release(ctx, other)).To(Succeed())
+			again, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(Equal(addrs))
+		})
+
+		It("Should keep the allocations of each NfDeploy in a ConfigMap of its own", func() {
+			_, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = allocator.Allocate(
+				ctx, nfDeploy2, "upf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{
+				Namespace: "nephio-user", Name: "nfdeploy-ip-allocations.nephio-user.nfdeploy2",
+			}, cm)).To(Succeed())
+			Expect(cm.Labels).To(HaveKeyWithValue(ipam.AllocationsLabel, ipam.DefaultAllocationsConfigMapName))
+			Expect(cm.Data).To(Equal(map[string]string{
+				"nephio-user.nfdeploy2": `[{"siteId":"upf1","interfaceId":31,"ipAddr":"10.0.0.2/29"}]`,
+			}))
+		})
+
+		It("Should retry the allocation when the ConfigMap changed in the meantime", func() {
+			_, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			conflicting := &conflictingClient{Client: k8sClient, conflicts: 1}
+			allocator.Client = conflicting
+			addrs, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31, 41}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addrs).To(Equal(map[int]string{31: "10.0.0.1/29", 41: "10.0.0.2/29"}))
+			Expect(conflicting.conflicts).To(BeZero())
+		})
+
+		It("Should re-allocate addresses which are outside the blocks", func() {
+			_, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/30"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			addrs, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.1.0/30"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addrs).To(Equal(map[int]string{31: "10.0.1.1/30"}))
+		})
+
+		It("Should return error when the blocks are exhausted", func() {
+			_, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/30"}, []int{31, 41, 61}, nil,
+			)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should return error for invalid blocks", func() {
+			_, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0"}, []int{31}, nil,
+			)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Looking up addresses", func() {
+		It("Should return the allocated addresses", func() {
+			addrs, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			looked, err := allocator.Lookup(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(looked).To(Equal(addrs))
+		})
+
+		It("Should not persist new addresses", func() {
+			readOnly := ipam.ReadOnly(allocator)
+			addrs, err := readOnly.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/29"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addrs).To(Equal(map[int]string{31: "10.0.0.1/29"}))
+
+			cms := &corev1.ConfigMapList{}
+			Expect(k8sClient.List(ctx, cms)).To(Succeed())
+			Expect(cms.Items).To(BeEmpty())
+		})
+
+		It("Should return nil for a nil allocator", func() {
+			Expect(ipam.ReadOnly(nil)).To(BeNil())
+		})
+	})
+
+	Context("Releasing addresses", func() {
+		It("Should free the addresses of the NfDeploy", func() {
+			_, err := allocator.Allocate(
+				ctx, nfDeploy1, "upf1", []string{"10.0.0.0/30"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allocator.Release(ctx, nfDeploy1)).To(Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKey{
+				Namespace: "nephio-user", Name: "nfdeploy-ip-allocations.nephio-user.nfdeploy1",
+			}, &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			addrs, err := allocator.Allocate(
+				ctx, nfDeploy2, "upf1", []string{"10.0.0.0/30"}, []int{31}, nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addrs).To(Equal(map[int]string{31: "10.0.0.1/30"}))
+		})
+
+		It("Should succeed when nothing is allocated", func() {
+			Expect(allocator.Release(ctx, nfDeploy1)).To(Succeed())
+		})
+	})
+})
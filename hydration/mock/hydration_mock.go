@@ -65,12 +65,13 @@ func (mr *MockHydrationInterfaceMockRecorder) CreateNFDeployActuators(ctx, nfDep
 }
 
 // Hydrate mocks base method.
-func (m *MockHydrationInterface) Hydrate(ctx context.Context, nfDeploy v1alpha1.NfDeploy) ([]string, error) {
+func (m *MockHydrationInterface) Hydrate(ctx context.Context, nfDeploy v1alpha1.NfDeploy) ([]string, *v1alpha1.ProfilesStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Hydrate", ctx, nfDeploy)
 	ret0, _ := ret[0].([]string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*v1alpha1.ProfilesStatus)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Hydrate indicates an expected call of Hydrate.
@@ -78,3 +79,48 @@ func (mr *MockHydrationInterfaceMockRecorder) Hydrate(ctx, nfDeploy interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hydrate", reflect.TypeOf((*MockHydrationInterface)(nil).Hydrate), ctx, nfDeploy)
 }
+
+// HydrateSites mocks base method.
+func (m *MockHydrationInterface) HydrateSites(ctx context.Context, nfDeploy v1alpha1.NfDeploy, siteIds []string) ([]string, *v1alpha1.ProfilesStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HydrateSites", ctx, nfDeploy, siteIds)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(*v1alpha1.ProfilesStatus)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// HydrateSites indicates an expected call of HydrateSites.
+func (mr *MockHydrationInterfaceMockRecorder) HydrateSites(ctx, nfDeploy, siteIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HydrateSites", reflect.TypeOf((*MockHydrationInterface)(nil).HydrateSites), ctx, nfDeploy, siteIds)
+}
+
+// ReleaseAllocations mocks base method.
+func (m *MockHydrationInterface) ReleaseAllocations(ctx context.Context, nfDeploy v1alpha1.NfDeploy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseAllocations", ctx, nfDeploy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseAllocations indicates an expected call of ReleaseAllocations.
+func (mr *MockHydrationInterfaceMockRecorder) ReleaseAllocations(ctx, nfDeploy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseAllocations", reflect.TypeOf((*MockHydrationInterface)(nil).ReleaseAllocations), ctx, nfDeploy)
+}
+
+// Render mocks base method.
+func (m *MockHydrationInterface) Render(ctx context.Context, nfDeploy v1alpha1.NfDeploy) (map[string]map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Render", ctx, nfDeploy)
+	ret0, _ := ret[0].(map[string]map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Render indicates an expected call of Render.
+func (mr *MockHydrationInterfaceMockRecorder) Render(ctx, nfDeploy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Render", reflect.TypeOf((*MockHydrationInterface)(nil).Render), ctx, nfDeploy)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 
 	ausftypes "github.com/nephio-project/common-lib/ausf"
@@ -46,19 +47,24 @@ type AusfDeployImpl struct {
 // GenerateNfTypeDeploy generates AusfDeploy
 func (adi *AusfDeployImpl) GenerateNfTypeDeploy(
 	ctx context.Context,
-	s deployv1alpha1.Site, nfDeployName string,
+	s deployv1alpha1.Site, in SiteDeployInput,
 ) ([]byte, error) {
 
 	adi.Log.Info("Generating AusfDeploy", "siteID", s.Id)
-	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, nfDeployName)
+	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, in.NfDeployName)
 	if err != nil {
 		return nil, fmt.Errorf("error creating naming context: %w", err)
 	}
-	cp, err := getAusfCapacityProfile(ctx, adi.PS, AusfCapacityProfileKind, nc)
+	// without flavor, the only AusfCapacityProfile is used
+	cpName, err := utils.GetFlavorCapacityProfileName(ctx, adi.PS, s, nc)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving flavor: %w", err)
+	}
+	cp, err := getAusfCapacityProfile(ctx, adi.PS, AusfCapacityProfileKind, cpName, nc)
 	if err != nil {
 		return nil, fmt.Errorf("error getting AusfCapacityProfile: %w", err)
 	}
-	ausfDeploy := generateAusfDeploy(s, cp, nfDeployName)
+	ausfDeploy := generateAusfDeploy(s, cp, in)
 
 	content, err := yaml.Marshal(ausfDeploy)
 	if err != nil {
@@ -68,11 +74,20 @@ func (adi *AusfDeployImpl) GenerateNfTypeDeploy(
 	return content, nil
 }
 
+// GetNetworkInterfaces returns no interfaces as AusfDeploy does not have
+// network interfaces yet
+func (adi *AusfDeployImpl) GetNetworkInterfaces(
+	ctx context.Context,
+	s deployv1alpha1.Site, nfDeploy k8stypes.NamespacedName,
+) (map[deployv1alpha1.ReferencePoint][]types.NetworkInterface, error) {
+	return map[deployv1alpha1.ReferencePoint][]types.NetworkInterface{}, nil
+}
+
 // generateAusfDeploy generates AusfDeploy
 func generateAusfDeploy(
 	s deployv1alpha1.Site,
 	cp *types.AusfCapacityProfile,
-	nfDeployName string,
+	in SiteDeployInput,
 ) *types.AusfDeploy {
 
 	return &types.AusfDeploy{
@@ -89,19 +104,23 @@ func generateAusfDeploy(
 				Labels: map[string]string{
 					nfdeployutil.NFSiteIDLabel: s.Id,
 					nfdeployutil.NFTypeLabel:   s.NFType,
-					nfdeployutil.NFDeployLabel: nfDeployName,
+					nfdeployutil.NFDeployLabel: in.NfDeployName,
 				},
+				Annotations: in.annotations(),
 			},
 		},
-		Spec: ausftypes.AusfDeploySpec{
-			CapacityProfile: ausftypes.CapacityProfile{
-				RequestedCpu:    cp.Spec.RequestedCpu,
-				RequestedMemory: cp.Spec.RequestedMemory,
-			},
-			NfInfo: ausftypes.NfInfo{
-				Vendor:  s.NFVendor,
-				Version: s.NFVersion,
+		Spec: types.AusfDeploySpec{
+			AusfDeploySpec: ausftypes.AusfDeploySpec{
+				CapacityProfile: ausftypes.CapacityProfile{
+					RequestedCpu:    cp.Spec.RequestedCpu,
+					RequestedMemory: cp.Spec.RequestedMemory,
+				},
+				NfInfo: ausftypes.NfInfo{
+					Vendor:  s.NFVendor,
+					Version: s.NFVersion,
+				},
 			},
+			Plmns: in.Plmns,
 		},
 	}
 }
@@ -110,10 +129,11 @@ func generateAusfDeploy(
 // This section contains packageservice interaction methods
 //----------------------------------------------------------
 
-// getAusfCapacityProfile returns AusfCapacityProfile
+// getAusfCapacityProfile returns the AusfCapacityProfile with the given name,
+// or the only one when name is empty
 func getAusfCapacityProfile(
 	ctx context.Context, psi ps.PackageServiceInterface,
-	kind string, nc nfdeployutil.NamingContext,
+	kind, name string, nc nfdeployutil.NamingContext,
 ) (*types.AusfCapacityProfile, error) {
 
 	cpMap, err := psi.GetNFProfiles(
@@ -122,6 +142,7 @@ func getAusfCapacityProfile(
 				ID:         1,
 				ApiVersion: utils.IpAPIVersion,
 				Kind:       kind,
+				Name:       name,
 			},
 		}, nc,
 	)
@@ -129,6 +150,12 @@ func getAusfCapacityProfile(
 		return nil, err
 	}
 	if len(cpMap[1]) != 1 {
+		if name != "" {
+			return nil, fmt.Errorf(
+				"expecting exactly one %s kind with name: %s, received: %d",
+				kind, name, len(cpMap[1]),
+			)
+		}
 		return nil, fmt.Errorf(
 			"expecting exactly one %s kind, received: %d",
 			kind, len(cpMap[1]),
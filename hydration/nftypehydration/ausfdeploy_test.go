@@ -29,6 +29,7 @@ import (
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 	"github.com/nephio-project/nf-deploy-controller/hydration/nftypehydration"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
 	hydrationutil "github.com/nephio-project/nf-deploy-controller/hydration/utils"
 	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
 	mps "github.com/nephio-project/nf-deploy-controller/packageservice/mock"
@@ -41,8 +42,9 @@ const (
 )
 
 var (
-	ausfcp, ausfDeploy1 []byte
-	ausfNC              nfdeployutil.NamingContext
+	ausfcp, ausfDeploy1, ausfDeploy1WithPlmns []byte
+	ausfFlavorLarge                           []byte
+	ausfNC                                    nfdeployutil.NamingContext
 )
 
 func expectAusfCapacityProfile(mpsi *mps.MockPackageServiceInterface) {
@@ -67,6 +69,8 @@ var _ = Describe("Ausfdeploy", func() {
 
 	ausfcp, _ = os.ReadFile("../testhelper/ausfcapacityprofile.yaml")
 	ausfDeploy1, _ = os.ReadFile("../testhelper/ausfdeploy1.yaml")
+	ausfDeploy1WithPlmns, _ = os.ReadFile("../testhelper/ausfdeploy1withplmns.yaml")
+	ausfFlavorLarge, _ = os.ReadFile("../testhelper/ausfflavor_large.yaml")
 	ausfNC, _ = nfdeployutil.NewNamingContext(ausfClusterName, ausfNfDeployName)
 
 	BeforeEach(func() {
@@ -97,10 +101,57 @@ var _ = Describe("Ausfdeploy", func() {
 			})
 			It("should process a single ausf and return ausfdeploy", func() {
 				format.MaxLength = 0
-				resp, err := adi.GenerateNfTypeDeploy(ctx, site, ausfNfDeployName)
+				resp, err := adi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: ausfNfDeployName})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp).To(Equal(ausfDeploy1))
 			})
+			It("should process a single ausf and return ausfdeploy with plmns", func() {
+				format.MaxLength = 0
+				plmns := []types.Plmn{{MCC: "310", MNC: "260"}, {MCC: "001", MNC: "001"}}
+				resp, err := adi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{
+					NfDeployName: ausfNfDeployName, Plmns: plmns,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(resp)).To(Equal(string(ausfDeploy1WithPlmns)))
+			})
+		})
+		Context("testing ausfdeploy with flavor", func() {
+			flavorSite := site
+			flavorSite.Flavor = "large"
+			BeforeEach(func() {
+				mpsi.EXPECT().GetNFProfiles(gomock.Any(), gomock.Eq([]ps.GetResourceRequest{
+					{
+						ID:         1,
+						ApiVersion: hydrationutil.IpAPIVersion,
+						Kind:       hydrationutil.NFFlavorKind,
+					},
+				}), gomock.Eq(ausfNC)).Return(map[int][]string{
+					1: {string(ausfFlavorLarge)},
+				}, nil).Times(1)
+			})
+			It("should use the capacity profile mapped to the flavor", func() {
+				mpsi.EXPECT().GetNFProfiles(gomock.Any(), gomock.Eq([]ps.GetResourceRequest{
+					{
+						ID:         1,
+						ApiVersion: hydrationutil.IpAPIVersion,
+						Kind:       "AusfCapacityProfile",
+						Name:       "ausf-large",
+					},
+				}), gomock.Eq(ausfNC)).Return(map[int][]string{
+					1: {string(ausfcp)},
+				}, nil).Times(1)
+				resp, err := adi.GenerateNfTypeDeploy(ctx, flavorSite, nftypehydration.SiteDeployInput{NfDeployName: ausfNfDeployName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp).To(Equal(ausfDeploy1))
+			})
+			It("should return an error when the flavor is not mapped for the vendor", func() {
+				otherVendorSite := flavorSite
+				otherVendorSite.NFVendor = "other"
+				resp, err := adi.GenerateNfTypeDeploy(ctx, otherVendorSite, nftypehydration.SiteDeployInput{NfDeployName: ausfNfDeployName})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("error resolving flavor"))
+				Expect(resp).To(BeNil())
+			})
 		})
 		Context("expecting no value from packageservice for GetNFProfiles", func() {
 			BeforeEach(func() {
@@ -115,7 +166,7 @@ var _ = Describe("Ausfdeploy", func() {
 				}, nil).Times(1)
 			})
 			It("should process a single ausf and return error", func() {
-				resp, err := adi.GenerateNfTypeDeploy(ctx, site, ausfNfDeployName)
+				resp, err := adi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: ausfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(HavePrefix("error getting AusfCapacityProfile"))
 				Expect(resp).To(BeNil())
@@ -132,7 +183,7 @@ var _ = Describe("Ausfdeploy", func() {
 					},
 				}), gomock.Eq(ausfNC)).Return(nil, expectedErr).Times(1)
 
-				resp, err := adi.GenerateNfTypeDeploy(ctx, site, ausfNfDeployName)
+				resp, err := adi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: ausfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(resp).To(BeNil())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
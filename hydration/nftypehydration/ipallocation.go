@@ -0,0 +1,93 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nftypehydration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/ipam"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
+)
+
+// allocateInterfaceConfigs returns the interface configs of the site with
+// the addresses of all the interfaces in iProfiles allocated from the
+// Site.IPAddrBlock. The name and vlan of an interface are still taken from
+// its InterfaceConfig if present. icMap is returned as is when the site has no
+// IPAddrBlock.
+func allocateInterfaceConfigs(
+	ctx context.Context, allocator ipam.AllocatorInterface,
+	s deployv1alpha1.Site, nfDeploy k8stypes.NamespacedName,
+	iProfiles map[deployv1alpha1.ReferencePoint][]types.NFTypeInterfaceProfile,
+	icMap map[int]types.InterfaceCfgSpec,
+) (map[int]types.InterfaceCfgSpec, error) {
+
+	if len(s.IPAddrBlock) == 0 {
+		return icMap, nil
+	}
+	if allocator == nil {
+		return nil, fmt.Errorf(
+			"site %s has ipAddrBlock but no address allocator is configured", s.Id,
+		)
+	}
+	// sorted for the fallback interface names to be deterministic
+	referencePoints := make([]string, 0, len(iProfiles))
+	for referencePoint := range iProfiles {
+		referencePoints = append(referencePoints, string(referencePoint))
+	}
+	sort.Strings(referencePoints)
+	names := make(map[int]string)
+	ids := []int{}
+	for _, referencePoint := range referencePoints {
+		for _, profile := range iProfiles[deployv1alpha1.ReferencePoint(referencePoint)] {
+			if _, ok := names[profile.ID]; ok {
+				continue
+			}
+			names[profile.ID] = fmt.Sprintf(
+				"%s-%s-%d", s.Id, strings.ToLower(referencePoint), profile.ID,
+			)
+			ids = append(ids, profile.ID)
+		}
+	}
+	reserved := []string{}
+	for _, config := range icMap {
+		reserved = append(reserved, config.IPAddr...)
+	}
+	addrs, err := allocator.Allocate(ctx, nfDeploy, s.Id, s.IPAddrBlock, ids, reserved)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make(map[int]types.InterfaceCfgSpec, len(icMap))
+	for id, config := range icMap {
+		resp[id] = config
+	}
+	for _, id := range ids {
+		config, ok := resp[id]
+		if !ok {
+			config = types.InterfaceCfgSpec{ID: id, Name: names[id]}
+		}
+		config.IPAddr = []string{addrs[id]}
+		resp[id] = config
+	}
+	return resp, nil
+}
@@ -18,12 +18,56 @@ package nftypehydration
 
 import (
 	"context"
+	"strconv"
+
+	k8stypes "k8s.io/apimachinery/pkg/types"
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
+	nfdeployutil "github.com/nephio-project/nf-deploy-controller/util"
 )
 
+// SiteDeployInput holds the inputs derived from NfDeploy for generating the
+// NfTypeDeploy of a site
+type SiteDeployInput struct {
+	// NfDeployName is the name of NfDeploy
+	NfDeployName string
+	// NfDeployNamespace is the namespace of NfDeploy
+	NfDeployNamespace string
+	// NfDeployGeneration is the generation of NfDeploy which is hydrated
+	NfDeployGeneration int64
+	// Peers are the neighbors of the site as per the connectivities in NfDeploy
+	Peers []types.Peer
+	// Plmns are the PLMNs of NfDeploy served by the NF
+	Plmns []types.Plmn
+	// Capacity is the share of the site in the capacity intent of NfDeploy,
+	// nil when NfDeploy has no capacity intent
+	Capacity *deployv1alpha1.Capacity
+}
+
 type NfTypeHydrationInterface interface {
 	// GenerateNfTypeDeploy generates NfTypeDeploy (UpfDeploy, SmfDeploy)
-	// and returns the generates resource as []byte
-	GenerateNfTypeDeploy(ctx context.Context, s deployv1alpha1.Site, nfDeployName string) ([]byte, error)
+	// and returns the generates resource as []byte.
+	GenerateNfTypeDeploy(ctx context.Context, s deployv1alpha1.Site,
+		in SiteDeployInput) ([]byte, error)
+
+	// GetNetworkInterfaces returns the network interfaces of the site grouped
+	// by the reference point. These are used as the endpoints of the site by
+	// its neighbors.
+	GetNetworkInterfaces(ctx context.Context, s deployv1alpha1.Site,
+		nfDeploy k8stypes.NamespacedName) (map[deployv1alpha1.ReferencePoint][]types.NetworkInterface, error)
+}
+
+// nfDeploy returns the namespaced name of NfDeploy
+func (in SiteDeployInput) nfDeploy() k8stypes.NamespacedName {
+	return k8stypes.NamespacedName{Namespace: in.NfDeployNamespace, Name: in.NfDeployName}
+}
+
+// annotations returns the annotations of the NfTypeDeploy, which record the
+// generation of NfDeploy it is hydrated from so that the NF reported from the
+// edge can be matched with it
+func (in SiteDeployInput) annotations() map[string]string {
+	return map[string]string{
+		nfdeployutil.NFDeployGenerationAnnotation: strconv.FormatInt(in.NfDeployGeneration, 10),
+	}
 }
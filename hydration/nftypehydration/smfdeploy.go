@@ -21,9 +21,11 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/ipam"
 	"github.com/nephio-project/nf-deploy-controller/hydration/types"
 	"github.com/nephio-project/nf-deploy-controller/hydration/utils"
 	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
@@ -41,16 +43,18 @@ const (
 type SmfDeployImpl struct {
 	PS  ps.PackageServiceInterface
 	Log logr.Logger
+	// Allocator allocates the interface addresses of sites with IPAddrBlock
+	Allocator ipam.AllocatorInterface
 }
 
 // GenerateNfTypeDeploy generates SmfDeploy
 func (sdi *SmfDeployImpl) GenerateNfTypeDeploy(
 	ctx context.Context,
-	s deployv1alpha1.Site, nfDeployName string,
+	s deployv1alpha1.Site, in SiteDeployInput,
 ) ([]byte, error) {
 
 	sdi.Log.Info("Generating SmfDeploy", "siteID", s.Id)
-	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, nfDeployName)
+	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, in.NfDeployName)
 	if err != nil {
 		return nil, fmt.Errorf("error creating naming context: %w", err)
 	}
@@ -58,22 +62,43 @@ func (sdi *SmfDeployImpl) GenerateNfTypeDeploy(
 	if err != nil {
 		return nil, fmt.Errorf("error getting SmfType: %w", err)
 	}
-	nfBgpConfig, interfaceConfigs, err := utils.GetReferencedProfiles(
+	nfBgpConfigs, interfaceConfigs, err := utils.GetReferencedProfiles(
 		ctx, sdi.PS,
 		utils.NFBGPConfigKind, utils.InterfaceConfigKind, nc,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error getting nfProfiles: %w", err)
 	}
+	nfBgpConfig, err := utils.SelectNFBGPConfig(nfBgpConfigs, smfType.Spec.NFBGPConfig, s.Id)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting nfBgpConfig: %w", err)
+	}
+	cpName, err := utils.GetFlavorCapacityProfileName(ctx, sdi.PS, s, nc)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving flavor: %w", err)
+	}
+	if cpName == "" {
+		cpName = smfType.Spec.CapacityProfile.ProfileName
+	}
 	cp, err := getSmfCapacityProfile(
-		ctx, sdi.PS, SmfCapacityProfileKind,
-		smfType.Spec.CapacityProfile.ProfileName, nc,
+		ctx, sdi.PS, SmfCapacityProfileKind, cpName, nc,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error getting SmfCapacityProfile: %w", err)
 	}
 	// InterfaceConfigMap
-	icMap := utils.GetInterfaceConfigSpecMap(interfaceConfigs)
+	icMap, err := utils.GetInterfaceConfigSpecMap(
+		interfaceConfigs, s.Id, smfType.Spec.InterfaceGroup,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving interfaceConfigs: %w", err)
+	}
+	icMap, err = allocateInterfaceConfigs(
+		ctx, sdi.Allocator, s, in.nfDeploy(), getSmfInterfaceProfiles(smfType), icMap,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error allocating interface addresses: %w", err)
+	}
 	// InterfaceProfileMap
 	ipMap, err := utils.GetInterfaceProfile(
 		ctx, sdi.PS, utils.InterfaceProfileKind,
@@ -84,7 +109,7 @@ func (sdi *SmfDeployImpl) GenerateNfTypeDeploy(
 	}
 
 	smfDeploy, err := generateSmfDeploy(
-		s, smfType, cp, nfBgpConfig, icMap, ipMap, nfDeployName,
+		s, smfType, cp, nfBgpConfig, icMap, ipMap, in,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error generating SmfDeploy: %w", err)
@@ -111,6 +136,83 @@ func getInterfaceProfileNames(smfType *types.SmfType) []string {
 	return names
 }
 
+// GetNetworkInterfaces returns the N4, N7, N10 and N11 interfaces of the SMF site
+func (sdi *SmfDeployImpl) GetNetworkInterfaces(
+	ctx context.Context,
+	s deployv1alpha1.Site, nfDeploy k8stypes.NamespacedName,
+) (map[deployv1alpha1.ReferencePoint][]types.NetworkInterface, error) {
+
+	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, nfDeploy.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error creating naming context: %w", err)
+	}
+	smfType, err := getSmfType(ctx, sdi.PS, SmfTypeKind, s.NFTypeName, nc)
+	if err != nil {
+		return nil, fmt.Errorf("error getting SmfType: %w", err)
+	}
+	_, interfaceConfigs, err := utils.GetReferencedProfiles(
+		ctx, sdi.PS,
+		utils.NFBGPConfigKind, utils.InterfaceConfigKind, nc,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting nfProfiles: %w", err)
+	}
+	icMap, err := utils.GetInterfaceConfigSpecMap(
+		interfaceConfigs, s.Id, smfType.Spec.InterfaceGroup,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving interfaceConfigs: %w", err)
+	}
+	icMap, err = allocateInterfaceConfigs(
+		ctx, sdi.Allocator, s, nfDeploy, getSmfInterfaceProfiles(smfType), icMap,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error allocating interface addresses: %w", err)
+	}
+	// InterfaceProfileMap, as the SmfDeploy of the site is generated with it
+	ipMap, err := utils.GetInterfaceProfile(
+		ctx, sdi.PS, utils.InterfaceProfileKind,
+		getInterfaceProfileNames(smfType), nc,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting InterfaceProfile: %w", err)
+	}
+	return getSmfNetworkInterfaces(smfType, icMap, ipMap)
+}
+
+// getSmfInterfaceProfiles returns the interface profiles of SmfType grouped
+// by reference point
+func getSmfInterfaceProfiles(
+	smfType *types.SmfType,
+) map[deployv1alpha1.ReferencePoint][]types.NFTypeInterfaceProfile {
+
+	return map[deployv1alpha1.ReferencePoint][]types.NFTypeInterfaceProfile{
+		deployv1alpha1.N4:  smfType.Spec.N4InterfaceProfile,
+		deployv1alpha1.N7:  smfType.Spec.N7InterfaceProfile,
+		deployv1alpha1.N10: smfType.Spec.N10InterfaceProfile,
+		deployv1alpha1.N11: smfType.Spec.N11InterfaceProfile,
+	}
+}
+
+// getSmfNetworkInterfaces returns the interfaces of SmfType grouped by
+// reference point
+func getSmfNetworkInterfaces(
+	smfType *types.SmfType,
+	icMap map[int]types.InterfaceCfgSpec,
+	ipMap map[string]*types.InterfaceProfile,
+) (map[deployv1alpha1.ReferencePoint][]types.NetworkInterface, error) {
+
+	resp := make(map[deployv1alpha1.ReferencePoint][]types.NetworkInterface)
+	for referencePoint, iProfile := range getSmfInterfaceProfiles(smfType) {
+		nIf, err := utils.GetNetworkInterfaces(iProfile, icMap, ipMap)
+		if err != nil {
+			return nil, err
+		}
+		resp[referencePoint] = nIf
+	}
+	return resp, nil
+}
+
 // generateSmfDeploy generates SmfDeploy
 func generateSmfDeploy(
 	s deployv1alpha1.Site,
@@ -119,36 +221,14 @@ func generateSmfDeploy(
 	nfBgpConfig *types.NFBGPConfig,
 	icMap map[int]types.InterfaceCfgSpec,
 	ipMap map[string]*types.InterfaceProfile,
-	nfDeployName string,
+	in SiteDeployInput,
 ) (*types.SmfDeploy, error) {
 
-	var cap types.SmfCapacity
-	if cp != nil {
-		cap = types.SmfCapacity{
-			MaxSession: cp.MaxSessions,
-		}
-	}
-	n4If, err := utils.GetNetworkInterfaces(
-		smfType.Spec.N4InterfaceProfile, icMap, ipMap,
-	)
+	cap, err := getSmfCapacity(cp, in.Capacity)
 	if err != nil {
 		return nil, err
 	}
-	n7If, err := utils.GetNetworkInterfaces(
-		smfType.Spec.N7InterfaceProfile, icMap, ipMap,
-	)
-	if err != nil {
-		return nil, err
-	}
-	n10If, err := utils.GetNetworkInterfaces(
-		smfType.Spec.N10InterfaceProfile, icMap, ipMap,
-	)
-	if err != nil {
-		return nil, err
-	}
-	n11If, err := utils.GetNetworkInterfaces(
-		smfType.Spec.N11InterfaceProfile, icMap, ipMap,
-	)
+	nIfs, err := getSmfNetworkInterfaces(smfType, icMap, ipMap)
 	if err != nil {
 		return nil, err
 	}
@@ -166,21 +246,63 @@ func generateSmfDeploy(
 				Labels: map[string]string{
 					nfdeployutil.NFSiteIDLabel: s.Id,
 					nfdeployutil.NFTypeLabel:   s.NFType,
-					nfdeployutil.NFDeployLabel: nfDeployName,
+					nfdeployutil.NFDeployLabel: in.NfDeployName,
 				},
+				Annotations: in.annotations(),
 			},
 		},
 		Spec: types.SmfDeploySpec{
 			Capacity:      cap,
 			BGPConfigs:    utils.GetBGPConfig(nfBgpConfig),
-			N4Interfaces:  n4If,
-			N7Interfaces:  n7If,
-			N10Interfaces: n10If,
-			N11Interfaces: n11If,
+			N4Interfaces:  nIfs[deployv1alpha1.N4],
+			N7Interfaces:  nIfs[deployv1alpha1.N7],
+			N10Interfaces: nIfs[deployv1alpha1.N10],
+			N11Interfaces: nIfs[deployv1alpha1.N11],
+			Peers:         in.Peers,
+			Plmns:         in.Plmns,
 		},
 	}, nil
 }
 
+// getSmfCapacity returns the capacity of the site from its share in the
+// capacity intent of NfDeploy. The capacity of SmfCapacityProfile is used for
+// what the intent does not specify. Returns error if SmfCapacityProfile can
+// not meet the share of the site.
+func getSmfCapacity(
+	cp *types.SmfCapacityProfile, intent *deployv1alpha1.Capacity,
+) (types.SmfCapacity, error) {
+
+	var cap types.SmfCapacity
+	if cp != nil {
+		cap = types.SmfCapacity{
+			MaxSession:    cp.MaxSessions,
+			MaxSubscriber: cp.MaxSubscribers,
+		}
+	}
+	if intent == nil {
+		return cap, nil
+	}
+	if intent.MaxSessions > 0 {
+		if cap.MaxSession > 0 && intent.MaxSessions > cap.MaxSession {
+			return cap, fmt.Errorf(
+				"SmfCapacityProfile %s can not meet maxSessions %d, maximum: %d",
+				cp.ObjectMeta.Name, intent.MaxSessions, cap.MaxSession,
+			)
+		}
+		cap.MaxSession = intent.MaxSessions
+	}
+	if intent.MaxSubscribers > 0 {
+		if cap.MaxSubscriber > 0 && intent.MaxSubscribers > cap.MaxSubscriber {
+			return cap, fmt.Errorf(
+				"SmfCapacityProfile %s can not meet maxSubscribers %d, maximum: %d",
+				cp.ObjectMeta.Name, intent.MaxSubscribers, cap.MaxSubscriber,
+			)
+		}
+		cap.MaxSubscriber = intent.MaxSubscribers
+	}
+	return cap, nil
+}
+
 //----------------------------------------------------------
 // This section contains packageservice interaction methods
 //----------------------------------------------------------
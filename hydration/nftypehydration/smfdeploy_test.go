@@ -25,6 +25,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/format"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
@@ -176,7 +177,7 @@ var _ = Describe("Smfdeploy", func() {
 			})
 			It("should process a single smf and return smfdeploy", func() {
 				format.MaxLength = 0
-				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, smfNfDeployName)
+				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: smfNfDeployName})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp).To(Equal(smfDeploy1))
 			})
@@ -215,7 +216,7 @@ var _ = Describe("Smfdeploy", func() {
 					},
 				}), gomock.Eq(smfNC)).Return(nil, expectedErr).Times(1)
 
-				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, smfNfDeployName)
+				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: smfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(resp).To(BeNil())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
@@ -234,7 +235,7 @@ var _ = Describe("Smfdeploy", func() {
 					},
 				}), gomock.Eq(smfNC)).Return(nil, expectedErr).Times(1)
 
-				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, smfNfDeployName)
+				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: smfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(resp).To(BeNil())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
@@ -256,7 +257,7 @@ var _ = Describe("Smfdeploy", func() {
 					},
 				}), gomock.Eq(smfNC)).Return(nil, expectedErr).Times(1)
 
-				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, smfNfDeployName)
+				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: smfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(resp).To(BeNil())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
@@ -272,11 +273,31 @@ var _ = Describe("Smfdeploy", func() {
 					},
 				}), gomock.Eq(smfNC)).Return(nil, expectedErr).Times(1)
 
-				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, smfNfDeployName)
+				resp, err := sdi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: smfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(resp).To(BeNil())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
 			})
 		})
 	})
+
+	Describe("Testing GetNetworkInterfaces for smf site", func() {
+		site := deployv1alpha1.Site{
+			Id:          "smf1",
+			ClusterName: smfClusterName,
+			NFType:      "smf",
+			NFTypeName:  "smfsmall",
+		}
+		It("should return the interfaces the smfdeploy of the site is generated with", func() {
+			expectSmfType(mpsi)
+			expectSmfReferencedProfiles(mpsi)
+			expectSmfInterfaceProfile(mpsi)
+			resp, err := sdi.GetNetworkInterfaces(ctx, site, types.NamespacedName{Name: smfNfDeployName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp[deployv1alpha1.N4]).NotTo(BeEmpty())
+			Expect(resp[deployv1alpha1.N4][0].InterfaceName).To(Equal("google-cmg12c-LB1_Port1_SxN4"))
+			Expect(resp[deployv1alpha1.N4][0].Latency).To(Equal("40"))
+			Expect(resp[deployv1alpha1.N4][0].Bandwidth).To(Equal("400"))
+		})
+	})
 })
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 
 	udmtypes "github.com/nephio-project/common-lib/udm"
@@ -46,20 +47,25 @@ type UdmDeployImpl struct {
 // GenerateNfTypeDeploy generates UdmDeploy
 func (udi *UdmDeployImpl) GenerateNfTypeDeploy(
 	ctx context.Context,
-	s deployv1alpha1.Site, nfDeployName string,
+	s deployv1alpha1.Site, in SiteDeployInput,
 ) ([]byte, error) {
 
 	udi.Log.Info("Generating UdmDeploy", "siteID", s.Id)
-	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, nfDeployName)
+	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, in.NfDeployName)
 	if err != nil {
 		return nil, fmt.Errorf("error creating naming context: %w", err)
 	}
-	cp, err := getUdmCapacityProfile(ctx, udi.PS, UdmCapacityProfileKind, nc)
+	// without flavor, the only UdmCapacityProfile is used
+	cpName, err := utils.GetFlavorCapacityProfileName(ctx, udi.PS, s, nc)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving flavor: %w", err)
+	}
+	cp, err := getUdmCapacityProfile(ctx, udi.PS, UdmCapacityProfileKind, cpName, nc)
 	if err != nil {
 		return nil, fmt.Errorf("error getting UdmCapacityProfile: %w", err)
 	}
 
-	udmDeploy := generateUdmDeploy(s, cp, nfDeployName)
+	udmDeploy := generateUdmDeploy(s, cp, in)
 
 	content, err := yaml.Marshal(udmDeploy)
 	if err != nil {
@@ -69,11 +75,20 @@ func (udi *UdmDeployImpl) GenerateNfTypeDeploy(
 	return content, nil
 }
 
+// GetNetworkInterfaces returns no interfaces as UdmDeploy does not have
+// network interfaces yet
+func (udi *UdmDeployImpl) GetNetworkInterfaces(
+	ctx context.Context,
+	s deployv1alpha1.Site, nfDeploy k8stypes.NamespacedName,
+) (map[deployv1alpha1.ReferencePoint][]types.NetworkInterface, error) {
+	return map[deployv1alpha1.ReferencePoint][]types.NetworkInterface{}, nil
+}
+
 // generateUdmDeploy generates UdmDeploy
 func generateUdmDeploy(
 	s deployv1alpha1.Site,
 	cp *types.UdmCapacityProfile,
-	nfDeployName string,
+	in SiteDeployInput,
 ) *types.UdmDeploy {
 
 	return &types.UdmDeploy{
@@ -90,19 +105,23 @@ func generateUdmDeploy(
 				Labels: map[string]string{
 					nfdeployutil.NFSiteIDLabel: s.Id,
 					nfdeployutil.NFTypeLabel:   s.NFType,
-					nfdeployutil.NFDeployLabel: nfDeployName,
+					nfdeployutil.NFDeployLabel: in.NfDeployName,
 				},
+				Annotations: in.annotations(),
 			},
 		},
-		Spec: udmtypes.UdmDeploySpec{
-			CapacityProfile: udmtypes.CapacityProfile{
-				RequestedCpu:    cp.Spec.RequestedCpu,
-				RequestedMemory: cp.Spec.RequestedMemory,
-			},
-			NfInfo: udmtypes.NfInfo{
-				Vendor:  s.NFVendor,
-				Version: s.NFVersion,
+		Spec: types.UdmDeploySpec{
+			UdmDeploySpec: udmtypes.UdmDeploySpec{
+				CapacityProfile: udmtypes.CapacityProfile{
+					RequestedCpu:    cp.Spec.RequestedCpu,
+					RequestedMemory: cp.Spec.RequestedMemory,
+				},
+				NfInfo: udmtypes.NfInfo{
+					Vendor:  s.NFVendor,
+					Version: s.NFVersion,
+				},
 			},
+			Plmns: in.Plmns,
 		},
 	}
 }
@@ -111,10 +130,11 @@ func generateUdmDeploy(
 // This section contains packageservice interaction methods
 //----------------------------------------------------------
 
-// getUdmCapacityProfile returns UdmCapacityProfile
+// getUdmCapacityProfile returns the UdmCapacityProfile with the given name,
+// or the only one when name is empty
 func getUdmCapacityProfile(
 	ctx context.Context, psi ps.PackageServiceInterface,
-	kind string, nc nfdeployutil.NamingContext,
+	kind, name string, nc nfdeployutil.NamingContext,
 ) (*types.UdmCapacityProfile, error) {
 
 	cpMap, err := psi.GetNFProfiles(
@@ -123,6 +143,7 @@ func getUdmCapacityProfile(
 				ID:         1,
 				ApiVersion: utils.IpAPIVersion,
 				Kind:       kind,
+				Name:       name,
 			},
 		}, nc,
 	)
@@ -130,6 +151,12 @@ func getUdmCapacityProfile(
 		return nil, err
 	}
 	if len(cpMap[1]) != 1 {
+		if name != "" {
+			return nil, fmt.Errorf(
+				"expecting exactly one %s kind with name: %s, received: %d",
+				kind, name, len(cpMap[1]),
+			)
+		}
 		return nil, fmt.Errorf(
 			"expecting exactly one %s kind, received: %d",
 			kind, len(cpMap[1]),
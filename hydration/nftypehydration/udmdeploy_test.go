@@ -97,7 +97,7 @@ var _ = Describe("Udmdeploy", func() {
 			})
 			It("should process a single udm and return udmdeploy", func() {
 				format.MaxLength = 0
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, udmNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: udmNfDeployName})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp).To(Equal(udmDeploy1))
 			})
@@ -115,7 +115,7 @@ var _ = Describe("Udmdeploy", func() {
 				}, nil).Times(1)
 			})
 			It("should process a single udm and return error", func() {
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, udmNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: udmNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(HavePrefix("error getting UdmCapacityProfile"))
 				Expect(resp).To(BeNil())
@@ -132,7 +132,7 @@ var _ = Describe("Udmdeploy", func() {
 					},
 				}), gomock.Eq(udmNC)).Return(nil, expectedErr).Times(1)
 
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, udmNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: udmNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(resp).To(BeNil())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
@@ -22,10 +22,12 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 	yamlutil "sigs.k8s.io/yaml"
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/ipam"
 	"github.com/nephio-project/nf-deploy-controller/hydration/types"
 	"github.com/nephio-project/nf-deploy-controller/hydration/utils"
 	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
@@ -44,16 +46,18 @@ const (
 type UpfDeployImpl struct {
 	PS  ps.PackageServiceInterface
 	Log logr.Logger
+	// Allocator allocates the interface addresses of sites with IPAddrBlock
+	Allocator ipam.AllocatorInterface
 }
 
 // GenerateNfTypeDeploy generates UpfDeploy
 func (udi *UpfDeployImpl) GenerateNfTypeDeploy(
 	ctx context.Context,
-	s deployv1alpha1.Site, nfDeployName string,
+	s deployv1alpha1.Site, in SiteDeployInput,
 ) ([]byte, error) {
 
 	udi.Log.Info("Generating UpfDeploy", "siteID", s.Id)
-	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, nfDeployName)
+	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, in.NfDeployName)
 	if err != nil {
 		return nil, fmt.Errorf("error creating naming context: %w", err)
 	}
@@ -61,24 +65,45 @@ func (udi *UpfDeployImpl) GenerateNfTypeDeploy(
 	if err != nil {
 		return nil, fmt.Errorf("error getting UpfType: %w", err)
 	}
-	nfBgpConfig, interfaceConfigs, err := utils.GetReferencedProfiles(
+	nfBgpConfigs, interfaceConfigs, err := utils.GetReferencedProfiles(
 		ctx, udi.PS,
 		utils.NFBGPConfigKind, utils.InterfaceConfigKind, nc,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error getting nfProfiles: %w", err)
 	}
+	nfBgpConfig, err := utils.SelectNFBGPConfig(nfBgpConfigs, upfType.Spec.NFBGPConfig, s.Id)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting nfBgpConfig: %w", err)
+	}
+	cpName, err := utils.GetFlavorCapacityProfileName(ctx, udi.PS, s, nc)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving flavor: %w", err)
+	}
+	if cpName == "" {
+		cpName = upfType.Spec.UpfCapacityProfile.ProfileName
+	}
 	cp, err := getUpfCapacityProfile(
-		ctx, udi.PS, UpfCapacityProfileKind,
-		upfType.Spec.UpfCapacityProfile.ProfileName, nc,
+		ctx, udi.PS, UpfCapacityProfileKind, cpName, nc,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error getting UpfCapacityProfile: %w", err)
 	}
-	icMap := utils.GetInterfaceConfigSpecMap(interfaceConfigs)
+	icMap, err := utils.GetInterfaceConfigSpecMap(
+		interfaceConfigs, s.Id, upfType.Spec.InterfaceGroup,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving interfaceConfigs: %w", err)
+	}
+	icMap, err = allocateInterfaceConfigs(
+		ctx, udi.Allocator, s, in.nfDeploy(), getUpfInterfaceProfiles(upfType), icMap,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error allocating interface addresses: %w", err)
+	}
 
 	upfDeploy, err := generateUpfDeploy(
-		s, upfType, cp, nfBgpConfig, icMap, nfDeployName,
+		s, upfType, cp, nfBgpConfig, icMap, in,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error generating UpfDeploy: %w", err)
@@ -101,41 +126,89 @@ func (udi *UpfDeployImpl) GenerateNfTypeDeploy(
 	return content, nil
 }
 
-// generateUpfDeploy generates UpfDeploy
-func generateUpfDeploy(
-	s deployv1alpha1.Site,
-	upfType *types.UpfType,
-	cp *types.UpfCapacityProfile,
-	nfBgpConfig *types.NFBGPConfig,
-	icMap map[int]types.InterfaceCfgSpec,
-	nfDeployName string,
-) (*types.UpfDeploy, error) {
+// GetNetworkInterfaces returns the N3, N4, N6 and N9 interfaces of the UPF site
+func (udi *UpfDeployImpl) GetNetworkInterfaces(
+	ctx context.Context,
+	s deployv1alpha1.Site, nfDeploy k8stypes.NamespacedName,
+) (map[deployv1alpha1.ReferencePoint][]types.NetworkInterface, error) {
 
-	var cap types.UpfCapacity
-	if cp != nil {
-		cap = cp.UpfCPSpec.UpfCapacity
+	nc, err := nfdeployutil.NewNamingContext(s.ClusterName, nfDeploy.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error creating naming context: %w", err)
+	}
+	upfType, err := getUpfType(ctx, udi.PS, UpfTypeKind, s.NFTypeName, nc)
+	if err != nil {
+		return nil, fmt.Errorf("error getting UpfType: %w", err)
 	}
-	n3If, err := utils.GetNetworkInterfaces(
-		upfType.Spec.N3InterfaceProfile, icMap, nil,
+	_, interfaceConfigs, err := utils.GetReferencedProfiles(
+		ctx, udi.PS,
+		utils.NFBGPConfigKind, utils.InterfaceConfigKind, nc,
 	)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error getting nfProfiles: %w", err)
 	}
-	n4If, err := utils.GetNetworkInterfaces(
-		upfType.Spec.N4InterfaceProfile, icMap, nil,
+	icMap, err := utils.GetInterfaceConfigSpecMap(
+		interfaceConfigs, s.Id, upfType.Spec.InterfaceGroup,
 	)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error resolving interfaceConfigs: %w", err)
 	}
-	n6If, err := utils.GetNetworkInterfaces(
-		upfType.Spec.N6InterfaceProfile, icMap, nil,
+	icMap, err = allocateInterfaceConfigs(
+		ctx, udi.Allocator, s, nfDeploy, getUpfInterfaceProfiles(upfType), icMap,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("error allocating interface addresses: %w", err)
+	}
+	return getUpfNetworkInterfaces(upfType, icMap)
+}
+
+// getUpfInterfaceProfiles returns the interface profiles of UpfType grouped
+// by reference point
+func getUpfInterfaceProfiles(
+	upfType *types.UpfType,
+) map[deployv1alpha1.ReferencePoint][]types.NFTypeInterfaceProfile {
+
+	return map[deployv1alpha1.ReferencePoint][]types.NFTypeInterfaceProfile{
+		deployv1alpha1.N3: upfType.Spec.N3InterfaceProfile,
+		deployv1alpha1.N4: upfType.Spec.N4InterfaceProfile,
+		deployv1alpha1.N6: upfType.Spec.N6InterfaceProfile,
+		deployv1alpha1.N9: upfType.Spec.N9InterfaceProfile,
+	}
+}
+
+// getUpfNetworkInterfaces returns the interfaces of UpfType grouped by
+// reference point
+func getUpfNetworkInterfaces(
+	upfType *types.UpfType,
+	icMap map[int]types.InterfaceCfgSpec,
+) (map[deployv1alpha1.ReferencePoint][]types.NetworkInterface, error) {
+
+	resp := make(map[deployv1alpha1.ReferencePoint][]types.NetworkInterface)
+	for referencePoint, iProfile := range getUpfInterfaceProfiles(upfType) {
+		nIf, err := utils.GetNetworkInterfaces(iProfile, icMap, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp[referencePoint] = nIf
+	}
+	return resp, nil
+}
+
+// generateUpfDeploy generates UpfDeploy
+func generateUpfDeploy(
+	s deployv1alpha1.Site,
+	upfType *types.UpfType,
+	cp *types.UpfCapacityProfile,
+	nfBgpConfig *types.NFBGPConfig,
+	icMap map[int]types.InterfaceCfgSpec,
+	in SiteDeployInput,
+) (*types.UpfDeploy, error) {
+
+	cap, err := getUpfCapacity(cp, in.Capacity)
 	if err != nil {
 		return nil, err
 	}
-	n9If, err := utils.GetNetworkInterfaces(
-		upfType.Spec.N9InterfaceProfile, icMap, nil,
-	)
+	nIfs, err := getUpfNetworkInterfaces(upfType, icMap)
 	if err != nil {
 		return nil, err
 	}
@@ -153,21 +226,69 @@ func generateUpfDeploy(
 				Labels: map[string]string{
 					nfdeployutil.NFSiteIDLabel: s.Id,
 					nfdeployutil.NFTypeLabel:   s.NFType,
-					nfdeployutil.NFDeployLabel: nfDeployName,
+					nfdeployutil.NFDeployLabel: in.NfDeployName,
 				},
+				Annotations: in.annotations(),
 			},
 		},
 		Spec: types.UpfDeploySpec{
 			Capacity:     cap,
 			BGPConfigs:   utils.GetBGPConfig(nfBgpConfig),
-			N3Interfaces: n3If,
-			N4Interfaces: n4If,
-			N6Interfaces: n6If,
-			N9Interfaces: n9If,
+			N3Interfaces: nIfs[deployv1alpha1.N3],
+			N4Interfaces: nIfs[deployv1alpha1.N4],
+			N6Interfaces: nIfs[deployv1alpha1.N6],
+			N9Interfaces: nIfs[deployv1alpha1.N9],
+			Peers:        in.Peers,
+			Plmns:        in.Plmns,
 		},
 	}, nil
 }
 
+// getUpfCapacity returns the capacity of the site from its share in the
+// capacity intent of NfDeploy. The capacity of UpfCapacityProfile is used for
+// what the intent does not specify. Returns error if UpfCapacityProfile can
+// not meet the share of the site.
+func getUpfCapacity(
+	cp *types.UpfCapacityProfile, intent *deployv1alpha1.Capacity,
+) (types.UpfCapacity, error) {
+
+	var cap types.UpfCapacity
+	if cp != nil {
+		cap = cp.UpfCPSpec.UpfCapacity
+	}
+	if intent == nil {
+		return cap, nil
+	}
+	if intent.UplinkThroughput != nil {
+		if !cap.UplinkThroughput.IsZero() && intent.UplinkThroughput.Cmp(cap.UplinkThroughput) > 0 {
+			return cap, fmt.Errorf(
+				"UpfCapacityProfile %s can not meet uplinkThroughput %s, maximum: %s",
+				cp.ObjectMeta.Name, intent.UplinkThroughput, &cap.UplinkThroughput,
+			)
+		}
+		cap.UplinkThroughput = intent.UplinkThroughput.DeepCopy()
+	}
+	if intent.DownlinkThroughput != nil {
+		if !cap.DownlinkThroughput.IsZero() && intent.DownlinkThroughput.Cmp(cap.DownlinkThroughput) > 0 {
+			return cap, fmt.Errorf(
+				"UpfCapacityProfile %s can not meet downlinkThroughput %s, maximum: %s",
+				cp.ObjectMeta.Name, intent.DownlinkThroughput, &cap.DownlinkThroughput,
+			)
+		}
+		cap.DownlinkThroughput = intent.DownlinkThroughput.DeepCopy()
+	}
+	if intent.MaxSessions > 0 {
+		if cap.MaximumConnections > 0 && intent.MaxSessions > cap.MaximumConnections {
+			return cap, fmt.Errorf(
+				"UpfCapacityProfile %s can not meet maxSessions %d, maximum: %d",
+				cp.ObjectMeta.Name, intent.MaxSessions, cap.MaximumConnections,
+			)
+		}
+		cap.MaximumConnections = intent.MaxSessions
+	}
+	return cap, nil
+}
+
 //----------------------------------------------------------
 // This section contains packageservice interaction methods
 //----------------------------------------------------------
@@ -24,10 +24,15 @@ import (
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	yamlutil "sigs.k8s.io/yaml"
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/ipam"
 	"github.com/nephio-project/nf-deploy-controller/hydration/nftypehydration"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
 	hydrationutil "github.com/nephio-project/nf-deploy-controller/hydration/utils"
 	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
 	mps "github.com/nephio-project/nf-deploy-controller/packageservice/mock"
@@ -42,7 +47,7 @@ const (
 var (
 	upfTypeSmall, upfcp, upfDeploy1, upfDeploy1WithExtn      []byte
 	upfInterfaceConfig1, upfInterfaceConfig2, upfNfbgpconfig []byte
-	upfExtension                                             []byte
+	upfInterfaceConfig3, upfExtension                        []byte
 	upfNC                                                    nfdeployutil.NamingContext
 )
 
@@ -59,7 +64,11 @@ func expectUpfType(mpsi *mps.MockPackageServiceInterface) {
 	}, nil).Times(1)
 }
 
-func expectUpfReferencedProfiles(mpsi *mps.MockPackageServiceInterface) {
+func expectUpfReferencedProfiles(mpsi *mps.MockPackageServiceInterface, interfaceConfigs ...[]byte) {
+	interfaceConfigResources := []string{string(upfInterfaceConfig1), string(upfInterfaceConfig2)}
+	for _, interfaceConfig := range interfaceConfigs {
+		interfaceConfigResources = append(interfaceConfigResources, string(interfaceConfig))
+	}
 	mpsi.EXPECT().GetNFProfiles(gomock.Any(), gomock.Eq([]ps.GetResourceRequest{
 		{
 			ID:         1,
@@ -73,7 +82,7 @@ func expectUpfReferencedProfiles(mpsi *mps.MockPackageServiceInterface) {
 		},
 	}), gomock.Eq(upfNC)).Return(map[int][]string{
 		1: {string(upfNfbgpconfig)},
-		2: {string(upfInterfaceConfig1), string(upfInterfaceConfig2)},
+		2: interfaceConfigResources,
 	}, nil).Times(1)
 }
 
@@ -102,6 +111,7 @@ var _ = Describe("Upfdeploy", func() {
 	upfNfbgpconfig, _ = os.ReadFile("../testhelper/nfbgpconfig.yaml")
 	upfInterfaceConfig1, _ = os.ReadFile("../testhelper/interfaceconfig1.yaml")
 	upfInterfaceConfig2, _ = os.ReadFile("../testhelper/interfaceconfig2.yaml")
+	upfInterfaceConfig3, _ = os.ReadFile("../testhelper/interfaceconfig3.yaml")
 	upfcp, _ = os.ReadFile("../testhelper/upfcapacityprofile.yaml")
 	upfDeploy1, _ = os.ReadFile("../testhelper/upfdeploy1.yaml")
 	upfDeploy1WithExtn, _ = os.ReadFile("../testhelper/upfdeploy1withextn.yaml")
@@ -140,7 +150,7 @@ var _ = Describe("Upfdeploy", func() {
 				mpsi.EXPECT().GetVendorExtensionPackage(ctx, upfNC, gomock.Eq(ps.VendorNFKey{
 					Vendor: site.NFVendor, Version: site.NFVersion, NFType: site.NFType,
 				})).Times(1).Return([]string{}, nil)
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, upfNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp).To(Equal(upfDeploy1))
 			})
@@ -148,12 +158,113 @@ var _ = Describe("Upfdeploy", func() {
 				mpsi.EXPECT().GetVendorExtensionPackage(ctx, upfNC, gomock.Eq(ps.VendorNFKey{
 					Vendor: site.NFVendor, Version: site.NFVersion, NFType: site.NFType,
 				})).Times(1).Return([]string{string(upfExtension)}, nil)
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, upfNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp).To(Equal(upfDeploy1WithExtn))
 			})
 		})
 
+		Context("testing upfdeploy for site with ipAddrBlock", func() {
+			blockSite := site
+			blockSite.IPAddrBlock = []string{"10.10.0.0/24"}
+			BeforeEach(func() {
+				expectUpfType(mpsi)
+				expectUpfReferencedProfiles(mpsi)
+			})
+			It("should allocate the interface addresses from ipAddrBlock", func() {
+				expectUpfCapacityProfile(mpsi)
+				mpsi.EXPECT().GetVendorExtensionPackage(ctx, upfNC, gomock.Any()).
+					Times(1).Return([]string{}, nil)
+				udi.Allocator = &ipam.ConfigMapAllocator{
+					Client:    fake.NewClientBuilder().Build(),
+					Namespace: "nephio-user",
+					Name:      ipam.DefaultAllocationsConfigMapName,
+					Log:       ctrl.Log.WithName("ipam"),
+				}
+				resp, err := udi.GenerateNfTypeDeploy(ctx, blockSite, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
+				Expect(err).NotTo(HaveOccurred())
+				upfDeploy := &types.UpfDeploy{}
+				Expect(yamlutil.Unmarshal(resp, upfDeploy)).To(Succeed())
+				Expect(upfDeploy.Spec.N3Interfaces).To(Equal([]types.NetworkInterface{{
+					InterfaceName: "google-cmg12u-MG1_RAN_1",
+					IPAddr:        []string{"10.10.0.1/24"},
+					Vlan:          []string{"200", "201"},
+				}}))
+				Expect(upfDeploy.Spec.N4Interfaces[0].IPAddr).To(Equal([]string{"10.10.0.2/24"}))
+				Expect(upfDeploy.Spec.N6Interfaces[0].IPAddr).To(Equal([]string{"10.10.0.3/24"}))
+				Expect(upfDeploy.Spec.N9Interfaces[0].IPAddr).To(Equal([]string{"10.10.0.4/24"}))
+			})
+			It("should return error when no allocator is configured", func() {
+				expectUpfCapacityProfile(mpsi)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, blockSite, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("no address allocator is configured"))
+				Expect(resp).To(BeNil())
+			})
+		})
+
+		Context("testing upfdeploy with site scoped interfaceConfig", func() {
+			BeforeEach(func() {
+				expectUpfType(mpsi)
+				expectUpfReferencedProfiles(mpsi, upfInterfaceConfig3)
+				expectUpfCapacityProfile(mpsi)
+			})
+			It("should overlay the shared interfaces with the interfaces of the site", func() {
+				mpsi.EXPECT().GetVendorExtensionPackage(ctx, upfNC, gomock.Any()).
+					Times(1).Return([]string{}, nil)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
+				Expect(err).NotTo(HaveOccurred())
+				upfDeploy := &types.UpfDeploy{}
+				Expect(yamlutil.Unmarshal(resp, upfDeploy)).To(Succeed())
+				Expect(upfDeploy.Spec.N3Interfaces).To(Equal([]types.NetworkInterface{{
+					InterfaceName: "google-cmg12u-MG1_RAN_upf1",
+					IPAddr:        []string{"192.168.251.163/28"},
+					Vlan:          []string{"210", "211"},
+				}}))
+				Expect(upfDeploy.Spec.N4Interfaces).To(Equal([]types.NetworkInterface{{
+					InterfaceName: "google-cmg12c-LB1_Port1_SxN4",
+					IPAddr:        []string{"192.168.250.166/28"},
+					Vlan:          []string{"300", "301"},
+				}}))
+			})
+		})
+
+		Context("testing upfdeploy with capacity intent", func() {
+			BeforeEach(func() {
+				expectUpfType(mpsi)
+				expectUpfReferencedProfiles(mpsi)
+				expectUpfCapacityProfile(mpsi)
+			})
+			It("should write the share of the site as capacity", func() {
+				mpsi.EXPECT().GetVendorExtensionPackage(ctx, upfNC, gomock.Any()).
+					Times(1).Return([]string{}, nil)
+				uplink, downlink := resource.MustParse("500k"), resource.MustParse("5M")
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{
+					NfDeployName: upfNfDeployName,
+					Capacity: &deployv1alpha1.Capacity{
+						UplinkThroughput: &uplink, DownlinkThroughput: &downlink,
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				upfDeploy := &types.UpfDeploy{}
+				Expect(yamlutil.Unmarshal(resp, upfDeploy)).To(Succeed())
+				Expect(upfDeploy.Spec.Capacity.UplinkThroughput.String()).To(Equal("500k"))
+				Expect(upfDeploy.Spec.Capacity.DownlinkThroughput.String()).To(Equal("5M"))
+				Expect(upfDeploy.Spec.Capacity.MaximumConnections).To(Equal(1))
+			})
+			It("should return error when UpfCapacityProfile can not meet the share of the site", func() {
+				uplink := resource.MustParse("2M")
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{
+					NfDeployName: upfNfDeployName,
+					Capacity:     &deployv1alpha1.Capacity{UplinkThroughput: &uplink},
+				})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(
+					"UpfCapacityProfile upfCapacityProfile1 can not meet uplinkThroughput 2M, maximum: 1M"))
+				Expect(resp).To(BeNil())
+			})
+		})
+
 		Context("error scenarios for vendor extension package", func() {
 			BeforeEach(func() {
 				expectUpfType(mpsi)
@@ -165,7 +276,7 @@ var _ = Describe("Upfdeploy", func() {
 				mpsi.EXPECT().GetVendorExtensionPackage(ctx, upfNC, gomock.Eq(ps.VendorNFKey{
 					Vendor: site.NFVendor, Version: site.NFVersion, NFType: site.NFType,
 				})).Times(1).Return(nil, cause)
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, upfNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(cause))
 				Expect(resp).To(BeNil())
@@ -174,7 +285,7 @@ var _ = Describe("Upfdeploy", func() {
 				mpsi.EXPECT().GetVendorExtensionPackage(ctx, upfNC, gomock.Eq(ps.VendorNFKey{
 					Vendor: site.NFVendor, Version: site.NFVersion, NFType: site.NFType,
 				})).Times(1).Return([]string{string(upfExtension), string(upfExtension)}, nil)
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, upfNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("More than one extension object found"))
 				Expect(resp).To(BeNil())
@@ -196,7 +307,7 @@ var _ = Describe("Upfdeploy", func() {
 					},
 				}), gomock.Eq(upfNC)).Return(nil, expectedErr).Times(1)
 
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, upfNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(resp).To(BeNil())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
@@ -218,7 +329,7 @@ var _ = Describe("Upfdeploy", func() {
 					},
 				}), gomock.Eq(upfNC)).Return(nil, expectedErr).Times(1)
 
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, upfNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(resp).To(BeNil())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
@@ -234,7 +345,7 @@ var _ = Describe("Upfdeploy", func() {
 					},
 				}), gomock.Eq(upfNC)).Return(nil, expectedErr).Times(1)
 
-				resp, err := udi.GenerateNfTypeDeploy(ctx, site, upfNfDeployName)
+				resp, err := udi.GenerateNfTypeDeploy(ctx, site, nftypehydration.SiteDeployInput{NfDeployName: upfNfDeployName})
 				Expect(err).To(HaveOccurred())
 				Expect(resp).To(BeNil())
 				Expect(err.Error()).To(HaveSuffix(expectedErr.Error()))
@@ -0,0 +1,83 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hydration
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
+	"github.com/nephio-project/nf-deploy-controller/hydration/utils"
+	nfdeployutil "github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// getNfTypeDeploy returns an empty NfTypeDeploy of the given nfType, which is
+// used to validate the overridden NfTypeDeploy
+func getNfTypeDeploy(nfType string) (interface{}, error) {
+	switch nfType {
+	case utils.UPFKind:
+		return &types.UpfDeploy{}, nil
+	case utils.SMFKind:
+		return &types.SmfDeploy{}, nil
+	case utils.AUSFKind:
+		return &types.AusfDeploy{}, nil
+	case utils.UDMKind:
+		return &types.UdmDeploy{}, nil
+	default:
+		return nil, fmt.Errorf("invalid NfType:%s", nfType)
+	}
+}
+
+// applySiteOverrides applies the overrides of the site onto the NfTypeDeploy,
+// which is the first object in content. The remaining objects, like the
+// vendor extension, are kept as is and the record of the applied overrides is
+// appended to content.
+func applySiteOverrides(s deployv1alpha1.Site, content []byte) ([]byte, error) {
+	if s.Overrides == nil {
+		return content, nil
+	}
+	target, err := getNfTypeDeploy(s.NFType)
+	if err != nil {
+		return nil, err
+	}
+	delimiter := "\n" + nfdeployutil.YamlObjectDelimiter + "\n"
+	docs := strings.SplitN(string(content), delimiter, 2)
+	deploy, err := utils.ApplyOverrides([]byte(docs[0]), s.Overrides, target)
+	if err != nil {
+		return nil, err
+	}
+	record, err := utils.GetAppliedOverrides([]byte(docs[0]), s.Overrides)
+	if err != nil {
+		return nil, err
+	}
+	recordString, err := yaml.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	resp := string(deploy)
+	if len(docs) > 1 {
+		resp += nfdeployutil.YamlObjectDelimiter + "\n" + docs[1]
+		if !strings.HasSuffix(resp, "\n") {
+			resp += "\n"
+		}
+	}
+	resp += nfdeployutil.YamlObjectDelimiter + "\n" + string(recordString)
+	return []byte(resp), nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hydration
+
+import (
+	"context"
+	"fmt"
+
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/profiles"
+	nfdeployutil "github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// profileRecorder is the package service of a rendering. It records the NF
+// profile objects returned by GetNFProfiles and the revision of the
+// nf-profiles package published when the rendering started.
+type profileRecorder struct {
+	ps.PackageServiceInterface
+	revision string
+	objects  map[profiles.Key]deployv1alpha1.ProfileObject
+}
+
+func newProfileRecorder(ctx context.Context, psi ps.PackageServiceInterface,
+	nfDeploy deployv1alpha1.NfDeploy) (*profileRecorder, error) {
+	r := &profileRecorder{
+		PackageServiceInterface: psi,
+		objects:                 make(map[profiles.Key]deployv1alpha1.ProfileObject),
+	}
+	if len(nfDeploy.Spec.Sites) == 0 {
+		return r, nil
+	}
+	nc, err := nfdeployutil.NewNamingContext(nfDeploy.Spec.Sites[0].ClusterName, nfDeploy.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error creating naming context: %w", err)
+	}
+	r.revision, _, err = psi.GetNFProfilesPackage(ctx, nc, "")
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetNFProfiles implements PackageServiceInterface
+func (r *profileRecorder) GetNFProfiles(ctx context.Context, req []ps.GetResourceRequest,
+	nc nfdeployutil.NamingContext) (map[int][]string, error) {
+	res, err := r.PackageServiceInterface.GetNFProfiles(ctx, req, nc)
+	if err != nil {
+		return nil, err
+	}
+	for _, contents := range res {
+		for _, content := range contents {
+			object, err := profiles.ObjectOf(content)
+			if err != nil {
+				return nil, fmt.Errorf("error recording NF profile: %w", err)
+			}
+			r.objects[profiles.KeyOf(object)] = object
+		}
+	}
+	return res, nil
+}
+
+// profiles returns the recorded profile objects
+func (r *profileRecorder) profiles() *deployv1alpha1.ProfilesStatus {
+	status := &deployv1alpha1.ProfilesStatus{Revision: r.revision}
+	for _, object := range r.objects {
+		status.Objects = append(status.Objects, object)
+	}
+	profiles.Sort(status.Objects)
+	return status
+}
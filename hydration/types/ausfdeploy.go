@@ -24,5 +24,12 @@ import (
 
 type AusfDeploy struct {
 	yaml.ResourceMeta `json:",inline" yaml:",inline"`
-	Spec              ausftypes.AusfDeploySpec `json:"spec" yaml:"spec"`
+	Spec              AusfDeploySpec `json:"spec" yaml:"spec"`
+}
+
+// AusfDeploySpec extends the common AusfDeploySpec with the PLMNs served by
+// the AUSF
+type AusfDeploySpec struct {
+	ausftypes.AusfDeploySpec `json:",inline" yaml:",inline"`
+	Plmns                    []Plmn `json:"plmns,omitempty" yaml:"plmns,omitempty"`
 }
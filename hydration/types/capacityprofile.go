@@ -38,8 +38,9 @@ type SmfCapacityProfile struct {
 }
 
 type SmfCPSpec struct {
-	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
-	MaxSessions int    `json:"maxSessions,omitempty" yaml:"maxSessions,omitempty"`
+	Name           string `json:"name,omitempty" yaml:"name,omitempty"`
+	MaxSessions    int    `json:"maxSessions,omitempty" yaml:"maxSessions,omitempty"`
+	MaxSubscribers int    `json:"maxSubscribers,omitempty" yaml:"maxSubscribers,omitempty"`
 }
 
 type AusfCapacityProfile struct {
@@ -30,4 +30,6 @@ type InterfaceCfgSpec struct {
 	ID             int      `json:"id" yaml:"id"`
 	IPAddr         []string `json:"ipAddr" yaml:"ipAddr"`
 	Vlan           []string `json:"vlan" yaml:"vlan"`
+	// SiteID restricts the entry to the NfDeploy site with this ID
+	SiteID string `json:"siteId,omitempty" yaml:"siteId,omitempty"`
 }
@@ -0,0 +1,35 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import "sigs.k8s.io/kustomize/kyaml/yaml"
+
+// NFFlavor maps a flavor of a NF type to one of its capacity profiles, for
+// e.g. the large flavor of upf to the UpfCapacityProfile upf-large
+type NFFlavor struct {
+	yaml.ResourceMeta `json:",inline" yaml:",inline"`
+	Spec              NFFlavorSpec `json:"spec" yaml:"spec"`
+}
+
+type NFFlavorSpec struct {
+	Flavor string `json:"flavor" yaml:"flavor"`
+	NFType string `json:"nfType" yaml:"nfType"`
+	// Vendor restricts the mapping to the NFs of the vendor. A mapping with
+	// vendor takes precedence over the one without it.
+	Vendor          string `json:"vendor,omitempty" yaml:"vendor,omitempty"`
+	CapacityProfile string `json:"capacityProfile" yaml:"capacityProfile"`
+}
@@ -0,0 +1,30 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+// Peer represents a neighbor NF connected to the NF over a reference point
+// as described by the connectivities of the site in NfDeploy.
+type Peer struct {
+	// SiteID of the neighbor NF in NfDeploy.
+	SiteID string `json:"siteId" yaml:"siteId"`
+	// NFType of the neighbor NF like upf, smf.
+	NFType string `json:"nfType" yaml:"nfType"`
+	// ReferencePoint between the NF and its neighbor like N4, N9.
+	ReferencePoint string `json:"referencePoint" yaml:"referencePoint"`
+	// Interfaces of the neighbor NF on the reference point.
+	Interfaces []NetworkInterface `json:"interfaces,omitempty" yaml:"interfaces,omitempty"`
+}
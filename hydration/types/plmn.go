@@ -0,0 +1,24 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+// Plmn is the identity of a PLMN served by the NF. MCC and MNC are strings
+// to keep their leading zeros.
+type Plmn struct {
+	MCC string `json:"mcc" yaml:"mcc"`
+	MNC string `json:"mnc" yaml:"mnc"`
+}
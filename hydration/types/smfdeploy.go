@@ -30,6 +30,8 @@ type SmfDeploySpec struct {
 	N7Interfaces  []NetworkInterface `json:"N7Interfaces,omitempty" yaml:"N7Interfaces,omitempty"`
 	N10Interfaces []NetworkInterface `json:"N10Interfaces,omitempty" yaml:"N10Interfaces,omitempty"`
 	N11Interfaces []NetworkInterface `json:"N11Interfaces,omitempty" yaml:"N11Interfaces,omitempty"`
+	Peers         []Peer             `json:"peers,omitempty" yaml:"peers,omitempty"`
+	Plmns         []Plmn             `json:"plmns,omitempty" yaml:"plmns,omitempty"`
 }
 
 type SmfCapacity struct {
@@ -30,4 +30,8 @@ type SmfTypeSpec struct {
 	N7InterfaceProfile  []NFTypeInterfaceProfile `json:"N7InterfaceProfile,omitempty" yaml:"N7InterfaceProfile,omitempty"`
 	N10InterfaceProfile []NFTypeInterfaceProfile `json:"N10InterfaceProfile,omitempty" yaml:"N10InterfaceProfile,omitempty"`
 	N11InterfaceProfile []NFTypeInterfaceProfile `json:"N11InterfaceProfile,omitempty" yaml:"N11InterfaceProfile,omitempty"`
+	NFBGPConfig         NFTypeBGPConfig          `json:"nfBgpConfig,omitempty" yaml:"nfBgpConfig,omitempty"`
+	// InterfaceGroup restricts the InterfaceConfig entries used by the sites
+	// to this group
+	InterfaceGroup string `json:"interfaceGroup,omitempty" yaml:"interfaceGroup,omitempty"`
 }
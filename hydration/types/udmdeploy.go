@@ -24,5 +24,12 @@ import (
 
 type UdmDeploy struct {
 	yaml.ResourceMeta `json:",inline" yaml:",inline"`
-	Spec              udmtypes.UdmDeploySpec `json:"spec" yaml:"spec"`
+	Spec              UdmDeploySpec `json:"spec" yaml:"spec"`
+}
+
+// UdmDeploySpec extends the common UdmDeploySpec with the PLMNs served by
+// the UDM
+type UdmDeploySpec struct {
+	udmtypes.UdmDeploySpec `json:",inline" yaml:",inline"`
+	Plmns                  []Plmn `json:"plmns,omitempty" yaml:"plmns,omitempty"`
 }
@@ -33,6 +33,8 @@ type UpfDeploySpec struct {
 	N4Interfaces []NetworkInterface `json:"N4Interfaces,omitempty" yaml:"N4Interfaces,omitempty"`
 	N6Interfaces []NetworkInterface `json:"N6Interfaces,omitempty" yaml:"N6Interfaces,omitempty"`
 	N9Interfaces []NetworkInterface `json:"N9Interfaces,omitempty" yaml:"N9Interfaces,omitempty"`
+	Peers        []Peer             `json:"peers,omitempty" yaml:"peers,omitempty"`
+	Plmns        []Plmn             `json:"plmns,omitempty" yaml:"plmns,omitempty"`
 	VendorRef    *ObjectReference   `json:"vendorRef,omitempty" yaml:"vendorRef,omitempty"`
 }
 
@@ -30,12 +30,22 @@ type UpfTypeSpec struct {
 	N4InterfaceProfile []NFTypeInterfaceProfile `json:"N4InterfaceProfile,omitempty" yaml:"N4InterfaceProfile,omitempty"`
 	N6InterfaceProfile []NFTypeInterfaceProfile `json:"N6InterfaceProfile,omitempty" yaml:"N6InterfaceProfile,omitempty"`
 	N9InterfaceProfile []NFTypeInterfaceProfile `json:"N9InterfaceProfile,omitempty" yaml:"N9InterfaceProfile,omitempty"`
+	NFBGPConfig        NFTypeBGPConfig          `json:"nfBgpConfig,omitempty" yaml:"nfBgpConfig,omitempty"`
+	// InterfaceGroup restricts the InterfaceConfig entries used by the sites
+	// to this group
+	InterfaceGroup string `json:"interfaceGroup,omitempty" yaml:"interfaceGroup,omitempty"`
 }
 
 type NFTypeCapacityProfile struct {
 	ProfileName string `json:"profileName,omitempty" yaml:"profileName,omitempty"`
 }
 
+// NFTypeBGPConfig refers to the NfBgpConfig to be used by the sites of the
+// NfType. When not set, the NfBgpConfig is selected by its nfName.
+type NFTypeBGPConfig struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
 type NFTypeInterfaceProfile struct {
 	InterfaceProfileName string `json:"intfProfileName,omitempty" yaml:"intfProfileName,omitempty"`
 	ID                   int    `json:"id,omitempty" yaml:"id,omitempty"`
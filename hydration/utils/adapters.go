@@ -19,17 +19,47 @@ package utils
 import (
 	"fmt"
 
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 	"github.com/nephio-project/nf-deploy-controller/hydration/types"
 )
 
-func GetInterfaceConfigSpecMap(configs []*types.InterfaceConfig) map[int]types.InterfaceCfgSpec {
-	resp := make(map[int]types.InterfaceCfgSpec)
+// GetInterfaceConfigSpecMap returns the InterfaceConfig entries of the site
+// keyed by ID. The shared entries are overlaid by ID with the entries
+// referring to the site by siteId or nfName. Entries referring to another
+// site by siteId are not used, while an nfName which is not the site id is
+// free-form and its entry is shared.
+// When interfaceGroup is given only the entries of that group are used.
+// Returns error if an ID is present more than once in the shared entries or
+// in the entries of the site.
+func GetInterfaceConfigSpecMap(configs []*types.InterfaceConfig, siteID,
+	interfaceGroup string) (map[int]types.InterfaceCfgSpec, error) {
+
+	shared := make(map[int]types.InterfaceCfgSpec)
+	siteScoped := make(map[int]types.InterfaceCfgSpec)
 	for _, config := range configs {
 		for _, spec := range config.Spec {
-			resp[spec.ID] = spec
+			if interfaceGroup != "" && spec.InterfaceGroup != interfaceGroup {
+				continue
+			}
+			specs := shared
+			if spec.SiteID == siteID || (spec.SiteID == "" && spec.NFName == siteID) {
+				specs = siteScoped
+			} else if spec.SiteID != "" {
+				continue
+			}
+			if existing, ok := specs[spec.ID]; ok {
+				return nil, fmt.Errorf(
+					"duplicate interfaceConfig ID:%d for site %s in %s and %s",
+					spec.ID, siteID, existing.Name, spec.Name,
+				)
+			}
+			specs[spec.ID] = spec
 		}
 	}
-	return resp
+	for id, spec := range siteScoped {
+		shared[id] = spec
+	}
+	return shared, nil
 }
 
 func GetBGPConfig(nfBgpConfig *types.NFBGPConfig) []types.BGPConfig {
@@ -91,6 +121,19 @@ func GetNetworkInterfaces(iProfiles []types.NFTypeInterfaceProfile,
 	return resp, nil
 }
 
+// GetPlmns returns all the PLMNs of the NfDeploy with MCC and MNC formatted
+// to their number of digits
+func GetPlmns(spec deployv1alpha1.NfDeploySpec) []types.Plmn {
+	var resp []types.Plmn
+	for _, plmn := range spec.GetPlmns() {
+		resp = append(resp, types.Plmn{
+			MCC: plmn.MCCString(),
+			MNC: plmn.MNCString(),
+		})
+	}
+	return resp
+}
+
 func emptyIfNil[T any](slice []T) []T {
 	if slice == nil {
 		return []T{}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
+	"github.com/nephio-project/nf-deploy-controller/hydration/utils"
+)
+
+var _ = Describe("GetInterfaceConfigSpecMap", func() {
+	upf1N3 := types.InterfaceCfgSpec{Name: "upf1-n3", SiteID: "upf1", InterfaceGroup: "ran", ID: 1}
+	upf1N6 := types.InterfaceCfgSpec{Name: "upf1-n6", SiteID: "upf1", InterfaceGroup: "core", ID: 2}
+	upf2N3 := types.InterfaceCfgSpec{Name: "upf2-n3", SiteID: "upf2", InterfaceGroup: "ran", ID: 1}
+	sharedN3 := types.InterfaceCfgSpec{Name: "shared-n3", InterfaceGroup: "ran", ID: 1}
+	sharedN6 := types.InterfaceCfgSpec{Name: "shared-n6", NFName: "nf1", InterfaceGroup: "core", ID: 2}
+	sharedN9 := types.InterfaceCfgSpec{Name: "shared-n9", InterfaceGroup: "core", ID: 3}
+	configs := []*types.InterfaceConfig{
+		{Spec: []types.InterfaceCfgSpec{upf1N3, upf1N6}},
+		{Spec: []types.InterfaceCfgSpec{upf2N3, sharedN3, sharedN6, sharedN9}},
+	}
+
+	It("Should overlay the shared entries with the entries of the site by ID", func() {
+		resp, err := utils.GetInterfaceConfigSpecMap(configs, "upf1", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(map[int]types.InterfaceCfgSpec{1: upf1N3, 2: upf1N6, 3: sharedN9}))
+	})
+
+	It("Should keep the shared entries whose IDs the site does not define", func() {
+		resp, err := utils.GetInterfaceConfigSpecMap(configs, "upf2", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(map[int]types.InterfaceCfgSpec{1: upf2N3, 2: sharedN6, 3: sharedN9}))
+	})
+
+	It("Should use only the entries of the interface group", func() {
+		resp, err := utils.GetInterfaceConfigSpecMap(configs, "upf1", "core")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(map[int]types.InterfaceCfgSpec{2: upf1N6, 3: sharedN9}))
+	})
+
+	It("Should use the entries referring to the site by nfName", func() {
+		upf3N3 := types.InterfaceCfgSpec{Name: "upf3-n3", NFName: "upf3", InterfaceGroup: "ran", ID: 1}
+		resp, err := utils.GetInterfaceConfigSpecMap(
+			[]*types.InterfaceConfig{{Spec: []types.InterfaceCfgSpec{sharedN3, upf3N3}}}, "upf3", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(map[int]types.InterfaceCfgSpec{1: upf3N3}))
+	})
+
+	It("Should share the entries whose nfName is not a site id", func() {
+		resp, err := utils.GetInterfaceConfigSpecMap(
+			[]*types.InterfaceConfig{{Spec: []types.InterfaceCfgSpec{upf2N3, sharedN6}}}, "upf3", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(map[int]types.InterfaceCfgSpec{2: sharedN6}))
+	})
+
+	It("Should return error for duplicate IDs in the entries of the site", func() {
+		upf1N3Dup := types.InterfaceCfgSpec{Name: "upf1-n3-dup", SiteID: "upf1", ID: 1}
+		_, err := utils.GetInterfaceConfigSpecMap(
+			append(configs, &types.InterfaceConfig{Spec: []types.InterfaceCfgSpec{upf1N3Dup}}), "upf1", "")
+		Expect(err).To(MatchError("duplicate interfaceConfig ID:1 for site upf1 in upf1-n3 and upf1-n3-dup"))
+	})
+
+	It("Should return error for duplicate IDs in the shared entries", func() {
+		sharedN3Dup := types.InterfaceCfgSpec{Name: "shared-n3-dup", NFName: "nf2", ID: 1}
+		_, err := utils.GetInterfaceConfigSpecMap(
+			append(configs, &types.InterfaceConfig{Spec: []types.InterfaceCfgSpec{sharedN3Dup}}), "upf1", "")
+		Expect(err).To(MatchError("duplicate interfaceConfig ID:1 for site upf1 in shared-n3 and shared-n3-dup"))
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"math/big"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+)
+
+// SplitCapacity splits the capacity intent of NfDeploy across its UPF and
+// SMF sites as per their capacity weights and returns the share of each site
+// keyed by site ID. The throughput and the sessions are split across the UPF
+// sites while the sessions and the subscribers are split across the SMF
+// sites. Returns nil if NfDeploy has no capacity intent.
+func SplitCapacity(spec deployv1alpha1.NfDeploySpec) map[string]*deployv1alpha1.Capacity {
+	if spec.CapacityIntent == nil {
+		return nil
+	}
+	resp := make(map[string]*deployv1alpha1.Capacity)
+	upfSites, upfWeights := sitesOfType(spec.Sites, UPFKind)
+	sessions := splitInt(int64(spec.CapacityIntent.MaxSessions), upfWeights)
+	uplink := splitQuantity(spec.CapacityIntent.UplinkThroughput, upfWeights)
+	downlink := splitQuantity(spec.CapacityIntent.DownlinkThroughput, upfWeights)
+	for i, siteID := range upfSites {
+		resp[siteID] = &deployv1alpha1.Capacity{
+			UplinkThroughput:   uplink[i],
+			DownlinkThroughput: downlink[i],
+			MaxSessions:        int(sessions[i]),
+		}
+	}
+	smfSites, smfWeights := sitesOfType(spec.Sites, SMFKind)
+	sessions = splitInt(int64(spec.CapacityIntent.MaxSessions), smfWeights)
+	subscribers := splitInt(int64(spec.CapacityIntent.MaxSubscribers), smfWeights)
+	for i, siteID := range smfSites {
+		resp[siteID] = &deployv1alpha1.Capacity{
+			MaxSessions:    int(sessions[i]),
+			MaxSubscribers: int(subscribers[i]),
+		}
+	}
+	return resp
+}
+
+// sitesOfType returns the IDs and the capacity weights of the sites of the
+// given NF type
+func sitesOfType(sites []deployv1alpha1.Site, nfType string) ([]string, []int64) {
+	ids := []string{}
+	weights := []int64{}
+	for _, s := range sites {
+		if s.NFType != nfType {
+			continue
+		}
+		weight := int64(s.CapacityWeight)
+		if weight == 0 {
+			weight = 1
+		}
+		ids = append(ids, s.Id)
+		weights = append(weights, weight)
+	}
+	return ids, weights
+}
+
+// splitInt splits total in proportion to the weights. The remainder of the
+// integer division goes to the first sites so that the shares add up to
+// total.
+func splitInt(total int64, weights []int64) []int64 {
+	resp := make([]int64, len(weights))
+	if len(weights) == 0 {
+		return resp
+	}
+	var sum int64
+	for _, w := range weights {
+		sum += w
+	}
+	bigTotal, bigSum := big.NewInt(total), big.NewInt(sum)
+	var assigned int64
+	for i, w := range weights {
+		share := new(big.Int).Mul(bigTotal, big.NewInt(w))
+		resp[i] = share.Quo(share, bigSum).Int64()
+		assigned += resp[i]
+	}
+	for i := 0; assigned < total; i = (i + 1) % len(resp) {
+		resp[i]++
+		assigned++
+	}
+	return resp
+}
+
+func splitQuantity(total *resource.Quantity, weights []int64) []*resource.Quantity {
+	resp := make([]*resource.Quantity, len(weights))
+	if total == nil {
+		return resp
+	}
+	for i, share := range splitInt(total.Value(), weights) {
+		resp[i] = resource.NewQuantity(share, total.Format)
+	}
+	return resp
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/utils"
+)
+
+var _ = Describe("SplitCapacity", func() {
+	uplink := resource.MustParse("10M")
+	downlink := resource.MustParse("100M")
+	spec := deployv1alpha1.NfDeploySpec{
+		CapacityIntent: &deployv1alpha1.Capacity{
+			UplinkThroughput:   &uplink,
+			DownlinkThroughput: &downlink,
+			MaxSessions:        1001,
+			MaxSubscribers:     500,
+		},
+		Sites: []deployv1alpha1.Site{
+			{Id: "upf1", NFType: "upf"},
+			{Id: "upf2", NFType: "upf"},
+			{Id: "smf1", NFType: "smf", CapacityWeight: 3},
+			{Id: "smf2", NFType: "smf", CapacityWeight: 1},
+			{Id: "ausf1", NFType: "ausf"},
+		},
+	}
+
+	It("Should split evenly across sites without weight", func() {
+		resp := utils.SplitCapacity(spec)
+		Expect(resp["upf1"].UplinkThroughput.String()).To(Equal("5M"))
+		Expect(resp["upf2"].DownlinkThroughput.String()).To(Equal("50M"))
+		Expect(resp["upf1"].MaxSessions).To(Equal(501))
+		Expect(resp["upf2"].MaxSessions).To(Equal(500))
+		Expect(resp["upf1"].MaxSubscribers).To(BeZero())
+	})
+
+	It("Should split by weight across weighted sites", func() {
+		resp := utils.SplitCapacity(spec)
+		Expect(resp["smf1"]).To(Equal(&deployv1alpha1.Capacity{MaxSessions: 751, MaxSubscribers: 375}))
+		Expect(resp["smf2"]).To(Equal(&deployv1alpha1.Capacity{MaxSessions: 250, MaxSubscribers: 125}))
+	})
+
+	It("Should not split across other NF types", func() {
+		Expect(utils.SplitCapacity(spec)).NotTo(HaveKey("ausf1"))
+	})
+
+	It("Should return nil without capacity intent", func() {
+		Expect(utils.SplitCapacity(deployv1alpha1.NfDeploySpec{Sites: spec.Sites})).To(BeNil())
+	})
+})
@@ -32,9 +32,9 @@ import (
 // all the NfTypes (UPF, SMF) to interact with packageservice
 //===========================================================================
 
-// GetReferencedProfiles returns the nfBgpConfig, interfaceConfig
+// GetReferencedProfiles returns all the nfBgpConfigs and interfaceConfigs
 func GetReferencedProfiles(ctx context.Context, psi ps.PackageServiceInterface, nfBgpKind,
-	interfaceConfigKind string, nc nfdeployutil.NamingContext) (*types.NFBGPConfig, []*types.InterfaceConfig, error) {
+	interfaceConfigKind string, nc nfdeployutil.NamingContext) ([]*types.NFBGPConfig, []*types.InterfaceConfig, error) {
 
 	nfProfilesMap, err := psi.GetNFProfiles(ctx, []ps.GetResourceRequest{
 		{
@@ -51,18 +51,10 @@ func GetReferencedProfiles(ctx context.Context, psi ps.PackageServiceInterface,
 	if err != nil {
 		return nil, nil, err
 	}
-	// Assumption: 	We are assuming that there will be at the most one NfBgpConfig file containing details
-	//   about all the virtualRouters as there is no name for NfBgpConfig specified in NfDeploy/NfType CRDs
-	//   to fetch the specific NfBgpConfig.
-	if len(nfProfilesMap[1]) > 1 {
-		return nil, nil, fmt.Errorf("expecting at the most one %s kind, received: %d",
-			nfBgpKind, len(nfProfilesMap[1]))
-	}
-	nfBgpConfig := &types.NFBGPConfig{}
-	if len(nfProfilesMap[1]) == 0 {
-		nfBgpConfig = nil
-	} else {
-		err = yaml.Unmarshal([]byte(nfProfilesMap[1][0]), nfBgpConfig)
+	nfBgpConfigs := make([]*types.NFBGPConfig, len(nfProfilesMap[1]))
+	for i := range nfProfilesMap[1] {
+		nfBgpConfigs[i] = &types.NFBGPConfig{}
+		err = yaml.Unmarshal([]byte(nfProfilesMap[1][i]), nfBgpConfigs[i])
 		if err != nil {
 			return nil, nil, err
 		}
@@ -75,7 +67,54 @@ func GetReferencedProfiles(ctx context.Context, psi ps.PackageServiceInterface,
 			return nil, nil, err
 		}
 	}
-	return nfBgpConfig, ic, err
+	return nfBgpConfigs, ic, err
+}
+
+// SelectNFBGPConfig returns the NfBgpConfig to be used by the site. It is
+// selected in the below order
+//  1. the NfBgpConfig named by the NfType of the site
+//  2. the NfBgpConfig with nfName same as the site ID
+//  3. the only NfBgpConfig in the package
+//  4. the only NfBgpConfig without nfName
+//
+// Returns nil if there is no NfBgpConfig and an error if the selection is
+// ambiguous.
+func SelectNFBGPConfig(nfBgpConfigs []*types.NFBGPConfig, ref types.NFTypeBGPConfig,
+	siteID string) (*types.NFBGPConfig, error) {
+
+	if ref.Name != "" {
+		for _, config := range nfBgpConfigs {
+			if config.Name == ref.Name {
+				return config, nil
+			}
+		}
+		return nil, fmt.Errorf("did not find %s with name:%s", NFBGPConfigKind, ref.Name)
+	}
+	if len(nfBgpConfigs) <= 1 {
+		for _, config := range nfBgpConfigs {
+			return config, nil
+		}
+		return nil, nil
+	}
+	var bySite, unnamed []*types.NFBGPConfig
+	for _, config := range nfBgpConfigs {
+		switch config.Spec.NFName {
+		case siteID:
+			bySite = append(bySite, config)
+		case "":
+			unnamed = append(unnamed, config)
+		}
+	}
+	if len(bySite) == 1 {
+		return bySite[0], nil
+	}
+	if len(bySite) == 0 && len(unnamed) == 1 {
+		return unnamed[0], nil
+	}
+	return nil, fmt.Errorf(
+		"could not select %s for site %s out of %d: name it in the NfType or set its nfName to the site ID",
+		NFBGPConfigKind, siteID, len(nfBgpConfigs),
+	)
 }
 
 // GetInterfaceProfile returns InterfaceProfile map with name as key
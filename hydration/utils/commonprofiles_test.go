@@ -0,0 +1,84 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
+	"github.com/nephio-project/nf-deploy-controller/hydration/utils"
+)
+
+func newNFBGPConfig(name, nfName string) *types.NFBGPConfig {
+	config := &types.NFBGPConfig{Spec: types.NFBGPSpec{Name: name, NFName: nfName}}
+	config.Name = name
+	return config
+}
+
+var _ = Describe("SelectNFBGPConfig", func() {
+	upf1 := newNFBGPConfig("bgp-upf1", "upf1")
+	upf2 := newNFBGPConfig("bgp-upf2", "upf2")
+	common := newNFBGPConfig("bgp-common", "")
+
+	It("Should select the NfBgpConfig named by the NfType", func() {
+		resp, err := utils.SelectNFBGPConfig([]*types.NFBGPConfig{upf1, upf2, common},
+			types.NFTypeBGPConfig{Name: "bgp-upf2"}, "upf1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(upf2))
+	})
+
+	It("Should return error when the named NfBgpConfig is not present", func() {
+		_, err := utils.SelectNFBGPConfig([]*types.NFBGPConfig{upf1},
+			types.NFTypeBGPConfig{Name: "bgp-upf2"}, "upf1")
+		Expect(err).To(MatchError("did not find NfBgpConfig with name:bgp-upf2"))
+	})
+
+	It("Should select the NfBgpConfig by nfName", func() {
+		resp, err := utils.SelectNFBGPConfig([]*types.NFBGPConfig{upf1, upf2, common},
+			types.NFTypeBGPConfig{}, "upf2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(upf2))
+	})
+
+	It("Should select the only NfBgpConfig", func() {
+		resp, err := utils.SelectNFBGPConfig([]*types.NFBGPConfig{upf1},
+			types.NFTypeBGPConfig{}, "upf2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(upf1))
+	})
+
+	It("Should fall back to the NfBgpConfig without nfName", func() {
+		resp, err := utils.SelectNFBGPConfig([]*types.NFBGPConfig{upf1, common},
+			types.NFTypeBGPConfig{}, "upf3")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(common))
+	})
+
+	It("Should return nil when there is no NfBgpConfig", func() {
+		resp, err := utils.SelectNFBGPConfig(nil, types.NFTypeBGPConfig{}, "upf1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(BeNil())
+	})
+
+	It("Should return error when the selection is ambiguous", func() {
+		_, err := utils.SelectNFBGPConfig([]*types.NFBGPConfig{upf1, upf2},
+			types.NFTypeBGPConfig{}, "upf3")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("could not select NfBgpConfig for site upf3 out of 2"))
+	})
+})
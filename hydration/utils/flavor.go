@@ -0,0 +1,97 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
+	ps "github.com/nephio-project/nf-deploy-controller/packageservice"
+	nfdeployutil "github.com/nephio-project/nf-deploy-controller/util"
+)
+
+const NFFlavorKind = "NfFlavor"
+
+// GetFlavorCapacityProfileName returns the name of the capacity profile
+// mapped to the flavor of the site by the NfFlavors in the nf-profiles.
+// Returns an empty name when the site has no flavor.
+func GetFlavorCapacityProfileName(ctx context.Context, psi ps.PackageServiceInterface,
+	s deployv1alpha1.Site, nc nfdeployutil.NamingContext) (string, error) {
+
+	if s.Flavor == "" {
+		return "", nil
+	}
+	nfProfilesMap, err := psi.GetNFProfiles(ctx, []ps.GetResourceRequest{
+		{
+			ID:         1,
+			ApiVersion: IpAPIVersion,
+			Kind:       NFFlavorKind,
+		},
+	}, nc)
+	if err != nil {
+		return "", err
+	}
+	flavors := make([]*types.NFFlavor, len(nfProfilesMap[1]))
+	for i := range nfProfilesMap[1] {
+		flavors[i] = &types.NFFlavor{}
+		err = yaml.Unmarshal([]byte(nfProfilesMap[1][i]), flavors[i])
+		if err != nil {
+			return "", err
+		}
+	}
+	flavor, err := SelectNFFlavor(flavors, s)
+	if err != nil {
+		return "", err
+	}
+	return flavor.Spec.CapacityProfile, nil
+}
+
+// SelectNFFlavor returns the NfFlavor mapping the flavor of the site for its
+// NF type and vendor, preferring the one specific to the vendor over the one
+// for all the vendors
+func SelectNFFlavor(flavors []*types.NFFlavor, s deployv1alpha1.Site) (*types.NFFlavor, error) {
+	var byVendor, anyVendor []*types.NFFlavor
+	for _, flavor := range flavors {
+		if flavor.Spec.Flavor != s.Flavor || flavor.Spec.NFType != s.NFType {
+			continue
+		}
+		switch flavor.Spec.Vendor {
+		case s.NFVendor:
+			byVendor = append(byVendor, flavor)
+		case "":
+			anyVendor = append(anyVendor, flavor)
+		}
+	}
+	candidates := byVendor
+	if len(candidates) == 0 {
+		candidates = anyVendor
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("did not find %s for flavor %s of nfType %s and vendor %s",
+			NFFlavorKind, s.Flavor, s.NFType, s.NFVendor)
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, fmt.Errorf("found %d %s for flavor %s of nfType %s and vendor %s, expecting one",
+			len(candidates), NFFlavorKind, s.Flavor, s.NFType, s.NFVendor)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
+	"github.com/nephio-project/nf-deploy-controller/hydration/utils"
+)
+
+func getNFFlavor(flavor, nfType, vendor, capacityProfile string) *types.NFFlavor {
+	return &types.NFFlavor{
+		Spec: types.NFFlavorSpec{
+			Flavor:          flavor,
+			NFType:          nfType,
+			Vendor:          vendor,
+			CapacityProfile: capacityProfile,
+		},
+	}
+}
+
+var _ = Describe("SelectNFFlavor", func() {
+	flavors := []*types.NFFlavor{
+		getNFFlavor("large", "upf", "", "upf-large"),
+		getNFFlavor("large", "upf", "casa", "upf-casa-large"),
+		getNFFlavor("large", "smf", "", "smf-large"),
+		getNFFlavor("small", "upf", "", "upf-small"),
+	}
+	site := deployv1alpha1.Site{Id: "upf1", NFType: "upf", NFVendor: "casa", Flavor: "large"}
+
+	It("Should prefer the flavor of the vendor", func() {
+		resp, err := utils.SelectNFFlavor(flavors, site)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Spec.CapacityProfile).To(Equal("upf-casa-large"))
+	})
+
+	It("Should fall back to the flavor for all vendors", func() {
+		s := site
+		s.NFVendor = "other"
+		resp, err := utils.SelectNFFlavor(flavors, s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Spec.CapacityProfile).To(Equal("upf-large"))
+	})
+
+	It("Should fail for an unknown flavor", func() {
+		s := site
+		s.Flavor = "medium"
+		_, err := utils.SelectNFFlavor(flavors, s)
+		Expect(err).To(MatchError("did not find NfFlavor for flavor medium of nfType upf and vendor casa"))
+	})
+
+	It("Should fail for an ambiguous flavor", func() {
+		_, err := utils.SelectNFFlavor(append(flavors, getNFFlavor("large", "upf", "casa", "other")), site)
+		Expect(err).To(MatchError(ContainSubstring("found 2 NfFlavor")))
+	})
+})
@@ -0,0 +1,141 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/merge2"
+	yamlutil "sigs.k8s.io/yaml"
+
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
+)
+
+const (
+	AppliedOverridesName  = "%s-overrides" // NfTypeDeploy name-overrides
+	LocalConfigAnnotation = "config.kubernetes.io/local-config"
+)
+
+// ApplyOverrides strategic-merges and then JSON-patches the overrides onto
+// the generated NfTypeDeploy. The result is validated by unmarshalling it
+// strictly into target, which must be the type of the NfTypeDeploy like
+// *types.UpfDeploy. The overrides can not change the kind, name or namespace.
+func ApplyOverrides(content []byte, overrides *deployv1alpha1.SiteOverrides,
+	target interface{}) ([]byte, error) {
+
+	before := &yaml.ResourceMeta{}
+	if err := yamlutil.Unmarshal(content, before); err != nil {
+		return nil, err
+	}
+	out := content
+	if overrides.Merge != nil && len(overrides.Merge.Raw) > 0 {
+		src, err := yamlutil.JSONToYAML(overrides.Merge.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid merge: %w", err)
+		}
+		merged, err := merge2.MergeStrings(string(src), string(out), true, yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error applying merge: %w", err)
+		}
+		out = []byte(merged)
+	}
+	if len(overrides.JSONPatch) > 0 {
+		patchJSON, err := json.Marshal(overrides.JSONPatch)
+		if err != nil {
+			return nil, err
+		}
+		patch, err := jsonpatch.DecodePatch(patchJSON)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonPatch: %w", err)
+		}
+		doc, err := yamlutil.YAMLToJSON(out)
+		if err != nil {
+			return nil, err
+		}
+		patched, err := patch.Apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("error applying jsonPatch: %w", err)
+		}
+		out = patched
+	}
+	if err := yamlutil.UnmarshalStrict(out, target); err != nil {
+		return nil, fmt.Errorf("overridden %s is invalid: %w", before.Kind, err)
+	}
+	after := &yaml.ResourceMeta{}
+	if err := yamlutil.Unmarshal(out, after); err != nil {
+		return nil, err
+	}
+	if after.Kind != before.Kind || after.Name != before.Name ||
+		after.Namespace != before.Namespace {
+		return nil, fmt.Errorf("overrides can not change the kind, name or namespace of %s %s",
+			before.Kind, before.Name)
+	}
+	return yamlutil.Marshal(target)
+}
+
+// GetAppliedOverrides returns the record of the overrides applied to the
+// NfTypeDeploy in content
+func GetAppliedOverrides(content []byte,
+	overrides *deployv1alpha1.SiteOverrides) (*types.AppliedOverrides, error) {
+
+	target := &yaml.ResourceMeta{}
+	if err := yamlutil.Unmarshal(content, target); err != nil {
+		return nil, err
+	}
+	data := map[string]string{
+		"target": target.Kind + "/" + target.Name,
+	}
+	if overrides.Merge != nil && len(overrides.Merge.Raw) > 0 {
+		merge, err := yamlutil.JSONToYAML(overrides.Merge.Raw)
+		if err != nil {
+			return nil, err
+		}
+		data["merge"] = string(merge)
+	}
+	if len(overrides.JSONPatch) > 0 {
+		patch, err := yamlutil.Marshal(overrides.JSONPatch)
+		if err != nil {
+			return nil, err
+		}
+		data["jsonPatch"] = string(patch)
+	}
+	return &types.AppliedOverrides{
+		ResourceMeta: yaml.ResourceMeta{
+			TypeMeta: yaml.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+			},
+			ObjectMeta: yaml.ObjectMeta{
+				NameMeta: yaml.NameMeta{
+					Name:      fmt.Sprintf(AppliedOverridesName, target.Name),
+					Namespace: target.Namespace,
+				},
+				Labels: target.Labels,
+				Annotations: map[string]string{
+					LocalConfigAnnotation: "true",
+				},
+			},
+		},
+		Data: data,
+	}, nil
+}
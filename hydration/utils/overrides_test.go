@@ -0,0 +1,104 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	yamlutil "sigs.k8s.io/yaml"
+
+	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/hydration/types"
+	"github.com/nephio-project/nf-deploy-controller/hydration/utils"
+)
+
+var _ = Describe("ApplyOverrides", func() {
+	var content []byte
+
+	BeforeEach(func() {
+		var err error
+		content, err = os.ReadFile("../testhelper/upfdeploy1.yaml")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should merge and patch the NfTypeDeploy", func() {
+		overrides := &deployv1alpha1.SiteOverrides{
+			Merge: &runtime.RawExtension{
+				Raw: []byte(`{"spec":{"capacity":{"maximumConnections":5}}}`),
+			},
+			JSONPatch: []deployv1alpha1.JSONPatchOperation{
+				{Op: "replace", Path: "/spec/N9Interfaces/0/vlan",
+					Value: &runtime.RawExtension{Raw: []byte(`["500"]`)}},
+				{Op: "remove", Path: "/spec/BgpConfig/1"},
+			},
+		}
+		resp, err := utils.ApplyOverrides(content, overrides, &types.UpfDeploy{})
+		Expect(err).NotTo(HaveOccurred())
+		upfDeploy := &types.UpfDeploy{}
+		Expect(yamlutil.Unmarshal(resp, upfDeploy)).To(Succeed())
+		Expect(upfDeploy.Name).To(Equal("upfdeploy-upf1"))
+		Expect(upfDeploy.Spec.Capacity.MaximumConnections).To(Equal(5))
+		Expect(upfDeploy.Spec.Capacity.UplinkThroughput.String()).To(Equal("1M"))
+		Expect(upfDeploy.Spec.N9Interfaces[0].Vlan).To(Equal([]string{"500"}))
+		Expect(upfDeploy.Spec.BGPConfigs).To(HaveLen(1))
+	})
+
+	It("Should fail for fields unknown to the NfTypeDeploy", func() {
+		overrides := &deployv1alpha1.SiteOverrides{
+			Merge: &runtime.RawExtension{Raw: []byte(`{"spec":{"unknown":1}}`)},
+		}
+		_, err := utils.ApplyOverrides(content, overrides, &types.UpfDeploy{})
+		Expect(err).To(MatchError(ContainSubstring("overridden UpfDeploy is invalid")))
+	})
+
+	It("Should fail when the name is changed", func() {
+		overrides := &deployv1alpha1.SiteOverrides{
+			JSONPatch: []deployv1alpha1.JSONPatchOperation{
+				{Op: "replace", Path: "/metadata/name",
+					Value: &runtime.RawExtension{Raw: []byte(`"other"`)}},
+			},
+		}
+		_, err := utils.ApplyOverrides(content, overrides, &types.UpfDeploy{})
+		Expect(err).To(MatchError(ContainSubstring("can not change the kind, name or namespace")))
+	})
+
+	It("Should fail when a patch does not apply", func() {
+		overrides := &deployv1alpha1.SiteOverrides{
+			JSONPatch: []deployv1alpha1.JSONPatchOperation{
+				{Op: "remove", Path: "/spec/N2Interfaces"},
+			},
+		}
+		_, err := utils.ApplyOverrides(content, overrides, &types.UpfDeploy{})
+		Expect(err).To(MatchError(ContainSubstring("error applying jsonPatch")))
+	})
+
+	It("Should record the applied overrides", func() {
+		overrides := &deployv1alpha1.SiteOverrides{
+			Merge: &runtime.RawExtension{Raw: []byte(`{"spec":{"capacity":{"maximumConnections":5}}}`)},
+		}
+		record, err := utils.GetAppliedOverrides(content, overrides)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(record.Name).To(Equal("upfdeploy-upf1-overrides"))
+		Expect(record.Annotations).To(HaveKeyWithValue(utils.LocalConfigAnnotation, "true"))
+		Expect(record.Data).To(HaveKeyWithValue("target", "UpfDeploy/upfdeploy-upf1"))
+		Expect(record.Data).To(HaveKey("merge"))
+		Expect(record.Data).NotTo(HaveKey("jsonPatch"))
+	})
+})
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/nephio-project/common-lib/edge/approve"
@@ -29,6 +30,7 @@ import (
 	"google.golang.org/grpc"
 	"k8s.io/client-go/dynamic"
 
+	"github.com/nephio-project/nf-deploy-controller/catalog"
 	crdreader "github.com/nephio-project/nf-deploy-controller/crd-reader"
 	deployment "github.com/nephio-project/nf-deploy-controller/deployment"
 
@@ -40,13 +42,20 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	nfdeployv1beta1 "github.com/nephio-project/nf-deploy-controller/api/v1beta1"
 	"github.com/nephio-project/nf-deploy-controller/controllers"
+	"github.com/nephio-project/nf-deploy-controller/drift"
 	"github.com/nephio-project/nf-deploy-controller/hydration"
+	"github.com/nephio-project/nf-deploy-controller/hydration/ipam"
 	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/profiles"
+	"github.com/nephio-project/nf-deploy-controller/status"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -59,17 +68,21 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(nfdeployv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(nfdeployv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
-const (
-	CRD_DIRECTORY = "CRD-DIRECTORY"
-)
-
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var ipAllocationsNamespace string
+	var deploymentOptions deployment.Options
+	var stalenessTimeouts string
+	var rolloutPollInterval time.Duration
+	var deletionPollInterval time.Duration
+	var terminationTimeout time.Duration
+	var driftCheckInterval time.Duration
 	flag.StringVar(
 		&metricsAddr, "metrics-bind-address", ":8080",
 		"The address the metric endpoint binds to.",
@@ -83,6 +96,52 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.",
 	)
+	flag.StringVar(
+		&ipAllocationsNamespace, "ip-allocations-namespace", "nephio-user",
+		"The namespace of the ConfigMaps which store the interface addresses "+
+			"allocated from the sites' ipAddrBlock.",
+	)
+	flag.IntVar(
+		&deploymentOptions.EventQueueSize, "edge-event-queue-size",
+		deployment.DefaultEventQueueSize,
+		"The number of NFs whose edge events can be queued per NfDeploy. "+
+			"Events of other NFs are dropped while the queue is full.",
+	)
+	flag.DurationVar(
+		&deploymentOptions.StatusFlushInterval, "status-flush-interval",
+		deployment.DefaultStatusFlushInterval,
+		"The minimum interval between two NfDeploy status updates driven by edge events.",
+	)
+	flag.StringVar(
+		&stalenessTimeouts, "nf-status-staleness-timeouts", "",
+		"Comma separated <nftype>=<duration> list, e.g. upf=5m,smf=5m. The status "+
+			"of a NF of the type is marked stale when no edge event of it is "+
+			"received within the duration. Staleness is not detected if not set.",
+	)
+	flag.DurationVar(
+		&rolloutPollInterval, "rollout-poll-interval",
+		controllers.DefaultRolloutPollInterval,
+		"The interval at which the NFs of the current wave of an ordered or "+
+			"progressive rollout are checked for readiness.",
+	)
+	flag.DurationVar(
+		&deletionPollInterval, "deletion-poll-interval",
+		controllers.DefaultDeletionPollInterval,
+		"The interval at which a deleted NfDeploy is checked while the deletion of "+
+			"its deploy packages awaits approval or its NFs are being removed from the "+
+			"workload clusters.",
+	)
+	flag.DurationVar(
+		&terminationTimeout, "termination-timeout",
+		controllers.DefaultTerminationTimeout,
+		"The time the workload clusters have to confirm the removal of the NFs of a "+
+			"deleted NfDeploy before it is reported Stalled.",
+	)
+	flag.DurationVar(
+		&driftCheckInterval, "drift-check-interval", drift.DefaultInterval,
+		"The interval at which the published deploy packages are compared with the "+
+			"content rendered for their NfDeploy. Drift is not detected if set to 0.",
+	)
 	opts := zap.Options{
 		Development: true,
 	}
@@ -91,6 +150,13 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	timeouts, err := deployment.ParseStalenessTimeouts(stalenessTimeouts)
+	if err != nil {
+		setupLog.Error(err, "invalid nf-status-staleness-timeouts")
+		os.Exit(1)
+	}
+	deploymentOptions.StalenessTimeouts = timeouts
+
 	mgr, err := ctrl.NewManager(
 		ctrl.GetConfigOrDie(), ctrl.Options{
 			Scheme:                 scheme,
@@ -117,17 +183,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	crdDir := os.Getenv(CRD_DIRECTORY)
-	setupLog.V(1).Info("reading capacity and interface profiles",
-		"crdDir", crdDir)
-
-	var crdReader crdreader.CRDReader = &crdreader.CRDSet{}
-	err = crdReader.ReadCRDFiles(crdDir)
-	if err != nil {
-		setupLog.Error(err, "CRD Reader initialisation failed")
-		os.Exit(1)
-	}
-
 	setupLog.V(1).Info("creating porch package service")
 
 	porchClient, err := packageservice.NewPorchClient(mgr.GetConfig())
@@ -139,9 +194,25 @@ func main() {
 		Client: porchClient,
 		Log:    ctrl.Log.WithName("PorchPackageService"),
 	}
+	// the deployment reads the capacity profiles from the nf-profiles
+	// revision each NfDeploy was hydrated with
+	var crdReader crdreader.CRDReader = &crdreader.PackageCRDReader{PS: ps}
+	// the allocations are read without a cache so that concurrent
+	// hydrations always see the latest allocations
+	ipamClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		setupLog.Error(err, "unable to create ip allocator client")
+		os.Exit(1)
+	}
 	h := &hydration.Hydration{
 		PS:  ps,
 		Log: ctrl.Log.WithName("Hydration"),
+		Allocator: &ipam.ConfigMapAllocator{
+			Client:    ipamClient,
+			Namespace: ipAllocationsNamespace,
+			Name:      ipam.DefaultAllocationsConfigMapName,
+			Log:       ctrl.Log.WithName("IPAllocator"),
+		},
 	}
 
 	setupLog.V(1).Info("creating k8s rest client")
@@ -195,27 +266,78 @@ func main() {
 
 	setupLog.V(1).Info("starting deployment")
 
+	statusAggregator := status.NewAggregator(
+		mgr.GetClient(), mgr.GetClient().Status(),
+		ctrl.Log.WithName("StatusAggregator"),
+	)
+	deploymentOptions.EventRecorder = mgr.GetEventRecorderFor("nfdeploy-controller")
 	var deploy deployment.DeploymentManager = deployment.NewDeploymentManager(
-		crdReader, subscriberChan, cancellationChan, mgr.GetClient(),
-		mgr.GetClient().Status(), ctrl.Log.WithName("Deployment"),
+		crdReader, subscriberChan, cancellationChan, statusAggregator,
+		deploymentOptions, ctrl.Log.WithName("Deployment"),
 	)
 
+	// NfDeploys to re-hydrate after the nf-profiles they read changed
+	profileEvents := make(chan event.GenericEvent, 100)
+	if err = mgr.Add(&profiles.Watcher{
+		Client:      mgr.GetClient(),
+		PorchClient: porchClient,
+		PS:          ps,
+		Events:      profileEvents,
+		Log:         ctrl.Log.WithName("ProfilesWatcher"),
+	}); err != nil {
+		setupLog.Error(err, "unable to add nf-profiles watcher")
+		os.Exit(1)
+	}
+	if err = mgr.Add(&catalog.Watcher{
+		Client:           mgr.GetClient(),
+		PorchClient:      porchClient,
+		Hydration:        h,
+		PS:               ps,
+		StatusAggregator: statusAggregator,
+		Log:              ctrl.Log.WithName("CatalogWatcher"),
+	}); err != nil {
+		setupLog.Error(err, "unable to add vendor catalog watcher")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.NfDeployReconciler{
-		Client:            mgr.GetClient(),
-		Scheme:            mgr.GetScheme(),
-		DeploymentManager: deploy,
-		Log:               ctrl.Log.WithName("controllers").WithName("NfDeploy"),
-		Hydration:         h,
-		PS:                ps,
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		DeploymentManager:    deploy,
+		Log:                  ctrl.Log.WithName("controllers").WithName("NfDeploy"),
+		Hydration:            h,
+		PS:                   ps,
+		StatusAggregator:     statusAggregator,
+		RolloutPollInterval:  rolloutPollInterval,
+		DeletionPollInterval: deletionPollInterval,
+		TerminationTimeout:   terminationTimeout,
+		ProfileEvents:        profileEvents,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NfDeploy")
 		os.Exit(1)
 	}
+	if driftCheckInterval > 0 {
+		if err = mgr.Add(&drift.Detector{
+			Client:           mgr.GetClient(),
+			Hydration:        h,
+			PS:               ps,
+			StatusAggregator: statusAggregator,
+			Interval:         driftCheckInterval,
+			Log:              ctrl.Log.WithName("DriftDetector"),
+		}); err != nil {
+			setupLog.Error(err, "unable to add drift detector")
+			os.Exit(1)
+		}
+	}
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		if err = (&nfdeployv1alpha1.NfDeploy{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "NfDeploy")
 			os.Exit(1)
 		}
+		if err = (&nfdeployv1beta1.NfDeploy{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NfDeploy")
+			os.Exit(1)
+		}
 	}
 	//+kubebuilder:scaffold:builder
 
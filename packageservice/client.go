@@ -28,16 +28,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// NewPorchClient creates a REST client for Porch server.
+// NewPorchClient creates a REST client for Porch server, which can also watch
+// the Porch resources.
 // A successful operation returns Client != nil and err == nil.
 // A unsuccessful operation returns Client == nil and err != nil.
-func NewPorchClient(config *rest.Config) (client.Client, error) {
+func NewPorchClient(config *rest.Config) (client.WithWatch, error) {
 	scheme, err := createScheme()
 	if err != nil {
 		return nil, err
 	}
 
-	c, err := client.New(config, client.Options{
+	c, err := client.NewWithWatch(config, client.Options{
 		Scheme: scheme,
 		Mapper: createRESTMapper(),
 	})
@@ -33,6 +33,9 @@ import (
 
 const (
 	KptfileName string = "Kptfile"
+	// NfDeployLabelKey is the label on the deploy package revisions set to the
+	// name of the NfDeploy owning them
+	NfDeployLabelKey string = "nfdeploy.nephio.org/nfdeploy"
 )
 
 // PorchPackageService implements PackageServiceInterface.
@@ -75,13 +78,37 @@ func (ps *PorchPackageService) GetNFProfiles(ctx context.Context, req []GetResou
 	return res, nil
 }
 
+// returns the name and the resources of the given revision of the NF profiles package, or of its
+// latest published revision when revision is empty
+func (ps *PorchPackageService) GetNFProfilesPackage(ctx context.Context,
+	nc util.NamingContext,
+	revision string) (string, map[string]string, error) {
+	if revision == "" {
+		pr, prr, _, err := ps.getLatestPackage(ctx, nc.GetNamespace(), nc.GetNFProfilePackageName(), nc.GetNFProfileRepoName())
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed to fetch latest package: %s : %w", nc.GetNFProfilePackageName(), err)
+		}
+		return pr.Name, prr.Spec.Resources, nil
+	}
+	prr, err := ps.getPackageRevisionResources(ctx, nc.GetNamespace(), revision)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to fetch package revision resources of %s: %w", revision, err)
+	}
+	if prr.Spec.PackageName != nc.GetNFProfilePackageName() || prr.Spec.RepositoryName != nc.GetNFProfileRepoName() {
+		return "", nil, fmt.Errorf("Package revision %s is not a revision of package: %s in repo: %s",
+			revision, nc.GetNFProfilePackageName(), nc.GetNFProfileRepoName())
+	}
+	return revision, prr.Spec.Resources, nil
+}
+
 // creates the package in the relevant deploy repository and returns the new package name
 func (ps *PorchPackageService) CreateDeployPackage(ctx context.Context, contents map[string]string, nc util.NamingContext) (string, error) {
 	// create the PackageRevision resource for the request
 	deployRepo := nc.GetDeployRepoName()
 	pName := nc.GetDeployPackageName()
 	ps.Log.Info(fmt.Sprintf("Creating package: %s in deploy repo: %s", pName, deployRepo))
-	pr, _, err := ps.createPackage(ctx, nc.GetNamespace(), pName, deployRepo, contents)
+	labels := map[string]string{NfDeployLabelKey: nc.GetNfDeployName()}
+	pr, _, err := ps.createPackage(ctx, nc.GetNamespace(), pName, deployRepo, labels, contents)
 	if err != nil {
 		return "", fmt.Errorf("Failed to create package: %s in deploy repo: %s : %w", pName, deployRepo, err)
 	}
@@ -123,7 +150,7 @@ func (ps *PorchPackageService) CreateNFDeployActuators(ctx context.Context,
 	if !createNewPkg {
 		return existingActuatorPR.Name, false, nil
 	}
-	newPR, _, err := ps.createPackage(ctx, nc.GetNamespace(), actuatorPkgName, actuatorDstRepo, actuatorPRR.Spec.Resources)
+	newPR, _, err := ps.createPackage(ctx, nc.GetNamespace(), actuatorPkgName, actuatorDstRepo, nil, actuatorPRR.Spec.Resources)
 	if err != nil {
 		return "", false, fmt.Errorf("Failed to create actuators package in deploy repo: %w", err)
 	}
@@ -182,6 +209,75 @@ func (ps *PorchPackageService) GetVendorExtensionPackage(
 	return extnResources, nil
 }
 
+// returns the name of the latest published revision of the deploy package, empty if absent
+func (ps *PorchPackageService) GetDeployPackageRevision(ctx context.Context, nc util.NamingContext) (string, error) {
+	return ps.getLatestPackageRevisionName(ctx, nc.GetNamespace(), nc.GetDeployPackageName(), nc.GetDeployRepoName())
+}
+
+// returns the name and the resources of the latest published revision of the deploy package, with
+// a flag telling if a newer draft or proposed revision of the package is waiting to be published.
+// the name is empty if the package was never published.
+func (ps *PorchPackageService) GetPublishedDeployPackage(ctx context.Context,
+	nc util.NamingContext) (string, map[string]string, bool, error) {
+	prs, err := ps.listDeployPackageRevisions(ctx, nc)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("Failed to fetch package revisions : %w", err)
+	}
+	pending := false
+	for _, pr := range prs {
+		if pr.Spec.Lifecycle == porchapi.PackageRevisionLifecycleDraft ||
+			pr.Spec.Lifecycle == porchapi.PackageRevisionLifecycleProposed {
+			pending = true
+		}
+	}
+	pr, prr, isAbsent, err := ps.getLatestPackage(ctx, nc.GetNamespace(), nc.GetDeployPackageName(), nc.GetDeployRepoName())
+	if err != nil && isAbsent {
+		return "", nil, pending, nil
+	} else if err != nil {
+		return "", nil, pending, err
+	}
+	return pr.Name, prr.Spec.Resources, pending, nil
+}
+
+// returns the name of the latest published revision of the actuators package in the deploy repo,
+// empty if absent
+func (ps *PorchPackageService) GetNFDeployActuatorsRevision(ctx context.Context,
+	nc util.NamingContext,
+	key VendorNFKey) (string, error) {
+	actuatorPkgName := nc.GetNFDeployActuatorPackageName(key.Vendor, key.Version, key.NFType)
+	return ps.getLatestPackageRevisionName(ctx, nc.GetNamespace(), actuatorPkgName, nc.GetDeployRepoName())
+}
+
+// creates a new draft revision of the package of the given revision with the content of the
+// revision, so that approving it reverts the package to that revision.
+func (ps *PorchPackageService) RestorePackageRevision(ctx context.Context,
+	nc util.NamingContext,
+	revision string) (string, error) {
+	var pr porchapi.PackageRevision
+	if err := ps.Client.Get(ctx, client.ObjectKey{Namespace: nc.GetNamespace(), Name: revision}, &pr); err != nil {
+		return "", fmt.Errorf("Failed to fetch package revision %s: %w", revision, err)
+	}
+	prr, err := ps.getPackageRevisionResources(ctx, nc.GetNamespace(), revision)
+	if err != nil {
+		return "", fmt.Errorf("Failed to fetch package revision resources of %s: %w", revision, err)
+	}
+	ps.Log.Info(fmt.Sprintf("Restoring package: %s in repo: %s to revision %s",
+		pr.Spec.PackageName, pr.Spec.RepositoryName, pr.Spec.Revision))
+	contents := make(map[string]string, len(prr.Spec.Resources))
+	for name, content := range prr.Spec.Resources {
+		contents[name] = content
+	}
+	var labels map[string]string
+	if owner, ok := pr.ObjectMeta.Labels[NfDeployLabelKey]; ok {
+		labels = map[string]string{NfDeployLabelKey: owner}
+	}
+	newPR, _, err := ps.createPackage(ctx, nc.GetNamespace(), pr.Spec.PackageName, pr.Spec.RepositoryName, labels, contents)
+	if err != nil {
+		return "", fmt.Errorf("Failed to restore package %s to revision %s: %w", pr.Spec.PackageName, revision, err)
+	}
+	return newPR.Name, nil
+}
+
 func (ps *PorchPackageService) convertResourcesToYamlNodes(prr *porchapi.PackageRevisionResources) []*yaml.RNode {
 	allNodes := []*yaml.RNode{}
 	for n, c := range prr.Spec.Resources {
@@ -202,8 +298,9 @@ func (ps *PorchPackageService) createPackage(ctx context.Context,
 	namespace string,
 	pkgName string,
 	repo string,
+	labels map[string]string,
 	contents map[string]string) (*porchapi.PackageRevision, *porchapi.PackageRevisionResources, error) {
-	pr, err := ps.createPackageRevision(ctx, namespace, pkgName, repo)
+	pr, err := ps.createPackageRevision(ctx, namespace, pkgName, repo, labels)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Failed to create package revision for the package name %s: %w", pkgName, err)
 	}
@@ -232,7 +329,11 @@ func (ps *PorchPackageService) createPackage(ctx context.Context,
 }
 
 // creates a new PackageRevision in Porch server for the deploy package given the naming context.
-func (ps *PorchPackageService) createPackageRevision(ctx context.Context, namespace string, pkgName string, repo string) (*porchapi.PackageRevision, error) {
+func (ps *PorchPackageService) createPackageRevision(ctx context.Context,
+	namespace string,
+	pkgName string,
+	repo string,
+	labels map[string]string) (*porchapi.PackageRevision, error) {
 	newPR := &porchapi.PackageRevision{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "PackageRevision",
@@ -240,6 +341,7 @@ func (ps *PorchPackageService) createPackageRevision(ctx context.Context, namesp
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
+			Labels:    labels,
 		},
 		Spec: porchapi.PackageRevisionSpec{
 			PackageName:    pkgName,
@@ -282,6 +384,21 @@ func (ps *PorchPackageService) getLatestPackage(ctx context.Context,
 	return pr, prr, false, nil
 }
 
+// returns the name of the latest published PackageRevision for the given arguments, or an empty
+// string if no revision was published.
+func (ps *PorchPackageService) getLatestPackageRevisionName(ctx context.Context,
+	namespace string,
+	pkgName string,
+	repo string) (string, error) {
+	pr, isAbsent, err := ps.getLatestPackageRevision(ctx, namespace, pkgName, repo)
+	if err != nil && isAbsent {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("Failed to fetch package revisions : %w", err)
+	}
+	return pr.Name, nil
+}
+
 // retrieves the latest published PackageRevision from Porch server for the given namespace, packageName and repository.
 // there should be exactly one latest published revision for the given parameters.
 // also returns a bool flag and an error if any. The flag represents if the error was because the package revision was
@@ -351,21 +468,87 @@ func (ps *PorchPackageService) DeleteDeployPackage(ctx context.Context, nc util.
 	return nil
 }
 
+// OrphanDeployPackage removes the NfDeploy ownership label from the packages in the deploy repo
+// and leaves them in place
+func (ps *PorchPackageService) OrphanDeployPackage(ctx context.Context, nc util.NamingContext) error {
+	prs, err := ps.listDeployPackageRevisions(ctx, nc)
+	if err != nil {
+		return err
+	}
+	for i := range prs {
+		pr := &prs[i]
+		if _, ok := pr.ObjectMeta.Labels[NfDeployLabelKey]; !ok {
+			continue
+		}
+		ps.Log.Info("Orphaning deploy package revision", "name", pr.Name)
+		delete(pr.ObjectMeta.Labels, NfDeployLabelKey)
+		if err := ps.Client.Update(ctx, pr); err != nil {
+			return fmt.Errorf("error orphaning package revision %s: %w", pr.Name, err)
+		}
+	}
+	ps.Log.Info(fmt.Sprintf("Successfully orphaned deploy packages for nfDeploy: %s", nc.GetNfDeployName()))
+	return nil
+}
+
+// ProposeDeployPackageDeletion deletes the unpublished revisions of the package in the deploy repo
+// and proposes the deletion of the published ones, which Porch only deletes once approved.
+// Returns the names of the revisions waiting for the deletion to be approved.
+func (ps *PorchPackageService) ProposeDeployPackageDeletion(ctx context.Context, nc util.NamingContext) ([]string, error) {
+	prs, err := ps.listDeployPackageRevisions(ctx, nc)
+	if err != nil {
+		return nil, err
+	}
+	pending := []string{}
+	for i := range prs {
+		pr := &prs[i]
+		switch pr.Spec.Lifecycle {
+		case porchapi.PackageRevisionLifecycleDeletionProposed:
+			pending = append(pending, pr.Name)
+		case porchapi.PackageRevisionLifecyclePublished:
+			ps.Log.Info("Proposing deletion of deploy package revision", "name", pr.Name)
+			pr.Spec.Lifecycle = porchapi.PackageRevisionLifecycleDeletionProposed
+			if err := ps.Client.Update(ctx, pr); err != nil {
+				return nil, fmt.Errorf("error proposing deletion of package revision %s: %w", pr.Name, err)
+			}
+			pending = append(pending, pr.Name)
+		default:
+			ps.Log.Info("Deleting deploy package revision", "name", pr.Name)
+			if err := ps.Client.Delete(ctx, pr); err != nil {
+				return nil, fmt.Errorf("error deleting package revision %s: %w", pr.Name, err)
+			}
+		}
+	}
+	return pending, nil
+}
+
 func (ps *PorchPackageService) deleteDeployPackageRevisions(ctx context.Context, nc util.NamingContext) error {
+	prs, err := ps.listDeployPackageRevisions(ctx, nc)
+	if err != nil {
+		return err
+	}
+	for i := range prs {
+		pr := &prs[i]
+		ps.Log.Info("Deleting deploy package revision", "name", pr.Name)
+		if err := ps.Client.Delete(ctx, pr); err != nil {
+			return fmt.Errorf("error deleting package revision %s: %w", pr.Name, err)
+		}
+	}
+	return nil
+}
+
+// returns all the revisions of the package in the deploy repo given the naming context
+func (ps *PorchPackageService) listDeployPackageRevisions(ctx context.Context, nc util.NamingContext) ([]porchapi.PackageRevision, error) {
 	var prList porchapi.PackageRevisionList
 	if err := ps.Client.List(ctx, &prList); err != nil {
-		return err
+		return nil, err
 	}
+	prs := []porchapi.PackageRevision{}
 	for _, pr := range prList.Items {
 		if pr.ObjectMeta.Namespace == nc.GetNamespace() &&
 			pr.Spec.RepositoryName == nc.GetDeployRepoName() &&
 			pr.Spec.PackageName == nc.GetDeployPackageName() {
-
-			ps.Log.Info("Deleting deploy package revision", "name", pr.Name)
-			if err := ps.Client.Delete(ctx, &pr); err != nil {
-				return fmt.Errorf("error deleting package revision %s: %w", pr.Name, err)
-			}
+			prs = append(prs, pr)
 		}
 	}
-	return nil
+	return prs, nil
 }
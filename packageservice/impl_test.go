@@ -254,6 +254,55 @@ var _ = Describe("Impl", func() {
 		})
 	})
 
+	Describe("testing GetNFProfilesPackage via Porch", func() {
+		It("should return the resources of the latest published revision", func() {
+			content := map[string]string{"upf.yaml": "kind: UpfType"}
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, prList *porchapi.PackageRevisionList, arg2 ...client.ListOption) {
+					prList.Items = []porchapi.PackageRevision{
+						getPackageRevisionCR("prev1", "private-catalog", "nf-profiles", "v1", true, false),
+						getPackageRevisionCR("prev2", "private-catalog", "nf-profiles", "v2", true, true),
+					}
+				})
+			mockClient.EXPECT().
+				Get(gomock.Any(), client.ObjectKey{Namespace: "nephio-user", Name: "prev2"}, gomock.Any()).
+				Return(nil).Times(1).
+				Do(func(ctx context.Context, key client.ObjectKey, prr *porchapi.PackageRevisionResources, opts ...client.GetOption) {
+					prr.Spec.Resources = content
+				})
+			revision, resources, err := ps.GetNFProfilesPackage(context.TODO(), nc, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revision).To(Equal("prev2"))
+			Expect(resources).To(Equal(content))
+		})
+
+		It("should return the resources of the given revision", func() {
+			content := map[string]string{"upf.yaml": "kind: UpfType"}
+			mockClient.EXPECT().
+				Get(gomock.Any(), client.ObjectKey{Namespace: "nephio-user", Name: "prev1"}, gomock.Any()).
+				Return(nil).Times(1).
+				Do(func(ctx context.Context, key client.ObjectKey, prr *porchapi.PackageRevisionResources, opts ...client.GetOption) {
+					prr.Spec.PackageName = "nf-profiles"
+					prr.Spec.RepositoryName = "private-catalog"
+					prr.Spec.Resources = content
+				})
+			revision, resources, err := ps.GetNFProfilesPackage(context.TODO(), nc, "prev1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revision).To(Equal("prev1"))
+			Expect(resources).To(Equal(content))
+		})
+
+		It("should error out when the given revision is not a revision of the NF profiles package", func() {
+			mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, key client.ObjectKey, prr *porchapi.PackageRevisionResources, opts ...client.GetOption) {
+					prr.Spec.PackageName = "upf-deploy"
+					prr.Spec.RepositoryName = "cluster-deploy-repo"
+				})
+			_, _, err := ps.GetNFProfilesPackage(context.TODO(), nc, "deployPkg1")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("testing CreateDeployPackage via Porch", func() {
 		Context("valid inputs, expecting package to be created", func() {
 			var prCall, prrGetCall, prrUpdateCall *gomock.Call
@@ -292,6 +341,7 @@ var _ = Describe("Impl", func() {
 					Expect(pr.Spec.Revision).To(Equal(""))
 					Expect(string(pr.Spec.WorkspaceName)).To(MatchRegexp("^v[0-9]+$"))
 					Expect(pr.Spec.Tasks[0].Type).To(Equal(porchapi.TaskTypeInit))
+					Expect(pr.ObjectMeta.Labels).To(HaveKeyWithValue(packageservice.NfDeployLabelKey, nc.GetNfDeployName()))
 				})
 				ps.CreateDeployPackage(context.TODO(), content, nc)
 			})
@@ -455,6 +505,87 @@ var _ = Describe("Impl", func() {
 		})
 	})
 
+	Describe("testing OrphanDeployPackage", func() {
+		It("should remove the NfDeploy label from the revisions of the correct package", func() {
+			pr1 := getPackageRevisionCR("prev1", "clusterName-deploy-repo", "nfDeployName-clusterName", "v1", true, true)
+			pr1.ObjectMeta.Labels[packageservice.NfDeployLabelKey] = "nfDeployName"
+			pr2 := getPackageRevisionCR("prev2", "clusterName-deploy-repo", "nfDeployName-clusterName", "v2", false, false)
+			pr3 := getPackageRevisionCR("prev3", "clusterName-deploy-repo", "nf-profiles-1", "v1", true, true)
+			pr3.ObjectMeta.Labels[packageservice.NfDeployLabelKey] = "nfDeployName"
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, prList *porchapi.PackageRevisionList, arg2 ...client.ListOption) {
+					prList.Items = []porchapi.PackageRevision{pr1, pr2, pr3}
+				})
+			mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, pr *porchapi.PackageRevision, arg2 ...client.UpdateOption) {
+					Expect(pr.Name).To(Equal("prev1"))
+					Expect(pr.ObjectMeta.Labels).NotTo(HaveKey(packageservice.NfDeployLabelKey))
+					Expect(pr.ObjectMeta.Labels).To(HaveKey(porchapi.LatestPackageRevisionKey))
+				})
+			Expect(ps.OrphanDeployPackage(context.Background(), nc)).To(Succeed())
+		})
+
+		It("should return error when updating a revision fails", func() {
+			pr1 := getPackageRevisionCR("prev1", "clusterName-deploy-repo", "nfDeployName-clusterName", "v1", true, true)
+			pr1.ObjectMeta.Labels[packageservice.NfDeployLabelKey] = "nfDeployName"
+			prErr := errors.New("expected error")
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, prList *porchapi.PackageRevisionList, arg2 ...client.ListOption) {
+					prList.Items = []porchapi.PackageRevision{pr1}
+				})
+			mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Return(prErr).Times(1)
+			err := ps.OrphanDeployPackage(context.Background(), nc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(prErr.Error()))
+		})
+	})
+
+	Describe("testing ProposeDeployPackageDeletion", func() {
+		pr1 := getPackageRevisionCR("prev1", "clusterName-deploy-repo", "nfDeployName-clusterName", "v1", true, true)   // latest published version
+		pr2 := getPackageRevisionCR("prev2", "clusterName-deploy-repo", "nfDeployName-clusterName", "v2", false, false) // newer version but not published
+		pr3 := getPackageRevisionCR("prev3", "clusterName-deploy-repo", "nfDeployName-clusterName", "v0", true, false)  // deletion already proposed
+		pr3.Spec.Lifecycle = porchapi.PackageRevisionLifecycleDeletionProposed
+		pr4 := getPackageRevisionCR("prev4", "soure-repo", "nfDeployName-clusterName", "v2", true, true) // published version in different repo
+
+		It("should delete the drafts and propose the deletion of the published revisions", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, prList *porchapi.PackageRevisionList, arg2 ...client.ListOption) {
+					prList.Items = []porchapi.PackageRevision{pr1, pr2, pr3, pr4}
+				})
+			mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, pr *porchapi.PackageRevision, arg2 ...client.UpdateOption) {
+					Expect(pr.Name).To(Equal("prev1"))
+					Expect(pr.Spec.Lifecycle).To(Equal(porchapi.PackageRevisionLifecycleDeletionProposed))
+				})
+			mockClient.EXPECT().Delete(gomock.Any(), gomock.Eq(&pr2)).Return(nil).Times(1)
+			pending, err := ps.ProposeDeployPackageDeletion(context.Background(), nc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(Equal([]string{"prev1", "prev3"}))
+		})
+
+		It("should return no revision once the deletion is approved", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, prList *porchapi.PackageRevisionList, arg2 ...client.ListOption) {
+					prList.Items = []porchapi.PackageRevision{pr4}
+				})
+			pending, err := ps.ProposeDeployPackageDeletion(context.Background(), nc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+		})
+
+		It("should return error when proposing the deletion fails", func() {
+			prErr := errors.New("expected error")
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, prList *porchapi.PackageRevisionList, arg2 ...client.ListOption) {
+					prList.Items = []porchapi.PackageRevision{pr1}
+				})
+			mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Return(prErr).Times(1)
+			_, err := ps.ProposeDeployPackageDeletion(context.Background(), nc)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(prErr.Error()))
+		})
+	})
+
 	Describe("testing CreateNFDeployActuators via Porch", func() {
 		var vendorNFKey packageservice.VendorNFKey
 		var actuatorResources map[string]string
@@ -817,4 +948,129 @@ var _ = Describe("Impl", func() {
 			})
 		})
 	})
+
+	Describe("testing GetDeployPackageRevision via Porch", func() {
+		It("should return the latest published revision of the deploy package", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, prList *porchapi.PackageRevisionList, arg2 ...client.ListOption) {
+					prList.Items = []porchapi.PackageRevision{
+						getPackageRevisionCR("deployPkg1", nc.GetDeployRepoName(), nc.GetDeployPackageName(), "v1", true, false),
+						getPackageRevisionCR("deployPkg2", nc.GetDeployRepoName(), nc.GetDeployPackageName(), "v2", true, true),
+						getPackageRevisionCR("deployPkg3", nc.GetDeployRepoName(), nc.GetDeployPackageName(), "", false, false),
+					}
+				})
+			revision, err := ps.GetDeployPackageRevision(context.TODO(), nc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revision).To(Equal("deployPkg2"))
+		})
+
+		It("should return an empty revision when the deploy package was never published", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			revision, err := ps.GetDeployPackageRevision(context.TODO(), nc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revision).To(BeEmpty())
+		})
+
+		It("should error out when listing package revisions fails", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(errors.New("error listing")).Times(1)
+			_, err := ps.GetDeployPackageRevision(context.TODO(), nc)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("testing GetPublishedDeployPackage via Porch", func() {
+		It("should return the resources of the latest published revision and the pending draft", func() {
+			content := map[string]string{"upf.yaml": "kind: UpfDeploy", "Kptfile": "kind: Kptfile"}
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(2).
+				Do(func(ctx context.Context, prList *porchapi.PackageRevisionList, arg2 ...client.ListOption) {
+					prList.Items = []porchapi.PackageRevision{
+						getPackageRevisionCR("deployPkg1", nc.GetDeployRepoName(), nc.GetDeployPackageName(), "v1", true, true),
+						getPackageRevisionCR("deployPkg2", nc.GetDeployRepoName(), nc.GetDeployPackageName(), "", false, false),
+					}
+				})
+			mockClient.EXPECT().
+				Get(gomock.Any(), client.ObjectKey{Namespace: nc.GetNamespace(), Name: "deployPkg1"}, gomock.Any()).
+				Return(nil).Times(1).
+				Do(func(ctx context.Context, key client.ObjectKey, prr *porchapi.PackageRevisionResources, opts ...client.GetOption) {
+					prr.Spec.Resources = content
+				})
+			revision, resources, pending, err := ps.GetPublishedDeployPackage(context.TODO(), nc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revision).To(Equal("deployPkg1"))
+			Expect(resources).To(Equal(content))
+			Expect(pending).To(BeTrue())
+		})
+
+		It("should return an empty revision when the deploy package was never published", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			revision, resources, pending, err := ps.GetPublishedDeployPackage(context.TODO(), nc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revision).To(BeEmpty())
+			Expect(resources).To(BeNil())
+			Expect(pending).To(BeFalse())
+		})
+
+		It("should error out when listing package revisions fails", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(errors.New("error listing")).Times(1)
+			_, _, _, err := ps.GetPublishedDeployPackage(context.TODO(), nc)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("testing GetNFDeployActuatorsRevision via Porch", func() {
+		It("should return the latest published revision of the actuators package in the deploy repo", func() {
+			key := packageservice.VendorNFKey{Vendor: "ABC", Version: "1.0", NFType: "Upf"}
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, prList *porchapi.PackageRevisionList, arg2 ...client.ListOption) {
+					prList.Items = []porchapi.PackageRevision{
+						getPackageRevisionCR("actuatorPkg1", "private-catalog", "ABC/1.0/Upf/actuators", "v1", true, true),
+						getPackageRevisionCR("actuatorPkg2", nc.GetDeployRepoName(), "ABC/1.0/Upf/actuators", "v1", true, true),
+					}
+				})
+			revision, err := ps.GetNFDeployActuatorsRevision(context.TODO(), nc, key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revision).To(Equal("actuatorPkg2"))
+		})
+	})
+
+	Describe("testing RestorePackageRevision via Porch", func() {
+		It("should create a new revision of the package with the content of the revision", func() {
+			content := map[string]string{"upf.yaml": "kind: UpfDeploy", "Kptfile": "kind: Kptfile"}
+			mockClient.EXPECT().
+				Get(gomock.Any(), client.ObjectKey{Namespace: nc.GetNamespace(), Name: "deployPkg1"}, gomock.Any()).
+				Return(nil).Times(1).
+				Do(func(ctx context.Context, key client.ObjectKey, pr *porchapi.PackageRevision, opts ...client.GetOption) {
+					*pr = getPackageRevisionCR("deployPkg1", nc.GetDeployRepoName(), nc.GetDeployPackageName(), "v1", true, false)
+				})
+			mockClient.EXPECT().
+				Get(gomock.Any(), client.ObjectKey{Namespace: nc.GetNamespace(), Name: "deployPkg1"}, gomock.Any()).
+				Return(nil).Times(1).
+				Do(func(ctx context.Context, key client.ObjectKey, prr *porchapi.PackageRevisionResources, opts ...client.GetOption) {
+					prr.Spec.Resources = content
+				})
+			mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, pr *porchapi.PackageRevision, arg2 ...client.CreateOption) {
+					Expect(pr.Spec.PackageName).To(Equal(nc.GetDeployPackageName()))
+					Expect(pr.Spec.RepositoryName).To(Equal(nc.GetDeployRepoName()))
+					pr.ObjectMeta.Name = "deployPkg3"
+				})
+			mockClient.EXPECT().
+				Get(gomock.Any(), client.ObjectKey{Namespace: nc.GetNamespace(), Name: "deployPkg3"}, gomock.Any()).
+				Return(nil).Times(1)
+			mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(1).
+				Do(func(ctx context.Context, prr *porchapi.PackageRevisionResources, arg2 ...client.UpdateOption) {
+					Expect(prr.Spec.Resources).To(Equal(content))
+				})
+			name, err := ps.RestorePackageRevision(context.TODO(), nc, "deployPkg1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("deployPkg3"))
+		})
+
+		It("should error out when the revision cannot be fetched", func() {
+			mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(errors.New("not found")).Times(1)
+			_, err := ps.RestorePackageRevision(context.TODO(), nc, "deployPkg1")
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -29,12 +29,25 @@ type PackageServiceInterface interface {
 	// actual CRs in string format
 	GetNFProfiles(ctx context.Context, req []GetResourceRequest, nc util.NamingContext) (map[int][]string, error)
 
+	// GetNFProfilesPackage returns the name and the resources of the given revision of the NF
+	// profiles package, or of its latest published revision when revision is empty
+	GetNFProfilesPackage(ctx context.Context, nc util.NamingContext, revision string) (string, map[string]string, error)
+
 	// CreateDeployPackage creates a package in the deploy repo and returns the package k8s resource name
 	CreateDeployPackage(ctx context.Context, contents map[string]string, nc util.NamingContext) (string, error)
 
 	// DeleteDeployPackage deletes packages from the deploy repo
 	DeleteDeployPackage(ctx context.Context, nc util.NamingContext) error
 
+	// OrphanDeployPackage removes the NfDeploy ownership label from the packages in the deploy repo
+	// and leaves them in place
+	OrphanDeployPackage(ctx context.Context, nc util.NamingContext) error
+
+	// ProposeDeployPackageDeletion deletes the unpublished revisions of the package in the deploy repo
+	// and proposes the deletion of the published ones. Returns the names of the revisions waiting for
+	// the deletion to be approved, empty once all the revisions are gone.
+	ProposeDeployPackageDeletion(ctx context.Context, nc util.NamingContext) ([]string, error)
+
 	// CreateNFDeployActuators creates the NFDeployActuators in the deploy repo and returns
 	// 1. the package k8s resource name.
 	// 2. True if the package was newly created or updated else false if the package
@@ -45,6 +58,22 @@ type PackageServiceInterface interface {
 	// GetVendorExtensionPackage returns the list of k8s object yamls as string in the extension package.
 	// Each string represent a single k8s object.
 	GetVendorExtensionPackage(ctx context.Context, nc util.NamingContext, key VendorNFKey) ([]string, error)
+	// GetDeployPackageRevision returns the name of the latest published revision of the package
+	// in the deploy repo, or an empty string if the package was never published.
+	GetDeployPackageRevision(ctx context.Context, nc util.NamingContext) (string, error)
+
+	// GetPublishedDeployPackage returns the name and the resources of the latest published revision of
+	// the package in the deploy repo, and true if a newer draft or proposed revision of the package exists.
+	// The name is an empty string if the package was never published.
+	GetPublishedDeployPackage(ctx context.Context, nc util.NamingContext) (string, map[string]string, bool, error)
+
+	// GetNFDeployActuatorsRevision returns the name of the latest published revision of the
+	// NFDeployActuators package in the deploy repo, or an empty string if it was never published.
+	GetNFDeployActuatorsRevision(ctx context.Context, nc util.NamingContext, key VendorNFKey) (string, error)
+
+	// RestorePackageRevision creates a new revision of the package of the given package revision
+	// with the same content and returns the new package k8s resource name.
+	RestorePackageRevision(ctx context.Context, nc util.NamingContext, revision string) (string, error)
 }
 
 // GetResourceRequest is used as the input for fetching NF Profiles
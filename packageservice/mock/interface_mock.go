@@ -95,6 +95,36 @@ func (mr *MockPackageServiceInterfaceMockRecorder) DeleteDeployPackage(ctx, nc i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeployPackage", reflect.TypeOf((*MockPackageServiceInterface)(nil).DeleteDeployPackage), ctx, nc)
 }
 
+// GetDeployPackageRevision mocks base method.
+func (m *MockPackageServiceInterface) GetDeployPackageRevision(ctx context.Context, nc util.NamingContext) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeployPackageRevision", ctx, nc)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeployPackageRevision indicates an expected call of GetDeployPackageRevision.
+func (mr *MockPackageServiceInterfaceMockRecorder) GetDeployPackageRevision(ctx, nc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeployPackageRevision", reflect.TypeOf((*MockPackageServiceInterface)(nil).GetDeployPackageRevision), ctx, nc)
+}
+
+// GetNFDeployActuatorsRevision mocks base method.
+func (m *MockPackageServiceInterface) GetNFDeployActuatorsRevision(ctx context.Context, nc util.NamingContext, key packageservice.VendorNFKey) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNFDeployActuatorsRevision", ctx, nc, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNFDeployActuatorsRevision indicates an expected call of GetNFDeployActuatorsRevision.
+func (mr *MockPackageServiceInterfaceMockRecorder) GetNFDeployActuatorsRevision(ctx, nc, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNFDeployActuatorsRevision", reflect.TypeOf((*MockPackageServiceInterface)(nil).GetNFDeployActuatorsRevision), ctx, nc, key)
+}
+
 // GetNFProfiles mocks base method.
 func (m *MockPackageServiceInterface) GetNFProfiles(ctx context.Context, req []packageservice.GetResourceRequest, nc util.NamingContext) (map[int][]string, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +140,39 @@ func (mr *MockPackageServiceInterfaceMockRecorder) GetNFProfiles(ctx, req, nc in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNFProfiles", reflect.TypeOf((*MockPackageServiceInterface)(nil).GetNFProfiles), ctx, req, nc)
 }
 
+// GetNFProfilesPackage mocks base method.
+func (m *MockPackageServiceInterface) GetNFProfilesPackage(ctx context.Context, nc util.NamingContext, revision string) (string, map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNFProfilesPackage", ctx, nc, revision)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(map[string]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetNFProfilesPackage indicates an expected call of GetNFProfilesPackage.
+func (mr *MockPackageServiceInterfaceMockRecorder) GetNFProfilesPackage(ctx, nc, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNFProfilesPackage", reflect.TypeOf((*MockPackageServiceInterface)(nil).GetNFProfilesPackage), ctx, nc, revision)
+}
+
+// GetPublishedDeployPackage mocks base method.
+func (m *MockPackageServiceInterface) GetPublishedDeployPackage(ctx context.Context, nc util.NamingContext) (string, map[string]string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublishedDeployPackage", ctx, nc)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(map[string]string)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetPublishedDeployPackage indicates an expected call of GetPublishedDeployPackage.
+func (mr *MockPackageServiceInterfaceMockRecorder) GetPublishedDeployPackage(ctx, nc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublishedDeployPackage", reflect.TypeOf((*MockPackageServiceInterface)(nil).GetPublishedDeployPackage), ctx, nc)
+}
+
 // GetVendorExtensionPackage mocks base method.
 func (m *MockPackageServiceInterface) GetVendorExtensionPackage(ctx context.Context, nc util.NamingContext, key packageservice.VendorNFKey) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -124,3 +187,47 @@ func (mr *MockPackageServiceInterfaceMockRecorder) GetVendorExtensionPackage(ctx
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVendorExtensionPackage", reflect.TypeOf((*MockPackageServiceInterface)(nil).GetVendorExtensionPackage), ctx, nc, key)
 }
+
+// OrphanDeployPackage mocks base method.
+func (m *MockPackageServiceInterface) OrphanDeployPackage(ctx context.Context, nc util.NamingContext) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrphanDeployPackage", ctx, nc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OrphanDeployPackage indicates an expected call of OrphanDeployPackage.
+func (mr *MockPackageServiceInterfaceMockRecorder) OrphanDeployPackage(ctx, nc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrphanDeployPackage", reflect.TypeOf((*MockPackageServiceInterface)(nil).OrphanDeployPackage), ctx, nc)
+}
+
+// ProposeDeployPackageDeletion mocks base method.
+func (m *MockPackageServiceInterface) ProposeDeployPackageDeletion(ctx context.Context, nc util.NamingContext) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposeDeployPackageDeletion", ctx, nc)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProposeDeployPackageDeletion indicates an expected call of ProposeDeployPackageDeletion.
+func (mr *MockPackageServiceInterfaceMockRecorder) ProposeDeployPackageDeletion(ctx, nc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposeDeployPackageDeletion", reflect.TypeOf((*MockPackageServiceInterface)(nil).ProposeDeployPackageDeletion), ctx, nc)
+}
+
+// RestorePackageRevision mocks base method.
+func (m *MockPackageServiceInterface) RestorePackageRevision(ctx context.Context, nc util.NamingContext, revision string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePackageRevision", ctx, nc, revision)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestorePackageRevision indicates an expected call of RestorePackageRevision.
+func (mr *MockPackageServiceInterfaceMockRecorder) RestorePackageRevision(ctx, nc, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePackageRevision", reflect.TypeOf((*MockPackageServiceInterface)(nil).RestorePackageRevision), ctx, nc, revision)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// Key identifies a NF profile object in the nf-profiles package
+type Key struct {
+	Kind string
+	Name string
+}
+
+// KeyOf returns the key of the profile object
+func KeyOf(object v1alpha1.ProfileObject) Key {
+	return Key{Kind: object.Kind, Name: object.Name}
+}
+
+// ObjectOf returns the kind, the name and the hash of the profile object in
+// content. The content is normalized first, so that the hash does not depend
+// on the formatting of the object.
+func ObjectOf(content string) (v1alpha1.ProfileObject, error) {
+	nodes, err := util.ParseStringToYamlNode(content)
+	if err != nil {
+		return v1alpha1.ProfileObject{}, err
+	}
+	if len(nodes) != 1 {
+		return v1alpha1.ProfileObject{}, fmt.Errorf("expecting exactly one object, found %d", len(nodes))
+	}
+	fields, err := nodes[0].Map()
+	if err != nil {
+		return v1alpha1.ProfileObject{}, err
+	}
+	// the keys of the maps are sorted in the JSON encoding
+	normalized, err := json.Marshal(fields)
+	if err != nil {
+		return v1alpha1.ProfileObject{}, err
+	}
+	sum := sha256.Sum256(normalized)
+	return v1alpha1.ProfileObject{
+		Kind: nodes[0].GetKind(),
+		Name: nodes[0].GetName(),
+		Hash: hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// Objects returns the profile objects of the resources of a revision of the
+// nf-profiles package by key. The Kptfile and the files which cannot be
+// parsed are skipped.
+func Objects(resources map[string]string) map[Key]v1alpha1.ProfileObject {
+	objects := make(map[Key]v1alpha1.ProfileObject)
+	for name, content := range resources {
+		if name == packageservice.KptfileName {
+			continue
+		}
+		nodes, err := util.ParseStringToYamlNode(content)
+		if err != nil {
+			continue
+		}
+		for _, node := range nodes {
+			object, err := ObjectOf(node.MustString())
+			if err != nil {
+				continue
+			}
+			objects[KeyOf(object)] = object
+		}
+	}
+	return objects
+}
+
+// Sort sorts the profile objects by kind and name
+func Sort(objects []v1alpha1.ProfileObject) {
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].Kind != objects[j].Kind {
+			return objects[i].Kind < objects[j].Kind
+		}
+		return objects[i].Name < objects[j].Name
+	})
+}
+
+// Changed returns the recorded profile objects which changed in, or were
+// removed from, the latest objects
+func Changed(recorded *v1alpha1.ProfilesStatus, latest map[Key]v1alpha1.ProfileObject) []v1alpha1.ProfileObject {
+	if recorded == nil {
+		return nil
+	}
+	var changed []v1alpha1.ProfileObject
+	for _, object := range recorded.Objects {
+		if current, ok := latest[KeyOf(object)]; !ok || current.Hash != object.Hash {
+			changed = append(changed, object)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiles_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/profiles"
+)
+
+const upfType = `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: UpfType
+metadata:
+  name: upfsmall
+spec:
+  flavor: small
+`
+
+const interfaceConfig = `apiVersion: nfdeploy.nephio.org/v1alpha1
+kind: InterfaceConfig
+metadata:
+  name: interfaceConfig1
+spec:
+  ipAddr: 10.0.0.1/24
+`
+
+var _ = Describe("Profiles", func() {
+	It("Should hash the objects independently of their formatting", func() {
+		object, err := profiles.ObjectOf(upfType)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(object.Kind).To(Equal("UpfType"))
+		Expect(object.Name).To(Equal("upfsmall"))
+		reformatted, err := profiles.ObjectOf(
+			"kind: UpfType\napiVersion: nfdeploy.nephio.org/v1alpha1\nmetadata: {name: upfsmall}\nspec: {flavor: small}\n",
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reformatted).To(Equal(object))
+
+		_, err = profiles.ObjectOf(upfType + "---\n" + interfaceConfig)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should return the objects of the package and the recorded ones which changed", func() {
+		objects := profiles.Objects(map[string]string{
+			"profiles.yaml": upfType + "---\n" + interfaceConfig,
+			"Kptfile":       "apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: nf-profiles\n",
+		})
+		Expect(objects).To(HaveLen(2))
+		upf, err := profiles.ObjectOf(upfType)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects[profiles.KeyOf(upf)]).To(Equal(upf))
+
+		recorded := &v1alpha1.ProfilesStatus{
+			Objects: []v1alpha1.ProfileObject{
+				upf,
+				{Kind: "InterfaceConfig", Name: "interfaceConfig1", Hash: "3b1f"},
+				{Kind: "UpfCapacityProfile", Name: "upfCapacityProfile1", Hash: "9c2e"},
+			},
+		}
+		Expect(profiles.Changed(recorded, objects)).To(Equal([]v1alpha1.ProfileObject{
+			{Kind: "InterfaceConfig", Name: "interfaceConfig1", Hash: "3b1f"},
+			{Kind: "UpfCapacityProfile", Name: "upfCapacityProfile1", Hash: "9c2e"},
+		}))
+		Expect(profiles.Changed(nil, objects)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,159 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiles
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	packageservice "github.com/nephio-project/nf-deploy-controller/packageservice"
+	"github.com/nephio-project/nf-deploy-controller/util"
+)
+
+// rewatchDelay is the delay before the PackageRevisions are watched again
+// once a watch ends
+const rewatchDelay = 5 * time.Second
+
+// Watcher watches the PackageRevisions of the nf-profiles package. When a
+// revision is published, the NfDeploys whose last hydration read profile
+// objects which changed in, or were removed from, the revision are sent to
+// Events to be hydrated again.
+type Watcher struct {
+	// Client reads the NfDeploys
+	Client client.Reader
+	// PorchClient watches the PackageRevisions
+	PorchClient client.WithWatch
+	PS          packageservice.PackageServiceInterface
+	// Events receives the NfDeploys to hydrate again
+	Events chan<- event.GenericEvent
+	Log    logr.Logger
+
+	// lastRevision is the last published revision whose changes were sent
+	lastRevision string
+}
+
+// nc is the naming context of the nf-profiles package, which depends
+// neither on the cluster nor on the NfDeploy
+var nc util.NamingContext
+
+// Start watches the PackageRevisions until ctx is done. Implements
+// manager.Runnable.
+func (w *Watcher) Start(ctx context.Context) error {
+	for {
+		if err := w.watch(ctx); err != nil {
+			w.Log.Error(err, "error watching the nf-profiles package revisions")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(rewatchDelay):
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable as the
+// NfDeploys are hydrated by the leader only
+func (w *Watcher) NeedLeaderElection() bool {
+	return true
+}
+
+// watch handles the events of the PackageRevisions until the watch ends. A
+// new watch lists the existing revisions first, so that the revisions
+// published while no watch was running are handled too.
+func (w *Watcher) watch(ctx context.Context) error {
+	watcher, err := w.PorchClient.Watch(ctx, &porchapi.PackageRevisionList{},
+		client.InNamespace(nc.GetNamespace()))
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-watcher.ResultChan():
+			if !ok {
+				w.Log.V(1).Info("Watch of the package revisions ended")
+				return nil
+			}
+			if e.Type != watch.Added && e.Type != watch.Modified {
+				continue
+			}
+			pr, ok := e.Object.(*porchapi.PackageRevision)
+			if !ok || !isLatestNFProfiles(pr) || pr.Name == w.lastRevision {
+				continue
+			}
+			if err := w.ProfilesPublished(ctx, pr.Name); err != nil {
+				w.Log.Error(err, "error handling the published nf-profiles revision",
+					"revision", pr.Name)
+				continue
+			}
+			w.lastRevision = pr.Name
+		}
+	}
+}
+
+// ProfilesPublished sends the NfDeploys whose profile objects changed in the
+// published revision of the nf-profiles package to Events
+func (w *Watcher) ProfilesPublished(ctx context.Context, revision string) error {
+	_, resources, err := w.PS.GetNFProfilesPackage(ctx, nc, revision)
+	if err != nil {
+		return err
+	}
+	latest := Objects(resources)
+	var nfDeploys v1alpha1.NfDeployList
+	if err := w.Client.List(ctx, &nfDeploys); err != nil {
+		return fmt.Errorf("error listing NfDeploys: %w", err)
+	}
+	for i := range nfDeploys.Items {
+		nfDeploy := &nfDeploys.Items[i]
+		recorded := nfDeploy.Status.Profiles
+		if !nfDeploy.DeletionTimestamp.IsZero() || recorded == nil || recorded.Revision == revision {
+			continue
+		}
+		changed := Changed(recorded, latest)
+		if len(changed) == 0 {
+			continue
+		}
+		w.Log.Info("NF profiles changed, hydrating NfDeploy again", "nfDeployName", nfDeploy.Name,
+			"revision", revision, "profiles", changed)
+		select {
+		case w.Events <- event.GenericEvent{Object: nfDeploy}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// isLatestNFProfiles returns true if pr is the latest published revision of
+// the nf-profiles package
+func isLatestNFProfiles(pr *porchapi.PackageRevision) bool {
+	return pr.Namespace == nc.GetNamespace() &&
+		pr.Spec.RepositoryName == nc.GetNFProfileRepoName() &&
+		pr.Spec.PackageName == nc.GetNFProfilePackageName() &&
+		pr.Spec.Lifecycle == porchapi.PackageRevisionLifecyclePublished &&
+		pr.Labels[porchapi.LatestPackageRevisionKey] == porchapi.LatestPackageRevisionValue
+}
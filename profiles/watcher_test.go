@@ -0,0 +1,122 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profiles_test
+
+import (
+	"context"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	psmock "github.com/nephio-project/nf-deploy-controller/packageservice/mock"
+	"github.com/nephio-project/nf-deploy-controller/profiles"
+)
+
+var _ = Describe("Watcher", func() {
+	var k8sClient client.WithWatch
+	var mockPS *psmock.MockPackageServiceInterface
+	var events chan event.GenericEvent
+	var watcher *profiles.Watcher
+	ctx := context.Background()
+
+	nfDeployWithProfiles := func(name, revision string, objects ...v1alpha1.ProfileObject) *v1alpha1.NfDeploy {
+		return &v1alpha1.NfDeploy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Status: v1alpha1.NfDeployStatus{
+				Profiles: &v1alpha1.ProfilesStatus{Revision: revision, Objects: objects},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		upf, err := profiles.ObjectOf(upfType)
+		Expect(err).NotTo(HaveOccurred())
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(porchapi.AddToScheme(scheme)).To(Succeed())
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			// reads the upf type which is unchanged
+			nfDeployWithProfiles("unchanged", "nf-profiles-v1", upf),
+			// reads the interface config which changed
+			nfDeployWithProfiles("changed", "nf-profiles-v1", upf,
+				v1alpha1.ProfileObject{Kind: "InterfaceConfig", Name: "interfaceConfig1", Hash: "3b1f"}),
+			// already hydrated with the revision
+			nfDeployWithProfiles("hydrated", "nf-profiles-v2",
+				v1alpha1.ProfileObject{Kind: "InterfaceConfig", Name: "interfaceConfig1", Hash: "3b1f"}),
+		).Build()
+		mockPS = psmock.NewMockPackageServiceInterface(gomock.NewController(GinkgoT()))
+		events = make(chan event.GenericEvent, 3)
+		watcher = &profiles.Watcher{
+			Client:      k8sClient,
+			PorchClient: k8sClient,
+			PS:          mockPS,
+			Events:      events,
+			Log:         logr.Discard(),
+		}
+	})
+
+	It("Should send the NfDeploys whose profile objects changed in the revision", func() {
+		mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "nf-profiles-v2").
+			Return("nf-profiles-v2", map[string]string{"profiles.yaml": upfType + "---\n" + interfaceConfig}, nil)
+		Expect(watcher.ProfilesPublished(ctx, "nf-profiles-v2")).To(Succeed())
+		Expect(events).To(HaveLen(1))
+		Expect((<-events).Object.GetName()).To(Equal("changed"))
+	})
+
+	It("Should handle the revisions of the nf-profiles package once published", func() {
+		mockPS.EXPECT().GetNFProfilesPackage(gomock.Any(), gomock.Any(), "nf-profiles-v2").
+			Return("nf-profiles-v2", map[string]string{"profiles.yaml": upfType}, nil).Times(1)
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(watcher.Start(watchCtx)).To(Succeed())
+		}()
+
+		pr := &porchapi.PackageRevision{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "nephio-user", Name: "nf-profiles-v2"},
+			Spec: porchapi.PackageRevisionSpec{
+				PackageName:    "nf-profiles",
+				RepositoryName: "private-catalog",
+				Lifecycle:      porchapi.PackageRevisionLifecycleProposed,
+			},
+		}
+		Eventually(func() error { return k8sClient.Create(ctx, pr) }).Should(Succeed())
+		Consistently(events).ShouldNot(Receive())
+
+		pr.Spec.Lifecycle = porchapi.PackageRevisionLifecyclePublished
+		pr.Labels = map[string]string{porchapi.LatestPackageRevisionKey: porchapi.LatestPackageRevisionValue}
+		Expect(k8sClient.Update(ctx, pr)).To(Succeed())
+		var got event.GenericEvent
+		Eventually(events).Should(Receive(&got))
+		Expect(got.Object.GetName()).To(Equal("changed"))
+
+		// the revision is handled once
+		pr.Annotations = map[string]string{"touched": "true"}
+		Expect(k8sClient.Update(ctx, pr)).To(Succeed())
+		Consistently(events).ShouldNot(Receive())
+	})
+})
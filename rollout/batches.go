@@ -0,0 +1,120 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+)
+
+// ComputeBatches returns the ids of the sites grouped in the batches of a
+// progressive rollout. The canary sites, if any, are the first batch. The
+// other sites are grouped by cluster, the sites of at most maxUnavailable
+// clusters per batch, in the order of the cluster names. The ids in a batch
+// are sorted.
+func ComputeBatches(sites []v1alpha1.Site, strategy v1alpha1.RolloutStrategy) ([][]string, error) {
+	siteIds := make(map[string]bool, len(sites))
+	clusterSites := make(map[string][]string)
+	for _, site := range sites {
+		siteIds[site.Id] = true
+		clusterSites[site.ClusterName] = append(clusterSites[site.ClusterName], site.Id)
+	}
+	maxUnavailable, err := maxUnavailableClusters(strategy.MaxUnavailable, len(clusterSites))
+	if err != nil {
+		return nil, err
+	}
+
+	var batches [][]string
+	canaries := make(map[string]bool, len(strategy.CanarySites))
+	if len(strategy.CanarySites) != 0 {
+		for _, siteId := range strategy.CanarySites {
+			if !siteIds[siteId] {
+				return nil, fmt.Errorf("canary site %s is not present", siteId)
+			}
+			canaries[siteId] = true
+		}
+		batch := append([]string(nil), strategy.CanarySites...)
+		sort.Strings(batch)
+		batches = append(batches, batch)
+	}
+
+	clusterNames := make([]string, 0, len(clusterSites))
+	for clusterName := range clusterSites {
+		clusterNames = append(clusterNames, clusterName)
+	}
+	sort.Strings(clusterNames)
+	var batch []string
+	clusters := 0
+	for _, clusterName := range clusterNames {
+		var remaining []string
+		for _, siteId := range clusterSites[clusterName] {
+			if !canaries[siteId] {
+				remaining = append(remaining, siteId)
+			}
+		}
+		if len(remaining) == 0 {
+			continue
+		}
+		batch = append(batch, remaining...)
+		clusters++
+		if clusters == maxUnavailable {
+			sort.Strings(batch)
+			batches = append(batches, batch)
+			batch = nil
+			clusters = 0
+		}
+	}
+	if len(batch) != 0 {
+		sort.Strings(batch)
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// maxUnavailableClusters returns the number of clusters of a batch, which is
+// at least 1
+func maxUnavailableClusters(maxUnavailable *intstr.IntOrString, clusters int) (int, error) {
+	if maxUnavailable == nil {
+		return 1, nil
+	}
+	value, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, clusters, false)
+	if err != nil {
+		return 0, fmt.Errorf("invalid maxUnavailable %s: %w", maxUnavailable.String(), err)
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("invalid maxUnavailable %s: must not be negative",
+			maxUnavailable.String())
+	}
+	if value == 0 {
+		return 1, nil
+	}
+	return value, nil
+}
+
+// ComputeRolloutWaves returns the ids of the sites of nfDeploy grouped in the
+// waves of its rollout strategy: in dependency order for an ordered rollout,
+// in batches of clusters for a progressive one
+func ComputeRolloutWaves(nfDeploy v1alpha1.NfDeploy, rules *DependencyRules) ([][]string, error) {
+	if nfDeploy.Spec.Rollout.IsProgressive() {
+		return ComputeBatches(nfDeploy.Spec.Sites, *nfDeploy.Spec.Rollout)
+	}
+	return ComputeWaves(nfDeploy.Spec.Sites, rules)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/rollout"
+)
+
+var _ = Describe("ComputeBatches", func() {
+	sites := []v1alpha1.Site{
+		{Id: "upf-1", NFType: "upf", ClusterName: "edge-1"},
+		{Id: "upf-2", NFType: "upf", ClusterName: "edge-2"},
+		{Id: "upf-3", NFType: "upf", ClusterName: "edge-3"},
+		{Id: "upf-4", NFType: "upf", ClusterName: "edge-4"},
+		{Id: "smf-1", NFType: "smf", ClusterName: "edge-1"},
+	}
+
+	It("Should roll out one cluster per batch by default", func() {
+		batches, err := rollout.ComputeBatches(sites, v1alpha1.RolloutStrategy{
+			Type: v1alpha1.ProgressiveRollout,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(batches).To(Equal([][]string{
+			{"smf-1", "upf-1"}, {"upf-2"}, {"upf-3"}, {"upf-4"},
+		}))
+	})
+
+	It("Should roll out the canary sites first", func() {
+		maxUnavailable := intstr.FromInt(2)
+		batches, err := rollout.ComputeBatches(sites, v1alpha1.RolloutStrategy{
+			Type:           v1alpha1.ProgressiveRollout,
+			MaxUnavailable: &maxUnavailable,
+			CanarySites:    []string{"upf-3"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(batches).To(Equal([][]string{
+			{"upf-3"}, {"smf-1", "upf-1", "upf-2"}, {"upf-4"},
+		}))
+	})
+
+	It("Should scale a percentage to the number of clusters", func() {
+		maxUnavailable := intstr.FromString("50%")
+		batches, err := rollout.ComputeBatches(sites, v1alpha1.RolloutStrategy{
+			Type:           v1alpha1.ProgressiveRollout,
+			MaxUnavailable: &maxUnavailable,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(batches).To(Equal([][]string{
+			{"smf-1", "upf-1", "upf-2"}, {"upf-3", "upf-4"},
+		}))
+	})
+
+	It("Should fail when a canary site is not present", func() {
+		_, err := rollout.ComputeBatches(sites, v1alpha1.RolloutStrategy{
+			Type:        v1alpha1.ProgressiveRollout,
+			CanarySites: []string{"upf-9"},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should fail when maxUnavailable is invalid", func() {
+		maxUnavailable := intstr.FromString("half")
+		_, err := rollout.ComputeBatches(sites, v1alpha1.RolloutStrategy{
+			Type:           v1alpha1.ProgressiveRollout,
+			MaxUnavailable: &maxUnavailable,
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ReleasedConnectivities of a progressive rollout", func() {
+	It("Should keep the connectivities to the sites not released yet", func() {
+		nfDeploy := v1alpha1.NfDeploy{
+			Spec: v1alpha1.NfDeploySpec{
+				Rollout: &v1alpha1.RolloutStrategy{Type: v1alpha1.ProgressiveRollout},
+				Sites: []v1alpha1.Site{
+					site("upf-1", v1alpha1.UPFNFType, "smf-1"),
+					site("smf-1", v1alpha1.SMFNFType),
+				},
+			},
+		}
+		released := rollout.ReleasedConnectivities(nfDeploy, [][]string{{"upf-1"}, {"smf-1"}}, 0)
+		Expect(released.Spec.Sites).To(HaveLen(2))
+		Expect(released.Spec.Sites[0].Connectivities).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,231 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+)
+
+// DependencyRules holds the NF types a NF type depends on, i.e. which have
+// to be Ready before an NF of the type is rolled out. A site depends only on
+// its neighbors of those types. Thread-safe.
+type DependencyRules struct {
+	mu           sync.RWMutex
+	dependencies map[v1alpha1.NFType]map[v1alpha1.NFType]bool
+}
+
+// NewDependencyRules returns empty DependencyRules
+func NewDependencyRules() *DependencyRules {
+	return &DependencyRules{
+		dependencies: make(map[v1alpha1.NFType]map[v1alpha1.NFType]bool),
+	}
+}
+
+// Register makes the sites of nfType depend on their neighbors of dependency
+func (d *DependencyRules) Register(nfType v1alpha1.NFType, dependency v1alpha1.NFType) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.dependencies[nfType]; !ok {
+		d.dependencies[nfType] = make(map[v1alpha1.NFType]bool)
+	}
+	d.dependencies[nfType][dependency] = true
+}
+
+// DependsOn returns true if the sites of nfType depend on their neighbors of
+// dependency
+func (d *DependencyRules) DependsOn(nfType v1alpha1.NFType, dependency v1alpha1.NFType) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.dependencies[nfType][dependency]
+}
+
+// NewDefaultDependencyRules returns the DependencyRules where the user plane
+// comes up after the control plane controlling it, and the control plane
+// after the subscriber data it uses.
+func NewDefaultDependencyRules() *DependencyRules {
+	d := NewDependencyRules()
+	d.Register(v1alpha1.UPFNFType, v1alpha1.SMFNFType)
+	d.Register(v1alpha1.SMFNFType, v1alpha1.UDMNFType)
+	d.Register(v1alpha1.SMFNFType, v1alpha1.AUSFNFType)
+	d.Register(v1alpha1.AUSFNFType, v1alpha1.UDMNFType)
+	d.Register(v1alpha1.AMFNFType, v1alpha1.AUSFNFType)
+	d.Register(v1alpha1.AMFNFType, v1alpha1.UDMNFType)
+	return d
+}
+
+// DefaultDependencyRules are used to order the ordered rollouts. NF types
+// added later should register their dependencies here before the controller
+// is started.
+var DefaultDependencyRules = NewDefaultDependencyRules()
+
+// CycleError is returned when the dependencies between the sites form a cycle
+type CycleError struct {
+	// Sites are the ids of the sites which depend on each other
+	Sites []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("dependency cycle between sites %v", e.Sites)
+}
+
+// ComputeWaves returns the ids of the sites of nfDeploy grouped in waves. The
+// sites of a wave only depend on the sites of the previous waves. The ids in
+// a wave are sorted. Returns a CycleError if the sites depend on each other.
+func ComputeWaves(sites []v1alpha1.Site, rules *DependencyRules) ([][]string, error) {
+	nfTypes := make(map[string]v1alpha1.NFType, len(sites))
+	for _, site := range sites {
+		nfTypes[site.Id] = v1alpha1.NFType(site.NFType)
+	}
+	// dependencies of each site which are not rolled out yet
+	pending := make(map[string]map[string]bool, len(sites))
+	addDependency := func(siteId, dependency string) {
+		if rules.DependsOn(nfTypes[siteId], nfTypes[dependency]) {
+			pending[siteId][dependency] = true
+		}
+	}
+	for _, site := range sites {
+		pending[site.Id] = make(map[string]bool)
+	}
+	for _, site := range sites {
+		for _, connectivity := range site.Connectivities {
+			if _, ok := nfTypes[connectivity.NeighborName]; !ok {
+				return nil, fmt.Errorf(
+					"neighbor %s of site %s is not present", connectivity.NeighborName, site.Id,
+				)
+			}
+			// connectivities are declared on one or both sites
+			addDependency(site.Id, connectivity.NeighborName)
+			addDependency(connectivity.NeighborName, site.Id)
+		}
+	}
+
+	var waves [][]string
+	for len(pending) != 0 {
+		var wave []string
+		for siteId, dependencies := range pending {
+			if len(dependencies) == 0 {
+				wave = append(wave, siteId)
+			}
+		}
+		if len(wave) == 0 {
+			return nil, &CycleError{Sites: cycleSites(pending)}
+		}
+		sort.Strings(wave)
+		for _, siteId := range wave {
+			delete(pending, siteId)
+		}
+		for _, dependencies := range pending {
+			for _, siteId := range wave {
+				delete(dependencies, siteId)
+			}
+		}
+		waves = append(waves, wave)
+	}
+	return waves, nil
+}
+
+// cycleSites returns the sorted ids of the sites on the dependency cycles
+// among the pending sites, i.e. without the sites which only depend on a
+// cycle
+func cycleSites(pending map[string]map[string]bool) []string {
+	remaining := make(map[string]map[string]bool, len(pending))
+	for siteId, dependencies := range pending {
+		remaining[siteId] = dependencies
+	}
+	for {
+		dependents := make(map[string]bool)
+		for _, dependencies := range remaining {
+			for dependency := range dependencies {
+				dependents[dependency] = true
+			}
+		}
+		pruned := false
+		for siteId := range remaining {
+			if !dependents[siteId] {
+				delete(remaining, siteId)
+				pruned = true
+			}
+		}
+		if !pruned {
+			break
+		}
+	}
+	sites := make([]string, 0, len(remaining))
+	for siteId := range remaining {
+		sites = append(sites, siteId)
+	}
+	sort.Strings(sites)
+	return sites
+}
+
+// NewStatus returns the status of the rollout of the waves of nfDeploy up to
+// currentWave
+func NewStatus(
+	nfDeploy v1alpha1.NfDeploy, waves [][]string, currentWave int,
+) *v1alpha1.RolloutStatus {
+	rolloutStatus := &v1alpha1.RolloutStatus{
+		ObservedGeneration: nfDeploy.Generation,
+		CurrentWave:        int32(currentWave),
+	}
+	for _, wave := range waves {
+		rolloutStatus.Waves = append(rolloutStatus.Waves, v1alpha1.RolloutWave{Sites: wave})
+	}
+	return rolloutStatus
+}
+
+// ReleasedSiteIds returns the ids of the sites of the waves up to
+// currentWave
+func ReleasedSiteIds(waves [][]string, currentWave int) []string {
+	var siteIds []string
+	for i := 0; i <= currentWave && i < len(waves); i++ {
+		siteIds = append(siteIds, waves[i]...)
+	}
+	return siteIds
+}
+
+// ReleasedConnectivities returns nfDeploy with all its sites, from which the
+// sites of the waves up to currentWave are hydrated so that their peers and
+// capacities are resolved from the whole site list. In an ordered rollout the
+// connectivities between the released sites and the sites of the later waves
+// are dropped, they are added when those sites are released. The sites of a
+// progressive rollout keep all their connectivities, as their neighbors are
+// usually deployed already with a previous generation.
+func ReleasedConnectivities(
+	nfDeploy v1alpha1.NfDeploy, waves [][]string, currentWave int,
+) v1alpha1.NfDeploy {
+	keepConnectivities := nfDeploy.Spec.Rollout.IsProgressive()
+	released := make(map[string]bool)
+	for _, siteId := range ReleasedSiteIds(waves, currentWave) {
+		released[siteId] = true
+	}
+	result := *nfDeploy.DeepCopy()
+	for i, site := range result.Spec.Sites {
+		connectivities := site.Connectivities
+		result.Spec.Sites[i].Connectivities = nil
+		for _, connectivity := range connectivities {
+			if keepConnectivities || released[connectivity.NeighborName] == released[site.Id] {
+				result.Spec.Sites[i].Connectivities = append(result.Spec.Sites[i].Connectivities,
+					connectivity)
+			}
+		}
+	}
+	return result
+}
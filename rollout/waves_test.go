@@ -0,0 +1,117 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/rollout"
+)
+
+func site(id string, nfType v1alpha1.NFType, neighbors ...string) v1alpha1.Site {
+	s := v1alpha1.Site{Id: id, NFType: string(nfType), ClusterName: "cluster-" + id}
+	for _, neighbor := range neighbors {
+		s.Connectivities = append(s.Connectivities, v1alpha1.Connectivity{NeighborName: neighbor})
+	}
+	return s
+}
+
+var _ = Describe("ComputeWaves", func() {
+	It("Should roll out the control plane before the user plane", func() {
+		sites := []v1alpha1.Site{
+			site("upf-1", v1alpha1.UPFNFType, "smf-1", "upf-2"),
+			site("upf-2", v1alpha1.UPFNFType, "smf-1"),
+			site("smf-1", v1alpha1.SMFNFType, "udm-1"),
+			site("ausf-1", v1alpha1.AUSFNFType, "udm-1"),
+			site("udm-1", v1alpha1.UDMNFType),
+		}
+		waves, err := rollout.ComputeWaves(sites, rollout.NewDefaultDependencyRules())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(waves).To(Equal([][]string{
+			{"udm-1"}, {"ausf-1", "smf-1"}, {"upf-1", "upf-2"},
+		}))
+	})
+
+	It("Should roll out unconnected sites in the first wave", func() {
+		sites := []v1alpha1.Site{
+			site("upf-1", v1alpha1.UPFNFType),
+			site("smf-1", v1alpha1.SMFNFType),
+		}
+		waves, err := rollout.ComputeWaves(sites, rollout.NewDefaultDependencyRules())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(waves).To(Equal([][]string{{"smf-1", "upf-1"}}))
+	})
+
+	It("Should detect dependency cycles", func() {
+		rules := rollout.NewDefaultDependencyRules()
+		rules.Register(v1alpha1.SMFNFType, v1alpha1.UPFNFType)
+		sites := []v1alpha1.Site{
+			site("upf-1", v1alpha1.UPFNFType, "smf-1"),
+			site("smf-1", v1alpha1.SMFNFType, "udm-1"),
+			site("udm-1", v1alpha1.UDMNFType),
+			site("ausf-1", v1alpha1.AUSFNFType, "udm-1"),
+		}
+		_, err := rollout.ComputeWaves(sites, rules)
+		var cycleErr *rollout.CycleError
+		Expect(errors.As(err, &cycleErr)).To(BeTrue())
+		Expect(cycleErr.Sites).To(Equal([]string{"smf-1", "upf-1"}))
+	})
+
+	It("Should fail when a neighbor is not present", func() {
+		_, err := rollout.ComputeWaves(
+			[]v1alpha1.Site{site("upf-1", v1alpha1.UPFNFType, "smf-1")},
+			rollout.NewDefaultDependencyRules(),
+		)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ReleasedConnectivities", func() {
+	It("Should keep only the connectivities between the released sites", func() {
+		nfDeploy := v1alpha1.NfDeploy{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample", Generation: 2},
+			Spec: v1alpha1.NfDeploySpec{
+				Sites: []v1alpha1.Site{
+					site("upf-1", v1alpha1.UPFNFType, "smf-1"),
+					site("smf-1", v1alpha1.SMFNFType, "upf-1"),
+				},
+			},
+		}
+		waves := [][]string{{"smf-1"}, {"upf-1"}}
+		Expect(rollout.ReleasedSiteIds(waves, 0)).To(Equal([]string{"smf-1"}))
+		Expect(rollout.ReleasedSiteIds(waves, 1)).To(Equal([]string{"smf-1", "upf-1"}))
+
+		released := rollout.ReleasedConnectivities(nfDeploy, waves, 0)
+		Expect(released.Spec.Sites).To(HaveLen(2))
+		Expect(released.Spec.Sites[0].Connectivities).To(BeEmpty())
+		Expect(released.Spec.Sites[1].Connectivities).To(BeEmpty())
+		Expect(nfDeploy.Spec.Sites[1].Connectivities).To(HaveLen(1))
+
+		released = rollout.ReleasedConnectivities(nfDeploy, waves, 1)
+		Expect(released.Spec).To(Equal(nfDeploy.Spec))
+
+		rolloutStatus := rollout.NewStatus(nfDeploy, waves, 0)
+		Expect(rolloutStatus.ObservedGeneration).To(Equal(int64(2)))
+		Expect(rolloutStatus.IsComplete()).To(BeFalse())
+		Expect(rollout.NewStatus(nfDeploy, waves, 1).IsComplete()).To(BeTrue())
+	})
+})
@@ -0,0 +1,706 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+)
+
+// HydrationPhase is the phase of the hydration of a generation of NfDeploy
+type HydrationPhase string
+
+const (
+	// Hydrating : the generation is being hydrated into deploy packages
+	Hydrating HydrationPhase = "Hydrating"
+	// AwaitingApproval : the deploy packages of the generation are created and
+	// need to be approved
+	AwaitingApproval HydrationPhase = "AwaitingApproval"
+	// HydrationFailed : the generation could not be hydrated
+	HydrationFailed HydrationPhase = "HydrationFailed"
+	// AwaitingDeletionApproval : NfDeploy is deleted and the deletion of its
+	// deploy packages is proposed and needs to be approved
+	AwaitingDeletionApproval HydrationPhase = "AwaitingDeletionApproval"
+	// Terminating : NfDeploy is deleted, its deploy packages are removed and
+	// the removal of its NFs from the workload clusters is awaited
+	Terminating HydrationPhase = "Terminating"
+)
+
+// HydrationStatus is the hydration-phase input of the NfDeploy status,
+// reported by the reconciler
+type HydrationStatus struct {
+	// Generation of the NfDeploy which is hydrated
+	Generation int64
+	Phase      HydrationPhase
+	// PackageNames are the packages awaiting approval, or awaiting the
+	// approval of their deletion
+	PackageNames []string
+	// Err is the reason of HydrationFailed
+	Err error
+	// Rollout is the progress of the ordered or progressive rollout of the
+	// generation, nil when its sites are rolled out all at once
+	Rollout *v1alpha1.RolloutStatus
+	// Upgrade is the progress of the upgrade of the NF software versions,
+	// nil to keep the recorded one
+	Upgrade *v1alpha1.UpgradeStatus
+	// Termination is the progress of the removal of the NFs while
+	// Terminating
+	Termination *v1alpha1.TerminationStatus
+	// Profiles are the NF profile objects read by the hydration, nil to keep
+	// the recorded ones
+	Profiles *v1alpha1.ProfilesStatus
+}
+
+// RuntimeStatus is the runtime-phase input of the NfDeploy status, computed
+// by the deployment from the status of the NFs on the edge
+type RuntimeStatus struct {
+	// Generation of the NfDeploy the deployment graph is built for
+	Generation   int64
+	AvailableNFs int32
+	ReadyNFs     int32
+	StalledNFs   int32
+	TargetedNFs  int32
+	// Links is the peering status of the connectivities between the sites
+	Links []v1alpha1.NfDeployLink
+	// Conditions are the Reconciling, Stalled, Peering and Ready conditions
+	// aggregated over the NFs
+	Conditions []metav1.Condition
+}
+
+// DriftStatus is the drift input of the NfDeploy status, reported by the
+// drift detector
+type DriftStatus struct {
+	// Generation of the NfDeploy the deploy packages are rendered for
+	Generation int64
+	// Packages are the published deploy packages which drifted, empty when
+	// none did
+	Packages []PackageDrift
+}
+
+// PackageDrift is the drift of the latest published revision of the deploy
+// package of a cluster from the content rendered for NfDeploy
+type PackageDrift struct {
+	ClusterName string
+	// Revision is the name of the published package revision
+	Revision string
+	Files    []FileDrift
+}
+
+// FileDrift is the drift of a file of a deploy package
+type FileDrift struct {
+	// Name of the file in the package
+	Name string
+	// Fields are the paths of the fields which differ, e.g.
+	// spec.capacity.maxSessions
+	Fields []string
+	// Missing is true if the file is rendered but absent from the package
+	Missing bool
+	// Unexpected is true if the file is in the package but not rendered
+	Unexpected bool
+}
+
+// Aggregator is the only writer of the NfDeploy status subresource. It keeps
+// the latest hydration-phase and runtime-phase inputs of every NfDeploy and
+// writes the merge of both, so that neither overwrites the conditions the
+// other one is responsible for.
+type Aggregator interface {
+	// SetHydrationStatus records the hydration status of a NfDeploy and
+	// updates its status. The hydration status of a generation other than
+	// the current one of the NfDeploy is not applied.
+	SetHydrationStatus(
+		ctx context.Context, key types.NamespacedName, hydration HydrationStatus,
+	) error
+	// SetRolloutStatus records the progress of the rollout of the generation
+	// of a NfDeploy whose hydration status was recorded last, and updates its
+	// status. When the hydration status of the generation is not recorded,
+	// e.g. after a restart, its packages are deemed awaiting approval.
+	SetRolloutStatus(
+		ctx context.Context, key types.NamespacedName, rollout v1alpha1.RolloutStatus,
+	) error
+	// SetUpgradeStatus records the progress of the upgrade of the generation
+	// of a NfDeploy whose hydration status was recorded last, and updates its
+	// status, like SetRolloutStatus
+	SetUpgradeStatus(
+		ctx context.Context, key types.NamespacedName, upgrade v1alpha1.UpgradeStatus,
+	) error
+	// SetRuntimeStatus records the runtime status of a NfDeploy and updates
+	// its status
+	SetRuntimeStatus(
+		ctx context.Context, key types.NamespacedName, runtime RuntimeStatus,
+	) error
+	// SetDriftStatus records the drift of the published deploy packages of a
+	// NfDeploy and updates its Drifted condition. The drift of a generation
+	// other than the current one of the NfDeploy is not applied.
+	SetDriftStatus(
+		ctx context.Context, key types.NamespacedName, drift DriftStatus,
+	) error
+	// SetCatalogUpdate records a new revision of a vendor catalog package
+	// propagated to the current generation of a NfDeploy and lists it in its
+	// status, replacing the previous revision of the same package. The
+	// updates propagated to previous generations are dropped.
+	SetCatalogUpdate(
+		ctx context.Context, key types.NamespacedName, update v1alpha1.CatalogUpdate,
+	) error
+	// Forget drops the inputs recorded for a deleted NfDeploy
+	Forget(key types.NamespacedName)
+}
+
+// inputs are the latest status inputs of a NfDeploy
+type inputs struct {
+	// serializes the status writes of the NfDeploy
+	mu        sync.Mutex
+	hydration *HydrationStatus
+	runtime   *RuntimeStatus
+	drift     *DriftStatus
+	catalog   []v1alpha1.CatalogUpdate
+}
+
+type aggregator struct {
+	reader client.Reader
+	writer client.StatusWriter
+	log    logr.Logger
+
+	mu     sync.Mutex
+	inputs map[types.NamespacedName]*inputs
+}
+
+var _ Aggregator = &aggregator{}
+
+// NewAggregator returns an Aggregator writing the status through writer
+func NewAggregator(
+	reader client.Reader, writer client.StatusWriter, log logr.Logger,
+) Aggregator {
+	return &aggregator{
+		reader: reader,
+		writer: writer,
+		log:    log,
+		inputs: make(map[types.NamespacedName]*inputs),
+	}
+}
+
+// SetHydrationStatus implements Aggregator
+func (a *aggregator) SetHydrationStatus(
+	ctx context.Context, key types.NamespacedName, hydration HydrationStatus,
+) error {
+	in := a.getInputs(key)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.hydration = &hydration
+	return a.write(ctx, key, in)
+}
+
+// SetRolloutStatus implements Aggregator
+func (a *aggregator) SetRolloutStatus(
+	ctx context.Context, key types.NamespacedName, rollout v1alpha1.RolloutStatus,
+) error {
+	in := a.getInputs(key)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	hydration := in.recordedHydration(rollout.ObservedGeneration)
+	hydration.Rollout = &rollout
+	in.hydration = &hydration
+	return a.write(ctx, key, in)
+}
+
+// SetUpgradeStatus implements Aggregator
+func (a *aggregator) SetUpgradeStatus(
+	ctx context.Context, key types.NamespacedName, upgrade v1alpha1.UpgradeStatus,
+) error {
+	in := a.getInputs(key)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	hydration := in.recordedHydration(upgrade.ObservedGeneration)
+	hydration.Upgrade = &upgrade
+	in.hydration = &hydration
+	return a.write(ctx, key, in)
+}
+
+// recordedHydration returns the recorded hydration status of the generation,
+// or one awaiting approval when not recorded. Must be called with in.mu held.
+func (in *inputs) recordedHydration(generation int64) HydrationStatus {
+	if in.hydration != nil && in.hydration.Generation == generation {
+		return *in.hydration
+	}
+	return HydrationStatus{Generation: generation, Phase: AwaitingApproval}
+}
+
+// SetRuntimeStatus implements Aggregator
+func (a *aggregator) SetRuntimeStatus(
+	ctx context.Context, key types.NamespacedName, runtime RuntimeStatus,
+) error {
+	in := a.getInputs(key)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.runtime = &runtime
+	return a.write(ctx, key, in)
+}
+
+// SetDriftStatus implements Aggregator
+func (a *aggregator) SetDriftStatus(
+	ctx context.Context, key types.NamespacedName, drift DriftStatus,
+) error {
+	in := a.getInputs(key)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.drift = &drift
+	return a.write(ctx, key, in)
+}
+
+// SetCatalogUpdate implements Aggregator
+func (a *aggregator) SetCatalogUpdate(
+	ctx context.Context, key types.NamespacedName, update v1alpha1.CatalogUpdate,
+) error {
+	in := a.getInputs(key)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.catalog = addCatalogUpdate(in.catalog, update)
+	return a.write(ctx, key, in)
+}
+
+// Forget implements Aggregator
+func (a *aggregator) Forget(key types.NamespacedName) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.inputs, key)
+}
+
+func (a *aggregator) getInputs(key types.NamespacedName) *inputs {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	in, ok := a.inputs[key]
+	if !ok {
+		in = &inputs{}
+		a.inputs[key] = in
+	}
+	return in
+}
+
+// write merges the inputs into the latest NfDeploy status. Must be called
+// with in.mu held.
+func (a *aggregator) write(
+	ctx context.Context, key types.NamespacedName, in *inputs,
+) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var nfDeploy v1alpha1.NfDeploy
+		if err := a.reader.Get(ctx, key, &nfDeploy); err != nil {
+			return err
+		}
+		hydration := in.hydration
+		if hydration != nil && hydration.Generation != nfDeploy.Generation {
+			// a newer generation is being reconciled and sets its own status
+			hydration = nil
+		}
+		Merge(&nfDeploy.Status, hydration, in.runtime)
+		if in.drift != nil && in.drift.Generation == nfDeploy.Generation {
+			setDrift(&nfDeploy.Status, *in.drift)
+		}
+		setCatalogUpdates(&nfDeploy.Status, in.catalog, nfDeploy.Generation)
+		if err := a.writer.Update(ctx, &nfDeploy); err != nil {
+			return fmt.Errorf("error updating NfDeploy status: %w", err)
+		}
+		a.log.V(1).Info("Updated NfDeploy status", "nfDeploy", key.String())
+		return nil
+	})
+}
+
+// Merge sets the status of NfDeploy from the hydration and runtime inputs,
+// either of which can be nil. The NF counters, the links and the Peering and
+// Ready conditions are owned by runtime; Status.ObservedGeneration is owned by
+// hydration. Reconciling and Stalled are owned by hydration while it is in
+// progress or failed, or while the packages await approval and runtime has
+// not reported for the hydrated generation yet. Once it has, the approval
+// message is kept in Reconciling as long as the NFs are reconciling. Peering
+// and Ready already present are never reset by hydration. Reconciling stays
+// True while a rollout has waves left to release, and Stalled is True while
+// it is paused or once the upgrade of the generation is rolled back. The
+// upgrade and termination statuses and the profiles are kept until hydration
+// reports new ones.
+func Merge(
+	status *v1alpha1.NfDeployStatus, hydration *HydrationStatus, runtime *RuntimeStatus,
+) {
+	if runtime != nil {
+		status.AvailableNFs = runtime.AvailableNFs
+		status.ReadyNFs = runtime.ReadyNFs
+		status.StalledNFs = runtime.StalledNFs
+		status.TargetedNFs = runtime.TargetedNFs
+		status.Links = runtime.Links
+		for _, c := range runtime.Conditions {
+			if hydration != nil && ownedByHydration(*hydration, runtime, c) {
+				continue
+			}
+			if hydration != nil && hydration.Phase == AwaitingApproval &&
+				c.Type == string(v1alpha1.DeploymentReconciling) &&
+				c.Status == metav1.ConditionTrue {
+				c.Message = joinMessages(approvalMessage(*hydration), c.Message)
+			}
+			c.ObservedGeneration = runtime.Generation
+			meta.SetStatusCondition(&status.Conditions, c)
+		}
+	}
+	if hydration == nil {
+		return
+	}
+	status.ObservedGeneration = hydration.Generation
+	status.Rollout = hydration.Rollout
+	if hydration.Upgrade != nil {
+		status.Upgrade = hydration.Upgrade
+	}
+	if hydration.Termination != nil {
+		status.Termination = hydration.Termination
+	}
+	if hydration.Profiles != nil {
+		status.Profiles = hydration.Profiles
+	}
+	for _, c := range hydrationConditions(*hydration, runtime) {
+		c.ObservedGeneration = hydration.Generation
+		meta.SetStatusCondition(&status.Conditions, c)
+	}
+	if hydration.Phase != HydrationFailed && !hydration.Rollout.IsComplete() {
+		if hydration.Rollout.Paused {
+			setRolloutPaused(status, *hydration)
+		} else {
+			setRolloutInProgress(status, *hydration)
+		}
+	}
+	if upgrade := hydration.Upgrade; upgrade != nil &&
+		upgrade.Phase == v1alpha1.UpgradeRolledBack &&
+		upgrade.ObservedGeneration == hydration.Generation {
+		setUpgradeRolledBack(status, *hydration)
+	}
+	reason := hydrationReason(hydration.Phase)
+	for _, conditionType := range []v1alpha1.NFDeployConditionType{
+		v1alpha1.DeploymentPeering, v1alpha1.DeploymentReady,
+	} {
+		if meta.FindStatusCondition(status.Conditions, string(conditionType)) != nil {
+			continue
+		}
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               string(conditionType),
+			Status:             metav1.ConditionUnknown,
+			Reason:             reason,
+			ObservedGeneration: hydration.Generation,
+		})
+	}
+}
+
+// ownedByHydration returns true if the runtime condition c is overridden by
+// the hydration status
+func ownedByHydration(
+	hydration HydrationStatus, runtime *RuntimeStatus, c metav1.Condition,
+) bool {
+	if c.Type != string(v1alpha1.DeploymentReconciling) &&
+		c.Type != string(v1alpha1.DeploymentStalled) {
+		return false
+	}
+	if hydration.Phase != AwaitingApproval {
+		return true
+	}
+	return !runtimeTakesOver(hydration, runtime)
+}
+
+// runtimeTakesOver returns true if the runtime status is computed for the
+// deployment graph of the hydrated generation, which is built only after
+// its packages are created
+func runtimeTakesOver(hydration HydrationStatus, runtime *RuntimeStatus) bool {
+	return runtime != nil && runtime.Generation >= hydration.Generation
+}
+
+// hydrationConditions returns the conditions set by the hydration status
+func hydrationConditions(
+	hydration HydrationStatus, runtime *RuntimeStatus,
+) []metav1.Condition {
+	reason := hydrationReason(hydration.Phase)
+	switch hydration.Phase {
+	case HydrationFailed:
+		message := fmt.Errorf("Error Hydrating NfDeploy: %w", hydration.Err).Error()
+		return []metav1.Condition{
+			{
+				Type:    string(v1alpha1.DeploymentReconciling),
+				Status:  metav1.ConditionFalse,
+				Reason:  "Stalled",
+				Message: message,
+			},
+			{
+				Type:    string(v1alpha1.DeploymentStalled),
+				Status:  metav1.ConditionTrue,
+				Reason:  reason,
+				Message: message,
+			},
+		}
+	case AwaitingApproval:
+		if runtimeTakesOver(hydration, runtime) {
+			return nil
+		}
+		return []metav1.Condition{
+			{
+				Type:    string(v1alpha1.DeploymentReconciling),
+				Status:  metav1.ConditionTrue,
+				Reason:  reason,
+				Message: approvalMessage(hydration),
+			},
+			{
+				Type:   string(v1alpha1.DeploymentStalled),
+				Status: metav1.ConditionFalse,
+				Reason: reason,
+			},
+		}
+	case AwaitingDeletionApproval:
+		return []metav1.Condition{
+			{
+				Type:   string(v1alpha1.DeploymentReconciling),
+				Status: metav1.ConditionTrue,
+				Reason: reason,
+				Message: fmt.Sprintf(
+					"The deletion of these porch packages needs to be approved: %v",
+					hydration.PackageNames,
+				),
+			},
+			{
+				Type:   string(v1alpha1.DeploymentStalled),
+				Status: metav1.ConditionFalse,
+				Reason: reason,
+			},
+		}
+	case Terminating:
+		return terminationConditions(hydration)
+	default:
+		return []metav1.Condition{
+			{
+				Type:    string(v1alpha1.DeploymentReconciling),
+				Status:  metav1.ConditionTrue,
+				Reason:  reason,
+				Message: "Reconciling NfDeploy",
+			},
+			{
+				Type:   string(v1alpha1.DeploymentStalled),
+				Status: metav1.ConditionFalse,
+				Reason: reason,
+			},
+		}
+	}
+}
+
+// terminationConditions keeps NfDeploy Reconciling while its NFs are being
+// removed from the workload clusters, and Stalled once the termination timed
+// out
+func terminationConditions(hydration HydrationStatus) []metav1.Condition {
+	sites, clusters := hydration.Termination.TerminatingSites()
+	if hydration.Termination != nil && hydration.Termination.TimedOut {
+		message := fmt.Sprintf(
+			"Clusters %v did not confirm the removal of the NFs of sites %v in time",
+			clusters, sites,
+		)
+		return []metav1.Condition{
+			{
+				Type:    string(v1alpha1.DeploymentReconciling),
+				Status:  metav1.ConditionFalse,
+				Reason:  "TerminationTimedOut",
+				Message: message,
+			},
+			{
+				Type:    string(v1alpha1.DeploymentStalled),
+				Status:  metav1.ConditionTrue,
+				Reason:  "TerminationTimedOut",
+				Message: message,
+			},
+		}
+	}
+	return []metav1.Condition{
+		{
+			Type:   string(v1alpha1.DeploymentReconciling),
+			Status: metav1.ConditionTrue,
+			Reason: "Terminating",
+			Message: fmt.Sprintf(
+				"Waiting for clusters %v to remove the NFs of sites %v", clusters, sites,
+			),
+		},
+		{
+			Type:   string(v1alpha1.DeploymentStalled),
+			Status: metav1.ConditionFalse,
+			Reason: "Terminating",
+		},
+	}
+}
+
+// setRolloutPaused reports the NfDeploy stalled with the reason of the pause
+// of the rollout, which needs a new generation to be resumed
+func setRolloutPaused(status *v1alpha1.NfDeployStatus, hydration HydrationStatus) {
+	message := fmt.Sprintf(
+		"Rollout paused at wave %d of %d: %s", hydration.Rollout.CurrentWave+1,
+		len(hydration.Rollout.Waves), hydration.Rollout.PauseReason,
+	)
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               string(v1alpha1.DeploymentReconciling),
+		Status:             metav1.ConditionFalse,
+		Reason:             "RolloutPaused",
+		Message:            message,
+		ObservedGeneration: hydration.Generation,
+	})
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               string(v1alpha1.DeploymentStalled),
+		Status:             metav1.ConditionTrue,
+		Reason:             "RolloutPaused",
+		Message:            message,
+		ObservedGeneration: hydration.Generation,
+	})
+}
+
+// setUpgradeRolledBack reports the NfDeploy stalled as its generation cannot
+// be reached once its upgrade is rolled back
+func setUpgradeRolledBack(status *v1alpha1.NfDeployStatus, hydration HydrationStatus) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               string(v1alpha1.DeploymentStalled),
+		Status:             metav1.ConditionTrue,
+		Reason:             "UpgradeRolledBack",
+		Message:            hydration.Upgrade.Message,
+		ObservedGeneration: hydration.Generation,
+	})
+}
+
+// setRolloutInProgress keeps Reconciling True until the last wave of the
+// ordered rollout is released, whatever the state of the NFs of the waves
+// released so far
+func setRolloutInProgress(status *v1alpha1.NfDeployStatus, hydration HydrationStatus) {
+	rollout := hydration.Rollout
+	message := fmt.Sprintf(
+		"Rolled out wave %d of %d, waiting for sites %v to be Ready",
+		rollout.CurrentWave+1, len(rollout.Waves), rollout.Waves[rollout.CurrentWave].Sites,
+	)
+	reconciling := meta.FindStatusCondition(
+		status.Conditions, string(v1alpha1.DeploymentReconciling),
+	)
+	if reconciling != nil && reconciling.Status == metav1.ConditionTrue {
+		message = joinMessages(message, reconciling.Message)
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               string(v1alpha1.DeploymentReconciling),
+		Status:             metav1.ConditionTrue,
+		Reason:             "RolloutInProgress",
+		Message:            message,
+		ObservedGeneration: hydration.Generation,
+	})
+}
+
+// setDrift sets the Drifted condition from the drift of the published deploy
+// packages, listing the files and the fields which differ
+func setDrift(status *v1alpha1.NfDeployStatus, drift DriftStatus) {
+	if len(drift.Packages) == 0 {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               string(v1alpha1.DeploymentDrifted),
+			Status:             metav1.ConditionFalse,
+			Reason:             "NoDrift",
+			ObservedGeneration: drift.Generation,
+		})
+		return
+	}
+	packages := make([]string, 0, len(drift.Packages))
+	for _, pkg := range drift.Packages {
+		files := make([]string, 0, len(pkg.Files))
+		for _, file := range pkg.Files {
+			switch {
+			case file.Missing:
+				files = append(files, fmt.Sprintf("%s (missing)", file.Name))
+			case file.Unexpected:
+				files = append(files, fmt.Sprintf("%s (unexpected)", file.Name))
+			default:
+				files = append(files, fmt.Sprintf("%s %v", file.Name, file.Fields))
+			}
+		}
+		packages = append(packages, fmt.Sprintf(
+			"cluster %s revision %s: %s", pkg.ClusterName, pkg.Revision,
+			strings.Join(files, ", "),
+		))
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:   string(v1alpha1.DeploymentDrifted),
+		Status: metav1.ConditionTrue,
+		Reason: "PackagesDrifted",
+		Message: fmt.Sprintf(
+			"The published deploy packages differ from NfDeploy: %s",
+			strings.Join(packages, "; "),
+		),
+		ObservedGeneration: drift.Generation,
+	})
+}
+
+// setCatalogUpdates adds the catalog updates propagated to the generation to
+// the status and drops the ones propagated to previous generations, whose
+// hydration creates the packages from the latest catalog revisions anyway
+func setCatalogUpdates(
+	status *v1alpha1.NfDeployStatus, updates []v1alpha1.CatalogUpdate, generation int64,
+) {
+	var current []v1alpha1.CatalogUpdate
+	for _, update := range append(status.CatalogUpdates, updates...) {
+		if update.ObservedGeneration == generation {
+			current = addCatalogUpdate(current, update)
+		}
+	}
+	status.CatalogUpdates = current
+}
+
+// addCatalogUpdate adds update to updates, replacing the update of the same
+// catalog package
+func addCatalogUpdate(
+	updates []v1alpha1.CatalogUpdate, update v1alpha1.CatalogUpdate,
+) []v1alpha1.CatalogUpdate {
+	for i := range updates {
+		if updates[i].Package == update.Package {
+			updates[i] = update
+			return updates
+		}
+	}
+	return append(updates, update)
+}
+
+func approvalMessage(hydration HydrationStatus) string {
+	return fmt.Sprintf(
+		"These porch packages needs to be approved: %v", hydration.PackageNames,
+	)
+}
+
+func joinMessages(first, second string) string {
+	if second == "" {
+		return first
+	}
+	return fmt.Sprintf("%s; %s", first, second)
+}
+
+func hydrationReason(phase HydrationPhase) string {
+	switch phase {
+	case HydrationFailed:
+		return "HydrationFailure"
+	case AwaitingApproval:
+		return "AwaitingApproval"
+	case AwaitingDeletionApproval:
+		return "AwaitingDeletionApproval"
+	case Terminating:
+		return "Terminating"
+	default:
+		return "NewVersionAvailable"
+	}
+}
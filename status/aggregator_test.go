@@ -0,0 +1,390 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/status"
+)
+
+func runtimeStatus(reconciling, ready metav1.ConditionStatus, reason string) *status.RuntimeStatus {
+	return &status.RuntimeStatus{
+		Generation:  1,
+		ReadyNFs:    1,
+		TargetedNFs: 2,
+		Links: []v1alpha1.NfDeployLink{
+			{FirstSite: "smf-1", SecondSite: "upf-1", Peering: metav1.ConditionTrue},
+		},
+		Conditions: []metav1.Condition{
+			{
+				Type: string(v1alpha1.DeploymentStalled), Status: metav1.ConditionFalse,
+				Reason: reason,
+			},
+			{
+				Type: string(v1alpha1.DeploymentReady), Status: ready, Reason: reason,
+			},
+			{
+				Type: string(v1alpha1.DeploymentPeering), Status: ready, Reason: reason,
+			},
+			{
+				Type: string(v1alpha1.DeploymentReconciling), Status: reconciling,
+				Reason: reason, Message: "1 of 2 NFs reconciling",
+			},
+		},
+	}
+}
+
+func condition(s v1alpha1.NfDeployStatus, t v1alpha1.NFDeployConditionType) *metav1.Condition {
+	return meta.FindStatusCondition(s.Conditions, string(t))
+}
+
+var _ = Describe("Merge", func() {
+	awaitingApproval := &status.HydrationStatus{
+		Generation: 1, Phase: status.AwaitingApproval, PackageNames: []string{"pkg1"},
+	}
+
+	It("Should keep the approval message while the NFs are reconciling", func() {
+		var s v1alpha1.NfDeployStatus
+		status.Merge(&s, awaitingApproval, nil)
+		status.Merge(&s, awaitingApproval,
+			runtimeStatus(metav1.ConditionTrue, metav1.ConditionFalse, "SomeNFsReconciling"))
+
+		reconciling := condition(s, v1alpha1.DeploymentReconciling)
+		Expect(reconciling.Status).To(Equal(metav1.ConditionTrue))
+		Expect(reconciling.Reason).To(Equal("SomeNFsReconciling"))
+		Expect(reconciling.Message).To(ContainSubstring("pkg1"))
+		Expect(reconciling.Message).To(ContainSubstring("1 of 2 NFs reconciling"))
+		Expect(condition(s, v1alpha1.DeploymentReady).Reason).To(Equal("SomeNFsReconciling"))
+		Expect(s.ReadyNFs).To(Equal(int32(1)))
+		Expect(s.ObservedGeneration).To(Equal(int64(1)))
+	})
+
+	It("Should let runtime own Reconciling once the NFs are reconciled", func() {
+		var s v1alpha1.NfDeployStatus
+		status.Merge(&s, awaitingApproval,
+			runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"))
+
+		reconciling := condition(s, v1alpha1.DeploymentReconciling)
+		Expect(reconciling.Status).To(Equal(metav1.ConditionFalse))
+		Expect(reconciling.Reason).To(Equal("AllReady"))
+	})
+
+	It("Should not let runtime of an older generation own Reconciling", func() {
+		var s v1alpha1.NfDeployStatus
+		status.Merge(&s, &status.HydrationStatus{
+			Generation: 2, Phase: status.AwaitingApproval, PackageNames: []string{"pkg1"},
+		}, runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"))
+
+		reconciling := condition(s, v1alpha1.DeploymentReconciling)
+		Expect(reconciling.Status).To(Equal(metav1.ConditionTrue))
+		Expect(reconciling.Reason).To(Equal("AwaitingApproval"))
+		Expect(condition(s, v1alpha1.DeploymentReady).Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should not reset Ready when a new generation is hydrated", func() {
+		var s v1alpha1.NfDeployStatus
+		status.Merge(&s, awaitingApproval,
+			runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"))
+		status.Merge(&s, &status.HydrationStatus{Generation: 2, Phase: status.Hydrating}, nil)
+
+		Expect(condition(s, v1alpha1.DeploymentReady).Status).To(Equal(metav1.ConditionTrue))
+		reconciling := condition(s, v1alpha1.DeploymentReconciling)
+		Expect(reconciling.Status).To(Equal(metav1.ConditionTrue))
+		Expect(reconciling.Reason).To(Equal("NewVersionAvailable"))
+		Expect(reconciling.ObservedGeneration).To(Equal(int64(2)))
+		Expect(s.ObservedGeneration).To(Equal(int64(2)))
+	})
+
+	It("Should keep the hydration failure over the runtime status", func() {
+		var s v1alpha1.NfDeployStatus
+		status.Merge(&s, &status.HydrationStatus{
+			Generation: 1, Phase: status.HydrationFailed, Err: errors.New("porch error"),
+		}, runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"))
+
+		stalled := condition(s, v1alpha1.DeploymentStalled)
+		Expect(stalled.Status).To(Equal(metav1.ConditionTrue))
+		Expect(stalled.Reason).To(Equal("HydrationFailure"))
+		Expect(stalled.Message).To(ContainSubstring("porch error"))
+		Expect(condition(s, v1alpha1.DeploymentReconciling).Status).
+			To(Equal(metav1.ConditionFalse))
+		Expect(condition(s, v1alpha1.DeploymentReady).Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should keep Reconciling while the deletion of the packages awaits approval", func() {
+		var s v1alpha1.NfDeployStatus
+		status.Merge(&s, &status.HydrationStatus{
+			Generation: 1, Phase: status.AwaitingDeletionApproval, PackageNames: []string{"pkg1"},
+		}, runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"))
+
+		reconciling := condition(s, v1alpha1.DeploymentReconciling)
+		Expect(reconciling.Status).To(Equal(metav1.ConditionTrue))
+		Expect(reconciling.Reason).To(Equal("AwaitingDeletionApproval"))
+		Expect(reconciling.Message).To(ContainSubstring("pkg1"))
+		Expect(condition(s, v1alpha1.DeploymentStalled).Status).
+			To(Equal(metav1.ConditionFalse))
+	})
+
+	It("Should report the NFs terminating and the termination timed out", func() {
+		termination := &v1alpha1.TerminationStatus{
+			Sites: []v1alpha1.SiteTermination{
+				{Site: "upf-1", ClusterName: "edge-1", Phase: v1alpha1.SiteTerminating},
+				{Site: "smf-1", ClusterName: "core", Phase: v1alpha1.SiteRemoved},
+			},
+		}
+		var s v1alpha1.NfDeployStatus
+		status.Merge(&s, &status.HydrationStatus{
+			Generation: 1, Phase: status.Terminating, Termination: termination,
+		}, runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"))
+
+		Expect(s.Termination).To(Equal(termination))
+		reconciling := condition(s, v1alpha1.DeploymentReconciling)
+		Expect(reconciling.Status).To(Equal(metav1.ConditionTrue))
+		Expect(reconciling.Reason).To(Equal("Terminating"))
+		Expect(reconciling.Message).To(ContainSubstring("[edge-1]"))
+		Expect(reconciling.Message).To(ContainSubstring("[upf-1]"))
+
+		termination.TimedOut = true
+		status.Merge(&s, &status.HydrationStatus{
+			Generation: 1, Phase: status.Terminating, Termination: termination,
+		}, runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"))
+		stalled := condition(s, v1alpha1.DeploymentStalled)
+		Expect(stalled.Status).To(Equal(metav1.ConditionTrue))
+		Expect(stalled.Reason).To(Equal("TerminationTimedOut"))
+		Expect(stalled.Message).To(ContainSubstring("[edge-1]"))
+		Expect(condition(s, v1alpha1.DeploymentReconciling).Status).
+			To(Equal(metav1.ConditionFalse))
+	})
+
+	It("Should keep Reconciling while an ordered rollout has waves left", func() {
+		rollout := &v1alpha1.RolloutStatus{
+			ObservedGeneration: 1,
+			Waves: []v1alpha1.RolloutWave{
+				{Sites: []string{"smf-1"}}, {Sites: []string{"upf-1"}},
+			},
+		}
+		var s v1alpha1.NfDeployStatus
+		status.Merge(&s, &status.HydrationStatus{
+			Generation: 1, Phase: status.AwaitingApproval, Rollout: rollout,
+		}, runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"))
+
+		Expect(s.Rollout).To(Equal(rollout))
+		reconciling := condition(s, v1alpha1.DeploymentReconciling)
+		Expect(reconciling.Status).To(Equal(metav1.ConditionTrue))
+		Expect(reconciling.Reason).To(Equal("RolloutInProgress"))
+		Expect(reconciling.Message).To(ContainSubstring("wave 1 of 2"))
+
+		rollout.CurrentWave = 1
+		status.Merge(&s, &status.HydrationStatus{
+			Generation: 1, Phase: status.AwaitingApproval, Rollout: rollout,
+		}, runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"))
+		Expect(condition(s, v1alpha1.DeploymentReconciling).Status).
+			To(Equal(metav1.ConditionFalse))
+	})
+
+	It("Should keep the recorded profiles until hydration reports new ones", func() {
+		profiles := &v1alpha1.ProfilesStatus{
+			Revision: "nf-profiles-v1",
+			Objects:  []v1alpha1.ProfileObject{{Kind: "UpfType", Name: "upf-1", Hash: "3b1f"}},
+		}
+		var s v1alpha1.NfDeployStatus
+		status.Merge(&s, &status.HydrationStatus{
+			Generation: 1, Phase: status.AwaitingApproval, Profiles: profiles,
+		}, nil)
+		Expect(s.Profiles).To(Equal(profiles))
+
+		status.Merge(&s, &status.HydrationStatus{Generation: 2, Phase: status.Hydrating}, nil)
+		Expect(s.Profiles).To(Equal(profiles))
+	})
+})
+
+var _ = Describe("Aggregator", func() {
+	var k8sClient client.Client
+	var aggregator status.Aggregator
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "nfdeploy1"}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&v1alpha1.NfDeploy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: key.Namespace, Name: key.Name, Generation: 2,
+				},
+			},
+		).Build()
+		aggregator = status.NewAggregator(k8sClient, k8sClient.Status(), logr.Discard())
+	})
+
+	It("Should merge the runtime status into the hydration status", func() {
+		Expect(aggregator.SetHydrationStatus(ctx, key, status.HydrationStatus{
+			Generation: 2, Phase: status.AwaitingApproval, PackageNames: []string{"pkg1"},
+		})).To(Succeed())
+		Expect(aggregator.SetRuntimeStatus(ctx, key,
+			*runtimeStatus(metav1.ConditionTrue, metav1.ConditionFalse, "SomeNFsReconciling"),
+		)).To(Succeed())
+
+		var nfDeploy v1alpha1.NfDeploy
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		Expect(nfDeploy.Status.ObservedGeneration).To(Equal(int64(2)))
+		Expect(nfDeploy.Status.TargetedNFs).To(Equal(int32(2)))
+		Expect(nfDeploy.Status.Links).To(HaveLen(1))
+		Expect(condition(nfDeploy.Status, v1alpha1.DeploymentReconciling).Reason).
+			To(Equal("AwaitingApproval"))
+		Expect(nfDeploy.Status.Conditions).To(HaveLen(4))
+	})
+
+	It("Should not apply the hydration status of an older generation", func() {
+		Expect(aggregator.SetHydrationStatus(ctx, key, status.HydrationStatus{
+			Generation: 1, Phase: status.Hydrating,
+		})).To(Succeed())
+
+		var nfDeploy v1alpha1.NfDeploy
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		Expect(nfDeploy.Status.ObservedGeneration).To(BeZero())
+		Expect(nfDeploy.Status.Conditions).To(BeEmpty())
+	})
+
+	It("Should report the rollout paused", func() {
+		Expect(aggregator.SetHydrationStatus(ctx, key, status.HydrationStatus{
+			Generation: 2, Phase: status.AwaitingApproval, PackageNames: []string{"pkg1"},
+		})).To(Succeed())
+		Expect(aggregator.SetRolloutStatus(ctx, key, v1alpha1.RolloutStatus{
+			ObservedGeneration: 2,
+			Waves: []v1alpha1.RolloutWave{
+				{Sites: []string{"upf-1"}}, {Sites: []string{"upf-2"}},
+			},
+			Paused:      true,
+			PauseReason: "sites [upf-1] are Stalled",
+		})).To(Succeed())
+
+		var nfDeploy v1alpha1.NfDeploy
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		Expect(nfDeploy.Status.Rollout.Paused).To(BeTrue())
+		stalled := condition(nfDeploy.Status, v1alpha1.DeploymentStalled)
+		Expect(stalled.Status).To(Equal(metav1.ConditionTrue))
+		Expect(stalled.Reason).To(Equal("RolloutPaused"))
+		Expect(stalled.Message).To(ContainSubstring("upf-1"))
+		reconciling := condition(nfDeploy.Status, v1alpha1.DeploymentReconciling)
+		Expect(reconciling.Status).To(Equal(metav1.ConditionFalse))
+		Expect(reconciling.Reason).To(Equal("RolloutPaused"))
+	})
+
+	It("Should report the upgrade and keep it over later hydration statuses", func() {
+		Expect(aggregator.SetHydrationStatus(ctx, key, status.HydrationStatus{
+			Generation: 2, Phase: status.AwaitingApproval, PackageNames: []string{"pkg1"},
+		})).To(Succeed())
+		Expect(aggregator.SetUpgradeStatus(ctx, key, v1alpha1.UpgradeStatus{
+			ObservedGeneration: 2,
+			Phase:              v1alpha1.UpgradeRolledBack,
+			Message:            "Rolled back the upgrade of sites [upf-1]",
+		})).To(Succeed())
+
+		var nfDeploy v1alpha1.NfDeploy
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		Expect(nfDeploy.Status.Upgrade.Phase).To(Equal(v1alpha1.UpgradeRolledBack))
+		stalled := condition(nfDeploy.Status, v1alpha1.DeploymentStalled)
+		Expect(stalled.Status).To(Equal(metav1.ConditionTrue))
+		Expect(stalled.Reason).To(Equal("UpgradeRolledBack"))
+		Expect(condition(nfDeploy.Status, v1alpha1.DeploymentReconciling).Message).
+			To(ContainSubstring("pkg1"))
+
+		Expect(aggregator.SetRuntimeStatus(ctx, key,
+			*runtimeStatus(metav1.ConditionFalse, metav1.ConditionTrue, "AllReady"),
+		)).To(Succeed())
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		Expect(nfDeploy.Status.Upgrade.Phase).To(Equal(v1alpha1.UpgradeRolledBack))
+	})
+
+	It("Should report the drift of the published packages of the current generation", func() {
+		Expect(aggregator.SetDriftStatus(ctx, key, status.DriftStatus{
+			Generation: 2,
+			Packages: []status.PackageDrift{
+				{
+					ClusterName: "cluster1",
+					Revision:    "deployPkg1",
+					Files: []status.FileDrift{
+						{Name: "upf.yaml", Fields: []string{"spec.capacity.maxSessions"}},
+						{Name: "smf.yaml", Missing: true},
+					},
+				},
+			},
+		})).To(Succeed())
+
+		var nfDeploy v1alpha1.NfDeploy
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		drifted := condition(nfDeploy.Status, v1alpha1.DeploymentDrifted)
+		Expect(drifted.Status).To(Equal(metav1.ConditionTrue))
+		Expect(drifted.Reason).To(Equal("PackagesDrifted"))
+		Expect(drifted.Message).To(ContainSubstring("upf.yaml [spec.capacity.maxSessions]"))
+		Expect(drifted.Message).To(ContainSubstring("smf.yaml (missing)"))
+
+		Expect(aggregator.SetDriftStatus(ctx, key, status.DriftStatus{Generation: 1})).
+			To(Succeed())
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		Expect(condition(nfDeploy.Status, v1alpha1.DeploymentDrifted).Status).
+			To(Equal(metav1.ConditionTrue))
+
+		Expect(aggregator.SetDriftStatus(ctx, key, status.DriftStatus{Generation: 2})).
+			To(Succeed())
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		drifted = condition(nfDeploy.Status, v1alpha1.DeploymentDrifted)
+		Expect(drifted.Status).To(Equal(metav1.ConditionFalse))
+		Expect(drifted.Reason).To(Equal("NoDrift"))
+	})
+
+	It("Should list the catalog updates propagated to the current generation", func() {
+		update := v1alpha1.CatalogUpdate{
+			Package:            "casa/1.0/upf/actuators",
+			Revision:           "catalog-v2",
+			PackageNames:       []string{"actuators-v2"},
+			ObservedGeneration: 2,
+		}
+		Expect(aggregator.SetCatalogUpdate(ctx, key, update)).To(Succeed())
+		Expect(aggregator.SetCatalogUpdate(ctx, key, v1alpha1.CatalogUpdate{
+			Package: "casa/1.0/upf/extension", Revision: "catalog-v1", ObservedGeneration: 1,
+		})).To(Succeed())
+		update.Revision = "catalog-v3"
+		update.PackageNames = []string{"actuators-v3"}
+		Expect(aggregator.SetCatalogUpdate(ctx, key, update)).To(Succeed())
+
+		var nfDeploy v1alpha1.NfDeploy
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		Expect(nfDeploy.Status.CatalogUpdates).To(Equal([]v1alpha1.CatalogUpdate{update}))
+
+		nfDeploy.Generation = 3
+		Expect(k8sClient.Update(ctx, &nfDeploy)).To(Succeed())
+		Expect(aggregator.SetHydrationStatus(ctx, key, status.HydrationStatus{
+			Generation: 3, Phase: status.Hydrating,
+		})).To(Succeed())
+		Expect(k8sClient.Get(ctx, key, &nfDeploy)).To(Succeed())
+		Expect(nfDeploy.Status.CatalogUpdates).To(BeEmpty())
+	})
+})
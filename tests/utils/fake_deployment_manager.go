@@ -18,11 +18,12 @@ package utils
 
 import (
 	"github.com/go-logr/logr"
+	nfdeploytypes "github.com/nephio-project/common-lib/nfdeploy"
 	edgewatcher "github.com/nephio-project/edge-watcher"
 	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 	"github.com/nephio-project/nf-deploy-controller/deployment"
+	"github.com/nephio-project/nf-deploy-controller/status"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // FakeDeploymentManager : Implements Deployment Manager interface
@@ -34,7 +35,7 @@ type FakeDeploymentManager struct {
 
 // NewFakeDeploymentManager : Returns new FakeDeploymentManager
 func NewFakeDeploymentManager(
-	reader client.Reader, writer client.StatusWriter, log logr.Logger,
+	statusAggregator status.Aggregator, log logr.Logger,
 ) FakeDeploymentManager {
 
 	subscriptionReq := make(chan *edgewatcher.SubscriptionReq)
@@ -43,7 +44,8 @@ func NewFakeDeploymentManager(
 		SignalChan: make(chan error),
 		DeploymentManager: deployment.NewDeploymentManager(
 			&FakeCRDSet{},
-			subscriptionReq, cancellationReq, reader, writer, log,
+			subscriptionReq, cancellationReq, statusAggregator,
+			deployment.Options{}, log,
 		), SubscriptionReqChan: subscriptionReq,
 	}
 }
@@ -69,3 +71,15 @@ func (fakeDeploymentManager *FakeDeploymentManager) ReportNFDeployDeleteEvent(
 		deploy,
 	)
 }
+
+func (fakeDeploymentManager *FakeDeploymentManager) GetNFStates(
+	deploy nfdeployv1alpha1.NfDeploy,
+) map[string]nfdeploytypes.NFConditionType {
+	return fakeDeploymentManager.DeploymentManager.GetNFStates(deploy)
+}
+
+func (fakeDeploymentManager *FakeDeploymentManager) GetTerminatingNFs(
+	deploy nfdeployv1alpha1.NfDeploy,
+) ([]string, bool) {
+	return fakeDeploymentManager.DeploymentManager.GetTerminatingNFs(deploy)
+}
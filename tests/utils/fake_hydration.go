@@ -19,21 +19,61 @@ package utils
 import (
 	"context"
 	"errors"
+	"sync"
 
 	deployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
 	"github.com/nephio-project/nf-deploy-controller/hydration"
 )
 
 type FakeHydration struct {
+	mu sync.Mutex
+	// hydrations are the number of Hydrate calls by NfDeploy name
+	hydrations map[string]int
 }
 
 func (fakeHydration *FakeHydration) Hydrate(
 	ctx context.Context, nfDeploy deployv1alpha1.NfDeploy,
-) ([]string, error) {
+) ([]string, *deployv1alpha1.ProfilesStatus, error) {
+	fakeHydration.mu.Lock()
+	if fakeHydration.hydrations == nil {
+		fakeHydration.hydrations = map[string]int{}
+	}
+	fakeHydration.hydrations[nfDeploy.Name]++
+	fakeHydration.mu.Unlock()
 	if nfDeploy.Name == "hydration-failed" {
-		return nil, errors.New("error from porch")
+		return nil, nil, errors.New("error from porch")
+	}
+	if nfDeploy.Name == "profiles-changed" {
+		// the object is not in the nf-profiles package of FakePackageService
+		return []string{"resourceName"}, &deployv1alpha1.ProfilesStatus{
+			Revision: "nf-profiles-v1",
+			Objects: []deployv1alpha1.ProfileObject{
+				{Kind: "UpfCapacityProfile", Name: "small", Hash: "hash"},
+			},
+		}, nil
 	}
-	return []string{"resourceName"}, nil
+	return []string{"resourceName"}, &deployv1alpha1.ProfilesStatus{}, nil
+}
+
+func (fakeHydration *FakeHydration) HydrateSites(
+	ctx context.Context, nfDeploy deployv1alpha1.NfDeploy, siteIds []string,
+) ([]string, *deployv1alpha1.ProfilesStatus, error) {
+	return fakeHydration.Hydrate(ctx, nfDeploy)
+}
+
+// Hydrations returns the number of Hydrate and HydrateSites calls for the
+// NfDeploy named name
+func (fakeHydration *FakeHydration) Hydrations(name string) int {
+	fakeHydration.mu.Lock()
+	defer fakeHydration.mu.Unlock()
+	return fakeHydration.hydrations[name]
+}
+
+func (fakeHydration *FakeHydration) Render(
+	ctx context.Context, nfDeploy deployv1alpha1.NfDeploy,
+) (map[string]map[string]string, error) {
+	// implement this method when required
+	return map[string]map[string]string{}, nil
 }
 
 func (fakeHydration *FakeHydration) CreateNFDeployActuators(
@@ -45,4 +85,10 @@ func (fakeHydration *FakeHydration) CreateNFDeployActuators(
 	return []string{"operator-resourceName"}, nil
 }
 
+func (fakeHydration *FakeHydration) ReleaseAllocations(
+	ctx context.Context, nfDeploy deployv1alpha1.NfDeploy,
+) error {
+	return nil
+}
+
 var _ hydration.HydrationInterface = &FakeHydration{}
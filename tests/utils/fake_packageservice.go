@@ -34,6 +34,13 @@ func (fakeps *FakePackageService) GetNFProfiles(ctx context.Context,
 	return nil, nil
 }
 
+func (fakeps *FakePackageService) GetNFProfilesPackage(ctx context.Context,
+	nc util.NamingContext,
+	revision string) (string, map[string]string, error) {
+	// implement this method when required
+	return "", map[string]string{}, nil
+}
+
 func (fakeps *FakePackageService) CreateDeployPackage(ctx context.Context,
 	contents map[string]string, nc util.NamingContext) (string, error) {
 
@@ -51,6 +58,18 @@ func (fakeps *FakePackageService) DeleteDeployPackage(ctx context.Context,
 	return nil
 }
 
+func (fakeps *FakePackageService) OrphanDeployPackage(ctx context.Context,
+	nc util.NamingContext) error {
+	// implement this method when required
+	return nil
+}
+
+func (fakeps *FakePackageService) ProposeDeployPackageDeletion(ctx context.Context,
+	nc util.NamingContext) ([]string, error) {
+	// implement this method when required
+	return nil, nil
+}
+
 func (fakeps *FakePackageService) CreateNFDeployActuators(ctx context.Context,
 	nc util.NamingContext,
 	key ps.VendorNFKey) (string, bool, error) {
@@ -65,4 +84,30 @@ func (fakeps *FakePackageService) GetVendorExtensionPackage(ctx context.Context,
 	return []string{}, nil
 }
 
+func (fakeps *FakePackageService) GetDeployPackageRevision(ctx context.Context,
+	nc util.NamingContext) (string, error) {
+	// implement this method when required
+	return "", nil
+}
+
+func (fakeps *FakePackageService) GetNFDeployActuatorsRevision(ctx context.Context,
+	nc util.NamingContext,
+	key ps.VendorNFKey) (string, error) {
+	// implement this method when required
+	return "", nil
+}
+
+func (fakeps *FakePackageService) GetPublishedDeployPackage(ctx context.Context,
+	nc util.NamingContext) (string, map[string]string, bool, error) {
+	// implement this method when required
+	return "", nil, false, nil
+}
+
+func (fakeps *FakePackageService) RestorePackageRevision(ctx context.Context,
+	nc util.NamingContext,
+	revision string) (string, error) {
+	// implement this method when required
+	return "", nil
+}
+
 var _ ps.PackageServiceInterface = &FakePackageService{}
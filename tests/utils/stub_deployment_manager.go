@@ -0,0 +1,112 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync"
+
+	nfdeploytypes "github.com/nephio-project/common-lib/nfdeploy"
+	nfdeployv1alpha1 "github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/deployment"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// StubDeploymentManager : Implements Deployment Manager interface with the
+// NF states and the terminating NFs set by the tests instead of computed from
+// edge events
+type StubDeploymentManager struct {
+	mu             sync.Mutex
+	nfStates       map[string]nfdeploytypes.NFConditionType
+	terminatingNFs []string
+	present        bool
+	reported       int
+	blocking       chan struct{}
+}
+
+var _ deployment.DeploymentManager = &StubDeploymentManager{}
+
+// SetNFStates : Sets the states returned by GetNFStates
+func (stub *StubDeploymentManager) SetNFStates(states map[string]nfdeploytypes.NFConditionType) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	stub.nfStates = states
+}
+
+// SetTerminatingNFs : Sets the sites returned by GetTerminatingNFs. The
+// deployment is reported absent when present is false.
+func (stub *StubDeploymentManager) SetTerminatingNFs(sites []string, present bool) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	stub.terminatingNFs = sites
+	stub.present = present
+}
+
+// Reported : Returns the number of ReportNFDeployEvent calls
+func (stub *StubDeploymentManager) Reported() int {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	return stub.reported
+}
+
+// BlockReports : Makes ReportNFDeployEvent block, as while it listens to
+// the edge events of a new deployment, until ReleaseReports is called
+func (stub *StubDeploymentManager) BlockReports() {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	stub.blocking = make(chan struct{})
+}
+
+// ReleaseReports : Returns from the blocked ReportNFDeployEvent calls
+func (stub *StubDeploymentManager) ReleaseReports() {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	close(stub.blocking)
+	stub.blocking = nil
+}
+
+func (stub *StubDeploymentManager) ReportNFDeployEvent(
+	deploy nfdeployv1alpha1.NfDeploy, namespacedName types.NamespacedName,
+) {
+	stub.mu.Lock()
+	stub.reported++
+	blocking := stub.blocking
+	stub.mu.Unlock()
+	if blocking != nil {
+		<-blocking
+	}
+}
+
+func (stub *StubDeploymentManager) ReportNFDeployDeleteEvent(
+	deploy nfdeployv1alpha1.NfDeploy,
+) {
+}
+
+func (stub *StubDeploymentManager) GetNFStates(
+	deploy nfdeployv1alpha1.NfDeploy,
+) map[string]nfdeploytypes.NFConditionType {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	return stub.nfStates
+}
+
+func (stub *StubDeploymentManager) GetTerminatingNFs(
+	deploy nfdeployv1alpha1.NfDeploy,
+) ([]string, bool) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	return stub.terminatingNFs, stub.present
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	nfdeploytypes "github.com/nephio-project/common-lib/nfdeploy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+)
+
+// MaxRollbackHistory is the number of rollbacks kept in the upgrade status
+const MaxRollbackHistory = 10
+
+// Versions returns the NF software version of every site of nfDeploy, sorted
+// by site id
+func Versions(nfDeploy v1alpha1.NfDeploy) []v1alpha1.SiteVersion {
+	versions := make([]v1alpha1.SiteVersion, 0, len(nfDeploy.Spec.Sites))
+	for _, site := range nfDeploy.Spec.Sites {
+		versions = append(versions, v1alpha1.SiteVersion{Site: site.Id, Version: site.NFVersion})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Site < versions[j].Site })
+	return versions
+}
+
+// UpgradedSites returns the sites of nfDeploy whose NF software version
+// differs from the version they are deployed with. New sites are not
+// upgraded.
+func UpgradedSites(nfDeploy v1alpha1.NfDeploy, deployed []v1alpha1.SiteVersion) []v1alpha1.SiteUpgrade {
+	deployedVersions := make(map[string]string, len(deployed))
+	for _, version := range deployed {
+		deployedVersions[version.Site] = version.Version
+	}
+	var sites []v1alpha1.SiteUpgrade
+	for _, version := range Versions(nfDeploy) {
+		from, ok := deployedVersions[version.Site]
+		if !ok || from == version.Version {
+			continue
+		}
+		sites = append(sites, v1alpha1.SiteUpgrade{
+			Site: version.Site, FromVersion: from, ToVersion: version.Version,
+		})
+	}
+	return sites
+}
+
+// Hydrated returns the upgrade status once the packages of the generation of
+// nfDeploy are created. The upgrade of the sites is in progress from now on,
+// the previous revisions of their packages being recorded. Without upgraded
+// sites only the versions and the rollback history are kept.
+func Hydrated(
+	previous *v1alpha1.UpgradeStatus, nfDeploy v1alpha1.NfDeploy, sites []v1alpha1.SiteUpgrade,
+	previousRevisions []v1alpha1.PackageRevisionRef, now time.Time,
+) *v1alpha1.UpgradeStatus {
+	upgrade := &v1alpha1.UpgradeStatus{}
+	if previous != nil {
+		upgrade = previous.DeepCopy()
+	}
+	if len(sites) == 0 && upgrade.ObservedGeneration == nfDeploy.Generation {
+		// the generation is hydrated again, e.g. after a restart
+		return upgrade
+	}
+	upgrade.Versions = Versions(nfDeploy)
+	upgrade.ObservedGeneration = nfDeploy.Generation
+	if len(sites) == 0 {
+		// only the rollback history is kept
+		upgrade.Phase = ""
+		upgrade.StartTime = nil
+		upgrade.Sites = nil
+		upgrade.PreviousRevisions = nil
+		upgrade.Message = ""
+		return upgrade
+	}
+	startTime := metav1.NewTime(now)
+	upgrade.Phase = v1alpha1.UpgradeProgressing
+	upgrade.StartTime = &startTime
+	upgrade.Sites = sites
+	upgrade.PreviousRevisions = previousRevisions
+	upgrade.Message = fmt.Sprintf("Upgrading sites %v", siteIds(sites))
+	return upgrade
+}
+
+// Check returns the phase of the upgrade in progress given the state of the
+// NFs, and why when it is to be rolled back. The upgrade succeeds once the
+// packages of the new versions are published and the NFs of all the upgraded
+// sites are Ready. It is rolled back as soon as an NF of an upgraded site is
+// Stalled, or when the progress deadline passes.
+func Check(
+	upgrade v1alpha1.UpgradeStatus, states map[string]nfdeploytypes.NFConditionType,
+	published bool, now time.Time, deadline time.Duration,
+) (v1alpha1.UpgradePhase, string) {
+	var stalled, notReady []string
+	for _, site := range upgrade.Sites {
+		switch states[site.Site] {
+		case nfdeploytypes.Stalled:
+			stalled = append(stalled, site.Site)
+		case nfdeploytypes.Ready:
+		default:
+			notReady = append(notReady, site.Site)
+		}
+	}
+	if len(stalled) != 0 {
+		return v1alpha1.UpgradeRolledBack, fmt.Sprintf("sites %v are Stalled", stalled)
+	}
+	if published && len(notReady) == 0 {
+		return v1alpha1.UpgradeSucceeded, ""
+	}
+	if upgrade.StartTime != nil && now.Sub(upgrade.StartTime.Time) > deadline {
+		if !published {
+			return v1alpha1.UpgradeRolledBack, fmt.Sprintf(
+				"progress deadline of %v exceeded, packages not published", deadline)
+		}
+		return v1alpha1.UpgradeRolledBack, fmt.Sprintf(
+			"progress deadline of %v exceeded, sites %v are not Ready", deadline, notReady)
+	}
+	return v1alpha1.UpgradeProgressing, ""
+}
+
+// Succeeded returns the upgrade status once the NFs of the upgraded sites are
+// Ready
+func Succeeded(upgrade v1alpha1.UpgradeStatus) *v1alpha1.UpgradeStatus {
+	result := upgrade.DeepCopy()
+	result.Phase = v1alpha1.UpgradeSucceeded
+	result.Message = fmt.Sprintf("Upgraded sites %v", siteIds(upgrade.Sites))
+	return result
+}
+
+// RolledBack returns the upgrade status once the packages of the upgraded
+// sites are reverted to their previous revisions. The sites are deemed
+// deployed with their previous version, and the rollback is added to the
+// history.
+func RolledBack(upgrade v1alpha1.UpgradeStatus, reason string, now time.Time) *v1alpha1.UpgradeStatus {
+	result := upgrade.DeepCopy()
+	result.Phase = v1alpha1.UpgradeRolledBack
+	result.Message = fmt.Sprintf("Rolled back the upgrade of sites %v: %s", siteIds(upgrade.Sites), reason)
+	previousVersions := make(map[string]string, len(upgrade.Sites))
+	for _, site := range upgrade.Sites {
+		previousVersions[site.Site] = site.FromVersion
+	}
+	for i, version := range result.Versions {
+		if from, ok := previousVersions[version.Site]; ok {
+			result.Versions[i].Version = from
+		}
+	}
+	result.RollbackHistory = append(result.RollbackHistory, v1alpha1.UpgradeRollback{
+		Generation:        upgrade.ObservedGeneration,
+		Time:              metav1.NewTime(now),
+		Reason:            reason,
+		Sites:             append([]v1alpha1.SiteUpgrade(nil), upgrade.Sites...),
+		RestoredRevisions: append([]v1alpha1.PackageRevisionRef(nil), upgrade.PreviousRevisions...),
+	})
+	if len(result.RollbackHistory) > MaxRollbackHistory {
+		result.RollbackHistory = result.RollbackHistory[len(result.RollbackHistory)-MaxRollbackHistory:]
+	}
+	return result
+}
+
+func siteIds(sites []v1alpha1.SiteUpgrade) []string {
+	ids := make([]string, 0, len(sites))
+	for _, site := range sites {
+		ids = append(ids, site.Site)
+	}
+	return ids
+}
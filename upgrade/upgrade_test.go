@@ -0,0 +1,136 @@
+/*
+Copyright 2022-2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade_test
+
+import (
+	"time"
+
+	nfdeploytypes "github.com/nephio-project/common-lib/nfdeploy"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nephio-project/nf-deploy-controller/api/v1alpha1"
+	"github.com/nephio-project/nf-deploy-controller/upgrade"
+)
+
+var _ = Describe("Upgrade", func() {
+	var nfDeploy v1alpha1.NfDeploy
+	now := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	revisions := []v1alpha1.PackageRevisionRef{{ClusterName: "edge-1", Name: "edge-1-deploy-v1"}}
+
+	BeforeEach(func() {
+		nfDeploy = v1alpha1.NfDeploy{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample", Generation: 3},
+			Spec: v1alpha1.NfDeploySpec{
+				Sites: []v1alpha1.Site{
+					{Id: "upf-1", NFType: "upf", ClusterName: "edge-1", NFVersion: "1.1"},
+					{Id: "smf-1", NFType: "smf", ClusterName: "core", NFVersion: "2.0"},
+					{Id: "upf-2", NFType: "upf", ClusterName: "edge-2", NFVersion: "1.1"},
+				},
+			},
+		}
+	})
+
+	It("Should find the sites whose version changed", func() {
+		deployed := []v1alpha1.SiteVersion{
+			{Site: "smf-1", Version: "2.0"}, {Site: "upf-1", Version: "1.0"},
+		}
+		Expect(upgrade.UpgradedSites(nfDeploy, deployed)).To(Equal([]v1alpha1.SiteUpgrade{
+			{Site: "upf-1", FromVersion: "1.0", ToVersion: "1.1"},
+		}))
+	})
+
+	It("Should record the versions without upgrade on the first hydration", func() {
+		status := upgrade.Hydrated(nil, nfDeploy, nil, nil, now)
+		Expect(status.Phase).To(BeEmpty())
+		Expect(status.ObservedGeneration).To(Equal(int64(3)))
+		Expect(status.Versions).To(Equal([]v1alpha1.SiteVersion{
+			{Site: "smf-1", Version: "2.0"},
+			{Site: "upf-1", Version: "1.1"},
+			{Site: "upf-2", Version: "1.1"},
+		}))
+	})
+
+	Context("with an upgrade in progress", func() {
+		var status *v1alpha1.UpgradeStatus
+		deadline := 10 * time.Minute
+
+		BeforeEach(func() {
+			status = upgrade.Hydrated(&v1alpha1.UpgradeStatus{}, nfDeploy,
+				[]v1alpha1.SiteUpgrade{{Site: "upf-1", FromVersion: "1.0", ToVersion: "1.1"}},
+				revisions, now)
+		})
+
+		It("Should be progressing until the NFs are Ready", func() {
+			Expect(status.Phase).To(Equal(v1alpha1.UpgradeProgressing))
+			Expect(status.PreviousRevisions).To(Equal(revisions))
+			phase, _ := upgrade.Check(*status, map[string]nfdeploytypes.NFConditionType{
+				"upf-1": nfdeploytypes.Reconciling,
+			}, true, now.Add(time.Minute), deadline)
+			Expect(phase).To(Equal(v1alpha1.UpgradeProgressing))
+		})
+
+		It("Should not succeed until the packages are published", func() {
+			states := map[string]nfdeploytypes.NFConditionType{"upf-1": nfdeploytypes.Ready}
+			phase, _ := upgrade.Check(*status, states, false, now.Add(time.Minute), deadline)
+			Expect(phase).To(Equal(v1alpha1.UpgradeProgressing))
+			phase, _ = upgrade.Check(*status, states, true, now.Add(time.Minute), deadline)
+			Expect(phase).To(Equal(v1alpha1.UpgradeSucceeded))
+		})
+
+		It("Should roll back when an NF is Stalled", func() {
+			phase, reason := upgrade.Check(*status, map[string]nfdeploytypes.NFConditionType{
+				"upf-1": nfdeploytypes.Stalled,
+			}, true, now.Add(time.Minute), deadline)
+			Expect(phase).To(Equal(v1alpha1.UpgradeRolledBack))
+			Expect(reason).To(ContainSubstring("upf-1"))
+		})
+
+		It("Should roll back when the progress deadline passes", func() {
+			phase, reason := upgrade.Check(*status, map[string]nfdeploytypes.NFConditionType{},
+				true, now.Add(11*time.Minute), deadline)
+			Expect(phase).To(Equal(v1alpha1.UpgradeRolledBack))
+			Expect(reason).To(ContainSubstring("progress deadline"))
+		})
+
+		It("Should restore the previous versions and record the rollback", func() {
+			rolledBack := upgrade.RolledBack(*status, "sites [upf-1] are Stalled", now)
+			Expect(rolledBack.Phase).To(Equal(v1alpha1.UpgradeRolledBack))
+			Expect(rolledBack.Versions).To(ContainElement(v1alpha1.SiteVersion{Site: "upf-1", Version: "1.0"}))
+			Expect(rolledBack.Versions).To(ContainElement(v1alpha1.SiteVersion{Site: "upf-2", Version: "1.1"}))
+			Expect(rolledBack.RollbackHistory).To(HaveLen(1))
+			Expect(rolledBack.RollbackHistory[0].Generation).To(Equal(int64(3)))
+			Expect(rolledBack.RollbackHistory[0].RestoredRevisions).To(Equal(revisions))
+			Expect(status.Phase).To(Equal(v1alpha1.UpgradeProgressing))
+
+			// the next generation keeps the history only
+			nfDeploy.Generation = 4
+			next := upgrade.Hydrated(rolledBack, nfDeploy, nil, nil, now)
+			Expect(next.Phase).To(BeEmpty())
+			Expect(next.PreviousRevisions).To(BeEmpty())
+			Expect(next.RollbackHistory).To(HaveLen(1))
+		})
+
+		It("Should keep a bounded rollback history", func() {
+			for i := 0; i < upgrade.MaxRollbackHistory+2; i++ {
+				status = upgrade.RolledBack(*status, "deadline", now)
+			}
+			Expect(status.RollbackHistory).To(HaveLen(upgrade.MaxRollbackHistory))
+		})
+	})
+})
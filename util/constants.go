@@ -20,4 +20,7 @@ const (
 	NFSiteIDLabel = "nephio.org/nf-site-id"
 	NFDeployLabel = "nephio.org/nf-deploy2"
 	NFTypeLabel   = "nephio.org/nf-type"
+	// NFDeployGenerationAnnotation is the generation of the NfDeploy an
+	// NfTypeDeploy is hydrated from
+	NFDeployGenerationAnnotation = "nephio.org/nf-deploy-generation"
 )